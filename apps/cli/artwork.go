@@ -0,0 +1,93 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/lobinuxsoft/capydeploy/internal/shortcuts"
+	"github.com/lobinuxsoft/capydeploy/pkg/steam"
+)
+
+// applyArtwork copies local images into the grid folder of every Steam user on
+// the device, named for the shortcut's app ID
+func applyArtwork(args []string) error {
+	fs, deviceName := newFlagSet("artwork apply")
+	appID := fs.Uint("appid", 0, "App ID of the shortcut (required)")
+	files := map[steam.ArtworkType]*string{
+		steam.ArtworkPortrait: fs.String("capsule", "", "600x900 portrait grid image"),
+		steam.ArtworkGrid:     fs.String("wide", "", "920x430 landscape grid image"),
+		steam.ArtworkHero:     fs.String("hero", "", "1920x620 hero image"),
+		steam.ArtworkLogo:     fs.String("logo", "", "Logo with transparency"),
+		steam.ArtworkIcon:     fs.String("icon", "", "Square icon"),
+	}
+	noRestart := fs.Bool("no-restart", false, "Don't restart Steam to reload the artwork")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *appID == 0 {
+		return fmt.Errorf("--appid is required")
+	}
+
+	// Read every image before connecting, so a bad path changes nothing
+	images := make(map[string][]byte)
+	for artType, file := range files {
+		if *file == "" {
+			continue
+		}
+		ext := strings.ToLower(strings.TrimPrefix(filepath.Ext(*file), "."))
+		if !isImageExt(ext) {
+			return fmt.Errorf("%s is not a supported image (png, jpg, webp, gif or ico)", *file)
+		}
+		data, err := os.ReadFile(*file)
+		if err != nil {
+			return err
+		}
+		images[steam.ArtworkFilename(uint32(*appID), artType, ext)] = data
+	}
+	if len(images) == 0 {
+		return fmt.Errorf("no images given, use --capsule, --wide, --hero, --logo or --icon")
+	}
+
+	deviceCfg, err := findDevice(*deviceName)
+	if err != nil {
+		return err
+	}
+	client, err := connect(deviceCfg)
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+
+	gridDirs, err := shortcuts.GridDirs(client)
+	if err != nil {
+		return failed(exitShortcut, err)
+	}
+	for _, gridDir := range gridDirs {
+		if err := client.MkdirAll(gridDir); err != nil {
+			return failed(exitTransfer, err)
+		}
+		for filename, data := range images {
+			if err := client.WriteFile(path.Join(gridDir, filename), data, 0644); err != nil {
+				return failed(exitTransfer, err)
+			}
+		}
+	}
+
+	if !*noRestart {
+		shortcuts.RefreshSteamLibrary(remoteConfig(client, deviceCfg))
+	}
+
+	printResult(fmt.Sprintf("Applied %d image(s) to app ID %d", len(images), *appID), map[string]any{"appId": *appID, "images": len(images)})
+	return nil
+}
+
+func isImageExt(ext string) bool {
+	switch ext {
+	case "png", "jpg", "jpeg", "webp", "gif", "ico":
+		return true
+	}
+	return false
+}
@@ -0,0 +1,151 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"strings"
+
+	"github.com/lobinuxsoft/capydeploy/internal/deploy"
+	"github.com/lobinuxsoft/capydeploy/pkg/artwork"
+	"github.com/lobinuxsoft/capydeploy/pkg/config"
+)
+
+// stageExitCodes maps the stage a deploy failed at to the exit code of the command
+var stageExitCodes = map[deploy.Stage]int{
+	deploy.StageConnection:   exitConnection,
+	deploy.StageBuild:        exitError,
+	deploy.StageTransfer:     exitTransfer,
+	deploy.StagePostDeploy:   exitPostDeploy,
+	deploy.StageShortcut:     exitShortcut,
+	deploy.StageVerification: exitVerification,
+}
+
+// deploySetup uploads a saved game setup to the device the way the Hub does,
+// checks the files that were sent and creates its shortcuts
+func deploySetup(args []string) error {
+	fs, deviceName := newFlagSet("deploy")
+	setupName := fs.String("setup", "", "Name or ID of the saved game setup (required)")
+	full := fs.Bool("full", false, "Upload every file, not only the changed ones")
+	noVerify := fs.Bool("no-verify", false, "Don't check the uploaded files on the device")
+	noRestart := fs.Bool("no-restart", false, "Don't restart Steam to reload the library")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *setupName == "" {
+		return fmt.Errorf("--setup is required")
+	}
+
+	setup, err := findSetup(*setupName)
+	if err != nil {
+		return err
+	}
+	if err := checkSupported(setup); err != nil {
+		return err
+	}
+
+	deviceCfg, err := findDevice(*deviceName)
+	if err != nil {
+		return err
+	}
+	printProgress(0, "Connecting to "+deviceCfg.Host+"...", nil)
+	client, err := connect(deviceCfg)
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	deployer := &deploy.Deployer{
+		Client: client,
+		Device: deviceCfg,
+		Progress: func(p deploy.Progress) {
+			printProgress(p.Progress, p.Status, p.Transfer)
+		},
+		// The output of the post-deploy command goes with the progress, off stdout
+		PostDeployOutput: func(string) io.WriteCloser { return nopCloser{os.Stderr} },
+	}
+	result, err := deployer.Run(ctx, setup, deploy.Options{
+		Delta:            !setup.FullUpload && !*full,
+		Verify:           !*noVerify,
+		KeepSteamRunning: *noRestart,
+	})
+	if err != nil {
+		var deployErr *deploy.Error
+		if errors.As(err, &deployErr) {
+			return failed(stageExitCodes[deployErr.Stage], err)
+		}
+		return err
+	}
+
+	for _, verb := range result.Verbs {
+		if !verb.OK {
+			printWarning(fmt.Sprintf("Prefix verb %s not installed: %s", verb.Verb, verb.Error))
+		}
+	}
+	message := fmt.Sprintf("Deployed %s with app ID %d: %d files uploaded, %d already on the device",
+		setup.DeployName(), result.AppID, result.Uploaded, result.Unchanged)
+	if result.Removed > 0 {
+		message += fmt.Sprintf(", %d no longer in the build removed", result.Removed)
+	}
+	printResult(message, map[string]any{
+		"appId":     result.AppID,
+		"path":      result.GamePath,
+		"uploaded":  result.Uploaded,
+		"unchanged": result.Unchanged,
+		"removed":   result.Removed,
+		"total":     result.Total,
+		"retried":   result.Retried,
+		"verbs":     result.Verbs,
+	})
+	return nil
+}
+
+// findSetup returns the saved game setup with this ID or name
+func findSetup(nameOrID string) (*config.GameSetup, error) {
+	setups, err := config.GetGameSetups()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load game setups: %w", err)
+	}
+	for i := range setups {
+		if setups[i].ID == nameOrID || strings.EqualFold(setups[i].Name, nameOrID) {
+			return &setups[i], nil
+		}
+	}
+	return nil, fmt.Errorf("game setup %q not found", nameOrID)
+}
+
+// checkSupported refuses setups that only the Hub deploys as configured:
+// deploying them from here would leave a different game on the device
+func checkSupported(setup *config.GameSetup) error {
+	if setup.Build != nil {
+		return fmt.Errorf("%s has a build step, deploy it from the Hub", setup.Name)
+	}
+	for _, url := range []string{setup.GridPortrait, setup.GridLandscape, setup.HeroImage, setup.LogoImage, setup.IconImage} {
+		if artwork.IsGenerated(url) {
+			return fmt.Errorf("%s has artwork generated in the Hub, deploy it from the Hub", setup.Name)
+		}
+	}
+	return nil
+}
+
+// printWarning reports something the command skipped without failing
+func printWarning(message string) {
+	if jsonOutput {
+		printEvent("warning", map[string]any{"message": message})
+		return
+	}
+	fmt.Fprintln(os.Stderr, "Warning: "+message)
+}
+
+// nopCloser is a writer with nothing to close
+type nopCloser struct {
+	io.Writer
+}
+
+func (nopCloser) Close() error { return nil }
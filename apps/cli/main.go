@@ -0,0 +1,161 @@
+// Package main provides the CapyDeploy command line tool.
+// It deploys game setups and manages the Steam shortcuts and artwork of the
+// devices saved in the Hub, for scripted workflows that don't use the GUI.
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/lobinuxsoft/capydeploy/internal/device"
+	"github.com/lobinuxsoft/capydeploy/internal/shortcuts"
+	"github.com/lobinuxsoft/capydeploy/pkg/config"
+)
+
+// Version is set at build time.
+var Version = "dev"
+
+const usage = `Usage: capydeploy <command> [flags]
+
+Commands:
+  deploy             Upload a saved game setup and create its shortcuts
+  shortcuts list     List the Steam shortcuts on the device
+  shortcuts add      Add a shortcut
+  shortcuts remove   Remove a shortcut by name
+  shortcuts update   Change the executable, start directory or launch options of a shortcut
+  artwork apply      Copy local images as the artwork of a shortcut
+  version            Print the version
+
+Devices are the ones saved in the Hub. Pass --device with a name or host when
+more than one is saved. Run "capydeploy <command> -h" for the flags of a command.
+
+With --json, progress and the result are printed as JSON lines on stdout.
+A failed command exits with a code telling what failed:
+  1  Other errors, e.g. a bad flag or an unknown device
+  3  Connection: the device couldn't be reached or its host key isn't trusted
+  4  Transfer: files couldn't be written to the device
+  5  Shortcut: Steam shortcuts couldn't be read or changed
+  6  Verification: files on the device don't match the build
+  7  Post-deploy: the setup's post-deploy command failed
+`
+
+func main() {
+	err := run(os.Args[1:])
+	if errors.Is(err, flag.ErrHelp) {
+		return
+	}
+	if err != nil {
+		printError(err)
+		os.Exit(exitCode(err))
+	}
+}
+
+func run(args []string) error {
+	if len(args) == 0 {
+		fmt.Fprint(os.Stderr, usage)
+		os.Exit(exitUsage)
+	}
+
+	command := args[0]
+	if len(args) > 1 && (command == "shortcuts" || command == "artwork") {
+		command += " " + args[1]
+		args = args[1:]
+	}
+
+	switch command {
+	case "deploy":
+		return deploySetup(args[1:])
+	case "shortcuts list":
+		return listShortcuts(args[1:])
+	case "shortcuts add":
+		return addShortcut(args[1:])
+	case "shortcuts remove":
+		return removeShortcut(args[1:])
+	case "shortcuts update":
+		return updateShortcut(args[1:])
+	case "artwork apply":
+		return applyArtwork(args[1:])
+	case "version":
+		fmt.Println(Version)
+		return nil
+	case "help", "-h", "--help":
+		fmt.Print(usage)
+		return nil
+	}
+	return fmt.Errorf("unknown command %q, run \"capydeploy help\"", command)
+}
+
+// newFlagSet creates the flags of a command, all of which select a device
+// with --device and print JSON with --json
+func newFlagSet(command string) (*flag.FlagSet, *string) {
+	fs := flag.NewFlagSet("capydeploy "+command, flag.ContinueOnError)
+	deviceName := fs.String("device", "", "Saved device name or host (default: the only saved device)")
+	fs.BoolVar(&jsonOutput, "json", false, "Print progress and the result as JSON lines")
+	return fs, deviceName
+}
+
+// findDevice returns the saved device with this name or host. An empty name
+// picks the only saved device.
+func findDevice(nameOrHost string) (config.DeviceConfig, error) {
+	devices, err := config.GetDevices()
+	if err != nil {
+		return config.DeviceConfig{}, fmt.Errorf("failed to load devices: %w", err)
+	}
+
+	if nameOrHost == "" {
+		switch len(devices) {
+		case 0:
+			return config.DeviceConfig{}, fmt.Errorf("no devices saved, add one in the Hub first")
+		case 1:
+			return devices[0], nil
+		}
+		return config.DeviceConfig{}, fmt.Errorf("%d devices saved, choose one with --device", len(devices))
+	}
+
+	for _, d := range devices {
+		if strings.EqualFold(d.Name, nameOrHost) || d.Host == nameOrHost {
+			return d, nil
+		}
+	}
+	return config.DeviceConfig{}, fmt.Errorf("device %q not found", nameOrHost)
+}
+
+// connect opens an SSH connection to a saved device, whose host key must be
+// one trusted in the Hub
+func connect(deviceCfg config.DeviceConfig) (*device.Client, error) {
+	client, err := device.NewClient(deviceCfg.Host, deviceCfg.Port, deviceCfg.User, deviceCfg.Password, deviceCfg.KeyFile)
+	if err != nil {
+		return nil, failed(exitConnection, err)
+	}
+	knownHosts, err := config.KnownHostsPath()
+	if err != nil {
+		return nil, fmt.Errorf("failed to locate known hosts: %w", err)
+	}
+	client.SetKnownHosts(knownHosts)
+	perf, _ := config.GetPerformanceSettings()
+	client.SetMaxPacket(perf.ChunkSizeKB * 1024)
+	client.SetUploadLimit(int64(perf.UploadLimitKBps) * 1024)
+	if err := client.Connect(); err != nil {
+		var unknown *device.UnknownHostKeyError
+		var changed *device.HostKeyChangedError
+		if errors.As(err, &unknown) || errors.As(err, &changed) {
+			err = fmt.Errorf("%w\nConnect to the device from the Hub to check its fingerprint and trust it", err)
+		}
+		return nil, failed(exitConnection, err)
+	}
+	return client, nil
+}
+
+// remoteConfig converts a device config into shortcut manager connection
+// settings, going through the verified connection of client
+func remoteConfig(client *device.Client, deviceCfg config.DeviceConfig) *shortcuts.RemoteConfig {
+	return &shortcuts.RemoteConfig{
+		User:     deviceCfg.User,
+		Password: deviceCfg.Password,
+		KeyFile:  deviceCfg.KeyFile,
+		Device:   client,
+	}
+}
@@ -0,0 +1,120 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/lobinuxsoft/capydeploy/pkg/transfer"
+)
+
+// Exit codes of a failed command, by what failed
+const (
+	exitError        = 1 // anything else, e.g. a bad flag or an unknown device
+	exitUsage        = 2 // no command given
+	exitConnection   = 3 // the device couldn't be reached or its host key isn't trusted
+	exitTransfer     = 4 // files couldn't be written to the device
+	exitShortcut     = 5 // Steam shortcuts couldn't be read or changed
+	exitVerification = 6 // files on the device don't match the build
+	exitPostDeploy   = 7 // the post-deploy command of the setup failed
+)
+
+// failureClass names what failed in JSON output, e.g. "connection"
+var failureClass = map[int]string{
+	exitError:        "error",
+	exitConnection:   "connection",
+	exitTransfer:     "transfer",
+	exitShortcut:     "shortcut",
+	exitVerification: "verification",
+	exitPostDeploy:   "post-deploy",
+}
+
+// commandError is the error of a failed command along with its exit code
+type commandError struct {
+	code int
+	err  error
+}
+
+func (e *commandError) Error() string { return e.err.Error() }
+func (e *commandError) Unwrap() error { return e.err }
+
+// failed tags err with the exit code of what failed, nil stays nil
+func failed(code int, err error) error {
+	if err == nil {
+		return nil
+	}
+	return &commandError{code: code, err: err}
+}
+
+// exitCode returns the exit code of a failed command
+func exitCode(err error) int {
+	var cmdErr *commandError
+	if errors.As(err, &cmdErr) {
+		return cmdErr.code
+	}
+	return exitError
+}
+
+// jsonOutput prints progress and results as JSON lines on stdout instead of
+// text, set by --json
+var jsonOutput bool
+
+// printEvent writes one JSON line with the event name and fields
+func printEvent(event string, fields map[string]any) {
+	line := map[string]any{"event": event}
+	for k, v := range fields {
+		line[k] = v
+	}
+	data, _ := json.Marshal(line)
+	fmt.Println(string(data))
+}
+
+// lastStatus is the last status printed as text
+var lastStatus string
+
+// printProgress reports a step of a running command. Text output shows each
+// new status once, on stderr so it stays apart from the result.
+func printProgress(progress float64, status string, stats *transfer.Stats) {
+	if jsonOutput {
+		fields := map[string]any{"progress": progress, "status": status}
+		if stats != nil {
+			fields["transfer"] = stats
+		}
+		printEvent("progress", fields)
+		return
+	}
+	if status != lastStatus {
+		lastStatus = status
+		fmt.Fprintln(os.Stderr, status)
+	}
+}
+
+// printResult reports a command that succeeded: message as text, or a result
+// event with the fields
+func printResult(message string, fields map[string]any) {
+	if !jsonOutput {
+		fmt.Println(message)
+		return
+	}
+	result := map[string]any{"ok": true, "message": message}
+	for k, v := range fields {
+		result[k] = v
+	}
+	printEvent("result", result)
+}
+
+// printError reports a command that failed, with the class of its exit code
+func printError(err error) {
+	code := exitCode(err)
+	if !jsonOutput {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return
+	}
+	printEvent("result", map[string]any{
+		"ok":       false,
+		"error":    err.Error(),
+		"class":    failureClass[code],
+		"exitCode": code,
+	})
+}
@@ -0,0 +1,217 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/lobinuxsoft/capydeploy/internal/shortcuts"
+)
+
+// listShortcuts prints the shortcuts on the device, once per app ID even when
+// several Steam users have them
+func listShortcuts(args []string) error {
+	fs, deviceName := newFlagSet("shortcuts list")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	deviceCfg, err := findDevice(*deviceName)
+	if err != nil {
+		return err
+	}
+	client, err := connect(deviceCfg)
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+	list, err := shortcuts.ListShortcuts(remoteConfig(client, deviceCfg))
+	if err != nil {
+		return failed(exitShortcut, err)
+	}
+
+	if jsonOutput {
+		type listedShortcut struct {
+			AppID         uint32 `json:"appId"`
+			Name          string `json:"name"`
+			Exe           string `json:"exe"`
+			StartDir      string `json:"startDir"`
+			LaunchOptions string `json:"launchOptions"`
+		}
+		listed := []listedShortcut{}
+		seen := make(map[int64]bool)
+		for _, sc := range list {
+			if !seen[sc.AppID] {
+				seen[sc.AppID] = true
+				listed = append(listed, listedShortcut{
+					AppID:         uint32(sc.AppID),
+					Name:          sc.Name,
+					Exe:           unquote(sc.Exe),
+					StartDir:      unquote(sc.StartDir),
+					LaunchOptions: sc.LaunchOptions,
+				})
+			}
+		}
+		printResult(fmt.Sprintf("%d shortcuts", len(listed)), map[string]any{"shortcuts": listed})
+		return nil
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "APP ID\tNAME\tEXECUTABLE\tLAUNCH OPTIONS")
+	seen := make(map[int64]bool)
+	for _, sc := range list {
+		if seen[sc.AppID] {
+			continue
+		}
+		seen[sc.AppID] = true
+		fmt.Fprintf(w, "%d\t%s\t%s\t%s\n", uint32(sc.AppID), sc.Name, unquote(sc.Exe), sc.LaunchOptions)
+	}
+	return w.Flush()
+}
+
+// addShortcut adds a shortcut for every Steam user and prints its app ID
+func addShortcut(args []string) error {
+	fs, deviceName := newFlagSet("shortcuts add")
+	name := fs.String("name", "", "Name shown in Steam (required)")
+	exe := fs.String("exe", "", "Executable path on the device (required)")
+	startDir := fs.String("start-dir", "", "Working directory (default: the executable's directory)")
+	launchOptions := fs.String("launch-options", "", "Launch options, e.g. \"gamemoderun %command%\"")
+	tags := fs.String("tags", "", "Comma-separated Steam collections")
+	noRestart := fs.Bool("no-restart", false, "Don't restart Steam to reload the library")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *name == "" || *exe == "" {
+		return fmt.Errorf("--name and --exe are required")
+	}
+	if *startDir == "" {
+		*startDir = path.Dir(*exe)
+	}
+
+	deviceCfg, err := findDevice(*deviceName)
+	if err != nil {
+		return err
+	}
+	client, err := connect(deviceCfg)
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+	remoteCfg := remoteConfig(client, deviceCfg)
+	if err := shortcuts.AddShortcut(remoteCfg, *name, *exe, *startDir, *launchOptions, shortcuts.ParseTags(*tags)); err != nil {
+		return failed(exitShortcut, err)
+	}
+	if !*noRestart {
+		shortcuts.RefreshSteamLibrary(remoteCfg)
+	}
+
+	appID := uint32(shortcuts.ShortcutAppID(*exe, *name))
+	printResult(fmt.Sprintf("Added %q with app ID %d", *name, appID), map[string]any{"appId": appID})
+	return nil
+}
+
+// removeShortcut removes the shortcut with this name for every Steam user
+func removeShortcut(args []string) error {
+	fs, deviceName := newFlagSet("shortcuts remove")
+	name := fs.String("name", "", "Name of the shortcut (required)")
+	noRestart := fs.Bool("no-restart", false, "Don't restart Steam to reload the library")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *name == "" {
+		return fmt.Errorf("--name is required")
+	}
+
+	deviceCfg, err := findDevice(*deviceName)
+	if err != nil {
+		return err
+	}
+	client, err := connect(deviceCfg)
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+	remoteCfg := remoteConfig(client, deviceCfg)
+	if err := shortcuts.RemoveShortcut(remoteCfg, *name); err != nil {
+		return failed(exitShortcut, err)
+	}
+	if !*noRestart {
+		shortcuts.RefreshSteamLibrary(remoteCfg)
+	}
+
+	printResult(fmt.Sprintf("Removed %q", *name), nil)
+	return nil
+}
+
+// updateShortcut changes the flags that were given and keeps the rest of the
+// shortcut as it is
+func updateShortcut(args []string) error {
+	fs, deviceName := newFlagSet("shortcuts update")
+	appID := fs.Uint("appid", 0, "App ID of the shortcut (required)")
+	exe := fs.String("exe", "", "New executable path on the device")
+	startDir := fs.String("start-dir", "", "New working directory")
+	launchOptions := fs.String("launch-options", "", "New launch options, empty clears them")
+	noRestart := fs.Bool("no-restart", false, "Don't restart Steam to reload the library")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *appID == 0 {
+		return fmt.Errorf("--appid is required")
+	}
+	set := make(map[string]bool)
+	fs.Visit(func(f *flag.Flag) { set[f.Name] = true })
+
+	deviceCfg, err := findDevice(*deviceName)
+	if err != nil {
+		return err
+	}
+	client, err := connect(deviceCfg)
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+	remoteCfg := remoteConfig(client, deviceCfg)
+
+	list, err := shortcuts.ListShortcuts(remoteCfg)
+	if err != nil {
+		return failed(exitShortcut, err)
+	}
+	var current *shortcuts.ShortcutInfo
+	for i := range list {
+		if uint32(list[i].AppID) == uint32(*appID) {
+			current = &list[i]
+			break
+		}
+	}
+	if current == nil {
+		return failed(exitShortcut, fmt.Errorf("no shortcut with app ID %d found", *appID))
+	}
+
+	if !set["exe"] {
+		*exe = unquote(current.Exe)
+	}
+	if !set["start-dir"] {
+		*startDir = unquote(current.StartDir)
+	}
+	if !set["launch-options"] {
+		*launchOptions = current.LaunchOptions
+	}
+
+	if err := shortcuts.UpdateShortcut(remoteCfg, current.AppID, *exe, *startDir, *launchOptions); err != nil {
+		return failed(exitShortcut, err)
+	}
+	if !*noRestart {
+		shortcuts.RefreshSteamLibrary(remoteCfg)
+	}
+
+	printResult(fmt.Sprintf("Updated %q", current.Name), map[string]any{"appId": uint32(current.AppID)})
+	return nil
+}
+
+// unquote removes the quotes Steam keeps around shortcut paths
+func unquote(s string) string {
+	return strings.Trim(s, "\"")
+}
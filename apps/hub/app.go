@@ -3,24 +3,29 @@ package main
 import (
 	"context"
 	"encoding/base64"
+	"errors"
 	"fmt"
-	"io"
+	"log/slog"
 	"net"
 	"net/http"
 	"os"
-	"path"
-	"path/filepath"
 	"strings"
 	"sync"
 	"time"
 
 	"github.com/wailsapp/wails/v2/pkg/runtime"
 
+	"github.com/lobinuxsoft/capydeploy/internal/deploy"
 	"github.com/lobinuxsoft/capydeploy/internal/device"
-	"github.com/lobinuxsoft/capydeploy/internal/embedded"
 	"github.com/lobinuxsoft/capydeploy/internal/shortcuts"
+	"github.com/lobinuxsoft/capydeploy/internal/tray"
+	"github.com/lobinuxsoft/capydeploy/pkg/artwork"
 	"github.com/lobinuxsoft/capydeploy/pkg/config"
+	"github.com/lobinuxsoft/capydeploy/pkg/engine"
+	"github.com/lobinuxsoft/capydeploy/pkg/logging"
+	"github.com/lobinuxsoft/capydeploy/pkg/protocol"
 	"github.com/lobinuxsoft/capydeploy/pkg/steamgriddb"
+	"github.com/lobinuxsoft/capydeploy/pkg/transfer"
 )
 
 // App struct holds the application state
@@ -28,6 +33,32 @@ type App struct {
 	ctx             context.Context
 	connectedDevice *ConnectedDevice
 	mu              sync.RWMutex
+	imageCache      *steamgriddb.ImageCache
+	imageFetcher    *steamgriddb.ImageFetcher
+	igdb            *artwork.IGDBProvider
+	igdbKey         string
+	results         *steamgriddb.ResultStore
+	gameNames       map[int]string
+	offline         bool
+	generated       *artwork.GeneratedStore
+	logs            *logging.Recent
+	logFile         *logging.RotatingFile
+	uploadStatus    *UploadProgress // latest progress of the running or last deploy
+	uploadJob       *UploadJob      // setup and devices of the running or last deploy
+	deployLog       *os.File        // transfer log of the running deploy
+	update          *UpdateInfo     // newer release found by the last update check
+	screenshot      []byte          // last screenshot captured from the device, PNG
+	screenshotAt    time.Time
+	automation      *http.Server
+	cancelUpload    context.CancelFunc // stops the running deploy
+	cancelPerf      context.CancelFunc // stops the performance monitor
+	cancelLogStream context.CancelFunc // stops the live game log
+	cancelWatch     context.CancelFunc // stops the watch mode
+	watchStatus     WatchStatus
+	tray            tray.Tray // nil when the desktop has no system tray
+	quitting        bool      // set when quitting from the tray, skips closing to it
+	// Host keys of devices waiting for the user to trust them, by host
+	hostKeys map[string]pendingHostKey
 }
 
 // ConnectedDevice represents a connected device with its client
@@ -51,19 +82,24 @@ type NetworkDevice struct {
 	HasSSH   bool   `json:"hasSSH"`
 }
 
-// InstalledGame represents a game installed on the remote device
-type InstalledGame struct {
-	Name string `json:"name"`
-	Path string `json:"path"`
-	Size string `json:"size"`
-}
-
 // UploadProgress represents upload progress data
 type UploadProgress struct {
 	Progress float64 `json:"progress"`
 	Status   string  `json:"status"`
 	Error    string  `json:"error,omitempty"`
 	Done     bool    `json:"done"`
+	// Per slot result of checking the applied artwork on the device
+	Artwork []ArtworkCheck `json:"artwork,omitempty"`
+	// Set when the automation API launched the game after the deploy
+	Launched bool `json:"launched,omitempty"`
+	// Per verb result of installing the setup's prefix verbs
+	Verbs []deploy.VerbResult `json:"verbs,omitempty"`
+	// Bytes sent, speed and time left while the files upload
+	Transfer *transfer.Stats `json:"transfer,omitempty"`
+	// Protocol error code of a failed deploy, e.g. protocol.ErrCodeDiskFull
+	ErrorCode string `json:"errorCode,omitempty"`
+	// Files that uploaded only after retries, with their retries
+	Retried []string `json:"retried,omitempty"`
 }
 
 // NewApp creates a new App application struct
@@ -74,6 +110,11 @@ func NewApp() *App {
 // startup is called when the app starts
 func (a *App) startup(ctx context.Context) {
 	a.ctx = ctx
+	a.initLogging()
+	a.initImageCache()
+	a.startAutomationAPI()
+	a.startTray()
+	go a.checkUpdatesOnStartup()
 }
 
 // shutdown is called when the app is closing
@@ -83,6 +124,15 @@ func (a *App) shutdown(ctx context.Context) {
 	if a.connectedDevice != nil && a.connectedDevice.Client != nil {
 		a.connectedDevice.Client.Close()
 	}
+	if a.logFile != nil {
+		a.logFile.Close()
+	}
+	if a.automation != nil {
+		a.automation.Close()
+	}
+	if a.tray != nil {
+		a.tray.Close()
+	}
 }
 
 // =============================================================================
@@ -138,6 +188,8 @@ func (a *App) ConnectDevice(host string) error {
 	}
 
 	// Disconnect existing connection
+	a.StopPerformanceMonitor()
+	a.StopGameLogStream()
 	a.mu.Lock()
 	if a.connectedDevice != nil && a.connectedDevice.Client != nil {
 		a.connectedDevice.Client.Close()
@@ -146,12 +198,13 @@ func (a *App) ConnectDevice(host string) error {
 	a.mu.Unlock()
 
 	// Create and connect client
-	client, err := device.NewClient(deviceCfg.Host, deviceCfg.Port, deviceCfg.User, deviceCfg.Password, deviceCfg.KeyFile)
+	client, err := newDeviceClient(*deviceCfg)
 	if err != nil {
 		return fmt.Errorf("failed to create client: %w", err)
 	}
 
 	if err := client.Connect(); err != nil {
+		a.recordHostKey(deviceCfg.Host, err)
 		return fmt.Errorf("connection failed: %w", err)
 	}
 
@@ -164,12 +217,15 @@ func (a *App) ConnectDevice(host string) error {
 
 	// Emit connection status change
 	runtime.EventsEmit(a.ctx, "connection:changed", a.GetConnectionStatus())
+	a.refreshTray()
 
 	return nil
 }
 
 // DisconnectDevice disconnects from the current device
 func (a *App) DisconnectDevice() {
+	a.StopPerformanceMonitor()
+	a.StopGameLogStream()
 	a.mu.Lock()
 	if a.connectedDevice != nil && a.connectedDevice.Client != nil {
 		a.connectedDevice.Client.Close()
@@ -179,6 +235,7 @@ func (a *App) DisconnectDevice() {
 
 	// Emit connection status change
 	runtime.EventsEmit(a.ctx, "connection:changed", a.GetConnectionStatus())
+	a.refreshTray()
 }
 
 // GetConnectionStatus returns the current connection status
@@ -252,17 +309,69 @@ func (a *App) GetGameSetups() ([]config.GameSetup, error) {
 
 // AddGameSetup adds a new game setup
 func (a *App) AddGameSetup(setup config.GameSetup) error {
+	if err := validateChannel(setup.Channel); err != nil {
+		return err
+	}
+	if err := engine.CheckPatterns(setup.Exclude); err != nil {
+		return err
+	}
+	if err := validateBuildStep(setup.Build); err != nil {
+		return err
+	}
+	if err := validatePrefixVerbs(setup.PrefixVerbs); err != nil {
+		return err
+	}
+	if err := validateExtraShortcuts(setup); err != nil {
+		return err
+	}
+	if err := validateKeepReleases(setup.KeepReleases); err != nil {
+		return err
+	}
 	return config.AddGameSetup(setup)
 }
 
 // UpdateGameSetup updates an existing game setup
 func (a *App) UpdateGameSetup(id string, setup config.GameSetup) error {
-	return config.UpdateGameSetup(id, setup)
+	if err := validateChannel(setup.Channel); err != nil {
+		return err
+	}
+	if err := engine.CheckPatterns(setup.Exclude); err != nil {
+		return err
+	}
+	if err := validateBuildStep(setup.Build); err != nil {
+		return err
+	}
+	if err := validatePrefixVerbs(setup.PrefixVerbs); err != nil {
+		return err
+	}
+	if err := validateExtraShortcuts(setup); err != nil {
+		return err
+	}
+	if err := validateKeepReleases(setup.KeepReleases); err != nil {
+		return err
+	}
+	if err := config.UpdateGameSetup(id, setup); err != nil {
+		return err
+	}
+	a.refreshTray()
+	return nil
+}
+
+// validateChannel rejects build channels that can't be part of a directory name
+func validateChannel(channel string) error {
+	if strings.ContainsAny(channel, "/\\\"[]\x00") {
+		return fmt.Errorf("invalid build channel: %q", channel)
+	}
+	return nil
 }
 
 // RemoveGameSetup removes a game setup
 func (a *App) RemoveGameSetup(id string) error {
-	return config.RemoveGameSetup(id)
+	if err := config.RemoveGameSetup(id); err != nil {
+		return err
+	}
+	a.refreshTray()
+	return nil
 }
 
 // SelectFolder opens a folder selection dialog
@@ -302,227 +411,226 @@ func (a *App) UploadGame(setupID string) error {
 	}
 
 	// Start upload in goroutine
-	go a.performUpload(client, &deviceCfg, setup)
+	go a.performUpload(client, &deviceCfg, setup, !setup.FullUpload)
 
 	return nil
 }
 
-func (a *App) performUpload(client *device.Client, deviceCfg *config.DeviceConfig, setup *config.GameSetup) {
-	emitProgress := func(progress float64, status string, err string, done bool) {
-		runtime.EventsEmit(a.ctx, "upload:progress", UploadProgress{
-			Progress: progress,
-			Status:   status,
-			Error:    err,
-			Done:     done,
-		})
+// DeployLastSetup deploys the most recently deployed game setup again and
+// returns its name
+func (a *App) DeployLastSetup() (string, error) {
+	if a.deploying() {
+		return "", fmt.Errorf("a deploy is already running")
+	}
+	recent := recentSetups()
+	if len(recent) == 0 {
+		return "", fmt.Errorf("no game has been deployed yet")
+	}
+	if err := a.UploadGame(recent[0].ID); err != nil {
+		return "", err
 	}
+	return recent[0].Name, nil
+}
 
-	emitProgress(0, "Preparing upload...", "", false)
+// deploying reports whether a deploy is running
+func (a *App) deploying() bool {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	return a.uploadStatus != nil && !a.uploadStatus.Done
+}
 
-	// Expand remote path
-	remotePath := setup.RemotePath
-	if strings.HasPrefix(remotePath, "~") {
-		homeDir, err := client.GetHomeDir()
-		if err != nil {
-			emitProgress(0, "", fmt.Sprintf("Failed to expand remote path: %v", err), true)
-			return
-		}
-		remotePath = strings.Replace(remotePath, "~", homeDir, 1)
-	}
+// performUpload deploys a game setup. With delta set, files the device already
+// has are not uploaded again: their hash is checked against the one recorded by
+// the previous deploy, or their size and modification time without one.
+func (a *App) performUpload(client *device.Client, deviceCfg *config.DeviceConfig, setup *config.GameSetup, delta bool) {
+	ctx, done := a.beginUpload()
+	defer done()
 
-	remoteGamePath := path.Join(remotePath, setup.Name)
+	a.startDeployLog(fmt.Sprintf("Deploying %s from %s to %s:%s (%s), delta: %v",
+		setup.DeployName(), setup.LocalPath, deviceCfg.Host, setup.RemotePath, deviceCfg.Name, delta))
+	a.startUploadJob(setup, []config.DeviceConfig{*deviceCfg})
+	a.emitUploadProgress(UploadProgress{Status: "Preparing upload..."})
 
-	// Create remote directory
-	emitProgress(0.05, "Creating remote directory...", "", false)
-	if err := client.MkdirAll(remoteGamePath); err != nil {
-		emitProgress(0, "", fmt.Sprintf("Failed to create directory: %v", err), true)
+	setup, ok := a.buildForDeploy(ctx, setup, a.emitUploadProgress)
+	if !ok {
 		return
 	}
+	a.deploySetup(ctx, client, deviceCfg, setup, delta, a.emitUploadProgress)
+}
 
-	// Get list of files
-	emitProgress(0.1, "Scanning files...", "", false)
-	files, err := getFilesToUpload(setup.LocalPath)
-	if err != nil {
-		emitProgress(0, "", fmt.Sprintf("Failed to scan files: %v", err), true)
-		return
+// beginUpload starts a deploy that CancelUpload can stop. The returned
+// function ends it.
+func (a *App) beginUpload() (context.Context, func()) {
+	ctx, cancel := context.WithCancel(a.ctx)
+	a.mu.Lock()
+	a.cancelUpload = cancel
+	a.mu.Unlock()
+	return ctx, func() {
+		a.mu.Lock()
+		a.cancelUpload = nil
+		a.mu.Unlock()
+		cancel()
 	}
+}
 
-	// Upload files
-	totalFiles := len(files)
-	for i, file := range files {
-		relPath, _ := filepath.Rel(setup.LocalPath, file)
-		relPath = strings.ReplaceAll(relPath, "\\", "/")
-		remoteDest := path.Join(remoteGamePath, relPath)
-
-		remoteDir := path.Dir(remoteDest)
-		client.MkdirAll(remoteDir)
-
-		progress := 0.1 + (float64(i)/float64(totalFiles))*0.75
-		emitProgress(progress, fmt.Sprintf("Uploading: %s", relPath), "", false)
-
-		if err := client.UploadFile(file, remoteDest); err != nil {
-			emitProgress(0, "", fmt.Sprintf("Failed to upload %s: %v", relPath, err), true)
-			return
-		}
+// buildForDeploy runs the build step of a setup, when it has one, and returns
+// the setup to deploy from its output. It reports false after emitting why the
+// deploy can't go on.
+func (a *App) buildForDeploy(ctx context.Context, setup *config.GameSetup, emit func(UploadProgress)) (*config.GameSetup, bool) {
+	if setup.Build == nil {
+		return setup, true
 	}
-
-	emitProgress(0.85, "Setting executable permissions...", "", false)
-
-	exePath := path.Join(remoteGamePath, setup.Executable)
-	chmodCmd := fmt.Sprintf("chmod +x %q", exePath)
-	if _, err := client.RunCommand(chmodCmd); err != nil {
-		emitProgress(0, "", fmt.Sprintf("Failed to set permissions: %v", err), true)
-		return
+	emit(UploadProgress{Status: "Building..."})
+	output, err := a.runBuildStep(ctx, setup)
+	if ctx.Err() != nil {
+		emit(UploadProgress{Error: "Upload cancelled", Done: true})
+		return nil, false
 	}
-
-	// Set executable permissions on common executable files
-	chmodAllCmd := fmt.Sprintf("find %q -type f \\( -name '*.sh' -o -name '*.x86_64' -o -name '*.x86' \\) -exec chmod +x {} \\;", remoteGamePath)
-	client.RunCommand(chmodAllCmd)
-
-	// Ensure steam-shortcut-manager binary exists on remote device
-	emitProgress(0.87, "Checking steam-shortcut-manager binary...", "", false)
-
-	binaryRemotePath := path.Join(remotePath, embedded.SteamShortcutManagerName)
-	if !shortcuts.EnsureBinaryExists(client, binaryRemotePath) {
-		emitProgress(0.88, "Uploading steam-shortcut-manager binary...", "", false)
-		if err := shortcuts.UploadBinary(client, embedded.SteamShortcutManager, binaryRemotePath); err != nil {
-			emitProgress(0, "", fmt.Sprintf("Failed to upload binary: %v", err), true)
-			return
-		}
+	if err != nil {
+		emit(UploadProgress{Error: fmt.Sprintf("Build failed: %v", err), Done: true})
+		return nil, false
 	}
+	built := *setup
+	built.LocalPath = output
+	return &built, true
+}
 
-	emitProgress(0.9, "Creating Steam shortcut...", "", false)
-
-	// Prepare artwork config
-	var artworkCfg *shortcuts.ArtworkConfig
-	if setup.GridPortrait != "" || setup.GridLandscape != "" || setup.HeroImage != "" ||
-		setup.LogoImage != "" || setup.IconImage != "" {
-		artworkCfg = &shortcuts.ArtworkConfig{
-			GridPortrait:  setup.GridPortrait,
-			GridLandscape: setup.GridLandscape,
-			HeroImage:     setup.HeroImage,
-			LogoImage:     setup.LogoImage,
-			IconImage:     setup.IconImage,
+// deploySetup uploads a built setup to a device and creates its shortcuts,
+// reporting progress through emit
+func (a *App) deploySetup(ctx context.Context, client *device.Client, deviceCfg *config.DeviceConfig, setup *config.GameSetup, delta bool, emit func(UploadProgress)) {
+	art := &deployArtwork{app: a, host: deviceCfg.Host, emit: emit}
+	deployer := &deploy.Deployer{
+		Client:           client,
+		Device:           *deviceCfg,
+		Artwork:          art,
+		PostDeployOutput: a.postDeployOutput,
+		Progress: func(p deploy.Progress) {
+			emit(UploadProgress{Progress: p.Progress, Status: p.Status, Transfer: p.Transfer})
+		},
+	}
+	result, err := deployer.Run(ctx, setup, deploy.Options{Delta: delta})
+	if err != nil {
+		progress := UploadProgress{Error: err.Error(), Done: true}
+		var deployErr *deploy.Error
+		switch {
+		case errors.Is(err, deploy.ErrDiskFull):
+			progress.ErrorCode = protocol.ErrCodeDiskFull
+		case errors.As(err, &deployErr) && deployErr.Stage == deploy.StagePostDeploy && !errors.Is(err, deploy.ErrCancelled):
+			progress.Error += " (see the output under the setups)"
 		}
-		// Debug: log artwork URLs being used
-		fmt.Printf("[DEBUG] Setup artwork config from GameSetup:\n")
-		fmt.Printf("  Name: %s\n", setup.Name)
-		fmt.Printf("  GridDBGameID: %d\n", setup.GridDBGameID)
-		fmt.Printf("  GridPortrait: %s\n", setup.GridPortrait)
-		fmt.Printf("  GridLandscape: %s\n", setup.GridLandscape)
-		fmt.Printf("  HeroImage: %s\n", setup.HeroImage)
-		fmt.Printf("  LogoImage: %s\n", setup.LogoImage)
-		fmt.Printf("  IconImage: %s\n", setup.IconImage)
-	}
-
-	remoteCfg := &shortcuts.RemoteConfig{
-		Host:     deviceCfg.Host,
-		Port:     deviceCfg.Port,
-		User:     deviceCfg.User,
-		Password: deviceCfg.Password,
-		KeyFile:  deviceCfg.KeyFile,
+		emit(progress)
+		return
 	}
 
-	tags := shortcuts.ParseTags(setup.Tags)
-	if err := shortcuts.AddShortcutWithArtwork(remoteCfg, setup.Name, exePath, remoteGamePath, setup.LaunchOptions, tags, artworkCfg, binaryRemotePath); err != nil {
-		emitProgress(0, "", fmt.Sprintf("Failed to create shortcut: %v", err), true)
-		return
+	// Unchanged slots are checked too, they must still be on the device
+	status := "Upload complete!"
+	if result.Unchanged > 0 {
+		status = fmt.Sprintf("Upload complete! %d of %d files were already on the device.", result.Unchanged, result.Total)
+	}
+	if result.Removed > 0 {
+		status += fmt.Sprintf(" Removed %d files no longer in the build.", result.Removed)
+	}
+	if art.summary != "" {
+		status += " " + art.summary
 	}
 
-	shortcuts.RefreshSteamLibrary(remoteCfg)
+	config.AddRecentArtwork(appliedArtwork(setup)...)
+	config.AddRecentDeploy(setup.ID)
 
-	emitProgress(1.0, "Upload complete!", "", true)
+	emit(UploadProgress{
+		Progress: 1.0,
+		Status:   status,
+		Done:     true,
+		Artwork:  art.checks,
+		Verbs:    result.Verbs,
+		Retried:  result.Retried,
+	})
 }
 
-// =============================================================================
-// Installed Games Management
-// =============================================================================
+// deployArtwork applies the artwork of a setup deployed from the hub:
+// transcoded and upscaled images, generated artwork and all of it offline
+// copied from local files, and a check of what the device ends up showing
+type deployArtwork struct {
+	app  *App
+	host string
+	emit func(UploadProgress)
+
+	requested *shortcuts.ArtworkConfig // every slot of the setup, to verify
+	local     *shortcuts.ArtworkConfig // slots copied from local files
+	checks    []ArtworkCheck
+	summary   string
+}
 
-// GetInstalledGames returns games installed on the remote device
-func (a *App) GetInstalledGames(remotePath string) ([]InstalledGame, error) {
-	a.mu.RLock()
-	if a.connectedDevice == nil || a.connectedDevice.Client == nil {
-		a.mu.RUnlock()
-		return nil, fmt.Errorf("no device connected")
+// Resolve returns the artwork URLs the device downloads itself
+func (d *deployArtwork) Resolve(client *device.Client, appID uint32, art *shortcuts.ArtworkConfig) *shortcuts.ArtworkConfig {
+	for _, warning := range d.app.resolveTranscoded(art) {
+		slog.Warn(warning)
+		d.emit(UploadProgress{Progress: 0.9, Status: warning})
 	}
-	client := a.connectedDevice.Client
-	a.mu.RUnlock()
-
-	// Expand remote path
-	if strings.HasPrefix(remotePath, "~") {
-		homeDir, err := client.GetHomeDir()
-		if err != nil {
-			return nil, fmt.Errorf("failed to expand path: %w", err)
-		}
-		remotePath = strings.Replace(remotePath, "~", homeDir, 1)
+	if warning := d.app.resolveIconUpscale(art); warning != "" {
+		slog.Warn(warning)
 	}
 
-	// List directories
-	cmd := fmt.Sprintf("ls -1 %s 2>/dev/null || echo ''", remotePath)
-	output, err := client.RunCommand(cmd)
-	if err != nil {
-		return []InstalledGame{}, nil
+	d.requested = art
+	art, skipped := d.app.skipUnchangedArtwork(client, d.host, appID, art)
+	if len(skipped) > 0 {
+		slog.Info("Artwork unchanged on device, skipping", "types", strings.Join(skipped, ", "))
 	}
 
-	lines := strings.Split(strings.TrimSpace(output), "\n")
-	var games []InstalledGame
-
-	for _, line := range lines {
-		name := strings.TrimSpace(line)
-		if name == "" {
-			continue
-		}
-
-		gamePath := path.Join(remotePath, name)
+	// The device downloads artwork URLs itself. Generated artwork, and all artwork
+	// in offline mode, is copied from local files instead.
+	var remote *shortcuts.ArtworkConfig
+	remote, d.local = splitLocalArtwork(art, d.app.isOffline())
+	return remote
+}
 
-		sizeCmd := fmt.Sprintf("du -sh %q 2>/dev/null | cut -f1", gamePath)
-		sizeOutput, _ := client.RunCommand(sizeCmd)
-		size := strings.TrimSpace(sizeOutput)
-		if size == "" {
-			size = "Unknown"
+// Applied copies the local artwork and checks every slot on the device
+func (d *deployArtwork) Applied(client *device.Client, appID uint32, downloaded *shortcuts.ArtworkConfig) {
+	recordAppliedArtwork(d.host, appID, downloaded)
+	if d.local != nil {
+		d.emit(UploadProgress{Progress: 0.95, Status: "Copying local artwork..."})
+		if err := d.app.writeLocalArtwork(client, uint64(appID), d.local); err != nil {
+			slog.Warn("Failed to copy local artwork", "error", err)
+		} else {
+			recordAppliedArtwork(d.host, appID, d.local)
 		}
-
-		games = append(games, InstalledGame{
-			Name: name,
-			Path: gamePath,
-			Size: size,
-		})
 	}
 
-	return games, nil
+	d.emit(UploadProgress{Progress: 0.98, Status: "Verifying artwork on device..."})
+	d.checks = verifyArtwork(client, appID, d.requested)
+	summary, ok := artworkCheckSummary(d.checks)
+	if !ok {
+		slog.Warn(summary)
+	}
+	d.summary = summary
 }
 
-// DeleteGame deletes a game from the remote device
-func (a *App) DeleteGame(name, gamePath string) error {
+// CancelUpload stops the running deploy once the files being copied are done
+func (a *App) CancelUpload() {
 	a.mu.RLock()
-	if a.connectedDevice == nil || a.connectedDevice.Client == nil {
-		a.mu.RUnlock()
-		return fmt.Errorf("no device connected")
-	}
-	client := a.connectedDevice.Client
-	deviceCfg := a.connectedDevice.Config
+	cancel := a.cancelUpload
 	a.mu.RUnlock()
-
-	// Remove Steam shortcut
-	remoteCfg := &shortcuts.RemoteConfig{
-		Host:     deviceCfg.Host,
-		Port:     deviceCfg.Port,
-		User:     deviceCfg.User,
-		Password: deviceCfg.Password,
-		KeyFile:  deviceCfg.KeyFile,
+	if cancel != nil {
+		cancel()
 	}
+}
 
-	shortcuts.RemoveShortcut(remoteCfg, name)
-	shortcuts.RefreshSteamLibrary(remoteCfg)
-
-	// Delete game files
-	cmd := fmt.Sprintf("rm -rf %q", gamePath)
-	_, err := client.RunCommand(cmd)
-	if err != nil {
-		return fmt.Errorf("failed to delete game files: %w", err)
+// emitUploadProgress sends deploy progress to the frontend, along with the
+// deploy it belongs to, and keeps it for the automation API and the tray menu
+func (a *App) emitUploadProgress(progress UploadProgress) {
+	a.mu.Lock()
+	changed := a.uploadStatus == nil || a.uploadStatus.Done != progress.Done
+	a.uploadStatus = &progress
+	a.writeDeployLog(progress)
+	job := a.uploadJobLocked()
+	a.mu.Unlock()
+	runtime.EventsEmit(a.ctx, "upload:progress", progress)
+	if job != nil {
+		runtime.EventsEmit(a.ctx, "upload:job", job)
+	}
+	if changed {
+		a.refreshTray()
 	}
-
-	return nil
 }
 
 // =============================================================================
@@ -539,6 +647,21 @@ func (a *App) SetSteamGridDBAPIKey(apiKey string) error {
 	return config.SetSteamGridDBAPIKey(apiKey)
 }
 
+// KeyTestResult is the outcome of validating an API key
+type KeyTestResult struct {
+	Valid   bool   `json:"valid"`
+	Message string `json:"message"`
+}
+
+// TestSteamGridDBAPIKey checks an API key with an authenticated request without saving it
+func (a *App) TestSteamGridDBAPIKey(apiKey string) KeyTestResult {
+	client := steamgriddb.NewClient(strings.TrimSpace(apiKey), steamgriddb.WithHTTPClient(newHTTPClient()))
+	if err := client.ValidateKey(a.ctx); err != nil {
+		return KeyTestResult{Valid: false, Message: err.Error()}
+	}
+	return KeyTestResult{Valid: true, Message: "API key is valid"}
+}
+
 // GetCacheSize returns the size of the image cache
 func (a *App) GetCacheSize() (int64, error) {
 	return steamgriddb.GetCacheSize()
@@ -549,6 +672,74 @@ func (a *App) ClearImageCache() error {
 	return steamgriddb.ClearImageCache()
 }
 
+// GetImageCacheSettings returns the image cache limits
+func (a *App) GetImageCacheSettings() (config.ImageCacheSettings, error) {
+	return config.GetImageCacheSettings()
+}
+
+// SetImageCacheSettings saves the image cache limits and applies them immediately
+func (a *App) SetImageCacheSettings(settings config.ImageCacheSettings) error {
+	if err := config.SetImageCacheSettings(settings); err != nil {
+		return err
+	}
+	if a.imageCache != nil {
+		a.imageCache.SetLimits(cacheLimits(settings))
+		go a.imageCache.Evict()
+	}
+	return nil
+}
+
+// GetPerformanceSettings returns the transfer and download tuning
+func (a *App) GetPerformanceSettings() (config.PerformanceSettings, error) {
+	return config.GetPerformanceSettings()
+}
+
+// SetPerformanceSettings saves the transfer and download tuning. Download
+// workers and the upload limit apply immediately, the chunk size on the next
+// device connection.
+func (a *App) SetPerformanceSettings(settings config.PerformanceSettings) error {
+	if err := config.SetPerformanceSettings(settings); err != nil {
+		return err
+	}
+	if a.imageFetcher != nil {
+		a.imageFetcher.SetWorkers(settings.FetchWorkers)
+	}
+	if client, _, err := a.connectedClient(); err == nil {
+		client.SetUploadLimit(int64(settings.UploadLimitKBps) * 1024)
+	}
+	return nil
+}
+
+// GetAppearance returns the UI theme and scale
+func (a *App) GetAppearance() (config.Appearance, error) {
+	return config.GetAppearance()
+}
+
+// GetKeyBindings returns the key combination of every shortcut action
+func (a *App) GetKeyBindings() (config.KeyBindings, error) {
+	return config.GetKeyBindings()
+}
+
+// SetKeyBindings saves the key combinations of the shortcut actions
+func (a *App) SetKeyBindings(bindings config.KeyBindings) error {
+	return config.SetKeyBindings(bindings)
+}
+
+// SetAppearance saves the UI theme and scale
+func (a *App) SetAppearance(appearance config.Appearance) error {
+	return config.SetAppearance(appearance)
+}
+
+// GetDefaultLaunchOptions returns the launch options new game setups start with
+func (a *App) GetDefaultLaunchOptions() (string, error) {
+	return config.GetDefaultLaunchOptions()
+}
+
+// SetDefaultLaunchOptions saves the launch options new game setups start with
+func (a *App) SetDefaultLaunchOptions(options string) error {
+	return config.SetDefaultLaunchOptions(options)
+}
+
 // OpenCacheFolder opens the cache folder in the file explorer
 func (a *App) OpenCacheFolder() error {
 	cacheDir, err := steamgriddb.GetImageCacheDir()
@@ -559,63 +750,292 @@ func (a *App) OpenCacheFolder() error {
 	return nil
 }
 
+// IGDBCredentials holds the Twitch application credentials used for IGDB
+type IGDBCredentials struct {
+	ClientID     string `json:"clientId"`
+	ClientSecret string `json:"clientSecret"`
+}
+
+// GetIGDBCredentials returns the IGDB credentials
+func (a *App) GetIGDBCredentials() (IGDBCredentials, error) {
+	id, secret, err := config.GetIGDBCredentials()
+	return IGDBCredentials{ClientID: id, ClientSecret: secret}, err
+}
+
+// SetIGDBCredentials saves the IGDB credentials
+func (a *App) SetIGDBCredentials(creds IGDBCredentials) error {
+	return config.SetIGDBCredentials(strings.TrimSpace(creds.ClientID), strings.TrimSpace(creds.ClientSecret))
+}
+
+// =============================================================================
+// Alternative Artwork Providers
+// =============================================================================
+
+// GetArtworkProviders returns the configured alternative artwork providers
+func (a *App) GetArtworkProviders() []string {
+	providers := []string{}
+	if id, secret, err := config.GetIGDBCredentials(); err == nil && id != "" && secret != "" {
+		providers = append(providers, artwork.ProviderIGDB)
+	}
+	return providers
+}
+
+// SearchArtworkProvider searches games on an alternative artwork provider
+func (a *App) SearchArtworkProvider(provider, term string) ([]artwork.Game, error) {
+	p, err := a.artworkProvider(provider)
+	if err != nil {
+		return nil, err
+	}
+	return p.Search(a.ctx, term)
+}
+
+// GetProviderArtwork returns the artwork of a game from an alternative provider
+func (a *App) GetProviderArtwork(provider string, gameID int) (*artwork.Artwork, error) {
+	p, err := a.artworkProvider(provider)
+	if err != nil {
+		return nil, err
+	}
+	return p.GetArtwork(a.ctx, gameID)
+}
+
+// artworkProvider returns the named provider, reusing it while its credentials don't change
+func (a *App) artworkProvider(name string) (artwork.Provider, error) {
+	if a.isOffline() {
+		return nil, fmt.Errorf("alternative providers are not available offline")
+	}
+
+	switch name {
+	case artwork.ProviderIGDB:
+		id, secret, err := config.GetIGDBCredentials()
+		if err != nil || id == "" || secret == "" {
+			return nil, fmt.Errorf("IGDB credentials not configured")
+		}
+
+		a.mu.Lock()
+		defer a.mu.Unlock()
+		if a.igdb == nil || a.igdbKey != id+":"+secret {
+			a.igdb = artwork.NewIGDBProvider(id, secret)
+			a.igdb.SetHTTPClient(newHTTPClient())
+			a.igdbKey = id + ":" + secret
+		}
+		return a.igdb, nil
+	default:
+		return nil, fmt.Errorf("unknown artwork provider: %s", name)
+	}
+}
+
 // =============================================================================
 // SteamGridDB
 // =============================================================================
 
-// SearchGames searches for games on SteamGridDB
-func (a *App) SearchGames(query string) ([]steamgriddb.SearchResult, error) {
+// RateLimitEvent is emitted while SteamGridDB requests wait for a rate limit to clear
+type RateLimitEvent struct {
+	Seconds int `json:"seconds"`
+}
+
+// sgdbClient creates a SteamGridDB client that reports rate limit waits to the frontend
+func (a *App) sgdbClient() (*steamgriddb.Client, error) {
 	apiKey, err := config.GetSteamGridDBAPIKey()
 	if err != nil || apiKey == "" {
 		return nil, fmt.Errorf("SteamGridDB API key not configured")
 	}
 
-	client := steamgriddb.NewClient(apiKey)
-	return client.Search(query)
+	perf, _ := config.GetPerformanceSettings()
+	return steamgriddb.NewClient(apiKey,
+		steamgriddb.WithHTTPClient(newHTTPClient()),
+		steamgriddb.WithMaxRetries(perf.MaxRetries),
+		steamgriddb.WithRateLimitHandler(func(wait time.Duration) {
+			runtime.EventsEmit(a.ctx, "sgdb:ratelimit", RateLimitEvent{Seconds: int(wait.Round(time.Second).Seconds())})
+		})), nil
 }
 
-// GetGrids returns grid images for a game
-func (a *App) GetGrids(gameID int, filters steamgriddb.ImageFilters, page int) ([]steamgriddb.GridData, error) {
-	apiKey, err := config.GetSteamGridDBAPIKey()
-	if err != nil || apiKey == "" {
-		return nil, fmt.Errorf("SteamGridDB API key not configured")
+// SearchGames searches for games on SteamGridDB
+func (a *App) SearchGames(query string) ([]steamgriddb.SearchResult, error) {
+	if a.isOffline() {
+		if a.results == nil {
+			return nil, fmt.Errorf("no cached results available")
+		}
+		return a.results.Search(query)
 	}
 
-	client := steamgriddb.NewClient(apiKey)
-	return client.GetGrids(gameID, &filters, page)
+	client, err := a.sgdbClient()
+	if err != nil {
+		return nil, err
+	}
+	results, err := client.Search(a.ctx, query)
+	if err == nil {
+		a.rememberGameNames(results)
+	}
+	return results, err
 }
 
-// GetHeroes returns hero images for a game
-func (a *App) GetHeroes(gameID int, filters steamgriddb.ImageFilters, page int) ([]steamgriddb.ImageData, error) {
-	apiKey, err := config.GetSteamGridDBAPIKey()
-	if err != nil || apiKey == "" {
-		return nil, fmt.Errorf("SteamGridDB API key not configured")
+// GetGameByID looks up a SteamGridDB game by ID, for when search can't find it
+func (a *App) GetGameByID(gameID int) (*steamgriddb.SearchResult, error) {
+	if gameID <= 0 {
+		return nil, fmt.Errorf("invalid game ID: %d", gameID)
+	}
+
+	if a.isOffline() {
+		snap, err := a.offlineSnapshot(gameID)
+		if err != nil {
+			return nil, err
+		}
+		if snap.Game.Name == "" {
+			return nil, fmt.Errorf("game %d has no cached results", gameID)
+		}
+		return &snap.Game, nil
 	}
 
-	client := steamgriddb.NewClient(apiKey)
-	return client.GetHeroes(gameID, &filters, page)
+	client, err := a.sgdbClient()
+	if err != nil {
+		return nil, err
+	}
+	game, err := client.GetGame(a.ctx, gameID)
+	if err != nil {
+		return nil, err
+	}
+	result := steamgriddb.SearchResult{ID: game.ID, Name: game.Name, Verified: game.Verified}
+	a.rememberGameNames([]steamgriddb.SearchResult{result})
+	return &result, nil
 }
 
-// GetLogos returns logo images for a game
-func (a *App) GetLogos(gameID int, filters steamgriddb.ImageFilters, page int) ([]steamgriddb.ImageData, error) {
-	apiKey, err := config.GetSteamGridDBAPIKey()
-	if err != nil || apiKey == "" {
-		return nil, fmt.Errorf("SteamGridDB API key not configured")
+// GetGrids returns a page of grid images for a game
+func (a *App) GetGrids(gameID int, filters steamgriddb.ImageFilters, page int) (*steamgriddb.GridPage, error) {
+	if a.isOffline() {
+		snap, err := a.offlineSnapshot(gameID)
+		if err != nil {
+			return nil, err
+		}
+		return &steamgriddb.GridPage{Items: snap.Grids, Paging: offlinePageInfo(len(snap.Grids))}, nil
 	}
 
-	client := steamgriddb.NewClient(apiKey)
-	return client.GetLogos(gameID, &filters, page)
+	client, err := a.sgdbClient()
+	if err != nil {
+		return nil, err
+	}
+	result, err := client.GetGridsPage(a.ctx, gameID, &filters, page)
+	if err == nil {
+		a.recordResults(steamgriddb.GameSnapshot{Game: steamgriddb.SearchResult{ID: gameID}, Grids: result.Items})
+	}
+	return result, err
 }
 
-// GetIcons returns icon images for a game
-func (a *App) GetIcons(gameID int, filters steamgriddb.ImageFilters, page int) ([]steamgriddb.ImageData, error) {
-	apiKey, err := config.GetSteamGridDBAPIKey()
-	if err != nil || apiKey == "" {
-		return nil, fmt.Errorf("SteamGridDB API key not configured")
+// GetHeroes returns a page of hero images for a game
+func (a *App) GetHeroes(gameID int, filters steamgriddb.ImageFilters, page int) (*steamgriddb.ImagePage, error) {
+	if a.isOffline() {
+		snap, err := a.offlineSnapshot(gameID)
+		if err != nil {
+			return nil, err
+		}
+		return &steamgriddb.ImagePage{Items: snap.Heroes, Paging: offlinePageInfo(len(snap.Heroes))}, nil
 	}
 
-	client := steamgriddb.NewClient(apiKey)
-	return client.GetIcons(gameID, &filters, page)
+	client, err := a.sgdbClient()
+	if err != nil {
+		return nil, err
+	}
+	result, err := client.GetHeroesPage(a.ctx, gameID, &filters, page)
+	if err == nil {
+		a.recordResults(steamgriddb.GameSnapshot{Game: steamgriddb.SearchResult{ID: gameID}, Heroes: result.Items})
+	}
+	return result, err
+}
+
+// GetLogos returns a page of logo images for a game
+func (a *App) GetLogos(gameID int, filters steamgriddb.ImageFilters, page int) (*steamgriddb.ImagePage, error) {
+	if a.isOffline() {
+		snap, err := a.offlineSnapshot(gameID)
+		if err != nil {
+			return nil, err
+		}
+		return &steamgriddb.ImagePage{Items: snap.Logos, Paging: offlinePageInfo(len(snap.Logos))}, nil
+	}
+
+	client, err := a.sgdbClient()
+	if err != nil {
+		return nil, err
+	}
+	result, err := client.GetLogosPage(a.ctx, gameID, &filters, page)
+	if err == nil {
+		a.recordResults(steamgriddb.GameSnapshot{Game: steamgriddb.SearchResult{ID: gameID}, Logos: result.Items})
+	}
+	return result, err
+}
+
+// GetIcons returns a page of icon images for a game
+func (a *App) GetIcons(gameID int, filters steamgriddb.ImageFilters, page int) (*steamgriddb.ImagePage, error) {
+	if a.isOffline() {
+		snap, err := a.offlineSnapshot(gameID)
+		if err != nil {
+			return nil, err
+		}
+		return &steamgriddb.ImagePage{Items: snap.Icons, Paging: offlinePageInfo(len(snap.Icons))}, nil
+	}
+
+	client, err := a.sgdbClient()
+	if err != nil {
+		return nil, err
+	}
+	result, err := client.GetIconsPage(a.ctx, gameID, &filters, page)
+	if err == nil {
+		a.recordResults(steamgriddb.GameSnapshot{Game: steamgriddb.SearchResult{ID: gameID}, Icons: result.Items})
+	}
+	return result, err
+}
+
+// GetArtworkHistory returns recently applied and favorite artwork
+func (a *App) GetArtworkHistory() (*config.ArtworkHistory, error) {
+	return config.GetArtworkHistory()
+}
+
+// ToggleFavoriteArtwork stars or unstars an artwork. Returns true if it is now a favorite.
+func (a *App) ToggleFavoriteArtwork(ref config.ArtworkRef) (bool, error) {
+	if ref.URL == "" || ref.Type == "" {
+		return false, fmt.Errorf("invalid artwork reference")
+	}
+	return config.ToggleFavoriteArtwork(ref)
+}
+
+// GetArtworkSelection returns the last artwork selection saved for a game, or nil
+func (a *App) GetArtworkSelection(gameName string) (*config.ArtworkSelection, error) {
+	return config.GetArtworkSelection(gameName)
+}
+
+// SaveArtworkSelection remembers the artwork selection for a game across sessions
+func (a *App) SaveArtworkSelection(gameName string, sel config.ArtworkSelection) error {
+	return config.SaveArtworkSelection(gameName, sel)
+}
+
+// GetArtworkFilters returns the saved artwork browser filters keyed by asset type
+func (a *App) GetArtworkFilters() (map[string]config.ArtworkFilter, error) {
+	return config.GetArtworkFilters()
+}
+
+// SetArtworkFilter saves the artwork browser filters for an asset type
+func (a *App) SetArtworkFilter(assetType string, filter config.ArtworkFilter) error {
+	return config.SetArtworkFilter(assetType, filter)
+}
+
+// GetOfficialArtwork returns the official Steam CDN artwork for a game, if it is a Steam app
+func (a *App) GetOfficialArtwork(gameID int) (*steamgriddb.OfficialArtwork, error) {
+	if a.isOffline() {
+		return nil, fmt.Errorf("official artwork is not available offline")
+	}
+
+	client, err := a.sgdbClient()
+	if err != nil {
+		return nil, err
+	}
+	official, err := client.GetOfficialArtwork(a.ctx, gameID)
+	if err == nil && official != nil {
+		a.recordResults(steamgriddb.GameSnapshot{
+			Game:   steamgriddb.SearchResult{ID: gameID},
+			Grids:  append(append([]steamgriddb.GridData{}, official.Capsules...), official.Wide...),
+			Heroes: official.Heroes,
+			Logos:  official.Logos,
+		})
+	}
+	return official, err
 }
 
 // ProxyImage fetches an image from URL and returns it as a base64 data URL
@@ -625,49 +1045,142 @@ func (a *App) ProxyImage(imageURL string) (string, error) {
 		return "", fmt.Errorf("empty URL")
 	}
 
-	// Fetch the image
-	resp, err := http.Get(imageURL)
-	if err != nil {
-		return "", fmt.Errorf("failed to fetch image: %w", err)
+	if artwork.IsGenerated(imageURL) {
+		data, contentType, err := a.localImage(imageURL)
+		if err != nil {
+			return "", err
+		}
+		return toDataURL(data, contentType), nil
 	}
-	defer resp.Body.Close()
 
-	if resp.StatusCode != http.StatusOK {
-		return "", fmt.Errorf("HTTP error: %d", resp.StatusCode)
+	if a.isOffline() {
+		if a.imageCache == nil {
+			return "", fmt.Errorf("image cache unavailable")
+		}
+		data, contentType, ok := a.imageCache.Get(imageURL)
+		if !ok {
+			return "", fmt.Errorf("image not cached")
+		}
+		return toDataURL(data, contentType), nil
 	}
 
-	// Read image data
-	data, err := io.ReadAll(resp.Body)
+	data, contentType, err := a.imageFetcher.Fetch(imageURL)
 	if err != nil {
-		return "", fmt.Errorf("failed to read image: %w", err)
-	}
-
-	// Determine MIME type
-	contentType := resp.Header.Get("Content-Type")
-	if contentType == "" {
-		// Try to detect from URL
-		if strings.HasSuffix(strings.ToLower(imageURL), ".png") {
-			contentType = "image/png"
-		} else if strings.HasSuffix(strings.ToLower(imageURL), ".webp") {
-			contentType = "image/webp"
-		} else if strings.HasSuffix(strings.ToLower(imageURL), ".gif") {
-			contentType = "image/gif"
-		} else {
-			contentType = "image/jpeg"
+		return "", err
+	}
+
+	return toDataURL(data, contentType), nil
+}
+
+// CancelImageLoads aborts all pending image downloads (e.g. when the artwork selector closes)
+func (a *App) CancelImageLoads() {
+	a.imageFetcher.CancelAll()
+}
+
+// initImageCache opens the image disk cache, starts background eviction
+// and creates the image fetcher on top of it
+func (a *App) initImageCache() {
+	if cacheDir, err := steamgriddb.GetImageCacheDir(); err == nil {
+		settings, _ := config.GetImageCacheSettings()
+		maxBytes, ttl := cacheLimits(settings)
+		if cache, err := steamgriddb.NewImageCache(cacheDir, maxBytes, ttl); err == nil {
+			a.imageCache = cache
+			a.imageCache.StartJanitor(a.ctx, 10*time.Minute)
 		}
 	}
 
-	// Create data URL
-	base64Data := base64.StdEncoding.EncodeToString(data)
-	dataURL := fmt.Sprintf("data:%s;base64,%s", contentType, base64Data)
+	perf, _ := config.GetPerformanceSettings()
+	a.imageFetcher = steamgriddb.NewImageFetcher(a.imageCache, perf.FetchWorkers)
+	a.imageFetcher.SetHTTPClient(newHTTPClient())
 
-	return dataURL, nil
+	if resultsDir, err := steamgriddb.GetResultsCacheDir(); err == nil {
+		if store, err := steamgriddb.NewResultStore(resultsDir); err == nil {
+			a.results = store
+		}
+	}
+
+	if generatedDir, err := artwork.GetGeneratedDir(); err == nil {
+		if store, err := artwork.NewGeneratedStore(generatedDir); err == nil {
+			a.generated = store
+		}
+	}
+}
+
+// cacheLimits converts cache settings to byte and duration limits
+func cacheLimits(settings config.ImageCacheSettings) (int64, time.Duration) {
+	return int64(settings.MaxSizeMB) * 1024 * 1024, time.Duration(settings.TTLHours) * time.Hour
+}
+
+// toDataURL encodes image data as a base64 data URL
+func toDataURL(data []byte, contentType string) string {
+	return fmt.Sprintf("data:%s;base64,%s", contentType, base64.StdEncoding.EncodeToString(data))
 }
 
 // =============================================================================
 // Helper functions
 // =============================================================================
 
+// newDeviceClient creates a client for a saved device using the configured
+// chunk size and upload limit, which checks the device's host key against
+// the ones the user trusted
+func newDeviceClient(cfg config.DeviceConfig) (*device.Client, error) {
+	client, err := device.NewClient(cfg.Host, cfg.Port, cfg.User, cfg.Password, cfg.KeyFile)
+	if err != nil {
+		return nil, err
+	}
+	knownHosts, err := config.KnownHostsPath()
+	if err != nil {
+		return nil, fmt.Errorf("failed to locate known hosts: %w", err)
+	}
+	client.SetKnownHosts(knownHosts)
+	perf, _ := config.GetPerformanceSettings()
+	client.SetMaxPacket(perf.ChunkSizeKB * 1024)
+	client.SetUploadLimit(int64(perf.UploadLimitKBps) * 1024)
+	return client, nil
+}
+
+// connectedClient returns the SSH client and config of the connected device
+func (a *App) connectedClient() (*device.Client, config.DeviceConfig, error) {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	if a.connectedDevice == nil || a.connectedDevice.Client == nil {
+		return nil, config.DeviceConfig{}, fmt.Errorf("no device connected")
+	}
+	return a.connectedDevice.Client, a.connectedDevice.Config, nil
+}
+
+// remoteConfig converts a device config into shortcut manager connection settings
+func remoteConfig(client *device.Client, deviceCfg config.DeviceConfig) *shortcuts.RemoteConfig {
+	return &shortcuts.RemoteConfig{
+		User:     deviceCfg.User,
+		Password: deviceCfg.Password,
+		KeyFile:  deviceCfg.KeyFile,
+		Device:   client,
+	}
+}
+
+// appliedArtwork returns the artwork slots set in a game setup as history entries
+func appliedArtwork(setup *config.GameSetup) []config.ArtworkRef {
+	slots := []struct {
+		kind string
+		url  string
+	}{
+		{"capsule", setup.GridPortrait},
+		{"wide", setup.GridLandscape},
+		{"hero", setup.HeroImage},
+		{"logo", setup.LogoImage},
+		{"icon", setup.IconImage},
+	}
+
+	var refs []config.ArtworkRef
+	for _, slot := range slots {
+		if slot.url != "" {
+			refs = append(refs, config.ArtworkRef{Type: slot.kind, URL: slot.url, GameName: setup.Name})
+		}
+	}
+	return refs
+}
+
 func getLocalIP() string {
 	addrs, err := net.InterfaceAddrs()
 	if err != nil {
@@ -701,17 +1214,3 @@ func getHostname(ip string) string {
 	hostname := strings.TrimSuffix(names[0], ".")
 	return hostname
 }
-
-func getFilesToUpload(root string) ([]string, error) {
-	var files []string
-	err := filepath.Walk(root, func(path string, info os.FileInfo, err error) error {
-		if err != nil {
-			return err
-		}
-		if !info.IsDir() {
-			files = append(files, path)
-		}
-		return nil
-	})
-	return files, err
-}
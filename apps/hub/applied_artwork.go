@@ -0,0 +1,78 @@
+package main
+
+import (
+	"log/slog"
+
+	"github.com/lobinuxsoft/capydeploy/internal/device"
+	"github.com/lobinuxsoft/capydeploy/internal/shortcuts"
+	"github.com/lobinuxsoft/capydeploy/pkg/config"
+)
+
+// =============================================================================
+// Applied Artwork Tracking
+// =============================================================================
+
+// artworkSlotURL returns the field of art holding the image of a slot
+func artworkSlotURL(art *shortcuts.ArtworkConfig, slot string) *string {
+	switch slot {
+	case "capsule":
+		return &art.GridPortrait
+	case "wide":
+		return &art.GridLandscape
+	case "hero":
+		return &art.HeroImage
+	case "logo":
+		return &art.LogoImage
+	default:
+		return &art.IconImage
+	}
+}
+
+// skipUnchangedArtwork returns a copy of art without the slots whose image was
+// already applied to the shortcut and is still on the device, along with the
+// names of the skipped slots
+func (a *App) skipUnchangedArtwork(client *device.Client, host string, appID uint32, art *shortcuts.ArtworkConfig) (*shortcuts.ArtworkConfig, []string) {
+	applied, err := config.GetAppliedArtwork(host, appID)
+	if err != nil || len(applied) == 0 {
+		return art, nil
+	}
+
+	// A file removed on the device (or by Steam) must be applied again
+	files, err := currentGridFiles(client, appID)
+	if err != nil {
+		return art, nil
+	}
+
+	changed := *art
+	var skipped []string
+	for _, slot := range artworkSlots {
+		url := artworkSlotURL(&changed, slot.name)
+		if *url != "" && applied[slot.name] == *url && files[slot.name] != "" {
+			*url = ""
+			skipped = append(skipped, slot.name)
+		}
+	}
+	return &changed, skipped
+}
+
+// recordAppliedArtwork remembers the images applied to a shortcut so the next
+// apply can skip them
+func recordAppliedArtwork(host string, appID uint32, art *shortcuts.ArtworkConfig) {
+	if art == nil {
+		return
+	}
+
+	slots := make(map[string]string)
+	for _, slot := range artworkSlots {
+		if url := *artworkSlotURL(art, slot.name); url != "" {
+			slots[slot.name] = url
+		}
+	}
+	if len(slots) == 0 {
+		return
+	}
+
+	if err := config.SetAppliedArtwork(host, appID, slots); err != nil {
+		slog.Warn("Failed to record applied artwork", "error", err)
+	}
+}
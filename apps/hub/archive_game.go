@@ -0,0 +1,174 @@
+package main
+
+import (
+	"compress/gzip"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+
+	"github.com/lobinuxsoft/capydeploy/internal/device"
+	"github.com/lobinuxsoft/capydeploy/internal/upload"
+	"github.com/wailsapp/wails/v2/pkg/runtime"
+)
+
+// =============================================================================
+// Archive Game To The Hub
+// =============================================================================
+
+// ArchiveGame pulls an installed game to this computer, either as a .tar.gz
+// or as a plain folder. Returns the chosen destination, or "" if the dialog was
+// cancelled. Progress is reported through "archive:progress" events.
+func (a *App) ArchiveGame(gamePath string, compress bool) (string, error) {
+	client, _, err := a.connectedClient()
+	if err != nil {
+		return "", err
+	}
+	homeDir, err := client.GetHomeDir()
+	if err != nil {
+		return "", err
+	}
+	gamePath, err = validateGamePath(client, gamePath, homeDir)
+	if err != nil {
+		return "", err
+	}
+	name := path.Base(gamePath)
+
+	var dest string
+	if compress {
+		dest, err = runtime.SaveFileDialog(a.ctx, runtime.SaveDialogOptions{
+			Title:           "Archive Game As",
+			DefaultFilename: sanitizeFilename(name) + ".tar.gz",
+			Filters:         []runtime.FileFilter{{DisplayName: "Gzipped tar archives", Pattern: "*.tar.gz"}},
+		})
+	} else {
+		dest, err = runtime.OpenDirectoryDialog(a.ctx, runtime.OpenDialogOptions{
+			Title:                "Choose Folder To Download Into",
+			CanCreateDirectories: true,
+		})
+	}
+	if err != nil || dest == "" {
+		return "", err
+	}
+	if !compress {
+		dest = filepath.Join(dest, sanitizeFilename(name))
+		if _, err := os.Stat(dest); err == nil {
+			return "", fmt.Errorf("%s already exists", dest)
+		}
+	}
+
+	go a.performArchive(client, gamePath, dest, compress)
+	return dest, nil
+}
+
+func (a *App) performArchive(client *device.Client, gamePath, dest string, compress bool) {
+	emitProgress := func(progress float64, status string, err string, done bool) {
+		runtime.EventsEmit(a.ctx, "archive:progress", UploadProgress{
+			Progress: progress,
+			Status:   status,
+			Error:    err,
+			Done:     done,
+		})
+	}
+	name := path.Base(gamePath)
+
+	var err error
+	if compress {
+		err = archiveCompressed(client, gamePath, dest, emitProgress)
+	} else {
+		err = archiveFolder(client, gamePath, dest, emitProgress)
+		if err != nil {
+			// The folder didn't exist before, only the partial download goes
+			os.RemoveAll(dest)
+		}
+	}
+	if err != nil {
+		emitProgress(0, "", err.Error(), true)
+		return
+	}
+	emitProgress(1, fmt.Sprintf("Archived %s to %s", name, dest), "", true)
+}
+
+// archiveCompressed streams a tar of the game and gzips it locally, so progress
+// can be measured against the size of the game on the device. A truncated
+// archive is removed.
+func archiveCompressed(client *device.Client, gamePath, dest string, emitProgress func(float64, string, string, bool)) (err error) {
+	total := remoteSize(client, gamePath)
+
+	f, err := os.Create(dest)
+	if err != nil {
+		return fmt.Errorf("failed to create archive: %w", err)
+	}
+	defer func() {
+		if err != nil {
+			f.Close()
+			os.Remove(dest)
+		}
+	}()
+
+	gz := gzip.NewWriter(f)
+	counter := &progressWriter{w: gz, report: func(written int64) {
+		progress := 0.0
+		if total > 0 {
+			progress = min(float64(written)/float64(total), 0.99)
+		}
+		emitProgress(progress, "Downloading and compressing...", "", false)
+	}}
+
+	cmd := fmt.Sprintf("tar -cf - -C %s -- %s", device.ShellQuote(path.Dir(gamePath)), device.ShellQuote(path.Base(gamePath)))
+	if err := client.StreamCommand(cmd, counter); err != nil {
+		return fmt.Errorf("failed to download game: %w", err)
+	}
+	if err := gz.Close(); err != nil {
+		return fmt.Errorf("failed to write archive: %w", err)
+	}
+	if err := f.Close(); err != nil {
+		return fmt.Errorf("failed to write archive: %w", err)
+	}
+	return nil
+}
+
+// archiveFolder downloads every file of the game into dest
+func archiveFolder(client *device.Client, gamePath, dest string, emitProgress func(float64, string, string, bool)) error {
+	files, err := upload.RemoteIndex(client, gamePath)
+	if err != nil {
+		return fmt.Errorf("failed to list files: %w", err)
+	}
+	var total, done int64
+	for _, info := range files {
+		total += info.Size
+	}
+
+	for relPath, info := range files {
+		progress := 0.0
+		if total > 0 {
+			progress = float64(done) / float64(total)
+		}
+		emitProgress(progress, fmt.Sprintf("Downloading: %s", relPath), "", false)
+
+		if err := client.DownloadFile(path.Join(gamePath, relPath), filepath.Join(dest, filepath.FromSlash(relPath))); err != nil {
+			return fmt.Errorf("failed to download %s: %w", relPath, err)
+		}
+		done += info.Size
+	}
+	return nil
+}
+
+// progressWriter counts the bytes written through it, reporting every few MB
+type progressWriter struct {
+	w        io.Writer
+	written  int64
+	reported int64
+	report   func(written int64)
+}
+
+func (p *progressWriter) Write(b []byte) (int, error) {
+	n, err := p.w.Write(b)
+	p.written += int64(n)
+	if p.written-p.reported >= 8<<20 {
+		p.reported = p.written
+		p.report(p.written)
+	}
+	return n, err
+}
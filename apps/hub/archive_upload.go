@@ -0,0 +1,19 @@
+package main
+
+import (
+	"github.com/wailsapp/wails/v2/pkg/runtime"
+)
+
+// =============================================================================
+// Archive Deploys
+// =============================================================================
+
+// SelectArchive opens a file dialog to pick a zipped build, e.g. a CI artifact
+func (a *App) SelectArchive() (string, error) {
+	return runtime.OpenFileDialog(a.ctx, runtime.OpenDialogOptions{
+		Title: "Select Game Archive",
+		Filters: []runtime.FileFilter{
+			{DisplayName: "Game Archives (*.zip, *.tar.gz, *.tgz)", Pattern: "*.zip;*.tar.gz;*.tgz"},
+		},
+	})
+}
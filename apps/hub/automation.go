@@ -0,0 +1,497 @@
+package main
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+	"net/http"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/lobinuxsoft/capydeploy/internal/deploy"
+	"github.com/lobinuxsoft/capydeploy/internal/device"
+	"github.com/lobinuxsoft/capydeploy/pkg/config"
+	"github.com/lobinuxsoft/capydeploy/pkg/engine"
+)
+
+// =============================================================================
+// Automation API
+// =============================================================================
+
+// maxAPIRequestBytes bounds the JSON body of automation requests
+const maxAPIRequestBytes = 1 << 20
+
+// APIDevice is a saved device as listed by the automation API, without its
+// credentials
+type APIDevice struct {
+	Name      string `json:"name"`
+	Host      string `json:"host"`
+	Port      int    `json:"port"`
+	User      string `json:"user"`
+	Connected bool   `json:"connected"`
+}
+
+// APISetup is a game setup as listed by the automation API
+type APISetup struct {
+	ID         string `json:"id"`
+	Name       string `json:"name"`
+	LocalPath  string `json:"localPath"`
+	RemotePath string `json:"remotePath"`
+}
+
+// deployRequest starts a deploy of a game setup, chosen by ID or name, on a
+// saved device chosen by name or host. Without a device the connected one is used.
+type deployRequest struct {
+	Setup  string `json:"setup"`
+	Device string `json:"device,omitempty"`
+	// Launch the game once the deploy succeeds
+	Launch bool `json:"launch,omitempty"`
+}
+
+// buildDeployRequest deploys an engine build folder, which must be an absolute
+// path. Its game setup is found by folder or name, or created on the first deploy.
+type buildDeployRequest struct {
+	Folder string `json:"folder"`
+	Name   string `json:"name,omitempty"`
+	Device string `json:"device,omitempty"`
+	Launch bool   `json:"launch,omitempty"`
+}
+
+// launchRequest launches an installed game by name. Path is the games
+// directory on the device, the default remote path when empty.
+type launchRequest struct {
+	Game string `json:"game"`
+	Path string `json:"path,omitempty"`
+}
+
+// GetAutomationSettings returns the automation API settings
+func (a *App) GetAutomationSettings() (config.AutomationSettings, error) {
+	return config.GetAutomationSettings()
+}
+
+// SetAutomationSettings saves the automation API settings and restarts the API
+// with them
+func (a *App) SetAutomationSettings(settings config.AutomationSettings) (config.AutomationSettings, error) {
+	if err := config.SetAutomationSettings(settings); err != nil {
+		return settings, err
+	}
+	saved, err := config.GetAutomationSettings()
+	if err != nil {
+		return saved, err
+	}
+	return saved, a.restartAutomationAPI(saved)
+}
+
+// RegenerateAutomationToken replaces the API token, invalidating the old one
+func (a *App) RegenerateAutomationToken() (config.AutomationSettings, error) {
+	settings, err := config.GetAutomationSettings()
+	if err != nil {
+		return settings, err
+	}
+	token, err := config.NewAutomationToken()
+	if err != nil {
+		return settings, err
+	}
+	settings.Token = token
+	return a.SetAutomationSettings(settings)
+}
+
+// startAutomationAPI starts the API at startup when it is enabled
+func (a *App) startAutomationAPI() {
+	settings, err := config.GetAutomationSettings()
+	if err != nil || !settings.Enabled {
+		return
+	}
+	if err := a.restartAutomationAPI(settings); err != nil {
+		slog.Warn("Failed to start automation API", "error", err)
+	}
+}
+
+// restartAutomationAPI stops the running API and, when enabled, serves it again
+// on 127.0.0.1 so only local tools can reach it
+func (a *App) restartAutomationAPI(settings config.AutomationSettings) error {
+	a.mu.Lock()
+	previous := a.automation
+	a.automation = nil
+	a.mu.Unlock()
+	if previous != nil {
+		previous.Close()
+		slog.Info("Automation API stopped")
+	}
+	if !settings.Enabled || settings.Token == "" {
+		return nil
+	}
+
+	listener, err := net.Listen("tcp", fmt.Sprintf("127.0.0.1:%d", settings.Port))
+	if err != nil {
+		return fmt.Errorf("failed to listen on port %d: %w", settings.Port, err)
+	}
+	server := &http.Server{
+		Handler:           requireToken(settings.Token, a.automationRoutes()),
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	a.mu.Lock()
+	a.automation = server
+	a.mu.Unlock()
+
+	go func() {
+		if err := server.Serve(listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			slog.Warn("Automation API failed", "error", err)
+		}
+	}()
+	slog.Info("Automation API listening", "address", listener.Addr().String())
+	return nil
+}
+
+// automationRoutes maps the API endpoints to the same operations the GUI uses
+func (a *App) automationRoutes() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /api/v1/devices", a.apiDevices)
+	mux.HandleFunc("GET /api/v1/setups", a.apiSetups)
+	mux.HandleFunc("GET /api/v1/games", a.apiGames)
+	mux.HandleFunc("POST /api/v1/deploy", a.apiDeploy)
+	mux.HandleFunc("GET /api/v1/deploy", a.apiDeployStatus)
+	mux.HandleFunc("POST /api/v1/deploy/build", a.apiDeployBuild)
+	mux.HandleFunc("POST /api/v1/launch", a.apiLaunch)
+	return mux
+}
+
+// requireToken rejects requests without "Authorization: Bearer <token>"
+func requireToken(token string, next http.Handler) http.Handler {
+	want := []byte("Bearer " + token)
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got := []byte(r.Header.Get("Authorization"))
+		if subtle.ConstantTimeCompare(got, want) != 1 {
+			writeAPIError(w, http.StatusUnauthorized, fmt.Errorf("missing or invalid token"))
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// apiDevices lists the saved devices and which one is connected
+func (a *App) apiDevices(w http.ResponseWriter, r *http.Request) {
+	devices, err := config.GetDevices()
+	if err != nil {
+		writeAPIError(w, http.StatusInternalServerError, err)
+		return
+	}
+	status := a.GetConnectionStatus()
+
+	result := make([]APIDevice, 0, len(devices))
+	for _, d := range devices {
+		result = append(result, APIDevice{
+			Name:      d.Name,
+			Host:      d.Host,
+			Port:      d.Port,
+			User:      d.User,
+			Connected: status.Connected && status.Host == d.Host,
+		})
+	}
+	writeAPIJSON(w, http.StatusOK, result)
+}
+
+// apiSetups lists the game setups that can be deployed
+func (a *App) apiSetups(w http.ResponseWriter, r *http.Request) {
+	setups, err := config.GetGameSetups()
+	if err != nil {
+		writeAPIError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	result := make([]APISetup, 0, len(setups))
+	for _, s := range setups {
+		result = append(result, APISetup{ID: s.ID, Name: s.Name, LocalPath: s.LocalPath, RemotePath: s.RemotePath})
+	}
+	writeAPIJSON(w, http.StatusOK, result)
+}
+
+// apiGames lists the games installed on the connected device
+func (a *App) apiGames(w http.ResponseWriter, r *http.Request) {
+	if _, _, err := a.connectedClient(); err != nil {
+		writeAPIError(w, http.StatusConflict, err)
+		return
+	}
+	games, err := a.GetInstalledGames(apiRemotePath(r.URL.Query().Get("path")))
+	if err != nil {
+		writeAPIError(w, http.StatusBadGateway, err)
+		return
+	}
+	writeAPIJSON(w, http.StatusOK, games)
+}
+
+// apiDeploy starts a deploy and returns right away; its progress is read with
+// GET /api/v1/deploy
+func (a *App) apiDeploy(w http.ResponseWriter, r *http.Request) {
+	var req deployRequest
+	if err := decodeAPIRequest(w, r, &req); err != nil {
+		writeAPIError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	setup, err := findSetup(func(s config.GameSetup) bool { return s.ID == req.Setup || s.Name == req.Setup })
+	if err != nil {
+		writeAPIError(w, http.StatusInternalServerError, err)
+		return
+	}
+	if setup == nil {
+		writeAPIError(w, http.StatusNotFound, fmt.Errorf("game setup not found: %s", req.Setup))
+		return
+	}
+	a.startDeploy(w, setup, req.Device, req.Launch)
+}
+
+// apiDeployBuild deploys an engine build folder right after it is built. The
+// first build of a folder creates its game setup from the detected layout.
+func (a *App) apiDeployBuild(w http.ResponseWriter, r *http.Request) {
+	var req buildDeployRequest
+	if err := decodeAPIRequest(w, r, &req); err != nil {
+		writeAPIError(w, http.StatusBadRequest, err)
+		return
+	}
+	layout, err := a.DetectBuildLayout(req.Folder)
+	if err != nil {
+		writeAPIError(w, http.StatusBadRequest, err)
+		return
+	}
+	folder := filepath.Clean(req.Folder)
+
+	setup, err := findSetup(func(s config.GameSetup) bool {
+		return filepath.Clean(s.LocalPath) == folder || (req.Name != "" && s.Name == req.Name)
+	})
+	if err != nil {
+		writeAPIError(w, http.StatusInternalServerError, err)
+		return
+	}
+	if setup == nil {
+		setup, err = a.newBuildSetup(folder, req.Name, layout)
+		if err != nil {
+			writeAPIError(w, http.StatusUnprocessableEntity, err)
+			return
+		}
+		slog.Info("Game setup created by automation API", "setup", setup.Name)
+	}
+	a.startDeploy(w, setup, req.Device, req.Launch)
+}
+
+// newBuildSetup saves a game setup for a build folder, named after the folder
+// unless a name is given
+func (a *App) newBuildSetup(folder, name string, layout *engine.Layout) (*config.GameSetup, error) {
+	if name == "" {
+		name = filepath.Base(folder)
+	}
+	setup := config.GameSetup{
+		ID:         fmt.Sprintf("game_%d", time.Now().UnixNano()),
+		Name:       name,
+		LocalPath:  folder,
+		RemotePath: config.DefaultRemotePath,
+	}
+	if layout != nil {
+		setup.Executable = layout.Executable
+		setup.Exclude = layout.Exclude
+	} else {
+		candidates, err := a.FindExecutables(folder)
+		if err != nil {
+			return nil, err
+		}
+		if len(candidates) == 0 {
+			return nil, fmt.Errorf("no executable found in %s", folder)
+		}
+		setup.Executable = candidates[0]
+	}
+	if options, err := config.GetDefaultLaunchOptions(); err == nil {
+		setup.LaunchOptions = options
+	}
+	if layout != nil && layout.LaunchOptions != "" {
+		setup.LaunchOptions = strings.TrimSpace(setup.LaunchOptions + " " + layout.LaunchOptions)
+	}
+
+	if err := config.AddGameSetup(setup); err != nil {
+		return nil, err
+	}
+	return &setup, nil
+}
+
+// findSetup returns the first saved game setup that matches, or nil
+func findSetup(match func(config.GameSetup) bool) (*config.GameSetup, error) {
+	setups, err := config.GetGameSetups()
+	if err != nil {
+		return nil, err
+	}
+	for i := range setups {
+		if match(setups[i]) {
+			return &setups[i], nil
+		}
+	}
+	return nil, nil
+}
+
+// startDeploy connects to the requested device and deploys the setup in the
+// background, answering with the setup being deployed
+func (a *App) startDeploy(w http.ResponseWriter, setup *config.GameSetup, deviceName string, launch bool) {
+	if deviceName != "" {
+		if err := a.apiConnect(deviceName); err != nil {
+			writeAPIError(w, http.StatusBadGateway, err)
+			return
+		}
+	}
+
+	a.mu.Lock()
+	if a.uploadStatus != nil && !a.uploadStatus.Done {
+		a.mu.Unlock()
+		writeAPIError(w, http.StatusConflict, fmt.Errorf("a deploy is already running"))
+		return
+	}
+	a.uploadStatus = &UploadProgress{Status: "Queued"}
+	a.mu.Unlock()
+
+	client, deviceCfg, err := a.connectedClient()
+	if err != nil {
+		a.mu.Lock()
+		a.uploadStatus = &UploadProgress{Error: err.Error(), Done: true}
+		a.mu.Unlock()
+		writeAPIError(w, http.StatusConflict, err)
+		return
+	}
+	go func() {
+		a.performUpload(client, &deviceCfg, setup, !setup.FullUpload)
+		if launch {
+			a.launchDeployed(client, setup)
+		}
+	}()
+	slog.Info("Deploy started by automation API", "setup", setup.Name, "launch", launch)
+	writeAPIJSON(w, http.StatusAccepted, APISetup{ID: setup.ID, Name: setup.Name, LocalPath: setup.LocalPath, RemotePath: setup.RemotePath})
+}
+
+// launchDeployed launches a game right after a successful deploy and records
+// the outcome in the deploy status
+func (a *App) launchDeployed(client *device.Client, setup *config.GameSetup) {
+	a.mu.RLock()
+	failed := a.uploadStatus == nil || a.uploadStatus.Error != ""
+	a.mu.RUnlock()
+	if failed {
+		return
+	}
+
+	remotePath, err := deploy.ExpandRemotePath(client, setup.RemotePath)
+	if err == nil {
+		err = a.LaunchGame(path.Join(remotePath, setup.DeployName()))
+	}
+
+	a.mu.Lock()
+	status := *a.uploadStatus
+	if err != nil {
+		slog.Warn("Failed to launch deployed game", "game", setup.Name, "error", err)
+		status.Error = fmt.Sprintf("Deployed, but failed to launch: %v", err)
+	} else {
+		status.Launched = true
+	}
+	a.uploadStatus = &status
+	a.mu.Unlock()
+}
+
+// apiDeployStatus returns the progress of the running or last deploy
+func (a *App) apiDeployStatus(w http.ResponseWriter, r *http.Request) {
+	a.mu.RLock()
+	status := a.uploadStatus
+	a.mu.RUnlock()
+	if status == nil {
+		writeAPIError(w, http.StatusNotFound, fmt.Errorf("no deploy has run yet"))
+		return
+	}
+	writeAPIJSON(w, http.StatusOK, status)
+}
+
+// apiLaunch launches an installed game on the connected device
+func (a *App) apiLaunch(w http.ResponseWriter, r *http.Request) {
+	var req launchRequest
+	if err := decodeAPIRequest(w, r, &req); err != nil {
+		writeAPIError(w, http.StatusBadRequest, err)
+		return
+	}
+	if _, _, err := a.connectedClient(); err != nil {
+		writeAPIError(w, http.StatusConflict, err)
+		return
+	}
+
+	// Only games found on the device can be launched, never an arbitrary path
+	games, err := a.GetInstalledGames(apiRemotePath(req.Path))
+	if err != nil {
+		writeAPIError(w, http.StatusBadGateway, err)
+		return
+	}
+	var game *InstalledGame
+	for i := range games {
+		if games[i].Name == req.Game {
+			game = &games[i]
+			break
+		}
+	}
+	if game == nil {
+		writeAPIError(w, http.StatusNotFound, fmt.Errorf("game not installed: %s", req.Game))
+		return
+	}
+
+	if err := a.LaunchGame(game.Path); err != nil {
+		writeAPIError(w, http.StatusBadGateway, err)
+		return
+	}
+	slog.Info("Game launched by automation API", "game", game.Name)
+	writeAPIJSON(w, http.StatusOK, game)
+}
+
+// apiConnect connects to the saved device with this name or host, unless it
+// is already the connected one
+func (a *App) apiConnect(nameOrHost string) error {
+	devices, err := config.GetDevices()
+	if err != nil {
+		return err
+	}
+	for _, d := range devices {
+		if d.Name != nameOrHost && d.Host != nameOrHost {
+			continue
+		}
+		if status := a.GetConnectionStatus(); status.Connected && status.Host == d.Host {
+			return nil
+		}
+		return a.ConnectDevice(d.Host)
+	}
+	return fmt.Errorf("device not found: %s", nameOrHost)
+}
+
+// apiRemotePath returns the games directory to use, the default remote path
+// when none is given
+func apiRemotePath(remotePath string) string {
+	if remotePath = strings.TrimSpace(remotePath); remotePath != "" {
+		return remotePath
+	}
+	if cfg, err := config.Load(); err == nil && cfg.DefaultRemotePath != "" {
+		return cfg.DefaultRemotePath
+	}
+	return config.DefaultRemotePath
+}
+
+func decodeAPIRequest(w http.ResponseWriter, r *http.Request, v any) error {
+	dec := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxAPIRequestBytes))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(v); err != nil {
+		return fmt.Errorf("invalid request body: %w", err)
+	}
+	return nil
+}
+
+func writeAPIJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
+
+func writeAPIError(w http.ResponseWriter, status int, err error) {
+	writeAPIJSON(w, status, map[string]string{"error": err.Error()})
+}
@@ -0,0 +1,240 @@
+package main
+
+import (
+	"fmt"
+	"log/slog"
+
+	"github.com/wailsapp/wails/v2/pkg/runtime"
+
+	"github.com/lobinuxsoft/capydeploy/internal/deploy"
+	"github.com/lobinuxsoft/capydeploy/internal/shortcuts"
+	"github.com/lobinuxsoft/capydeploy/pkg/config"
+	"github.com/lobinuxsoft/capydeploy/pkg/steamgriddb"
+)
+
+// =============================================================================
+// Batch Artwork
+// =============================================================================
+
+// ShortcutEntry is a Steam shortcut on the connected device
+type ShortcutEntry struct {
+	Name          string `json:"name"`
+	Exe           string `json:"exe"`
+	StartDir      string `json:"startDir"`
+	LaunchOptions string `json:"launchOptions"`
+	AppID         uint64 `json:"appId"`
+}
+
+// ArtworkSuggestion is the automatically picked artwork for a shortcut, to be reviewed
+type ArtworkSuggestion struct {
+	Name          string `json:"name"`
+	AppID         uint64 `json:"appId"`
+	GridDBGameID  int    `json:"gridDBGameID"`
+	GridDBName    string `json:"gridDBName"`
+	GridPortrait  string `json:"gridPortrait"`
+	GridLandscape string `json:"gridLandscape"`
+	HeroImage     string `json:"heroImage"`
+	LogoImage     string `json:"logoImage"`
+	IconImage     string `json:"iconImage"`
+	Error         string `json:"error,omitempty"`
+}
+
+// BatchArtworkProgress is emitted while artwork is applied to several shortcuts
+type BatchArtworkProgress struct {
+	Current int    `json:"current"`
+	Total   int    `json:"total"`
+	Name    string `json:"name"`
+	Error   string `json:"error,omitempty"`
+	Done    bool   `json:"done"`
+}
+
+// GetShortcuts returns the Steam shortcuts on the connected device
+func (a *App) GetShortcuts() ([]ShortcutEntry, error) {
+	client, deviceCfg, err := a.connectedClient()
+	if err != nil {
+		return nil, err
+	}
+
+	list, err := shortcuts.ListShortcuts(remoteConfig(client, deviceCfg))
+	if err != nil {
+		return nil, err
+	}
+
+	// Shortcuts are listed once per Steam user, keep one entry per app
+	seen := make(map[uint64]bool)
+	result := []ShortcutEntry{}
+	for _, sc := range list {
+		appID := uint64(uint32(sc.AppID))
+		if seen[appID] {
+			continue
+		}
+		seen[appID] = true
+		result = append(result, ShortcutEntry{
+			Name:          sc.Name,
+			Exe:           sc.Exe,
+			StartDir:      sc.StartDir,
+			LaunchOptions: sc.LaunchOptions,
+			AppID:         appID,
+		})
+	}
+	return result, nil
+}
+
+// SuggestArtwork searches SteamGridDB for each shortcut and picks the top scored assets.
+// In offline mode only cached results are considered.
+func (a *App) SuggestArtwork(entries []ShortcutEntry) ([]ArtworkSuggestion, error) {
+	if !a.isOffline() {
+		if _, err := a.sgdbClient(); err != nil {
+			return nil, err
+		}
+	}
+
+	filters := steamgriddb.ImageFilters{}
+	suggestions := make([]ArtworkSuggestion, 0, len(entries))
+	for _, entry := range entries {
+		s := ArtworkSuggestion{Name: entry.Name, AppID: entry.AppID}
+
+		results, err := a.SearchGames(entry.Name)
+		if err != nil {
+			s.Error = err.Error()
+			suggestions = append(suggestions, s)
+			continue
+		}
+		match := steamgriddb.BestMatch(results, entry.Name)
+		if match == nil {
+			s.Error = "not found on SteamGridDB"
+			suggestions = append(suggestions, s)
+			continue
+		}
+		s.GridDBGameID = match.ID
+		s.GridDBName = match.Name
+
+		if grids, err := a.GetGrids(match.ID, filters, 0); err == nil {
+			if g := steamgriddb.BestGrid(grids.Items, true); g != nil {
+				s.GridPortrait = g.URL
+			}
+			if g := steamgriddb.BestGrid(grids.Items, false); g != nil {
+				s.GridLandscape = g.URL
+			}
+		}
+		if heroes, err := a.GetHeroes(match.ID, filters, 0); err == nil {
+			if img := steamgriddb.BestImage(heroes.Items); img != nil {
+				s.HeroImage = img.URL
+			}
+		}
+		if logos, err := a.GetLogos(match.ID, filters, 0); err == nil {
+			if img := steamgriddb.BestImage(logos.Items); img != nil {
+				s.LogoImage = img.URL
+			}
+		}
+		if icons, err := a.GetIcons(match.ID, filters, 0); err == nil {
+			if img := steamgriddb.BestImage(icons.Items); img != nil {
+				s.IconImage = img.URL
+			}
+		}
+
+		suggestions = append(suggestions, s)
+	}
+
+	return suggestions, nil
+}
+
+// ApplyBatchArtwork applies reviewed suggestions to their shortcuts in the background.
+// Progress is reported through "batchartwork:progress" events.
+func (a *App) ApplyBatchArtwork(items []ArtworkSuggestion) error {
+	client, deviceCfg, err := a.connectedClient()
+	if err != nil {
+		return err
+	}
+
+	cfg, err := config.Load()
+	if err != nil {
+		return err
+	}
+	remoteDir, err := deploy.ExpandRemotePath(client, cfg.DefaultRemotePath)
+	if err != nil {
+		return fmt.Errorf("failed to expand remote path: %w", err)
+	}
+
+	go func() {
+		emit := func(p BatchArtworkProgress) {
+			runtime.EventsEmit(a.ctx, "batchartwork:progress", p)
+		}
+
+		binaryPath, err := deploy.EnsureShortcutManager(client, remoteDir)
+		if err != nil {
+			emit(BatchArtworkProgress{Total: len(items), Error: fmt.Sprintf("Failed to upload binary: %v", err), Done: true})
+			return
+		}
+
+		remoteCfg := remoteConfig(client, deviceCfg)
+		offline := a.isOffline()
+		var failed int
+		for i, item := range items {
+			emit(BatchArtworkProgress{Current: i, Total: len(items), Name: item.Name})
+
+			art := &shortcuts.ArtworkConfig{
+				GridPortrait:  item.GridPortrait,
+				GridLandscape: item.GridLandscape,
+				HeroImage:     item.HeroImage,
+				LogoImage:     item.LogoImage,
+				IconImage:     item.IconImage,
+			}
+			for _, warning := range a.resolveTranscoded(art) {
+				slog.Warn(warning, "game", item.Name)
+			}
+			if warning := a.resolveIconUpscale(art); warning != "" {
+				slog.Warn(warning, "game", item.Name)
+			}
+
+			requested := art
+			art, _ = a.skipUnchangedArtwork(client, deviceCfg.Host, uint32(item.AppID), art)
+
+			remoteArt, localArt := splitLocalArtwork(art, offline)
+			var err error
+			if remoteArt != nil {
+				err = shortcuts.ApplyArtwork(remoteCfg, item.AppID, remoteArt, binaryPath)
+			}
+			if err == nil && localArt != nil {
+				err = a.writeLocalArtwork(client, item.AppID, localArt)
+			}
+			if err == nil {
+				recordAppliedArtwork(deviceCfg.Host, uint32(item.AppID), art)
+			}
+			if err == nil {
+				if summary, ok := artworkCheckSummary(verifyArtwork(client, uint32(item.AppID), requested)); !ok {
+					err = fmt.Errorf("%s", summary)
+				}
+			}
+			if err != nil {
+				failed++
+				emit(BatchArtworkProgress{Current: i, Total: len(items), Name: item.Name, Error: err.Error()})
+				continue
+			}
+
+			config.AddRecentArtwork(suggestionArtwork(item)...)
+		}
+
+		shortcuts.RefreshSteamLibrary(remoteCfg)
+
+		done := BatchArtworkProgress{Current: len(items), Total: len(items), Done: true}
+		if failed > 0 {
+			done.Error = fmt.Sprintf("%d of %d shortcuts failed", failed, len(items))
+		}
+		emit(done)
+	}()
+
+	return nil
+}
+
+// suggestionArtwork returns the applied slots of a suggestion as history entries
+func suggestionArtwork(item ArtworkSuggestion) []config.ArtworkRef {
+	return appliedArtwork(&config.GameSetup{
+		Name:          item.Name,
+		GridPortrait:  item.GridPortrait,
+		GridLandscape: item.GridLandscape,
+		HeroImage:     item.HeroImage,
+		LogoImage:     item.LogoImage,
+		IconImage:     item.IconImage,
+	})
+}
@@ -9,6 +9,7 @@ echo.
 :: Parse arguments
 set "MODE=production"
 set "SKIP_DEPS=0"
+if "%VERSION%"=="" set "VERSION=dev"
 
 :parse_args
 if "%~1"=="" goto :done_args
@@ -139,7 +140,7 @@ if "%MODE%"=="dev" (
     echo [4/5] Building production binary...
     echo.
 
-    wails build -clean
+    wails build -clean -ldflags "-X main.Version=%VERSION%"
     if %ERRORLEVEL% neq 0 (
         echo.
         echo ============================================
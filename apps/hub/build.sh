@@ -17,6 +17,7 @@ NC='\033[0m' # No Color
 # Default values
 MODE="production"
 SKIP_DEPS=0
+VERSION="${VERSION:-dev}"
 
 # Parse arguments
 while [[ $# -gt 0 ]]; do
@@ -165,7 +166,7 @@ else
     echo -e "${YELLOW}[4/5]${NC} Building production binary..."
     echo
 
-    if ! wails build -clean; then
+    if ! wails build -clean -ldflags "-X main.Version=$VERSION"; then
         echo
         echo "============================================"
         echo -e "  ${RED}BUILD FAILED${NC}"
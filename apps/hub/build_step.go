@@ -0,0 +1,139 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/wailsapp/wails/v2/pkg/runtime"
+
+	"github.com/lobinuxsoft/capydeploy/pkg/config"
+)
+
+// =============================================================================
+// Build Step
+// =============================================================================
+
+// maxBuildLineBytes bounds a single line of build output, longer lines are split
+const maxBuildLineBytes = 64 * 1024
+
+// BuildOutput is a line printed by the build step of a game setup
+type BuildOutput struct {
+	Line   string `json:"line"`
+	Stderr bool   `json:"stderr,omitempty"`
+	// Set on the first line of a build, the command being run
+	Start bool `json:"start,omitempty"`
+	// Set on the output of the post-deploy command, which runs on the device
+	Device bool `json:"device,omitempty"`
+}
+
+// validateBuildStep checks the build step of a setup, when it has one
+func validateBuildStep(step *config.BuildStep) error {
+	if step == nil {
+		return nil
+	}
+	return step.Validate()
+}
+
+// runBuildStep runs the build step of a setup on this machine, streaming its
+// output to the frontend, and returns the folder to deploy. Cancelling ctx
+// kills the build.
+func (a *App) runBuildStep(ctx context.Context, setup *config.GameSetup) (string, error) {
+	step := setup.Build
+	if err := step.Validate(); err != nil {
+		return "", err
+	}
+	dir := step.WorkingDir
+	if dir == "" {
+		dir = setup.LocalPath
+	}
+
+	// The command runs directly, never through a shell, so arguments are passed as is
+	cmd := exec.CommandContext(ctx, step.Command, step.Args...)
+	cmd.Dir = dir
+	cmd.Env = append(os.Environ(), step.Env...)
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return "", err
+	}
+	stderr, err := cmd.StderrPipe()
+	if err != nil {
+		return "", err
+	}
+
+	// Variables are left out of the log, they may hold credentials
+	slog.Info("Running build step", "setup", setup.Name, "command", step.Command, "dir", dir)
+	a.emitBuildOutput(BuildOutput{Line: strings.Join(append([]string{step.Command}, step.Args...), " "), Start: true})
+	if err := cmd.Start(); err != nil {
+		return "", fmt.Errorf("failed to start %s: %w", step.Command, err)
+	}
+
+	var wg sync.WaitGroup
+	wg.Add(2)
+	go a.streamBuildOutput(&wg, stdout, BuildOutput{})
+	go a.streamBuildOutput(&wg, stderr, BuildOutput{Stderr: true})
+	wg.Wait()
+	if err := cmd.Wait(); err != nil {
+		return "", fmt.Errorf("%s: %w", step.Command, err)
+	}
+
+	output := setup.LocalPath
+	if step.OutputDir != "" {
+		output = filepath.Join(dir, step.OutputDir)
+	}
+	info, err := os.Stat(output)
+	if err != nil || !info.IsDir() {
+		return "", fmt.Errorf("build output folder %s not found", output)
+	}
+	slog.Info("Build step finished", "setup", setup.Name, "output", output)
+	return output, nil
+}
+
+// streamBuildOutput sends each line read from r to the frontend, flagged as in kind
+func (a *App) streamBuildOutput(wg *sync.WaitGroup, r io.Reader, kind BuildOutput) {
+	defer wg.Done()
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 4096), maxBuildLineBytes)
+	for scanner.Scan() {
+		kind.Line = scanner.Text()
+		a.emitBuildOutput(kind)
+	}
+	// Keep draining after an oversized line so the build never blocks on a full pipe
+	io.Copy(io.Discard, r)
+}
+
+// emitBuildOutput sends a line of build output to the frontend
+func (a *App) emitBuildOutput(output BuildOutput) {
+	runtime.EventsEmit(a.ctx, "build:output", output)
+}
+
+// postDeployOutput streams the output of a post-deploy command to the
+// frontend along with the build's, until it is closed
+func (a *App) postDeployOutput(command string) io.WriteCloser {
+	a.emitBuildOutput(BuildOutput{Line: command, Start: true, Device: true})
+	pr, pw := io.Pipe()
+	out := &buildOutputWriter{PipeWriter: pw}
+	out.wg.Add(1)
+	go a.streamBuildOutput(&out.wg, pr, BuildOutput{Device: true})
+	return out
+}
+
+// buildOutputWriter is a pipe to streamBuildOutput whose Close waits for the
+// last line to be sent
+type buildOutputWriter struct {
+	*io.PipeWriter
+	wg sync.WaitGroup
+}
+
+func (w *buildOutputWriter) Close() error {
+	err := w.PipeWriter.Close()
+	w.wg.Wait()
+	return err
+}
@@ -0,0 +1,216 @@
+package main
+
+import (
+	"fmt"
+	"log/slog"
+	"path"
+	"strconv"
+	"strings"
+
+	"github.com/lobinuxsoft/capydeploy/internal/deploy"
+	"github.com/lobinuxsoft/capydeploy/internal/device"
+	"github.com/lobinuxsoft/capydeploy/internal/shortcuts"
+	"github.com/lobinuxsoft/capydeploy/internal/upload"
+	"github.com/lobinuxsoft/capydeploy/pkg/config"
+	"github.com/wailsapp/wails/v2/pkg/runtime"
+)
+
+// =============================================================================
+// Clone Game Between Devices
+// =============================================================================
+
+// CloneGame copies an installed game from the connected device to another saved
+// device, relaying the files through the hub, and recreates its Steam shortcut
+// and artwork there. Progress is reported through "clone:progress" events.
+func (a *App) CloneGame(gamePath, targetHost string) error {
+	source, sourceCfg, err := a.connectedClient()
+	if err != nil {
+		return err
+	}
+	if targetHost == sourceCfg.Host {
+		return fmt.Errorf("%s is the connected device", sourceCfg.Name)
+	}
+
+	homeDir, err := source.GetHomeDir()
+	if err != nil {
+		return err
+	}
+	gamePath, err = validateGamePath(source, gamePath, homeDir)
+	if err != nil {
+		return err
+	}
+
+	devices, err := config.GetDevices()
+	if err != nil {
+		return fmt.Errorf("failed to get devices: %w", err)
+	}
+	var targetCfg *config.DeviceConfig
+	for i := range devices {
+		if devices[i].Host == targetHost {
+			targetCfg = &devices[i]
+			break
+		}
+	}
+	if targetCfg == nil {
+		return fmt.Errorf("device not found: %s", targetHost)
+	}
+
+	go a.performClone(source, sourceCfg, *targetCfg, gamePath, homeDir)
+	return nil
+}
+
+func (a *App) performClone(source *device.Client, sourceCfg, targetCfg config.DeviceConfig, gamePath, sourceHome string) {
+	emitProgress := func(progress float64, status string, err string, done bool) {
+		runtime.EventsEmit(a.ctx, "clone:progress", UploadProgress{
+			Progress: progress,
+			Status:   status,
+			Error:    err,
+			Done:     done,
+		})
+	}
+	name := path.Base(gamePath)
+
+	emitProgress(0, fmt.Sprintf("Connecting to %s...", targetCfg.Name), "", false)
+	target, err := newDeviceClient(targetCfg)
+	if err != nil {
+		emitProgress(0, "", fmt.Sprintf("Failed to create client: %v", err), true)
+		return
+	}
+	if err := target.Connect(); err != nil {
+		emitProgress(0, "", fmt.Sprintf("Connection to %s failed: %v", targetCfg.Name, err), true)
+		return
+	}
+	defer target.Close()
+
+	// What the game is and how it launches, from its manifest or else its shortcut
+	manifest, ok := deploy.ReadManifest(source, gamePath)
+	if !ok {
+		manifest = &deploy.Manifest{Name: name}
+	}
+	list, err := shortcuts.ListShortcuts(remoteConfig(source, sourceCfg))
+	if err != nil {
+		slog.Warn("Failed to list shortcuts", "device", sourceCfg.Name, "error", err)
+	}
+	sc := gameShortcut(list, &InstalledGame{Name: name, Path: gamePath, AppID: manifest.AppID})
+	if sc != nil {
+		if manifest.Executable == "" {
+			manifest.Executable = strings.TrimPrefix(strings.Trim(sc.Exe, `"`), gamePath+"/")
+		}
+		if manifest.LaunchOptions == "" {
+			manifest.LaunchOptions = sc.LaunchOptions
+		}
+	}
+	if manifest.Executable == "" || path.IsAbs(manifest.Executable) {
+		emitProgress(0, "", fmt.Sprintf("Cannot tell the executable of %s, it has no deploy manifest or Steam shortcut", name), true)
+		return
+	}
+	sourceAppID := manifest.AppID
+	if sc != nil {
+		sourceAppID = uint32(sc.AppID)
+	}
+
+	// Keep the location relative to the home directory, users may differ
+	targetHome, err := target.GetHomeDir()
+	if err != nil {
+		emitProgress(0, "", fmt.Sprintf("Failed to read home directory of %s: %v", targetCfg.Name, err), true)
+		return
+	}
+	targetPath := path.Join(targetHome, strings.TrimPrefix(gamePath, sourceHome+"/"))
+
+	// Only the current release of a game deployed with releases is copied,
+	// into the game folder on the target
+	sourcePath := deploy.ReleaseFilesPath(gamePath, manifest)
+	manifest.Release = ""
+
+	emitProgress(0.02, "Scanning files...", "", false)
+	files, err := upload.RemoteIndex(source, sourcePath)
+	if err != nil {
+		emitProgress(0, "", fmt.Sprintf("Failed to list files: %v", err), true)
+		return
+	}
+	var totalBytes, copiedBytes int64
+	for _, info := range files {
+		totalBytes += info.Size
+	}
+
+	for relPath, info := range files {
+		if relPath == deploy.ManifestName || relPath == deploy.LegacyManifestName {
+			continue
+		}
+		progress := 0.05
+		if totalBytes > 0 {
+			progress += float64(copiedBytes) / float64(totalBytes) * 0.8
+		}
+		emitProgress(progress, fmt.Sprintf("Copying: %s", relPath), "", false)
+
+		dest := path.Join(targetPath, relPath)
+		target.MkdirAll(path.Dir(dest))
+		if err := source.CopyFileTo(path.Join(sourcePath, relPath), target, dest); err != nil {
+			emitProgress(0, "", fmt.Sprintf("Failed to copy %s: %v", relPath, err), true)
+			return
+		}
+		copiedBytes += info.Size
+	}
+
+	emitProgress(0.87, "Checking steam-shortcut-manager binary...", "", false)
+	binaryRemotePath, err := deploy.EnsureShortcutManager(target, path.Dir(targetPath))
+	if err != nil {
+		emitProgress(0, "", fmt.Sprintf("Failed to upload binary: %v", err), true)
+		return
+	}
+
+	emitProgress(0.9, "Creating Steam shortcut...", "", false)
+	exePath := path.Join(targetPath, manifest.Executable)
+	var tags []string
+	if setup := findGameSetup(manifest.SetupID); setup != nil {
+		tags = shortcuts.ParseTags(setup.Tags)
+	}
+	if err := shortcuts.AddShortcutWithArtwork(remoteConfig(target, targetCfg), name, exePath, targetPath, manifest.LaunchOptions, tags, nil, binaryRemotePath); err != nil {
+		emitProgress(0, "", fmt.Sprintf("Failed to create shortcut: %v", err), true)
+		return
+	}
+	targetAppID := uint32(shortcuts.ShortcutAppID(exePath, name))
+
+	if sourceAppID != 0 {
+		emitProgress(0.95, "Copying artwork...", "", false)
+		if err := cloneGridFiles(source, target, sourceAppID, targetAppID); err != nil {
+			slog.Warn("Failed to copy artwork", "error", err)
+		}
+	}
+
+	manifest.AppID = targetAppID
+	if err := deploy.WriteManifest(target, targetPath, *manifest); err != nil {
+		slog.Warn("Failed to write deploy manifest", "error", err)
+	}
+
+	emitProgress(1, fmt.Sprintf("Copied %s to %s", name, targetCfg.Name), "", true)
+}
+
+// cloneGridFiles copies the artwork and logo position of a shortcut to every
+// Steam user on the target, renamed for the shortcut's app ID there
+func cloneGridFiles(source, target *device.Client, sourceAppID, targetAppID uint32) error {
+	targetDirs, err := shortcuts.GridDirs(target)
+	if err != nil {
+		return err
+	}
+
+	prefix := strconv.FormatUint(uint64(sourceAppID), 10)
+	seen := make(map[string]bool)
+	for _, file := range gridFilesFor(source, sourceAppID) {
+		// Every Steam user on the source has the same artwork
+		name := path.Base(file)
+		if seen[name] {
+			continue
+		}
+		seen[name] = true
+
+		targetName := strconv.FormatUint(uint64(targetAppID), 10) + strings.TrimPrefix(name, prefix)
+		for _, dir := range targetDirs {
+			target.MkdirAll(dir)
+			if err := source.CopyFileTo(file, target, path.Join(dir, targetName)); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,378 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"path"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/wailsapp/wails/v2/pkg/runtime"
+
+	"github.com/lobinuxsoft/capydeploy/internal/deploy"
+	"github.com/lobinuxsoft/capydeploy/internal/device"
+	"github.com/lobinuxsoft/capydeploy/pkg/steam"
+)
+
+// =============================================================================
+// Compatibility Tools
+// =============================================================================
+
+// geProtonReleasesURL lists the GE-Proton releases on GitHub
+const geProtonReleasesURL = "https://api.github.com/repos/GloriousEggroll/proton-ge-custom/releases"
+
+// geProtonDownloadPrefix is where every GE-Proton download must come from
+const geProtonDownloadPrefix = "https://github.com/GloriousEggroll/proton-ge-custom/releases/download/"
+
+// geProtonTagPattern matches GE-Proton release tags such as "GE-Proton9-20".
+// Tags and asset names end up in shell commands on the device.
+var geProtonTagPattern = regexp.MustCompile(`^GE-Proton[0-9A-Za-z._-]+$`)
+
+// CompatTool is a compatibility tool installed on the device
+type CompatTool struct {
+	Name        string `json:"name"` // internal name, the one Steam's config refers to
+	DisplayName string `json:"displayName"`
+	// Installed in compatibilitytools.d rather than by Steam
+	Custom bool `json:"custom"`
+}
+
+// GEProtonRelease is a GE-Proton release published on GitHub
+type GEProtonRelease struct {
+	Tag         string    `json:"tag"`
+	PublishedAt time.Time `json:"publishedAt"`
+	Size        int64     `json:"size"` // of the download
+	Installed   bool      `json:"installed"`
+
+	archive  geProtonAsset
+	checksum geProtonAsset
+}
+
+type geProtonAsset struct {
+	Name string `json:"name"`
+	Size int64  `json:"size"`
+	URL  string `json:"browser_download_url"`
+}
+
+// GetCompatTools lists the compatibility tools installed on the connected device
+func (a *App) GetCompatTools() ([]CompatTool, error) {
+	client, _, err := a.connectedClient()
+	if err != nil {
+		return nil, err
+	}
+	homeDir, err := client.GetHomeDir()
+	if err != nil {
+		return nil, err
+	}
+	return compatTools(client, homeDir), nil
+}
+
+// compatTools lists the custom tools in compatibilitytools.d, from their
+// compatibilitytool.vdf, and the Proton versions Steam installed
+func compatTools(client *device.Client, homeDir string) []CompatTool {
+	tools := []CompatTool{}
+	customDir := compatToolsDir(homeDir)
+	dirs, _ := client.ListDir(customDir)
+	for _, dir := range dirs {
+		data, err := client.ReadFile(path.Join(customDir, dir, "compatibilitytool.vdf"))
+		if err != nil {
+			continue
+		}
+		manifest, err := steam.ParseKeyValues(data)
+		if err != nil {
+			slog.Warn("Invalid compatibility tool manifest", "tool", dir, "error", err)
+			continue
+		}
+		for _, info := range steam.CompatToolsFromManifest(manifest) {
+			tools = append(tools, CompatTool{Name: info.Name, DisplayName: info.DisplayName, Custom: true})
+		}
+	}
+
+	common, _ := client.ListDir(path.Join(homeDir, ".steam", "steam", "steamapps", "common"))
+	for _, dir := range common {
+		if name := steam.OfficialProtonName(dir); name != "" {
+			tools = append(tools, CompatTool{Name: name, DisplayName: dir})
+		}
+	}
+	return tools
+}
+
+// compatToolsDir is where Steam looks for custom compatibility tools
+func compatToolsDir(homeDir string) string {
+	return path.Join(homeDir, ".steam", "steam", "compatibilitytools.d")
+}
+
+// GetGEProtonReleases lists the latest GE-Proton releases and which of them
+// are installed on the connected device
+func (a *App) GetGEProtonReleases() ([]GEProtonRelease, error) {
+	client, _, err := a.connectedClient()
+	if err != nil {
+		return nil, err
+	}
+	if a.isOffline() {
+		return nil, fmt.Errorf("GE-Proton releases are not available in offline mode")
+	}
+
+	var releases []GEProtonRelease
+	if err := fetchGitHubJSON(geProtonReleasesURL+"?per_page=10", &releases); err != nil {
+		return nil, err
+	}
+
+	homeDir, err := client.GetHomeDir()
+	if err != nil {
+		return nil, err
+	}
+	installed, _ := client.ListDir(compatToolsDir(homeDir))
+	result := []GEProtonRelease{}
+	for _, r := range releases {
+		if r.archive.URL == "" {
+			continue
+		}
+		for _, dir := range installed {
+			if dir == r.Tag {
+				r.Installed = true
+			}
+		}
+		result = append(result, r)
+	}
+	return result, nil
+}
+
+// UnmarshalJSON reads a release from the GitHub API, keeping its archive and
+// checksum downloads
+func (r *GEProtonRelease) UnmarshalJSON(data []byte) error {
+	var raw struct {
+		TagName     string          `json:"tag_name"`
+		PublishedAt time.Time       `json:"published_at"`
+		Assets      []geProtonAsset `json:"assets"`
+	}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+
+	*r = GEProtonRelease{Tag: raw.TagName, PublishedAt: raw.PublishedAt}
+	if !geProtonTagPattern.MatchString(raw.TagName) {
+		return nil
+	}
+	for _, asset := range raw.Assets {
+		if !geProtonTagPattern.MatchString(asset.Name) || !strings.HasPrefix(asset.URL, geProtonDownloadPrefix) ||
+			strings.ContainsAny(asset.URL, "\"'`$\\ ") {
+			continue
+		}
+		switch {
+		case strings.HasSuffix(asset.Name, ".tar.gz"):
+			r.archive = asset
+			r.Size = asset.Size
+		case strings.HasSuffix(asset.Name, ".sha512sum"):
+			r.checksum = asset
+		}
+	}
+	return nil
+}
+
+// fetchGitHubJSON decodes a GitHub API response into v
+func fetchGitHubJSON(url string, v any) error {
+	req, err := http.NewRequest(http.MethodGet, url, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/vnd.github+json")
+
+	resp, err := newHTTPClient().Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to reach GitHub: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("GitHub returned %s", resp.Status)
+	}
+	return json.NewDecoder(resp.Body).Decode(v)
+}
+
+// InstallGEProton downloads a GE-Proton release on the connected device, checks
+// it against its published checksum and extracts it into compatibilitytools.d.
+// Progress is reported through "compattool:progress" events.
+func (a *App) InstallGEProton(tag string) error {
+	if !geProtonTagPattern.MatchString(tag) {
+		return fmt.Errorf("invalid GE-Proton release: %q", tag)
+	}
+	client, _, err := a.connectedClient()
+	if err != nil {
+		return err
+	}
+
+	// The download comes from the release itself, never from the frontend
+	var release GEProtonRelease
+	if err := fetchGitHubJSON(geProtonReleasesURL+"/tags/"+tag, &release); err != nil {
+		return err
+	}
+	if release.archive.URL == "" {
+		return fmt.Errorf("%s has no download", tag)
+	}
+
+	go a.performGEProtonInstall(client, release)
+	return nil
+}
+
+func (a *App) performGEProtonInstall(client *device.Client, release GEProtonRelease) {
+	emitProgress := func(progress float64, status string, err string, done bool) {
+		runtime.EventsEmit(a.ctx, "compattool:progress", UploadProgress{
+			Progress: progress,
+			Status:   status,
+			Error:    err,
+			Done:     done,
+		})
+	}
+	fail := func(format string, args ...any) {
+		msg := fmt.Sprintf(format, args...)
+		slog.Warn("GE-Proton install failed", "release", release.Tag, "error", msg)
+		emitProgress(0, "", msg, true)
+	}
+
+	homeDir, err := client.GetHomeDir()
+	if err != nil {
+		fail("Failed to get home directory: %v", err)
+		return
+	}
+	output, err := client.RunCommand("mktemp -d")
+	if err != nil {
+		fail("Failed to create temporary directory: %v", err)
+		return
+	}
+	tmp := strings.TrimSpace(output)
+	defer client.RunCommand(fmt.Sprintf("rm -rf -- %s", device.ShellQuote(tmp)))
+
+	emitProgress(0.1, fmt.Sprintf("Downloading %s (%d MB)...", release.Tag, release.Size>>20), "", false)
+	download := "cd %[1]s && { curl -fsSL -o %[2]s %[3]s || wget -q -O %[2]s %[3]s; } 2>&1"
+	assets := []geProtonAsset{release.archive}
+	if release.checksum.URL != "" {
+		assets = append(assets, release.checksum)
+	}
+	for _, asset := range assets {
+		if out, err := client.RunCommand(fmt.Sprintf(download, device.ShellQuote(tmp), device.ShellQuote(asset.Name), device.ShellQuote(asset.URL))); err != nil {
+			fail("Failed to download %s: %v %s", asset.Name, err, deploy.LastLine(out))
+			return
+		}
+	}
+
+	if release.checksum.URL != "" {
+		emitProgress(0.7, "Verifying checksum...", "", false)
+		if out, err := client.RunCommand(fmt.Sprintf("cd %s && sha512sum -c %s 2>&1", device.ShellQuote(tmp), device.ShellQuote(release.checksum.Name))); err != nil {
+			fail("Checksum mismatch: %s", deploy.LastLine(out))
+			return
+		}
+	} else {
+		slog.Warn("GE-Proton release has no checksum, installing unverified", "release", release.Tag)
+	}
+
+	emitProgress(0.8, "Extracting...", "", false)
+	dir := compatToolsDir(homeDir)
+	if out, err := client.RunCommand(fmt.Sprintf("mkdir -p %[1]s && tar -xzf %[2]s -C %[1]s 2>&1", device.ShellQuote(dir), device.ShellQuote(path.Join(tmp, release.archive.Name)))); err != nil {
+		fail("Failed to extract %s: %v %s", release.archive.Name, err, deploy.LastLine(out))
+		return
+	}
+
+	slog.Info("GE-Proton installed", "release", release.Tag)
+	emitProgress(1, fmt.Sprintf("%s installed. Steam lists it after restarting.", release.Tag), "", true)
+}
+
+// GetGameCompatTool returns the compatibility tool forced for an installed
+// game's shortcut, "" when it uses Steam's default
+func (a *App) GetGameCompatTool(gamePath string) (string, error) {
+	client, _, err := a.connectedClient()
+	if err != nil {
+		return "", err
+	}
+	prefix, err := a.protonPrefix(client, gamePath)
+	if err != nil {
+		return "", err
+	}
+	homeDir, err := client.GetHomeDir()
+	if err != nil {
+		return "", err
+	}
+	cfg, err := readSteamConfig(client, homeDir)
+	if err != nil {
+		return "", err
+	}
+	return steam.CompatToolFor(cfg, prefix.AppID), nil
+}
+
+// SetGameCompatTool forces a compatibility tool for an installed game's
+// shortcut, or goes back to Steam's default when name is empty. Steam rewrites
+// its config when it exits, so it is closed first; in Gaming Mode it restarts
+// on its own.
+func (a *App) SetGameCompatTool(gamePath, name string) error {
+	client, _, err := a.connectedClient()
+	if err != nil {
+		return err
+	}
+	prefix, err := a.protonPrefix(client, gamePath)
+	if err != nil {
+		return err
+	}
+	homeDir, err := client.GetHomeDir()
+	if err != nil {
+		return err
+	}
+	if name != "" {
+		known := false
+		for _, tool := range compatTools(client, homeDir) {
+			if tool.Name == name {
+				known = true
+				break
+			}
+		}
+		if !known {
+			return fmt.Errorf("%s is not installed on the device", name)
+		}
+	}
+
+	if err := stopSteam(client); err != nil {
+		return err
+	}
+	cfg, err := readSteamConfig(client, homeDir)
+	if err != nil {
+		return err
+	}
+	steam.SetCompatTool(cfg, prefix.AppID, name)
+
+	configPath := steamConfigPath(homeDir)
+	client.RunCommand(fmt.Sprintf("cp -- %s %s", device.ShellQuote(configPath), device.ShellQuote(configPath+".bak")))
+	if err := client.WriteFile(configPath, cfg.Marshal(), 0644); err != nil {
+		return fmt.Errorf("failed to write Steam config: %w", err)
+	}
+	slog.Info("Compatibility tool changed", "appId", prefix.AppID, "tool", name)
+	return nil
+}
+
+// steamConfigPath returns Steam's config.vdf, which holds the per app
+// compatibility tool choices
+func steamConfigPath(homeDir string) string {
+	return path.Join(homeDir, ".steam", "steam", "config", "config.vdf")
+}
+
+func readSteamConfig(client *device.Client, homeDir string) (*steam.KeyValues, error) {
+	data, err := client.ReadFile(steamConfigPath(homeDir))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read Steam config: %w", err)
+	}
+	cfg, err := steam.ParseKeyValues(data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse Steam config: %w", err)
+	}
+	return cfg, nil
+}
+
+// stopSteam closes Steam on the device and waits for it to exit
+func stopSteam(client *device.Client) error {
+	_, err := client.RunCommand(`steam -shutdown >/dev/null 2>&1 || true
+for i in $(seq 30); do pgrep -x steam >/dev/null || exit 0; sleep 1; done
+exit 1`)
+	if err != nil {
+		return fmt.Errorf("Steam did not close on the device, try again with it closed")
+	}
+	return nil
+}
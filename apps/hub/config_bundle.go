@@ -0,0 +1,67 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/wailsapp/wails/v2/pkg/runtime"
+
+	"github.com/lobinuxsoft/capydeploy/pkg/config"
+)
+
+// =============================================================================
+// Config Export/Import
+// =============================================================================
+
+// ExportConfig writes devices, game setups, artwork presets and settings to a
+// file chosen by the user, without passwords or API keys. Returns the saved
+// path, or "" if the dialog was cancelled.
+func (a *App) ExportConfig() (string, error) {
+	data, err := config.ExportBundle()
+	if err != nil {
+		return "", err
+	}
+
+	localPath, err := runtime.SaveFileDialog(a.ctx, runtime.SaveDialogOptions{
+		Title:           "Export Configuration",
+		DefaultFilename: fmt.Sprintf("capydeploy-config-%s.json", time.Now().Format("2006-01-02")),
+		Filters:         []runtime.FileFilter{{DisplayName: "JSON files", Pattern: "*.json"}},
+	})
+	if err != nil || localPath == "" {
+		return "", err
+	}
+
+	if err := os.WriteFile(localPath, data, 0600); err != nil {
+		return "", fmt.Errorf("failed to write %s: %w", localPath, err)
+	}
+	return localPath, nil
+}
+
+// ImportConfig merges a file written by ExportConfig into the configuration.
+// Returns nil if the dialog was cancelled.
+func (a *App) ImportConfig() (*config.ImportSummary, error) {
+	localPath, err := runtime.OpenFileDialog(a.ctx, runtime.OpenDialogOptions{
+		Title:   "Import Configuration",
+		Filters: []runtime.FileFilter{{DisplayName: "JSON files", Pattern: "*.json"}},
+	})
+	if err != nil || localPath == "" {
+		return nil, err
+	}
+
+	data, err := os.ReadFile(localPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", localPath, err)
+	}
+	summary, err := config.ImportBundle(data)
+	if err != nil {
+		return nil, err
+	}
+
+	// Apply imported settings that are live
+	if perf, err := config.GetPerformanceSettings(); err == nil && a.imageFetcher != nil {
+		a.imageFetcher.SetWorkers(perf.FetchWorkers)
+		a.imageFetcher.SetHTTPClient(newHTTPClient())
+	}
+	return &summary, nil
+}
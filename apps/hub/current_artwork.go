@@ -0,0 +1,136 @@
+package main
+
+import (
+	"path"
+	"strings"
+
+	"github.com/lobinuxsoft/capydeploy/internal/device"
+	"github.com/lobinuxsoft/capydeploy/internal/shortcuts"
+	"github.com/lobinuxsoft/capydeploy/pkg/config"
+	"github.com/lobinuxsoft/capydeploy/pkg/steam"
+)
+
+// =============================================================================
+// Current Device Artwork
+// =============================================================================
+
+// CurrentArtwork is the artwork a shortcut has on the device
+type CurrentArtwork struct {
+	AppID uint32 `json:"appId"`
+	// Data URLs keyed by slot: capsule, wide, hero, logo, icon
+	Images map[string]string `json:"images"`
+}
+
+// artworkSlots maps the artwork selector tabs to Steam grid file types
+var artworkSlots = []struct {
+	name    string
+	artType steam.ArtworkType
+}{
+	{"capsule", steam.ArtworkPortrait},
+	{"wide", steam.ArtworkGrid},
+	{"hero", steam.ArtworkHero},
+	{"logo", steam.ArtworkLogo},
+	{"icon", steam.ArtworkIcon},
+}
+
+// GetCurrentArtwork returns the grid files the shortcut named gameName currently
+// has on the connected device. Slots without a file are omitted.
+func (a *App) GetCurrentArtwork(gameName string) (*CurrentArtwork, error) {
+	client, deviceCfg, err := a.connectedClient()
+	if err != nil {
+		return nil, err
+	}
+
+	appID := shortcutAppIDByName(client, deviceCfg, gameName)
+	if appID == 0 {
+		appID = a.exportAppID(gameName)
+	}
+	result := &CurrentArtwork{AppID: appID, Images: map[string]string{}}
+	if appID == 0 {
+		return result, nil
+	}
+
+	files, err := currentGridFiles(client, appID)
+	if err != nil {
+		return nil, err
+	}
+	for slot, file := range files {
+		data, err := client.ReadFile(file)
+		if err != nil {
+			continue
+		}
+		result.Images[slot] = toDataURL(data, contentTypeFromExt(path.Ext(file)))
+	}
+	return result, nil
+}
+
+// shortcutAppIDByName returns the app ID of the device shortcut with this name, or 0
+func shortcutAppIDByName(client *device.Client, deviceCfg config.DeviceConfig, name string) uint32 {
+	list, err := shortcuts.ListShortcuts(remoteConfig(client, deviceCfg))
+	if err != nil {
+		return 0
+	}
+	for _, sc := range list {
+		if sc.Name == name {
+			return uint32(sc.AppID)
+		}
+	}
+	return 0
+}
+
+// currentGridFiles returns the remote path of each artwork slot the app has,
+// taken from the first Steam user that has a file for the slot
+func currentGridFiles(client *device.Client, appID uint32) (map[string]string, error) {
+	gridDirs, err := shortcuts.GridDirs(client)
+	if err != nil {
+		return nil, err
+	}
+
+	files := make(map[string]string)
+	for _, gridDir := range gridDirs {
+		names, err := client.ListDir(gridDir)
+		if err != nil {
+			continue
+		}
+		for _, slot := range artworkSlots {
+			if _, found := files[slot.name]; found {
+				continue
+			}
+			// Grid files differ only by extension, e.g. 123_hero.png or 123_hero.webp.
+			// 123.json holds the logo position and is not artwork.
+			prefix := strings.TrimSuffix(steam.ArtworkFilename(appID, slot.artType, "x"), "x")
+			for _, name := range names {
+				ext := strings.TrimPrefix(name, prefix)
+				if strings.HasPrefix(name, prefix) && isImageExt(ext) {
+					files[slot.name] = path.Join(gridDir, name)
+					break
+				}
+			}
+		}
+	}
+	return files, nil
+}
+
+// contentTypeFromExt returns the MIME type of a grid file extension
+func contentTypeFromExt(ext string) string {
+	switch strings.ToLower(strings.TrimPrefix(ext, ".")) {
+	case "jpg", "jpeg":
+		return "image/jpeg"
+	case "webp":
+		return "image/webp"
+	case "gif":
+		return "image/gif"
+	case "ico":
+		return "image/vnd.microsoft.icon"
+	default:
+		return "image/png"
+	}
+}
+
+func isImageExt(ext string) bool {
+	switch strings.ToLower(ext) {
+	case "png", "jpg", "jpeg", "webp", "gif", "ico":
+		return true
+	}
+	return false
+}
@@ -0,0 +1,82 @@
+package main
+
+import (
+	"fmt"
+	"log/slog"
+	"path"
+	"strings"
+
+	"github.com/lobinuxsoft/capydeploy/internal/deploy"
+	"github.com/lobinuxsoft/capydeploy/internal/shortcuts"
+)
+
+// =============================================================================
+// Edit Deployed Shortcut
+// =============================================================================
+
+// ShortcutEdit is the new launch configuration of a deployed game's shortcut
+type ShortcutEdit struct {
+	Executable    string `json:"executable"` // absolute path on the device
+	StartDir      string `json:"startDir"`
+	LaunchOptions string `json:"launchOptions"`
+}
+
+// EditGameShortcut rewrites the Steam shortcut of an installed game and restarts
+// Steam to load it, without touching the game files
+func (a *App) EditGameShortcut(gamePath string, edit ShortcutEdit) error {
+	client, deviceCfg, err := a.connectedClient()
+	if err != nil {
+		return err
+	}
+
+	for _, p := range []string{edit.Executable, edit.StartDir} {
+		if !path.IsAbs(p) {
+			return fmt.Errorf("%q is not an absolute path", p)
+		}
+	}
+	// Quotes and line breaks would corrupt the quoted paths in shortcuts.vdf
+	for _, value := range []string{edit.Executable, edit.StartDir, edit.LaunchOptions} {
+		if strings.ContainsAny(value, "\"\r\n\x00") {
+			return fmt.Errorf("quotes and line breaks are not allowed: %q", value)
+		}
+	}
+	edit.Executable = path.Clean(edit.Executable)
+	edit.StartDir = path.Clean(edit.StartDir)
+
+	game := &InstalledGame{Name: path.Base(gamePath), Path: gamePath}
+	manifest, hasManifest := deploy.ReadManifest(client, gamePath)
+	if hasManifest {
+		game.AppID = manifest.AppID
+	}
+	remoteCfg := remoteConfig(client, deviceCfg)
+	list, err := shortcuts.ListShortcuts(remoteCfg)
+	if err != nil {
+		return fmt.Errorf("failed to list shortcuts: %w", err)
+	}
+	sc := gameShortcut(list, game)
+	if sc == nil {
+		return fmt.Errorf("%s has no Steam shortcut to edit", game.Name)
+	}
+
+	if err := shortcuts.UpdateShortcut(remoteCfg, sc.AppID, edit.Executable, edit.StartDir, edit.LaunchOptions); err != nil {
+		return fmt.Errorf("failed to update shortcut: %w", err)
+	}
+
+	// Keep the deploy record in sync so copies of the game launch the same way
+	if hasManifest {
+		manifest.LaunchOptions = edit.LaunchOptions
+		if manifest.LogFile != "" {
+			homeDir, _ := client.GetHomeDir()
+			manifest.LaunchOptions = deploy.StripLogWrapper(edit.LaunchOptions, deploy.LogWrapperPath(homeDir), manifest.LogFile)
+		}
+		if rel, ok := strings.CutPrefix(edit.Executable, path.Clean(gamePath)+"/"); ok {
+			manifest.Executable = rel
+		}
+		if err := deploy.WriteManifest(client, gamePath, *manifest); err != nil {
+			slog.Warn("Failed to update deploy manifest", "error", err)
+		}
+	}
+
+	shortcuts.RefreshSteamLibrary(remoteCfg)
+	return nil
+}
@@ -0,0 +1,208 @@
+package main
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/lobinuxsoft/capydeploy/internal/upload"
+	"github.com/lobinuxsoft/capydeploy/pkg/engine"
+)
+
+// =============================================================================
+// Executable Detection
+// =============================================================================
+
+// maxExecutableDepth is how many directories deep the game folder is searched
+const maxExecutableDepth = 3
+
+// maxExecutableCandidates caps the list offered for the Executable field
+const maxExecutableCandidates = 20
+
+// helperBinaryHints mark executables that ship next to a game but never start it
+var helperBinaryHints = []string{"crash", "unins", "setup", "install", "redist", "vc_", "dxsetup", "dotnet", "report"}
+
+// executableCandidate is a file that may start a game
+type executableCandidate struct {
+	rel   string
+	score int
+	size  int64
+}
+
+// FindExecutables scans a local game folder, or game archive, for files that
+// can start the game and returns their slash separated relative paths, most
+// likely main binary first
+func (a *App) FindExecutables(folder string) ([]string, error) {
+	if !filepath.IsAbs(folder) {
+		return nil, fmt.Errorf("%q is not an absolute path", folder)
+	}
+	if upload.IsArchive(folder) {
+		return archiveExecutables(folder)
+	}
+	info, err := os.Stat(folder)
+	if err != nil {
+		return nil, err
+	}
+	if !info.IsDir() {
+		return nil, fmt.Errorf("%s is not a folder", folder)
+	}
+
+	var candidates []executableCandidate
+	err = filepath.WalkDir(folder, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return nil // unreadable entries are skipped, not fatal
+		}
+		rel, _ := filepath.Rel(folder, p)
+		depth := strings.Count(filepath.ToSlash(rel), "/")
+		if d.IsDir() {
+			if p != folder && (depth >= maxExecutableDepth || strings.HasPrefix(d.Name(), ".")) {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+
+		score, ok := executableScore(d.Name(), func() bool { return upload.IsELF(p) })
+		if !ok {
+			return nil
+		}
+
+		var size int64
+		if fi, err := d.Info(); err == nil {
+			size = fi.Size()
+		}
+		candidates = append(candidates, executableCandidate{
+			rel:   filepath.ToSlash(rel),
+			score: rankExecutable(score, depth, d.Name(), filepath.Base(folder)),
+			size:  size,
+		})
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return sortExecutables(candidates), nil
+}
+
+// archiveExecutables lists the files of a game archive that can start the game
+func archiveExecutables(archivePath string) ([]string, error) {
+	entries, err := upload.ReadArchive(archivePath)
+	if err != nil {
+		return nil, err
+	}
+	// The game is named after the archive, not the folder inside it
+	name := filepath.Base(archivePath)
+	for _, ext := range []string{".zip", ".tar.gz", ".tgz"} {
+		if len(name) > len(ext) && strings.EqualFold(name[len(name)-len(ext):], ext) {
+			name = name[:len(name)-len(ext)]
+			break
+		}
+	}
+
+	var candidates []executableCandidate
+	for _, entry := range entries {
+		depth := strings.Count(entry.RelPath, "/")
+		if depth > maxExecutableDepth || strings.HasPrefix(entry.RelPath, ".") || strings.Contains(entry.RelPath, "/.") {
+			continue
+		}
+		base := path.Base(entry.RelPath)
+		score, ok := executableScore(base, func() bool { return entry.ELF })
+		if !ok {
+			continue
+		}
+		candidates = append(candidates, executableCandidate{
+			rel:   entry.RelPath,
+			score: rankExecutable(score, depth, base, name),
+			size:  entry.Size,
+		})
+	}
+	return sortExecutables(candidates), nil
+}
+
+// rankExecutable adjusts the score of a file that may start a game by where it
+// is and what it is named
+func rankExecutable(score, depth int, name, folder string) int {
+	score -= depth * 10
+	if nameMatchesFolder(name, folder) {
+		score += 20
+	}
+	lower := strings.ToLower(name)
+	for _, hint := range helperBinaryHints {
+		if strings.Contains(lower, hint) {
+			score -= 50
+			break
+		}
+	}
+	return score
+}
+
+// sortExecutables returns the paths of the best candidates, most likely first
+func sortExecutables(candidates []executableCandidate) []string {
+	// The main binary is usually the biggest of equally likely files
+	sort.Slice(candidates, func(i, j int) bool {
+		if candidates[i].score != candidates[j].score {
+			return candidates[i].score > candidates[j].score
+		}
+		return candidates[i].size > candidates[j].size
+	})
+
+	result := []string{}
+	for i := 0; i < len(candidates) && i < maxExecutableCandidates; i++ {
+		result = append(result, candidates[i].rel)
+	}
+	return result
+}
+
+// executableScore rates how likely a file starts a game by its type, and reports
+// whether it is executable at all. isBinary checks files without an extension.
+func executableScore(name string, isBinary func() bool) (int, bool) {
+	switch strings.ToLower(filepath.Ext(name)) {
+	case ".x86_64":
+		return 30, true
+	case ".sh":
+		return 25, true
+	case ".exe":
+		return 20, true
+	case ".x86", ".appimage":
+		return 15, true
+	case "":
+		if isBinary() {
+			return 30, true
+		}
+	}
+	return 0, false
+}
+
+// nameMatchesFolder reports whether an executable is named after the game folder
+func nameMatchesFolder(name, folder string) bool {
+	normalize := func(s string) string {
+		return strings.Map(func(r rune) rune {
+			if (r >= 'a' && r <= 'z') || (r >= '0' && r <= '9') {
+				return r
+			}
+			return -1
+		}, strings.ToLower(s))
+	}
+	n, f := normalize(strings.TrimSuffix(name, filepath.Ext(name))), normalize(folder)
+	return n != "" && f != "" && (strings.Contains(n, f) || strings.Contains(f, n))
+}
+
+// DetectBuildLayout recognizes a game engine build in a local folder and
+// returns its executable and the files that must not ship, or nil when the
+// folder isn't the build of a known engine
+func (a *App) DetectBuildLayout(folder string) (*engine.Layout, error) {
+	if !filepath.IsAbs(folder) {
+		return nil, fmt.Errorf("%q is not an absolute path", folder)
+	}
+	info, err := os.Stat(folder)
+	if err != nil {
+		return nil, err
+	}
+	if !info.IsDir() {
+		return nil, fmt.Errorf("%s is not a folder", folder)
+	}
+	return engine.Detect(folder)
+}
@@ -0,0 +1,160 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/wailsapp/wails/v2/pkg/runtime"
+
+	"github.com/lobinuxsoft/capydeploy/internal/deploy"
+	"github.com/lobinuxsoft/capydeploy/internal/shortcuts"
+	"github.com/lobinuxsoft/capydeploy/pkg/artwork"
+	"github.com/lobinuxsoft/capydeploy/pkg/config"
+	"github.com/lobinuxsoft/capydeploy/pkg/steam"
+)
+
+// =============================================================================
+// Artwork Export
+// =============================================================================
+
+// exportedArtwork is an entry of the manifest written next to exported files
+type exportedArtwork struct {
+	Type string `json:"type"`
+	File string `json:"file"`
+	URL  string `json:"url"`
+}
+
+// exportManifest describes an exported artwork set
+type exportManifest struct {
+	Game         string            `json:"game"`
+	AppID        uint32            `json:"app_id,omitempty"`
+	GridDBGameID int               `json:"griddb_game_id,omitempty"`
+	ExportedAt   time.Time         `json:"exported_at"`
+	Files        []exportedArtwork `json:"files"`
+}
+
+// ExportArtwork saves the selected artwork to a folder chosen by the user, using
+// Steam grid filenames. Returns the folder, or "" if the dialog was cancelled.
+func (a *App) ExportArtwork(gameName string, selection config.ArtworkSelection) (string, error) {
+	dir, err := runtime.OpenDirectoryDialog(a.ctx, runtime.OpenDialogOptions{
+		Title:                "Export Artwork To",
+		CanCreateDirectories: true,
+	})
+	if err != nil || dir == "" {
+		return "", err
+	}
+
+	appID := a.exportAppID(gameName)
+	// Without a known shortcut the game name stands in for the app ID
+	base := sanitizeFilename(gameName)
+
+	slots := []struct {
+		name    string
+		url     string
+		artType steam.ArtworkType
+	}{
+		{"capsule", selection.GridPortrait, steam.ArtworkPortrait},
+		{"wide", selection.GridLandscape, steam.ArtworkGrid},
+		{"hero", selection.HeroImage, steam.ArtworkHero},
+		{"logo", selection.LogoImage, steam.ArtworkLogo},
+		{"icon", selection.IconImage, steam.ArtworkIcon},
+	}
+
+	manifest := exportManifest{
+		Game:         gameName,
+		AppID:        appID,
+		GridDBGameID: selection.GridDBGameID,
+		ExportedAt:   time.Now(),
+	}
+	for _, slot := range slots {
+		if slot.url == "" {
+			continue
+		}
+
+		var data []byte
+		var contentType string
+		var err error
+		if artwork.IsGenerated(slot.url) {
+			data, contentType, err = a.localImage(slot.url)
+		} else {
+			data, contentType, err = a.imageFetcher.Fetch(slot.url)
+		}
+		if err != nil {
+			return "", fmt.Errorf("failed to download %s: %w", slot.name, err)
+		}
+
+		ext := artworkExt(slot.url, contentType)
+		filename := steam.ArtworkFilename(appID, slot.artType, ext)
+		if appID == 0 {
+			filename = base + strings.TrimPrefix(filename, "0")
+		}
+
+		if err := os.WriteFile(filepath.Join(dir, filename), data, 0644); err != nil {
+			return "", err
+		}
+		manifest.Files = append(manifest.Files, exportedArtwork{Type: slot.name, File: filename, URL: slot.url})
+	}
+
+	if len(manifest.Files) == 0 {
+		return "", fmt.Errorf("no artwork selected")
+	}
+
+	data, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return "", err
+	}
+	if err := os.WriteFile(filepath.Join(dir, "artwork.json"), data, 0644); err != nil {
+		return "", err
+	}
+
+	return dir, nil
+}
+
+// exportAppID returns the shortcut app ID the game setup with this name gets on
+// the device, or 0 when it can't be determined
+func (a *App) exportAppID(gameName string) uint32 {
+	setups, err := config.GetGameSetups()
+	if err != nil {
+		return 0
+	}
+
+	for _, setup := range setups {
+		if setup.DeployName() != gameName || setup.Executable == "" {
+			continue
+		}
+
+		remotePath := setup.RemotePath
+		if strings.HasPrefix(remotePath, "~") {
+			client, _, err := a.connectedClient()
+			if err != nil {
+				return 0
+			}
+			if remotePath, err = deploy.ExpandRemotePath(client, remotePath); err != nil {
+				return 0
+			}
+		}
+
+		exePath := path.Join(remotePath, setup.DeployName(), setup.Executable)
+		return uint32(shortcuts.ShortcutAppID(exePath, setup.DeployName()))
+	}
+	return 0
+}
+
+// sanitizeFilename replaces characters that are not allowed in file names
+func sanitizeFilename(name string) string {
+	name = strings.Map(func(r rune) rune {
+		if strings.ContainsRune(`<>:"/\|?*`, r) || r < 32 {
+			return '_'
+		}
+		return r
+	}, strings.TrimSpace(name))
+	if name == "" {
+		return "artwork"
+	}
+	return name
+}
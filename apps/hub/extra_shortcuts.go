@@ -0,0 +1,27 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/lobinuxsoft/capydeploy/pkg/config"
+)
+
+// =============================================================================
+// Extra Shortcuts
+// =============================================================================
+
+// validateExtraShortcuts checks the extra shortcuts of a setup, which must be
+// named apart from each other and from the game
+func validateExtraShortcuts(setup config.GameSetup) error {
+	seen := map[string]bool{setup.Name: true}
+	for _, extra := range setup.ExtraShortcuts {
+		if err := extra.Validate(); err != nil {
+			return err
+		}
+		if seen[extra.Name] {
+			return fmt.Errorf("shortcut name %q is used more than once", extra.Name)
+		}
+		seen[extra.Name] = true
+	}
+	return nil
+}
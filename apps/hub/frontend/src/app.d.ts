@@ -33,7 +33,8 @@ declare global {
 	function UploadGame(setupID: string): Promise<void>;
 
 	function GetInstalledGames(remotePath: string): Promise<import('$lib/types').InstalledGame[]>;
-	function DeleteGame(name: string, path: string): Promise<void>;
+	function GetUninstallPlan(gamePath: string): Promise<import('$lib/types').UninstallPlan>;
+	function UninstallGame(gamePath: string, removeCompatData: boolean): Promise<void>;
 
 	function GetSteamGridDBAPIKey(): Promise<string>;
 	function SetSteamGridDBAPIKey(key: string): Promise<void>;
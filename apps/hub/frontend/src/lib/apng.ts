@@ -0,0 +1,181 @@
+// Animated WebP to APNG conversion
+// Decodes frames with the WebCodecs ImageDecoder and writes them as an animated PNG,
+// keeping each frame's duration and the loop count
+
+interface Frame {
+	pixels: Uint8ClampedArray;
+	delayMs: number;
+}
+
+// Minimal typing for ImageDecoder, which is not in every TS DOM lib yet
+interface DecodedImage {
+	image: { duration: number | null; close(): void } & CanvasImageSource;
+}
+interface ImageDecoderLike {
+	tracks: { ready: Promise<void>; selectedTrack: { frameCount: number; repetitionCount: number } | null };
+	completed: Promise<void>;
+	decode(options: { frameIndex: number }): Promise<DecodedImage>;
+	close(): void;
+}
+
+export function canTranscode(): boolean {
+	return 'ImageDecoder' in window && 'CompressionStream' in window;
+}
+
+// Converts an animated WebP data URL to an APNG data URL
+export async function webpToAPNG(dataUrl: string): Promise<string> {
+	if (!canTranscode()) {
+		throw new Error('Animated WebP conversion is not supported by this system webview');
+	}
+
+	const blob = await (await fetch(dataUrl)).blob();
+	const Decoder = (window as any).ImageDecoder;
+	const decoder: ImageDecoderLike = new Decoder({ data: blob.stream(), type: 'image/webp' });
+	try {
+		await decoder.completed;
+		await decoder.tracks.ready;
+		const track = decoder.tracks.selectedTrack;
+		if (!track || track.frameCount < 1) throw new Error('No frames found');
+
+		let width = 0;
+		let height = 0;
+		let ctx: CanvasRenderingContext2D | null = null;
+		const frames: Frame[] = [];
+
+		for (let i = 0; i < track.frameCount; i++) {
+			const { image } = await decoder.decode({ frameIndex: i });
+			try {
+				if (!ctx) {
+					const frame = image as unknown as { displayWidth: number; displayHeight: number };
+					width = frame.displayWidth;
+					height = frame.displayHeight;
+					const canvas = document.createElement('canvas');
+					canvas.width = width;
+					canvas.height = height;
+					ctx = canvas.getContext('2d', { willReadFrequently: true });
+					if (!ctx) throw new Error('Canvas not supported');
+				}
+				// Decoded frames are already composited, so each one replaces the canvas
+				ctx.clearRect(0, 0, width, height);
+				ctx.drawImage(image, 0, 0);
+				frames.push({
+					pixels: ctx.getImageData(0, 0, width, height).data,
+					delayMs: Math.min(65535, Math.max(10, Math.round((image.duration ?? 100000) / 1000)))
+				});
+			} finally {
+				image.close();
+			}
+		}
+
+		const loops = Number.isFinite(track.repetitionCount) ? track.repetitionCount + 1 : 0;
+		const apng = await encodeAPNG(frames, width, height, loops);
+		return 'data:image/png;base64,' + toBase64(apng);
+	} finally {
+		decoder.close();
+	}
+}
+
+// Writes RGBA frames as an APNG. loops = 0 repeats forever.
+async function encodeAPNG(frames: Frame[], width: number, height: number, loops: number): Promise<Uint8Array> {
+	const chunks: Uint8Array[] = [new Uint8Array([0x89, 0x50, 0x4e, 0x47, 0x0d, 0x0a, 0x1a, 0x0a])];
+
+	const ihdr = new DataView(new ArrayBuffer(13));
+	ihdr.setUint32(0, width);
+	ihdr.setUint32(4, height);
+	ihdr.setUint8(8, 8); // bit depth
+	ihdr.setUint8(9, 6); // RGBA
+	chunks.push(chunk('IHDR', new Uint8Array(ihdr.buffer)));
+
+	const actl = new DataView(new ArrayBuffer(8));
+	actl.setUint32(0, frames.length);
+	actl.setUint32(4, loops);
+	chunks.push(chunk('acTL', new Uint8Array(actl.buffer)));
+
+	let seq = 0;
+	for (let i = 0; i < frames.length; i++) {
+		const fctl = new DataView(new ArrayBuffer(26));
+		fctl.setUint32(0, seq++);
+		fctl.setUint32(4, width);
+		fctl.setUint32(8, height);
+		fctl.setUint32(12, 0); // x offset
+		fctl.setUint32(16, 0); // y offset
+		fctl.setUint16(20, frames[i].delayMs);
+		fctl.setUint16(22, 1000);
+		fctl.setUint8(24, 0); // dispose: none
+		fctl.setUint8(25, 0); // blend: source
+		chunks.push(chunk('fcTL', new Uint8Array(fctl.buffer)));
+
+		const data = await deflate(scanlines(frames[i].pixels, width, height));
+		if (i === 0) {
+			chunks.push(chunk('IDAT', data));
+		} else {
+			const fdat = new Uint8Array(4 + data.length);
+			new DataView(fdat.buffer).setUint32(0, seq++);
+			fdat.set(data, 4);
+			chunks.push(chunk('fdAT', fdat));
+		}
+	}
+
+	chunks.push(chunk('IEND', new Uint8Array(0)));
+	return concat(chunks);
+}
+
+// Prefixes each pixel row with filter type 0 (none)
+function scanlines(pixels: Uint8ClampedArray, width: number, height: number): Uint8Array {
+	const stride = width * 4;
+	const out = new Uint8Array((stride + 1) * height);
+	for (let y = 0; y < height; y++) {
+		out.set(pixels.subarray(y * stride, (y + 1) * stride), y * (stride + 1) + 1);
+	}
+	return out;
+}
+
+async function deflate(data: Uint8Array): Promise<Uint8Array> {
+	// 'deflate' produces the zlib format PNG expects
+	const stream = new Blob([data]).stream().pipeThrough(new CompressionStream('deflate'));
+	return new Uint8Array(await new Response(stream).arrayBuffer());
+}
+
+function chunk(type: string, data: Uint8Array): Uint8Array {
+	const out = new Uint8Array(12 + data.length);
+	const view = new DataView(out.buffer);
+	view.setUint32(0, data.length);
+	for (let i = 0; i < 4; i++) out[4 + i] = type.charCodeAt(i);
+	out.set(data, 8);
+	view.setUint32(8 + data.length, crc32(out.subarray(4, 8 + data.length)));
+	return out;
+}
+
+let crcTable: Uint32Array | null = null;
+
+function crc32(data: Uint8Array): number {
+	if (!crcTable) {
+		crcTable = new Uint32Array(256);
+		for (let n = 0; n < 256; n++) {
+			let c = n;
+			for (let k = 0; k < 8; k++) c = c & 1 ? 0xedb88320 ^ (c >>> 1) : c >>> 1;
+			crcTable[n] = c >>> 0;
+		}
+	}
+	let crc = 0xffffffff;
+	for (let i = 0; i < data.length; i++) crc = crcTable[(crc ^ data[i]) & 0xff] ^ (crc >>> 8);
+	return (crc ^ 0xffffffff) >>> 0;
+}
+
+function concat(parts: Uint8Array[]): Uint8Array {
+	const out = new Uint8Array(parts.reduce((n, p) => n + p.length, 0));
+	let offset = 0;
+	for (const p of parts) {
+		out.set(p, offset);
+		offset += p.length;
+	}
+	return out;
+}
+
+function toBase64(data: Uint8Array): string {
+	let binary = '';
+	for (let i = 0; i < data.length; i += 0x8000) {
+		binary += String.fromCharCode(...data.subarray(i, i + 0x8000));
+	}
+	return btoa(binary);
+}
@@ -0,0 +1,131 @@
+// Artwork generator
+// Renders Steam capsule, wide and hero images from a screenshot using canvas templates
+
+export type GeneratedSlot = 'capsule' | 'wide' | 'hero';
+export type GeneratorTemplate = 'gradient' | 'blur' | 'plain';
+
+export const generatedSizes: Record<GeneratedSlot, { width: number; height: number }> = {
+	capsule: { width: 600, height: 900 },
+	wide: { width: 920, height: 430 },
+	hero: { width: 1920, height: 620 }
+};
+
+export const generatorTemplates: { value: GeneratorTemplate; label: string }[] = [
+	{ value: 'gradient', label: 'Gradient + Title' },
+	{ value: 'blur', label: 'Blurred Backdrop + Title' },
+	{ value: 'plain', label: 'Crop Only' }
+];
+
+export interface GeneratorOptions {
+	template: GeneratorTemplate;
+	title: string;
+	// Crop focus point, 0..1 from the left/top of the screenshot
+	focusX: number;
+	focusY: number;
+}
+
+export function loadImage(src: string): Promise<HTMLImageElement> {
+	return new Promise((resolve, reject) => {
+		const img = new Image();
+		img.onload = () => resolve(img);
+		img.onerror = () => reject(new Error('Failed to load image'));
+		img.src = src;
+	});
+}
+
+// Renders one slot and returns it as a PNG data URL
+export function renderArtwork(img: HTMLImageElement, slot: GeneratedSlot, opts: GeneratorOptions): string {
+	const { width, height } = generatedSizes[slot];
+	const canvas = document.createElement('canvas');
+	canvas.width = width;
+	canvas.height = height;
+	const ctx = canvas.getContext('2d');
+	if (!ctx) throw new Error('Canvas not supported');
+
+	if (opts.template === 'blur') {
+		ctx.filter = 'blur(24px) brightness(0.6)';
+		drawCover(ctx, img, width, height, opts.focusX, opts.focusY);
+		ctx.filter = 'none';
+		drawContain(ctx, img, width, height);
+	} else {
+		drawCover(ctx, img, width, height, opts.focusX, opts.focusY);
+	}
+
+	if (opts.template === 'gradient') {
+		const gradient = ctx.createLinearGradient(0, height * 0.5, 0, height);
+		gradient.addColorStop(0, 'rgba(0, 0, 0, 0)');
+		gradient.addColorStop(1, 'rgba(0, 0, 0, 0.85)');
+		ctx.fillStyle = gradient;
+		ctx.fillRect(0, 0, width, height);
+	}
+
+	// Steam draws the logo over the hero, so it never gets a title
+	if (opts.template !== 'plain' && slot !== 'hero' && opts.title.trim()) {
+		drawTitle(ctx, opts.title.trim(), width, height, slot === 'capsule' ? 0.11 : 0.075);
+	}
+
+	return canvas.toDataURL('image/png');
+}
+
+// Scales the image to fill the canvas, cropping around the focus point
+function drawCover(ctx: CanvasRenderingContext2D, img: HTMLImageElement, width: number, height: number, focusX: number, focusY: number) {
+	const scale = Math.max(width / img.naturalWidth, height / img.naturalHeight);
+	const sw = width / scale;
+	const sh = height / scale;
+	const sx = (img.naturalWidth - sw) * clamp01(focusX);
+	const sy = (img.naturalHeight - sh) * clamp01(focusY);
+	ctx.drawImage(img, sx, sy, sw, sh, 0, 0, width, height);
+}
+
+// Scales the image to fit inside the canvas, centered
+function drawContain(ctx: CanvasRenderingContext2D, img: HTMLImageElement, width: number, height: number) {
+	const scale = Math.min(width / img.naturalWidth, height / img.naturalHeight);
+	const w = img.naturalWidth * scale;
+	const h = img.naturalHeight * scale;
+	ctx.drawImage(img, (width - w) / 2, (height - h) / 2, w, h);
+}
+
+// Draws the title at the bottom, wrapping and shrinking it to fit in three lines
+function drawTitle(ctx: CanvasRenderingContext2D, title: string, width: number, height: number, sizeRatio: number) {
+	const maxWidth = width * 0.88;
+	let fontSize = Math.round(width * sizeRatio);
+	let lines: string[] = [];
+
+	for (; fontSize > 12; fontSize -= 2) {
+		ctx.font = `bold ${fontSize}px sans-serif`;
+		lines = wrapText(ctx, title, maxWidth);
+		if (lines.length <= 3 && lines.every((l) => ctx.measureText(l).width <= maxWidth)) break;
+	}
+
+	const lineHeight = fontSize * 1.15;
+	const bottom = height - height * 0.06;
+	ctx.textAlign = 'center';
+	ctx.textBaseline = 'bottom';
+	ctx.fillStyle = '#ffffff';
+	ctx.shadowColor = 'rgba(0, 0, 0, 0.8)';
+	ctx.shadowBlur = fontSize * 0.3;
+	lines.forEach((line, i) => {
+		ctx.fillText(line, width / 2, bottom - (lines.length - 1 - i) * lineHeight, maxWidth);
+	});
+	ctx.shadowBlur = 0;
+}
+
+function wrapText(ctx: CanvasRenderingContext2D, text: string, maxWidth: number): string[] {
+	const lines: string[] = [];
+	let line = '';
+	for (const word of text.split(/\s+/)) {
+		const candidate = line ? `${line} ${word}` : word;
+		if (line && ctx.measureText(candidate).width > maxWidth) {
+			lines.push(line);
+			line = word;
+		} else {
+			line = candidate;
+		}
+	}
+	if (line) lines.push(line);
+	return lines;
+}
+
+function clamp01(value: number): number {
+	return Math.min(1, Math.max(0, value));
+}
@@ -3,4 +3,9 @@ export { default as DeviceList } from './DeviceList.svelte';
 export { default as GameSetupList } from './GameSetupList.svelte';
 export { default as ArtworkSelector } from './ArtworkSelector.svelte';
 export { default as InstalledGames } from './InstalledGames.svelte';
+export { default as Performance } from './Performance.svelte';
 export { default as Settings } from './Settings.svelte';
+export { default as HubLogs } from './HubLogs.svelte';
+export { default as SetupWizard } from './SetupWizard.svelte';
+export { default as UpdateBanner } from './UpdateBanner.svelte';
+export { default as UploadJobStatus } from './UploadJobStatus.svelte';
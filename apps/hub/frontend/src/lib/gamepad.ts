@@ -0,0 +1,93 @@
+// Gamepad navigation helper
+// Polls the Gamepad API and translates button presses into navigation actions
+
+export type NavAction =
+	| 'up'
+	| 'down'
+	| 'left'
+	| 'right'
+	| 'select'
+	| 'preview'
+	| 'back'
+	| 'prevTab'
+	| 'nextTab';
+
+// Standard gamepad mapping (https://w3c.github.io/gamepad/#remapping)
+const buttonActions: Record<number, NavAction> = {
+	0: 'select', // A
+	1: 'back', // B
+	2: 'preview', // X
+	4: 'prevTab', // LB
+	5: 'nextTab', // RB
+	12: 'up',
+	13: 'down',
+	14: 'left',
+	15: 'right'
+};
+
+const directions: NavAction[] = ['up', 'down', 'left', 'right'];
+const stickThreshold = 0.6;
+const repeatDelay = 400;
+const repeatInterval = 120;
+
+function pressedActions(pad: Gamepad): Set<NavAction> {
+	const actions = new Set<NavAction>();
+	for (const [index, action] of Object.entries(buttonActions)) {
+		if (pad.buttons[Number(index)]?.pressed) {
+			actions.add(action);
+		}
+	}
+
+	// Left stick acts as a D-pad
+	const [x = 0, y = 0] = pad.axes;
+	if (y < -stickThreshold) actions.add('up');
+	if (y > stickThreshold) actions.add('down');
+	if (x < -stickThreshold) actions.add('left');
+	if (x > stickThreshold) actions.add('right');
+
+	return actions;
+}
+
+// watchGamepad calls onaction for every new press. Directions auto-repeat while held.
+// Returns a function that stops polling.
+export function watchGamepad(onaction: (action: NavAction) => void): () => void {
+	if (typeof navigator === 'undefined' || !navigator.getGamepads) {
+		return () => {};
+	}
+
+	const heldSince = new Map<NavAction, number>();
+	const lastFired = new Map<NavAction, number>();
+	let frame = 0;
+
+	function poll(now: number) {
+		const current = new Set<NavAction>();
+		for (const pad of navigator.getGamepads()) {
+			if (pad) pressedActions(pad).forEach((a) => current.add(a));
+		}
+
+		for (const action of current) {
+			const since = heldSince.get(action);
+			if (since === undefined) {
+				heldSince.set(action, now);
+				lastFired.set(action, now);
+				onaction(action);
+			} else if (
+				directions.includes(action) &&
+				now - since > repeatDelay &&
+				now - (lastFired.get(action) ?? 0) > repeatInterval
+			) {
+				lastFired.set(action, now);
+				onaction(action);
+			}
+		}
+
+		for (const action of [...heldSince.keys()]) {
+			if (!current.has(action)) heldSince.delete(action);
+		}
+
+		frame = requestAnimationFrame(poll);
+	}
+
+	frame = requestAnimationFrame(poll);
+	return () => cancelAnimationFrame(frame);
+}
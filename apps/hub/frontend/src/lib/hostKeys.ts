@@ -0,0 +1,33 @@
+// Host key confirmation
+// The hub refuses devices whose host key the user hasn't trusted yet, or
+// whose key changed. These helpers show the fingerprint and trust the key
+// when the user confirms it.
+
+import { get } from 'svelte/store';
+import { t } from '$lib/i18n';
+import type { HostKeyPrompt } from '$lib/types';
+import { GetPendingHostKey, TrustHostKey } from '$lib/wailsjs';
+
+// confirmHostKey asks the user to trust the host key a connection to host was
+// refused for. It reports false when the user declined or the connection
+// failed for another reason.
+export async function confirmHostKey(host: string): Promise<boolean> {
+	const prompt: HostKeyPrompt | null = await GetPendingHostKey(host);
+	if (!prompt) return false;
+	const params = { host, type: prompt.keyType, fingerprint: prompt.fingerprint };
+	const message = get(t)(prompt.changed ? 'hostKey.changed' : 'hostKey.unknown', params);
+	if (!confirm(message)) return false;
+	await TrustHostKey(host, prompt.fingerprint);
+	return true;
+}
+
+// withHostKey runs a connection to host, asking to trust its host key and
+// trying again when that is why it failed
+export async function withHostKey<T>(host: string, connect: () => Promise<T>): Promise<T> {
+	try {
+		return await connect();
+	} catch (e) {
+		if (await confirmHostKey(host)) return connect();
+		throw e;
+	}
+}
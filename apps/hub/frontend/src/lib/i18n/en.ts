@@ -0,0 +1,228 @@
+// English catalog, the source of every message key. Other catalogs may leave
+// keys out, which then fall back to the English text.
+export const en = {
+	// Navigation
+	'tabs.devices': 'Devices',
+	'tabs.upload': 'Upload Game',
+	'tabs.games': 'Installed Games',
+	'tabs.performance': 'Performance',
+	'tabs.settings': 'Settings',
+	'tabs.logs': 'Logs',
+
+	// Common
+	'common.cancel': 'Cancel',
+	'common.save': 'Save',
+	'common.error': 'Error: {error}',
+
+	// Connection
+	'connection.notConnected': 'Not connected',
+	'connection.connected': 'Connected',
+	'connection.disconnected': 'Disconnected',
+
+	// Devices
+	'devices.scanNetwork': 'Scan Network',
+	'devices.add': 'Add Device',
+	'devices.edit': 'Edit Device',
+	'devices.empty': 'No devices configured. Add a device or scan your network.',
+	'devices.confirmDelete': 'Are you sure you want to delete this device?',
+	'devices.connectionFailed': 'Connection failed: {error}',
+	'devices.name': 'Name',
+	'devices.namePlaceholder': 'My Bazzite Device',
+	'devices.host': 'Host/IP',
+	'devices.port': 'Port',
+	'devices.user': 'User',
+	'devices.authMethod': 'Authentication Method',
+	'devices.password': 'Password',
+	'devices.passwordPlaceholder': 'SSH Password',
+	'devices.sshKey': 'SSH Key',
+	'devices.sshKeyPath': 'SSH Key Path',
+	'devices.scan': 'Scan',
+	'devices.scanning': 'Scanning...',
+	'devices.selectAndConfigure': 'Select & Configure',
+	'devices.select': 'Select for the connect shortcut',
+	'devices.scanningHint': 'Scanning network for devices with SSH (port 22)...',
+	'devices.found': 'Found {count} device(s) with SSH',
+	'devices.scanHint': "Click 'Scan' to find devices on your network...",
+	'devices.noneFound': 'No devices found. Click Scan to search.',
+
+	// Host keys
+	'hostKey.unknown':
+		'First connection to {host}. Its host key fingerprint is:\n\n{type} {fingerprint}\n\nCheck it matches the one shown on the device by ssh-keygen -lf /etc/ssh/ssh_host_*_key.pub, then trust it to connect.',
+	'hostKey.changed':
+		'WARNING: the host key of {host} has changed. It is now:\n\n{type} {fingerprint}\n\nThis is expected only if the device was reinstalled. Otherwise someone may be intercepting the connection, do not trust it. Trust the new key?',
+
+	// Setup wizard
+	'wizard.title': 'Welcome to CapyDeploy',
+	'wizard.step.device': 'Device',
+	'wizard.step.artwork': 'Artwork',
+	'wizard.step.deploy': 'First Deploy',
+	'wizard.step.done': 'Done',
+	'wizard.deviceIntro':
+		'CapyDeploy copies your game builds to a handheld or PC running Steam over SSH. Scan your network or enter the address of the device, then test the connection.',
+	'wizard.passwordHint': 'On a Steam Deck, set one with passwd in Desktop Mode and enable SSH with: sudo systemctl enable --now sshd',
+	'wizard.testConnection': 'Test Connection',
+	'wizard.testOk': 'Connected. Games will be installed under {home}.',
+	'wizard.noSteam': 'Steam was not found for this user, games cannot be added to its library until it is installed.',
+	'wizard.next': 'Next',
+	'wizard.skipStep': 'Skip',
+	'wizard.skipSetup': 'Skip setup',
+	'wizard.artworkIntro':
+		'Optional: with a SteamGridDB API key you can pick capsules, heroes, logos and icons for your games. You can add it later in Settings.',
+	'wizard.deployIntro': 'Pick the folder of a game build to deploy it to {device} and add it to Steam.',
+	'wizard.gameFolder': 'Game build folder',
+	'wizard.gameName': 'Game Name',
+	'wizard.executable': 'Executable',
+	'wizard.deploy': 'Deploy',
+	'wizard.deployStarting': 'Starting...',
+	'wizard.deployFailed': 'Deploy failed: {error}',
+	'wizard.doneDevice': 'Device: {device}',
+	'wizard.doneGame': '{game} was deployed and added to Steam.',
+	'wizard.doneHint': 'Manage devices in the Devices tab and game setups in the Upload Game tab.',
+	'wizard.finish': 'Finish',
+
+	// Updates
+	'update.available': 'CapyDeploy Hub {version} is available (you have {current}).',
+	'update.agentHint': 'Update the agent on your devices too.',
+	'update.showChanges': "What's New",
+	'update.hideChanges': 'Hide Changes',
+	'update.download': 'Download',
+	'update.skip': 'Skip This Version',
+
+	// Background deploys
+	'job.deploying': 'Deploying {name}',
+	'job.deployed': '{name} deployed',
+	'job.failed': '{name} failed: {error}',
+	'job.open': 'Show in Upload Game',
+
+	// Shortcuts
+	'shortcuts.failed': 'Shortcut failed: {error}',
+
+	// Settings
+	'settings.appearance': 'Appearance',
+	'settings.theme': 'Theme',
+	'settings.theme.system': 'System',
+	'settings.theme.dark': 'Dark',
+	'settings.theme.light': 'Light',
+	'settings.language': 'Language',
+	'settings.language.system': 'System',
+	'settings.uiScale': 'UI Scale ({percent}%)',
+	'settings.appearanceHint': 'Changes preview immediately and are kept when you save. Try 125% or more on a handheld screen.',
+	'settings.deployDefaults': 'Deploy Defaults',
+	'settings.defaultLaunchOptions': 'Default Launch Options',
+	'settings.defaultLaunchOptionsHint': 'Pre-filled in every new game setup. Change it per game in the setup form.',
+	'settings.background': 'Background',
+	'settings.keepInTray': 'Keep running in the system tray when the window is closed',
+	'settings.keepInTrayHint':
+		'Deploys and the automation API keep working, and the tray menu offers quick deploys of recent games. Close the Hub from the tray menu.',
+	'settings.trayUnavailable': 'No system tray was found on this desktop, closing the window quits the Hub.',
+	'settings.updates': 'Updates',
+	'settings.checkForUpdates': 'Check for a new version on startup',
+	'settings.checkNow': 'Check Now',
+	'settings.currentVersion': 'Installed: {version}',
+	'settings.updatesHint':
+		'Asks GitHub for the latest release, nothing else is sent. Newer versions may need the agent on your devices updated too.',
+	'settings.upToDate': 'You have the latest version.',
+	'settings.updatesDevBuild': 'This is a development build, updates are not checked.',
+	'settings.updateCheckFailed': 'Failed to check for updates: {error}',
+	'settings.shortcuts': 'Keyboard Shortcuts',
+	'settings.shortcut.deployLast': 'Deploy the last game again',
+	'settings.shortcut.cancelTransfer': 'Cancel the running deploy',
+	'settings.shortcut.toggleConnection': 'Connect or disconnect the selected device',
+	'settings.shortcut.tabDevices': 'Go to Devices',
+	'settings.shortcut.tabUpload': 'Go to Upload Game',
+	'settings.shortcut.tabGames': 'Go to Installed Games',
+	'settings.shortcut.tabSettings': 'Go to Settings',
+	'settings.shortcut.tabLogs': 'Go to Logs',
+	'settings.shortcutChange': 'Change',
+	'settings.shortcutPressKeys': 'Press keys...',
+	'settings.shortcutUnbound': 'None',
+	'settings.shortcutsReset': 'Reset to Defaults',
+	'settings.shortcutsHint':
+		'Click Change and press the new combination, Escape cancels. Shortcuts without Ctrl, Alt or Meta are ignored while typing in a field. Select a device by clicking its name in Devices.',
+	'settings.sgdb': 'SteamGridDB Integration',
+	'settings.sgdbDescription': 'SteamGridDB allows you to select custom artwork for your games.',
+	'settings.sgdbKeyFrom': 'Get your API key from',
+	'settings.apiKey': 'API Key',
+	'settings.apiKeyPlaceholder': 'Your SteamGridDB API key',
+	'settings.testKey': 'Test Key',
+	'settings.igdb': 'IGDB (Optional)',
+	'settings.igdbDescription': 'Fallback artwork source for games not listed on SteamGridDB. Requires a Twitch application from',
+	'settings.clientId': 'Client ID',
+	'settings.clientIdPlaceholder': 'Twitch client ID',
+	'settings.clientSecret': 'Client Secret',
+	'settings.clientSecretPlaceholder': 'Twitch client secret',
+	'settings.artwork': 'Artwork',
+	'settings.iconUpscaling': 'Icon Upscaling',
+	'settings.upscale.off': 'Off',
+	'settings.upscale.nearest': 'Nearest (pixel art)',
+	'settings.upscale.lanczos': 'Lanczos (smooth)',
+	'settings.iconUpscalingHint':
+		'Icons smaller than 128px are enlarged to 256px when applied. Nearest keeps pixel art crisp; Lanczos smooths detailed icons.',
+	'settings.imageCache': 'Image Cache',
+	'settings.imageCacheDescription': 'Cached images are stored locally for faster loading.',
+	'settings.cacheSize': 'Cache Size:',
+	'settings.calculating': 'Calculating...',
+	'settings.unableToCalculate': 'Unable to calculate',
+	'settings.maxSize': 'Max Size (MB)',
+	'settings.expireAfter': 'Expire After (days)',
+	'settings.memoryCache': 'Memory Cache (MB)',
+	'settings.imageCacheHint':
+		'Least recently used images are removed when the cache exceeds its size. Use 0 for no disk limit. The memory cache bounds the images kept loaded while browsing artwork (minimum 16 MB).',
+	'settings.clearCache': 'Clear Cache',
+	'settings.openCacheFolder': 'Open Cache Folder',
+	'settings.confirmClearCache': 'This will delete all cached SteamGridDB images.\nAre you sure?',
+	'settings.cacheCleared': 'Cache cleared',
+	'settings.clearCacheFailed': 'Failed to clear cache: {error}',
+	'settings.openCacheFolderFailed': 'Failed to open cache folder: {error}',
+	'settings.network': 'Network',
+	'settings.networkDescription':
+		"Applies to SteamGridDB, IGDB and image downloads. Transfers to devices go over SSH and don't use the proxy.",
+	'settings.proxy': 'Proxy',
+	'settings.timeout': 'Timeout (s)',
+	'settings.proxyHint':
+		'Leave the proxy empty to use the HTTP_PROXY and HTTPS_PROXY environment variables. socks5:// proxies are supported too.',
+	'settings.automation': 'Automation API',
+	'settings.automationDescription':
+		'A local API for editor plugins and build scripts to list devices and games, deploy and launch. It listens on 127.0.0.1 only and every request needs the token.',
+	'settings.automationEnabled': 'Enable the automation API',
+	'settings.port': 'Port',
+	'settings.token': 'Token',
+	'settings.tokenHint': 'Send it as "Authorization: Bearer <token>". It is created when you save with the API enabled.',
+	'settings.copy': 'Copy',
+	'settings.regenerateToken': 'Regenerate',
+	'settings.confirmRegenerateToken': 'Tools using the current token will stop working.\nRegenerate it?',
+	'settings.regenerateTokenFailed': 'Failed to regenerate the token: {error}',
+	'settings.performance': 'Performance',
+	'settings.performanceDescription':
+		'Tune transfers for your network. Lower values are gentler on slow Wi-Fi, higher values use a fast LAN fully.',
+	'settings.parallelUploads': 'Parallel Uploads (1-16)',
+	'settings.chunkSize': 'Chunk Size (KB, 8-256)',
+	'settings.imageDownloads': 'Image Downloads (1-32)',
+	'settings.sgdbRetries': 'SteamGridDB Retries (0-20)',
+	'settings.uploadLimit': 'Upload Limit (KB/s, 0 = none)',
+	'settings.uploadRetries': 'Retries per File',
+	'settings.uploadRetryBudget': 'Retries per Deploy',
+	'settings.chunkSizeHint':
+		'The chunk size applies the next time a device connects. Sizes above 32 KB need a recent OpenSSH on the device.',
+	'settings.uploadLimitHint':
+		'The upload limit is shared by parallel uploads. Set one to keep a deploy over Wi-Fi from lagging a game being played on the device.',
+	'settings.uploadRetriesHint':
+		'A file that fails to upload is retried after 1, 2, 4… seconds (up to 30). The deploy stops once a file runs out of retries or the whole deploy does.',
+	'settings.backup': 'Backup & Sharing',
+	'settings.backupDescription':
+		'Export devices, game setups, artwork presets and settings to share a standard setup with your team or move to another machine. Passwords and API keys are never exported.',
+	'settings.export': 'Export',
+	'settings.import': 'Import',
+	'settings.exported': 'Configuration exported to {path}\nPasswords and API keys are not included.',
+	'settings.exportFailed': 'Failed to export configuration: {error}',
+	'settings.imported':
+		'Imported {devices} device(s), {setups} game setup(s) and {presets} artwork preset(s).\nEnter the passwords of new devices before connecting.',
+	'settings.importedSkippedCommands':
+		'Build and post-deploy commands were not imported, enter them in these setups if you trust them: {setups}',
+	'settings.importFailed': 'Failed to import configuration: {error}',
+	'settings.save': 'Save Settings',
+	'settings.saved': 'Settings saved successfully',
+	'settings.saveFailed': 'Failed to save settings: {error}'
+} as const;
+
+export type MessageKey = keyof typeof en;
@@ -0,0 +1,231 @@
+import type { MessageKey } from './en';
+
+// Spanish catalog
+export const es: Partial<Record<MessageKey, string>> = {
+	// Navigation
+	'tabs.devices': 'Dispositivos',
+	'tabs.upload': 'Subir juego',
+	'tabs.games': 'Juegos instalados',
+	'tabs.performance': 'Rendimiento',
+	'tabs.settings': 'Ajustes',
+	'tabs.logs': 'Registros',
+
+	// Common
+	'common.cancel': 'Cancelar',
+	'common.save': 'Guardar',
+	'common.error': 'Error: {error}',
+
+	// Connection
+	'connection.notConnected': 'Sin conexión',
+	'connection.connected': 'Conectado',
+	'connection.disconnected': 'Desconectado',
+
+	// Devices
+	'devices.scanNetwork': 'Buscar en la red',
+	'devices.add': 'Agregar dispositivo',
+	'devices.edit': 'Editar dispositivo',
+	'devices.empty': 'No hay dispositivos configurados. Agregá uno o buscá en tu red.',
+	'devices.confirmDelete': '¿Seguro que querés eliminar este dispositivo?',
+	'devices.connectionFailed': 'Falló la conexión: {error}',
+	'devices.name': 'Nombre',
+	'devices.namePlaceholder': 'Mi dispositivo Bazzite',
+	'devices.host': 'Host/IP',
+	'devices.port': 'Puerto',
+	'devices.user': 'Usuario',
+	'devices.authMethod': 'Método de autenticación',
+	'devices.password': 'Contraseña',
+	'devices.passwordPlaceholder': 'Contraseña SSH',
+	'devices.sshKey': 'Clave SSH',
+	'devices.sshKeyPath': 'Ruta de la clave SSH',
+	'devices.scan': 'Buscar',
+	'devices.scanning': 'Buscando...',
+	'devices.selectAndConfigure': 'Seleccionar y configurar',
+	'devices.select': 'Seleccionar para el atajo de conexión',
+	'devices.scanningHint': 'Buscando dispositivos con SSH (puerto 22) en la red...',
+	'devices.found': 'Se encontraron {count} dispositivo(s) con SSH',
+	'devices.scanHint': "Hacé clic en 'Buscar' para encontrar dispositivos en tu red...",
+	'devices.noneFound': 'No se encontraron dispositivos. Hacé clic en Buscar.',
+
+	// Host keys
+	'hostKey.unknown':
+		'Primera conexión a {host}. La huella de su clave de host es:\n\n{type} {fingerprint}\n\nComprobá que coincida con la que muestra el dispositivo con ssh-keygen -lf /etc/ssh/ssh_host_*_key.pub y confiá en ella para conectarte.',
+	'hostKey.changed':
+		'ATENCIÓN: la clave de host de {host} cambió. Ahora es:\n\n{type} {fingerprint}\n\nEsto solo es normal si reinstalaste el dispositivo. Si no, alguien podría estar interceptando la conexión y no deberías confiar en ella. ¿Confiar en la nueva clave?',
+
+	// Setup wizard
+	'wizard.title': 'Bienvenido a CapyDeploy',
+	'wizard.step.device': 'Dispositivo',
+	'wizard.step.artwork': 'Artwork',
+	'wizard.step.deploy': 'Primer despliegue',
+	'wizard.step.done': 'Listo',
+	'wizard.deviceIntro':
+		'CapyDeploy copia los builds de tus juegos por SSH a una portátil o PC con Steam. Buscá en tu red o ingresá la dirección del dispositivo y probá la conexión.',
+	'wizard.passwordHint':
+		'En una Steam Deck, definila con passwd en el modo escritorio y activá SSH con: sudo systemctl enable --now sshd',
+	'wizard.testConnection': 'Probar conexión',
+	'wizard.testOk': 'Conectado. Los juegos se instalan en {home}.',
+	'wizard.noSteam': 'No se encontró Steam para este usuario, los juegos no se pueden agregar a su biblioteca hasta instalarlo.',
+	'wizard.next': 'Siguiente',
+	'wizard.skipStep': 'Omitir',
+	'wizard.skipSetup': 'Omitir configuración',
+	'wizard.artworkIntro':
+		'Opcional: con una API key de SteamGridDB podés elegir cápsulas, héroes, logos e íconos para tus juegos. También podés agregarla después en Ajustes.',
+	'wizard.deployIntro': 'Elegí la carpeta de un build para desplegarlo en {device} y agregarlo a Steam.',
+	'wizard.gameFolder': 'Carpeta del build',
+	'wizard.gameName': 'Nombre del juego',
+	'wizard.executable': 'Ejecutable',
+	'wizard.deploy': 'Desplegar',
+	'wizard.deployStarting': 'Iniciando...',
+	'wizard.deployFailed': 'Falló el despliegue: {error}',
+	'wizard.doneDevice': 'Dispositivo: {device}',
+	'wizard.doneGame': '{game} se desplegó y se agregó a Steam.',
+	'wizard.doneHint': 'Administrá los dispositivos en la pestaña Dispositivos y los juegos en Subir juego.',
+	'wizard.finish': 'Finalizar',
+
+	// Updates
+	'update.available': 'CapyDeploy Hub {version} está disponible (tenés {current}).',
+	'update.agentHint': 'Actualizá también el agente de tus dispositivos.',
+	'update.showChanges': 'Novedades',
+	'update.hideChanges': 'Ocultar novedades',
+	'update.download': 'Descargar',
+	'update.skip': 'Omitir esta versión',
+
+	// Background deploys
+	'job.deploying': 'Desplegando {name}',
+	'job.deployed': '{name} desplegado',
+	'job.failed': 'Falló {name}: {error}',
+	'job.open': 'Ver en Subir juego',
+
+	// Shortcuts
+	'shortcuts.failed': 'Falló el atajo: {error}',
+
+	// Settings
+	'settings.appearance': 'Apariencia',
+	'settings.theme': 'Tema',
+	'settings.theme.system': 'Sistema',
+	'settings.theme.dark': 'Oscuro',
+	'settings.theme.light': 'Claro',
+	'settings.language': 'Idioma',
+	'settings.language.system': 'Sistema',
+	'settings.uiScale': 'Escala de la interfaz ({percent}%)',
+	'settings.appearanceHint':
+		'Los cambios se ven al instante y se conservan al guardar. En una pantalla portátil probá con 125% o más.',
+	'settings.deployDefaults': 'Valores por defecto del despliegue',
+	'settings.defaultLaunchOptions': 'Opciones de lanzamiento por defecto',
+	'settings.defaultLaunchOptionsHint':
+		'Se completan en cada configuración de juego nueva. Podés cambiarlas por juego en el formulario.',
+	'settings.background': 'Segundo plano',
+	'settings.keepInTray': 'Seguir en la bandeja del sistema al cerrar la ventana',
+	'settings.keepInTrayHint':
+		'Los despliegues y la API de automatización siguen funcionando, y el menú de la bandeja ofrece despliegues rápidos de los juegos recientes. Para cerrar el Hub, usá el menú de la bandeja.',
+	'settings.trayUnavailable': 'No se encontró una bandeja del sistema en este escritorio, cerrar la ventana cierra el Hub.',
+	'settings.updates': 'Actualizaciones',
+	'settings.checkForUpdates': 'Buscar una versión nueva al iniciar',
+	'settings.checkNow': 'Buscar ahora',
+	'settings.currentVersion': 'Instalada: {version}',
+	'settings.updatesHint':
+		'Consulta a GitHub la última versión, no se envía nada más. Las versiones nuevas pueden requerir actualizar también el agente de tus dispositivos.',
+	'settings.upToDate': 'Tenés la última versión.',
+	'settings.updatesDevBuild': 'Esta es una build de desarrollo, no se buscan actualizaciones.',
+	'settings.updateCheckFailed': 'No se pudieron buscar actualizaciones: {error}',
+	'settings.shortcuts': 'Atajos de teclado',
+	'settings.shortcut.deployLast': 'Volver a desplegar el último juego',
+	'settings.shortcut.cancelTransfer': 'Cancelar el despliegue en curso',
+	'settings.shortcut.toggleConnection': 'Conectar o desconectar el dispositivo seleccionado',
+	'settings.shortcut.tabDevices': 'Ir a Dispositivos',
+	'settings.shortcut.tabUpload': 'Ir a Subir juego',
+	'settings.shortcut.tabGames': 'Ir a Juegos instalados',
+	'settings.shortcut.tabSettings': 'Ir a Ajustes',
+	'settings.shortcut.tabLogs': 'Ir a Registros',
+	'settings.shortcutChange': 'Cambiar',
+	'settings.shortcutPressKeys': 'Presioná las teclas...',
+	'settings.shortcutUnbound': 'Ninguno',
+	'settings.shortcutsReset': 'Restablecer',
+	'settings.shortcutsHint':
+		'Hacé clic en Cambiar y presioná la nueva combinación, Escape cancela. Los atajos sin Ctrl, Alt o Meta se ignoran mientras escribís en un campo. Para elegir un dispositivo, hacé clic en su nombre en Dispositivos.',
+	'settings.sgdb': 'Integración con SteamGridDB',
+	'settings.sgdbDescription': 'SteamGridDB te permite elegir artwork personalizado para tus juegos.',
+	'settings.sgdbKeyFrom': 'Obtené tu API key en',
+	'settings.apiKey': 'API key',
+	'settings.apiKeyPlaceholder': 'Tu API key de SteamGridDB',
+	'settings.testKey': 'Probar key',
+	'settings.igdb': 'IGDB (opcional)',
+	'settings.igdbDescription':
+		'Fuente alternativa de artwork para juegos que no están en SteamGridDB. Requiere una aplicación de Twitch de',
+	'settings.clientId': 'Client ID',
+	'settings.clientIdPlaceholder': 'Client ID de Twitch',
+	'settings.clientSecret': 'Client secret',
+	'settings.clientSecretPlaceholder': 'Client secret de Twitch',
+	'settings.artwork': 'Artwork',
+	'settings.iconUpscaling': 'Escalado de íconos',
+	'settings.upscale.off': 'Desactivado',
+	'settings.upscale.nearest': 'Vecino más cercano (pixel art)',
+	'settings.upscale.lanczos': 'Lanczos (suave)',
+	'settings.iconUpscalingHint':
+		'Los íconos de menos de 128px se agrandan a 256px al aplicarlos. Vecino más cercano mantiene nítido el pixel art; Lanczos suaviza los íconos detallados.',
+	'settings.imageCache': 'Caché de imágenes',
+	'settings.imageCacheDescription': 'Las imágenes se guardan localmente para cargar más rápido.',
+	'settings.cacheSize': 'Tamaño de la caché:',
+	'settings.calculating': 'Calculando...',
+	'settings.unableToCalculate': 'No se pudo calcular',
+	'settings.maxSize': 'Tamaño máximo (MB)',
+	'settings.expireAfter': 'Vence a los (días)',
+	'settings.memoryCache': 'Caché en memoria (MB)',
+	'settings.imageCacheHint':
+		'Cuando la caché supera su tamaño se eliminan las imágenes usadas hace más tiempo. Usá 0 para no limitar el disco. La caché en memoria limita las imágenes cargadas mientras navegás el artwork (mínimo 16 MB).',
+	'settings.clearCache': 'Vaciar caché',
+	'settings.openCacheFolder': 'Abrir carpeta de caché',
+	'settings.confirmClearCache': 'Se borrarán todas las imágenes de SteamGridDB en caché.\n¿Estás seguro?',
+	'settings.cacheCleared': 'Caché vaciada',
+	'settings.clearCacheFailed': 'No se pudo vaciar la caché: {error}',
+	'settings.openCacheFolderFailed': 'No se pudo abrir la carpeta de caché: {error}',
+	'settings.network': 'Red',
+	'settings.networkDescription':
+		'Se aplica a SteamGridDB, IGDB y la descarga de imágenes. Las transferencias a los dispositivos van por SSH y no usan el proxy.',
+	'settings.proxy': 'Proxy',
+	'settings.timeout': 'Timeout (s)',
+	'settings.proxyHint':
+		'Dejá el proxy vacío para usar las variables de entorno HTTP_PROXY y HTTPS_PROXY. También se admiten proxies socks5://.',
+	'settings.automation': 'API de automatización',
+	'settings.automationDescription':
+		'Una API local para que plugins de editores y scripts de build listen dispositivos y juegos, desplieguen y lancen. Solo escucha en 127.0.0.1 y cada pedido necesita el token.',
+	'settings.automationEnabled': 'Activar la API de automatización',
+	'settings.port': 'Puerto',
+	'settings.token': 'Token',
+	'settings.tokenHint': 'Envialo como "Authorization: Bearer <token>". Se crea al guardar con la API activada.',
+	'settings.copy': 'Copiar',
+	'settings.regenerateToken': 'Regenerar',
+	'settings.confirmRegenerateToken': 'Las herramientas que usan el token actual dejarán de funcionar.\n¿Regenerarlo?',
+	'settings.regenerateTokenFailed': 'No se pudo regenerar el token: {error}',
+	'settings.performance': 'Rendimiento',
+	'settings.performanceDescription':
+		'Ajustá las transferencias a tu red. Valores bajos cuidan un Wi-Fi lento, valores altos aprovechan una LAN rápida.',
+	'settings.parallelUploads': 'Subidas en paralelo (1-16)',
+	'settings.chunkSize': 'Tamaño de bloque (KB, 8-256)',
+	'settings.imageDownloads': 'Descargas de imágenes (1-32)',
+	'settings.sgdbRetries': 'Reintentos de SteamGridDB (0-20)',
+	'settings.uploadLimit': 'Límite de subida (KB/s, 0 = sin límite)',
+	'settings.uploadRetries': 'Reintentos por archivo',
+	'settings.uploadRetryBudget': 'Reintentos por deploy',
+	'settings.chunkSizeHint':
+		'El tamaño de bloque se aplica la próxima vez que se conecta un dispositivo. Más de 32 KB requiere un OpenSSH reciente en el dispositivo.',
+	'settings.uploadLimitHint':
+		'El límite de subida se reparte entre las subidas en paralelo. Usalo para que un deploy por Wi-Fi no haga trabar un juego que se está jugando en el dispositivo.',
+	'settings.uploadRetriesHint':
+		'Un archivo que falla al subir se reintenta tras 1, 2, 4… segundos (hasta 30). El deploy se detiene cuando un archivo o el deploy entero se queda sin reintentos.',
+	'settings.backup': 'Respaldo y uso compartido',
+	'settings.backupDescription':
+		'Exportá dispositivos, configuraciones de juegos, presets de artwork y ajustes para compartir una configuración estándar con tu equipo o pasarla a otra máquina. Las contraseñas y API keys nunca se exportan.',
+	'settings.export': 'Exportar',
+	'settings.import': 'Importar',
+	'settings.exported': 'Configuración exportada a {path}\nNo incluye contraseñas ni API keys.',
+	'settings.exportFailed': 'No se pudo exportar la configuración: {error}',
+	'settings.imported':
+		'Se importaron {devices} dispositivo(s), {setups} configuración(es) de juego y {presets} preset(s) de artwork.\nIngresá las contraseñas de los dispositivos nuevos antes de conectarte.',
+	'settings.importedSkippedCommands':
+		'No se importaron los comandos de build y post-deploy, ingresalos en estas configuraciones si confiás en ellos: {setups}',
+	'settings.importFailed': 'No se pudo importar la configuración: {error}',
+	'settings.save': 'Guardar ajustes',
+	'settings.saved': 'Ajustes guardados',
+	'settings.saveFailed': 'No se pudieron guardar los ajustes: {error}'
+};
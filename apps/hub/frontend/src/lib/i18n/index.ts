@@ -0,0 +1,35 @@
+import { derived, writable } from 'svelte/store';
+import { en, type MessageKey } from './en';
+import { es } from './es';
+
+export type Locale = 'en' | 'es';
+export type { MessageKey };
+
+// Languages offered in Settings, by their own name
+export const locales: { value: Locale; label: string }[] = [
+	{ value: 'en', label: 'English' },
+	{ value: 'es', label: 'Español' }
+];
+
+const catalogs: Record<Locale, Partial<Record<MessageKey, string>>> = { en, es };
+
+export const locale = writable<Locale>('en');
+
+// resolveLocale maps a saved language ('' follows the system) to a supported locale
+export function resolveLocale(language: string | undefined): Locale {
+	const wanted = (language || navigator.language || 'en').slice(0, 2).toLowerCase();
+	return wanted in catalogs ? (wanted as Locale) : 'en';
+}
+
+// translate looks key up in the locale's catalog, falling back to English,
+// and replaces {name} placeholders with params
+export function translate(lang: Locale, key: MessageKey, params?: Record<string, string | number>): string {
+	const message = catalogs[lang][key] ?? en[key] ?? key;
+	if (!params) return message;
+	return message.replace(/\{(\w+)\}/g, (match, name) => (name in params ? String(params[name]) : match));
+}
+
+// t is used in components as $t('key', { param })
+export const t = derived(locale, (lang) => (key: MessageKey, params?: Record<string, string | number>) =>
+	translate(lang, key, params)
+);
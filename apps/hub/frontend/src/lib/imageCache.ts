@@ -0,0 +1,69 @@
+// In-memory image cache
+// Keeps proxied image data URLs within a byte budget, evicting least recently used entries
+
+export const DEFAULT_MEMORY_CACHE_MB = 256;
+
+export class MemoryImageCache {
+	private entries = new Map<string, string>();
+	private bytes = 0;
+	private maxBytes: number;
+
+	constructor(maxMB = DEFAULT_MEMORY_CACHE_MB) {
+		this.maxBytes = maxMB * 1024 * 1024;
+	}
+
+	get size(): number {
+		return this.entries.size;
+	}
+
+	get totalBytes(): number {
+		return this.bytes;
+	}
+
+	setLimit(maxMB: number) {
+		this.maxBytes = Math.max(1, maxMB) * 1024 * 1024;
+		this.evict();
+	}
+
+	has(url: string): boolean {
+		return this.entries.has(url);
+	}
+
+	// Returns the cached data URL and marks it as most recently used
+	get(url: string): string | undefined {
+		const dataUrl = this.entries.get(url);
+		if (dataUrl !== undefined) {
+			this.entries.delete(url);
+			this.entries.set(url, dataUrl);
+		}
+		return dataUrl;
+	}
+
+	set(url: string, dataUrl: string) {
+		this.delete(url);
+		this.entries.set(url, dataUrl);
+		this.bytes += dataUrl.length;
+		this.evict();
+	}
+
+	delete(url: string) {
+		const dataUrl = this.entries.get(url);
+		if (dataUrl === undefined) return;
+		this.entries.delete(url);
+		this.bytes -= dataUrl.length;
+	}
+
+	clear() {
+		this.entries.clear();
+		this.bytes = 0;
+	}
+
+	// Map iteration follows insertion order, so the first entries are the least recently used.
+	// The newest entry is always kept, even if it alone exceeds the budget.
+	private evict() {
+		for (const url of this.entries.keys()) {
+			if (this.bytes <= this.maxBytes || this.entries.size <= 1) break;
+			this.delete(url);
+		}
+	}
+}
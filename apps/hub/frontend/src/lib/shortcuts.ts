@@ -0,0 +1,73 @@
+// Keyboard shortcut helper
+// Turns key presses into combinations such as "Ctrl+Shift+D", the format the
+// hub stores them in, and finds the action bound to them
+
+import { writable } from 'svelte/store';
+import type { KeyAction, KeyBindings } from '$lib/types';
+
+export const keyActions: KeyAction[] = [
+	'deploy_last',
+	'cancel_transfer',
+	'toggle_connection',
+	'tab_devices',
+	'tab_upload',
+	'tab_games',
+	'tab_settings',
+	'tab_logs'
+];
+
+// Same as DefaultKeyBindings in pkg/config
+export const defaultKeyBindings: KeyBindings = {
+	deploy_last: 'Ctrl+D',
+	cancel_transfer: 'Ctrl+Shift+X',
+	toggle_connection: 'Ctrl+K',
+	tab_devices: 'Ctrl+1',
+	tab_upload: 'Ctrl+2',
+	tab_games: 'Ctrl+3',
+	tab_settings: 'Ctrl+4',
+	tab_logs: 'Ctrl+5'
+};
+
+export const keyBindings = writable<KeyBindings>(defaultKeyBindings);
+
+const modifierKeys = ['Control', 'Alt', 'Shift', 'Meta', 'AltGraph', 'CapsLock'];
+
+const namedKeys: Record<string, string> = {
+	' ': 'Space',
+	'+': 'Plus'
+};
+
+// Returns the combination of a key press, or '' for a lone modifier
+export function comboFromEvent(e: KeyboardEvent): string {
+	if (modifierKeys.includes(e.key)) return '';
+
+	// The physical key for letters and digits, so Shift and keyboard
+	// layouts don't change the combination
+	let key: string;
+	if (/^Key[A-Z]$/.test(e.code)) {
+		key = e.code.slice(3);
+	} else if (/^Digit[0-9]$/.test(e.code)) {
+		key = e.code.slice(5);
+	} else if (namedKeys[e.key]) {
+		key = namedKeys[e.key];
+	} else if (e.key.length === 1) {
+		key = e.key.toUpperCase();
+	} else {
+		key = e.key;
+	}
+	if (!key || key === 'Unidentified' || key === 'Dead') return '';
+
+	const parts: string[] = [];
+	if (e.ctrlKey) parts.push('Ctrl');
+	if (e.altKey) parts.push('Alt');
+	if (e.shiftKey) parts.push('Shift');
+	if (e.metaKey) parts.push('Meta');
+	parts.push(key);
+	return parts.join('+');
+}
+
+// Returns the action bound to a combination
+export function actionForCombo(bindings: KeyBindings, combo: string): KeyAction | null {
+	if (!combo) return null;
+	return keyActions.find((action) => bindings[action] === combo) ?? null;
+}
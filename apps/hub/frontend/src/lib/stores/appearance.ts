@@ -0,0 +1,42 @@
+import { writable } from 'svelte/store';
+import type { Appearance } from '$lib/types';
+import { locale, resolveLocale } from '$lib/i18n';
+
+const defaultAppearance: Appearance = { theme: 'dark', ui_scale: 1 };
+
+const systemDark = () => window.matchMedia('(prefers-color-scheme: dark)').matches;
+
+// Applies the theme as data-theme on <html>, the scale as its font size,
+// which every rem based size follows, and the language
+function apply(appearance: Appearance) {
+	const root = document.documentElement;
+	const lang = resolveLocale(appearance.language);
+	locale.set(lang);
+	root.lang = lang;
+	const dark = appearance.theme === 'dark' || (appearance.theme === 'system' && systemDark());
+	root.dataset.theme = dark ? 'dark' : 'light';
+	root.style.fontSize = `${Math.round(appearance.ui_scale * 100)}%`;
+}
+
+function createAppearanceStore() {
+	const { subscribe, set } = writable<Appearance>(defaultAppearance);
+	let current = defaultAppearance;
+
+	// Follow the OS while the theme is "system"
+	if (typeof window !== 'undefined') {
+		window.matchMedia('(prefers-color-scheme: dark)').addEventListener('change', () => {
+			if (current.theme === 'system') apply(current);
+		});
+	}
+
+	return {
+		subscribe,
+		set: (appearance: Appearance) => {
+			current = appearance;
+			apply(appearance);
+			set(appearance);
+		}
+	};
+}
+
+export const appearance = createAppearanceStore();
@@ -17,3 +17,6 @@ function createDevicesStore() {
 }
 
 export const devices = createDevicesStore();
+
+// Host of the device the connect shortcut connects to
+export const selectedDevice = writable<string>('');
@@ -1,5 +1,5 @@
 import { writable } from 'svelte/store';
-import type { GameSetup, UploadProgress } from '$lib/types';
+import type { GameSetup, UploadJob, UploadProgress } from '$lib/types';
 
 function createGameSetupsStore() {
 	const { subscribe, set, update } = writable<GameSetup[]>([]);
@@ -19,3 +19,6 @@ function createGameSetupsStore() {
 export const gameSetups = createGameSetupsStore();
 
 export const uploadProgress = writable<UploadProgress | null>(null);
+
+// Running or last deploy shown in the header, null once dismissed
+export const uploadJob = writable<UploadJob | null>(null);
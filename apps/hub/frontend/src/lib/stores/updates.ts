@@ -0,0 +1,5 @@
+import { writable } from 'svelte/store';
+import type { UpdateInfo } from '$lib/types';
+
+// Newer Hub release announced in the update banner, null hides it
+export const availableUpdate = writable<UpdateInfo | null>(null);
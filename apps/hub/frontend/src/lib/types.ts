@@ -21,6 +21,12 @@ export interface NetworkDevice {
 	hasSSH: boolean;
 }
 
+// What testing a device before adding it found on it
+export interface DeviceTestResult {
+	homeDir: string;
+	steamFound: boolean;
+}
+
 // Game setup types
 export interface GameSetup {
 	id: string;
@@ -29,19 +35,234 @@ export interface GameSetup {
 	executable: string;
 	launch_options?: string;
 	tags?: string;
+	version?: string;
+	channel?: string;
+	notes?: string;
 	remote_path: string;
+	capture_logs?: boolean;
+	exclude?: string[];
+	prefix_verbs?: string[];
+	mangohud?: boolean; // launch with the MangoHud overlay
+	full_upload?: boolean; // upload every file instead of only the changed ones
+	compressed_upload?: boolean; // send the files as one gzipped tar stream
+	rsync?: boolean; // upload with rsync when both ends have it
+	binary_patch?: boolean; // block patching: send the changed blocks of large files
+	chunk_store?: boolean; // send only the chunks the device doesn't have from any build
+	keep_releases?: number; // builds kept on the device to roll back to, 0 deploys in place
+	mirror?: boolean; // delete files on the device the build no longer has
+	build?: BuildStep | null;
+	post_deploy?: string; // shell command run on the device after the upload
+	extra_shortcuts?: ExtraShortcut[];
 	griddb_game_id?: number;
 	grid_portrait?: string;
 	grid_landscape?: string;
 	hero_image?: string;
 	logo_image?: string;
 	icon_image?: string;
+	logo_position?: LogoPosition | null;
+}
+
+// Another executable of a game deployed with its own Steam shortcut
+export interface ExtraShortcut {
+	name: string;
+	executable: string; // relative to the game folder
+	launch_options?: string;
+}
+
+// Command run on this machine to build a game before each deploy
+export interface BuildStep {
+	command: string;
+	args?: string[];
+	working_dir?: string;
+	env?: string[];
+	output_dir?: string;
+}
+
+// Line printed by a build step
+export interface BuildOutput {
+	line: string;
+	stderr?: boolean;
+	start?: boolean;
+	device?: boolean; // output of the post-deploy command
+}
+
+// Build folder of a recognized game engine
+export interface BuildLayout {
+	engine: string;
+	executable: string;
+	exclude?: string[];
+	launchOptions?: string;
 }
 
 export interface InstalledGame {
 	name: string;
 	path: string;
-	size: string;
+	size: number; // bytes
+	fileCount: number;
+	deployedAt: string; // RFC 3339
+	hasShortcut: boolean;
+	running: boolean;
+	pid?: number; // oldest process when running
+	uptime?: number; // seconds
+	appId?: number;
+	setupId?: string;
+	version?: string;
+	device: string;
+}
+
+// Detail pane data of an installed game
+export interface GameDetails {
+	name: string;
+	path: string;
+	appId?: number;
+	executable?: string;
+	launchOptions?: string;
+	startDir?: string;
+	tags: string[];
+	version?: string;
+	deployedAt?: string;
+	setupName?: string;
+	notes?: string;
+	history: DeployHistoryEntry[]; // newest first
+	capsule?: string;
+	hero?: string;
+}
+
+// Earlier deploy of an installed game
+export interface DeployHistoryEntry {
+	version?: string;
+	deployedAt: string; // RFC 3339
+	notes?: string;
+}
+
+// Build of an installed game kept on the device to roll back to
+export interface GameRelease {
+	name: string; // release folder, named after the deploy time
+	version?: string;
+	deployedAt?: string; // RFC 3339
+	notes?: string;
+	current: boolean;
+}
+
+// Entry of an installed game's directory
+export interface GameFile {
+	name: string;
+	path: string; // relative to the game directory
+	isDir: boolean;
+	size: number;
+	modTime: string;
+}
+
+// New launch configuration of a deployed game's Steam shortcut
+export interface ShortcutEdit {
+	executable: string; // absolute path on the device
+	startDir: string;
+	launchOptions: string;
+}
+
+// Running state of an installed game
+export interface GameRunState {
+	path: string;
+	running: boolean;
+	pid?: number;
+	uptime?: number; // seconds
+}
+
+// Wine prefix Steam created for a game run under Proton
+export interface ProtonPrefix {
+	appId: number;
+	path: string;
+	exists: boolean;
+	size: number; // bytes
+	tool: '' | 'protontricks' | 'protontricks-flatpak' | 'winetricks';
+}
+
+// Compatibility tool installed on the device
+export interface CompatTool {
+	name: string; // internal name Steam's config refers to
+	displayName: string;
+	custom: boolean; // in compatibilitytools.d
+}
+
+// GE-Proton release published on GitHub
+export interface GEProtonRelease {
+	tag: string;
+	publishedAt: string;
+	size: number; // bytes
+	installed: boolean;
+}
+
+// Differences between an installed game and its local build
+export interface IntegrityReport {
+	name: string;
+	path: string;
+	checked: number;
+	missing: string[];
+	modified: string[];
+	extra: string[];
+	source: 'device' | 'manifest';
+}
+
+// Disk space breakdown of the connected device
+export interface StorageEntry {
+	name: string;
+	path: string;
+	size: number; // bytes
+}
+
+export interface DiskUsage {
+	mount: string;
+	total: number; // bytes
+	used: number;
+	free: number;
+}
+
+export interface StorageUsage {
+	disks: DiskUsage[];
+	games: StorageEntry[]; // largest first
+	libraries: StorageEntry[];
+}
+
+// Everything uninstalling a game removes from the device
+export interface UninstallPlan {
+	name: string;
+	path: string;
+	size: number;
+	shortcut?: string;
+	appId?: number;
+	gridFiles: string[];
+	extraShortcuts?: string[];
+	compatData?: string;
+	compatDataSize: number;
+}
+
+export interface ShortcutEntry {
+	name: string;
+	exe: string;
+	startDir: string;
+	launchOptions: string;
+	appId: number;
+}
+
+export interface ArtworkSuggestion {
+	name: string;
+	appId: number;
+	gridDBGameID: number;
+	gridDBName: string;
+	gridPortrait: string;
+	gridLandscape: string;
+	heroImage: string;
+	logoImage: string;
+	iconImage: string;
+	error?: string;
+}
+
+export interface BatchArtworkProgress {
+	current: number;
+	total: number;
+	name: string;
+	error?: string;
+	done: boolean;
 }
 
 export interface UploadProgress {
@@ -49,6 +270,67 @@ export interface UploadProgress {
 	status: string;
 	error?: string;
 	done: boolean;
+	artwork?: ArtworkCheck[];
+	verbs?: VerbResult[];
+	transfer?: TransferStats; // while the files upload
+	errorCode?: string; // e.g. 'DISK_FULL'
+	retried?: string[]; // files uploaded after retries, with their retries
+}
+
+// Host key of a device waiting for the user to trust it
+export interface HostKeyPrompt {
+	host: string;
+	keyType: string;
+	fingerprint: string; // SHA256:<base64>, like ssh-keygen -lf
+	changed: boolean; // another key was trusted for the device before
+}
+
+// Deploy running in the background, or the last one
+export interface UploadJob {
+	setupId: string;
+	name: string;
+	devices: string[]; // device names
+	startedAt: string;
+	progress: UploadProgress;
+}
+
+// Progress of one device of a multi-device deploy
+export interface DeviceProgress extends UploadProgress {
+	host: string;
+	name: string;
+}
+
+// Watch mode, which deploys a setup whenever its folder changes
+export interface WatchStatus {
+	setupId: string; // '' when no folder is watched
+	changed: boolean;
+	deploying: boolean;
+	lastDeploy?: string;
+	error?: string;
+}
+
+// Bytes sent, speed and time left of an upload
+export interface TransferStats {
+	sentBytes: number;
+	totalBytes: number;
+	bytesPerSecond: number;
+	etaSeconds: number; // 0 until the speed is known
+}
+
+// Result of installing a prefix verb on deploy
+export interface VerbResult {
+	verb: string;
+	ok: boolean;
+	error?: string;
+}
+
+// Result of checking an applied artwork slot on the device
+export interface ArtworkCheck {
+	slot: string;
+	file?: string;
+	size: number;
+	ok: boolean;
+	error?: string;
 }
 
 // SteamGridDB types
@@ -59,6 +341,12 @@ export interface SearchResult {
 	verified: boolean;
 }
 
+export interface ArtworkAuthor {
+	name: string;
+	steam64: string;
+	avatar: string;
+}
+
 export interface GridData {
 	id: number;
 	score: number;
@@ -75,6 +363,8 @@ export interface GridData {
 	epilepsy: boolean;
 	upvotes: number;
 	downvotes: number;
+	author?: ArtworkAuthor;
+	source?: string;
 }
 
 export interface ImageData {
@@ -93,6 +383,209 @@ export interface ImageData {
 	epilepsy: boolean;
 	upvotes: number;
 	downvotes: number;
+	author?: ArtworkAuthor;
+	source?: string;
+}
+
+export interface OfficialArtwork {
+	appId: number;
+	capsules: GridData[] | null;
+	wide: GridData[] | null;
+	heroes: ImageData[] | null;
+	logos: ImageData[] | null;
+}
+
+// Alternative artwork providers (IGDB)
+export interface ProviderGame {
+	id: number;
+	name: string;
+	provider: string;
+}
+
+export interface OfflineGame {
+	id: number;
+	name: string;
+	images: number;
+	updatedAt: string;
+}
+
+// Artwork a shortcut currently has on the device, data URLs keyed by artwork type
+export interface CurrentArtwork {
+	appId: number;
+	images: Partial<Record<ArtworkType, string>>;
+}
+
+export interface ProviderArtwork {
+	capsules: GridData[];
+	wide: GridData[];
+	heroes: ImageData[];
+	logos: ImageData[];
+}
+
+export interface IGDBCredentials {
+	clientId: string;
+	clientSecret: string;
+}
+
+// Recently applied / favorite artwork
+export type ArtworkType = 'capsule' | 'wide' | 'hero' | 'logo' | 'icon';
+
+export interface ArtworkRef {
+	type: ArtworkType;
+	url: string;
+	thumb?: string;
+	width?: number;
+	height?: number;
+	mime?: string;
+	style?: string;
+	game_name?: string;
+	used_at?: string;
+}
+
+export interface ArtworkHistory {
+	recent: ArtworkRef[];
+	favorites: ArtworkRef[];
+}
+
+// SteamGridDB paging metadata (pages are 0-based)
+export interface PageInfo {
+	page: number;
+	limit: number;
+	total: number;
+	totalPages: number;
+}
+
+export interface GridPage {
+	items: GridData[];
+	paging: PageInfo;
+}
+
+export interface ImagePage {
+	items: ImageData[];
+	paging: PageInfo;
+}
+
+// Result of validating an API key
+export interface KeyTestResult {
+	valid: boolean;
+	message: string;
+}
+
+// Image cache limits
+export interface ImageCacheSettings {
+	max_size_mb: number;
+	ttl_hours: number;
+	memory_mb: number;
+}
+
+// What a configuration import added or replaced
+export interface ImportSummary {
+	devices: number;
+	gameSetups: number;
+	presets: number;
+	settings: boolean;
+	// Setups whose build or post-deploy command was left out
+	skippedCommands?: string[];
+}
+
+// Hub log record shown in the Logs tab
+export interface LogEntry {
+	time: string;
+	level: 'DEBUG' | 'INFO' | 'WARN' | 'ERROR';
+	message: string;
+	attrs?: string;
+}
+
+// UI theme and scale
+export type Theme = 'system' | 'dark' | 'light';
+
+export interface Appearance {
+	theme: Theme;
+	ui_scale: number;
+	language?: string; // '' follows the system
+}
+
+// Shortcut actions and the key combination bound to each, such as "Ctrl+Shift+D"
+export type KeyAction =
+	| 'deploy_last'
+	| 'cancel_transfer'
+	| 'toggle_connection'
+	| 'tab_devices'
+	| 'tab_upload'
+	| 'tab_games'
+	| 'tab_settings'
+	| 'tab_logs';
+
+export type KeyBindings = Partial<Record<KeyAction, string>>;
+
+// MangoHud setup of the connected device
+export interface MangoHudConfig {
+	installed: boolean;
+	path: string;
+	config: string;
+}
+
+// Steam shortcut on the device and whether it shows the MangoHud overlay
+export interface MangoHudGame {
+	appId: number;
+	name: string;
+	enabled: boolean;
+}
+
+// One reading of the connected device's load, sensors it lacks are left out
+export interface PerfSample {
+	time: string;
+	cpu?: number; // percent, missing on the first sample
+	gpu?: number; // percent
+	ramUsed: number; // bytes
+	ramTotal: number; // bytes
+	cpuTemp?: number; // degrees Celsius
+	gpuTemp?: number; // degrees Celsius
+}
+
+// Line of the live log of a game
+export interface GameLogLine {
+	source: 'output' | 'proton' | 'journal';
+	text: string;
+}
+
+// Capture of the connected device's screen
+export interface Screenshot {
+	dataUrl: string;
+	deviceName: string;
+	takenAt: string;
+}
+
+// Hub release newer than the running one
+export interface UpdateInfo {
+	version: string;
+	currentVersion: string;
+	publishedAt: string;
+	changelog: string; // release notes since the running version, newest first
+}
+
+// Proxy and timeout of artwork requests
+export interface NetworkSettings {
+	proxy_url?: string;
+	timeout_seconds: number;
+}
+
+// Local automation API of the hub
+export interface AutomationSettings {
+	enabled: boolean;
+	port: number;
+	token?: string;
+}
+
+// Transfer and download tuning
+export interface PerformanceSettings {
+	transfer_workers: number;
+	chunk_size_kb: number;
+	fetch_workers: number;
+	max_retries: number;
+	upload_limit_kbps?: number; // 0 = unlimited
+	upload_retries: number; // per file
+	upload_retry_budget: number; // per deploy
 }
 
 export interface ImageFilters {
@@ -111,8 +604,24 @@ export interface ArtworkSelection {
 	heroImage: string;
 	logoImage: string;
 	iconImage: string;
+	logoPosition?: LogoPosition | null;
 }
 
+export type LogoPin = 'UpperLeft' | 'UpperCenter' | 'CenterCenter' | 'BottomLeft' | 'BottomCenter';
+
+// Logo placement over the hero, as stored by Steam in grid/{appid}.json
+export interface LogoPosition {
+	pinnedPosition: LogoPin;
+	nWidthPct: number;
+	nHeightPct: number;
+}
+
+export const defaultLogoPosition: LogoPosition = {
+	pinnedPosition: 'BottomLeft',
+	nWidthPct: 50,
+	nHeightPct: 50
+};
+
 // Filter options
 export const gridStyles = ['All Styles', 'alternate', 'white_logo', 'no_logo', 'blurred', 'material'];
 export const heroStyles = ['All Styles', 'alternate', 'blurred', 'material'];
@@ -130,3 +639,4 @@ export const logoMimes = ['All Formats', 'image/png', 'image/webp'];
 export const iconMimes = ['All Formats', 'image/png', 'image/vnd.microsoft.icon'];
 
 export const animationOptions = ['All', 'Static Only', 'Animated Only'];
+export const sortOptions = ['Default', 'Score', 'Newest', 'Votes'];
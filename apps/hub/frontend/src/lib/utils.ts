@@ -13,6 +13,13 @@ export function formatBytes(bytes: number): string {
 	return parseFloat((bytes / Math.pow(k, i)).toFixed(1)) + ' ' + sizes[i];
 }
 
+export function formatDuration(seconds: number): string {
+	const s = Math.ceil(seconds);
+	if (s < 60) return `${s}s`;
+	if (s < 3600) return `${Math.floor(s / 60)}m ${s % 60}s`;
+	return `${Math.floor(s / 3600)}h ${Math.floor((s % 3600) / 60)}m`;
+}
+
 export function truncatePath(path: string, maxLen: number): string {
 	if (path.length <= maxLen) return path;
 	return '...' + path.slice(-maxLen + 3);
@@ -14,25 +14,140 @@ declare global {
 					DisconnectDevice(): Promise<void>;
 					GetConnectionStatus(): Promise<any>;
 					ScanNetwork(): Promise<any[]>;
+					TestDeviceConnection(dev: any): Promise<any>;
+					GetPendingHostKey(host: string): Promise<any>;
+					TrustHostKey(host: string, fingerprint: string): Promise<void>;
+					NeedsFirstRunSetup(): Promise<boolean>;
+					CompleteFirstRunSetup(): Promise<void>;
 					GetGameSetups(): Promise<any[]>;
 					AddGameSetup(setup: any): Promise<void>;
 					UpdateGameSetup(id: string, setup: any): Promise<void>;
 					RemoveGameSetup(id: string): Promise<void>;
 					SelectFolder(): Promise<string>;
+					SelectArchive(): Promise<string>;
+					FindExecutables(folder: string): Promise<string[]>;
+					DetectBuildLayout(folder: string): Promise<any>;
 					UploadGame(setupID: string): Promise<void>;
+					UploadGameToDevices(setupID: string, hosts: string[]): Promise<void>;
+					DeployLastSetup(): Promise<string>;
+					CancelUpload(): Promise<void>;
+					GetUploadJob(): Promise<any>;
+					StartWatchDeploy(setupID: string): Promise<void>;
+					StopWatchDeploy(): Promise<void>;
+					GetWatchStatus(): Promise<any>;
 					GetInstalledGames(remotePath: string): Promise<any[]>;
-					DeleteGame(name: string, path: string): Promise<void>;
+					GetUninstallPlan(gamePath: string): Promise<any>;
+					UninstallGame(gamePath: string, removeCompatData: boolean): Promise<void>;
+					UpdateGame(gamePath: string): Promise<void>;
+					GetGameDetails(gamePath: string): Promise<any>;
+					SetGameNotes(gamePath: string, notes: string): Promise<void>;
+					GetGameReleases(gamePath: string): Promise<any[]>;
+					RollbackGame(gamePath: string, release: string): Promise<void>;
+					LaunchGame(gamePath: string): Promise<void>;
+					StopGame(gamePath: string): Promise<void>;
+					IsGameRunning(gamePath: string): Promise<boolean>;
+					GetGamesRunState(gamePaths: string[]): Promise<any[]>;
+					VerifyGame(gamePath: string): Promise<any>;
+					RepairGame(gamePath: string): Promise<any>;
+					ExportInstalledGames(remotePath: string, format: string): Promise<string>;
+					GetStorageUsage(remotePath: string): Promise<any>;
+					CloneGame(gamePath: string, targetHost: string): Promise<void>;
+					ArchiveGame(gamePath: string, compress: boolean): Promise<string>;
+					EditGameShortcut(gamePath: string, edit: any): Promise<void>;
+					GetGameLog(gamePath: string, lines: number, previous: boolean): Promise<string>;
+					StartGameLogStream(gamePath: string): Promise<void>;
+					StopGameLogStream(): Promise<void>;
+					SaveGameLog(gameName: string, content: string): Promise<string>;
+					GetProtonPrefix(gamePath: string): Promise<any>;
+					OpenProtonPrefix(gamePath: string): Promise<void>;
+					RunPrefixVerbs(gamePath: string, verbs: string[]): Promise<string>;
+					ResetProtonPrefix(gamePath: string): Promise<void>;
+					GetCompatTools(): Promise<any[]>;
+					GetGEProtonReleases(): Promise<any[]>;
+					InstallGEProton(tag: string): Promise<void>;
+					GetGameCompatTool(gamePath: string): Promise<string>;
+					SetGameCompatTool(gamePath: string, name: string): Promise<void>;
+					ListGameFiles(gamePath: string, dir: string): Promise<any[]>;
+					DownloadGameFile(gamePath: string, file: string): Promise<string>;
+					ReplaceGameFile(gamePath: string, file: string): Promise<boolean>;
+					DeleteGameFile(gamePath: string, file: string): Promise<void>;
+					GetShortcuts(): Promise<any[]>;
+					SuggestArtwork(entries: any[]): Promise<any[]>;
+					ApplyBatchArtwork(items: any[]): Promise<void>;
 					GetSteamGridDBAPIKey(): Promise<string>;
 					SetSteamGridDBAPIKey(key: string): Promise<void>;
+					TestSteamGridDBAPIKey(key: string): Promise<any>;
+					GetIGDBCredentials(): Promise<any>;
+					SetIGDBCredentials(creds: any): Promise<void>;
 					GetCacheSize(): Promise<number>;
 					ClearImageCache(): Promise<void>;
 					OpenCacheFolder(): Promise<void>;
+					GetImageCacheSettings(): Promise<any>;
+					SetImageCacheSettings(settings: any): Promise<void>;
+					GetPerformanceSettings(): Promise<any>;
+					SetPerformanceSettings(settings: any): Promise<void>;
+					GetAppearance(): Promise<any>;
+					GetDefaultLaunchOptions(): Promise<string>;
+					SetDefaultLaunchOptions(options: string): Promise<void>;
+					GetNetworkSettings(): Promise<any>;
+					GetHubLogs(level: string): Promise<any>;
+					ExportConfig(): Promise<string>;
+					ImportConfig(): Promise<any>;
+					OpenLogFolder(): Promise<void>;
+					CreateSupportBundle(): Promise<string>;
+					CheckForUpdate(): Promise<any>;
+					GetAvailableUpdate(): Promise<any>;
+					DownloadUpdate(): Promise<void>;
+					SkipUpdate(version: string): Promise<void>;
+					GetCheckForUpdates(): Promise<boolean>;
+					SetCheckForUpdates(enabled: boolean): Promise<void>;
+					GetVersion(): Promise<string>;
+					GetMangoHudConfig(): Promise<any>;
+					SetMangoHudConfig(content: string): Promise<void>;
+					GetMangoHudGames(): Promise<any[]>;
+					SetGameMangoHud(appID: number, enabled: boolean): Promise<void>;
+					StartPerformanceMonitor(): Promise<void>;
+					StopPerformanceMonitor(): Promise<void>;
+					CaptureScreenshot(): Promise<any>;
+					SaveScreenshot(): Promise<string>;
+					SetNetworkSettings(settings: any): Promise<void>;
+					GetAutomationSettings(): Promise<any>;
+					SetAutomationSettings(settings: any): Promise<any>;
+					RegenerateAutomationToken(): Promise<any>;
+					GetQuitOnClose(): Promise<boolean>;
+					SetQuitOnClose(quit: boolean): Promise<void>;
+					IsTrayAvailable(): Promise<boolean>;
+					GetKeyBindings(): Promise<Record<string, string>>;
+					SetKeyBindings(bindings: any): Promise<void>;
+					SetAppearance(appearance: any): Promise<void>;
+					GetIconUpscale(): Promise<string>;
+					SetIconUpscale(method: string): Promise<void>;
 					SearchGames(query: string): Promise<any[]>;
-					GetGrids(gameID: number, filters: any, page: number): Promise<any[]>;
-					GetHeroes(gameID: number, filters: any, page: number): Promise<any[]>;
-					GetLogos(gameID: number, filters: any, page: number): Promise<any[]>;
-					GetIcons(gameID: number, filters: any, page: number): Promise<any[]>;
+					GetGameByID(gameID: number): Promise<any>;
+					GetGrids(gameID: number, filters: any, page: number): Promise<any>;
+					GetHeroes(gameID: number, filters: any, page: number): Promise<any>;
+					GetLogos(gameID: number, filters: any, page: number): Promise<any>;
+					GetIcons(gameID: number, filters: any, page: number): Promise<any>;
+					GetOfficialArtwork(gameID: number): Promise<any>;
+					GetArtworkProviders(): Promise<string[]>;
+					SearchArtworkProvider(provider: string, term: string): Promise<any[]>;
+					GetProviderArtwork(provider: string, gameID: number): Promise<any>;
+					GetArtworkHistory(): Promise<any>;
+					GetArtworkSelection(gameName: string): Promise<any>;
+					SaveArtworkSelection(gameName: string, selection: any): Promise<void>;
+					ToggleFavoriteArtwork(ref: any): Promise<boolean>;
 					ProxyImage(imageURL: string): Promise<string>;
+					CancelImageLoads(): Promise<void>;
+					SetOfflineMode(enabled: boolean): Promise<void>;
+					IsOfflineMode(): Promise<boolean>;
+					GetOfflineGames(): Promise<any[]>;
+					ExportArtwork(gameName: string, selection: any): Promise<string>;
+					SaveGeneratedArtwork(slot: string, dataURL: string): Promise<string>;
+					GetCurrentArtwork(gameName: string): Promise<any>;
+					GetArtworkToTranscode(selection: any): Promise<string[]>;
+					SaveTranscodedArtwork(sourceURL: string, dataURL: string): Promise<void>;
+					GetArtworkFilters(): Promise<Record<string, any>>;
+					SetArtworkFilter(assetType: string, filter: any): Promise<void>;
 				};
 			};
 		};
@@ -52,6 +167,13 @@ export const ConnectDevice = (host: string) => window.go.main.App.ConnectDevice(
 export const DisconnectDevice = () => window.go.main.App.DisconnectDevice();
 export const GetConnectionStatus = () => window.go.main.App.GetConnectionStatus();
 export const ScanNetwork = () => window.go.main.App.ScanNetwork();
+export const TestDeviceConnection = (dev: any) => window.go.main.App.TestDeviceConnection(dev);
+export const GetPendingHostKey = (host: string) => window.go.main.App.GetPendingHostKey(host);
+export const TrustHostKey = (host: string, fingerprint: string) => window.go.main.App.TrustHostKey(host, fingerprint);
+
+// First run setup
+export const NeedsFirstRunSetup = () => window.go.main.App.NeedsFirstRunSetup();
+export const CompleteFirstRunSetup = () => window.go.main.App.CompleteFirstRunSetup();
 
 // Game setup functions
 export const GetGameSetups = () => window.go.main.App.GetGameSetups();
@@ -59,26 +181,138 @@ export const AddGameSetup = (setup: any) => window.go.main.App.AddGameSetup(setu
 export const UpdateGameSetup = (id: string, setup: any) => window.go.main.App.UpdateGameSetup(id, setup);
 export const RemoveGameSetup = (id: string) => window.go.main.App.RemoveGameSetup(id);
 export const SelectFolder = () => window.go.main.App.SelectFolder();
+export const SelectArchive = () => window.go.main.App.SelectArchive();
+export const FindExecutables = (folder: string) => window.go.main.App.FindExecutables(folder);
+export const DetectBuildLayout = (folder: string) => window.go.main.App.DetectBuildLayout(folder);
 export const UploadGame = (setupID: string) => window.go.main.App.UploadGame(setupID);
+export const UploadGameToDevices = (setupID: string, hosts: string[]) => window.go.main.App.UploadGameToDevices(setupID, hosts);
+export const DeployLastSetup = () => window.go.main.App.DeployLastSetup();
+export const CancelUpload = () => window.go.main.App.CancelUpload();
+export const GetUploadJob = () => window.go.main.App.GetUploadJob();
+export const StartWatchDeploy = (setupID: string) => window.go.main.App.StartWatchDeploy(setupID);
+export const StopWatchDeploy = () => window.go.main.App.StopWatchDeploy();
+export const GetWatchStatus = () => window.go.main.App.GetWatchStatus();
 
 // Installed games functions
 export const GetInstalledGames = (remotePath: string) => window.go.main.App.GetInstalledGames(remotePath);
-export const DeleteGame = (name: string, path: string) => window.go.main.App.DeleteGame(name, path);
+export const GetUninstallPlan = (gamePath: string) => window.go.main.App.GetUninstallPlan(gamePath);
+export const UninstallGame = (gamePath: string, removeCompatData: boolean) => window.go.main.App.UninstallGame(gamePath, removeCompatData);
+export const UpdateGame = (gamePath: string) => window.go.main.App.UpdateGame(gamePath);
+export const LaunchGame = (gamePath: string) => window.go.main.App.LaunchGame(gamePath);
+export const StopGame = (gamePath: string) => window.go.main.App.StopGame(gamePath);
+export const IsGameRunning = (gamePath: string) => window.go.main.App.IsGameRunning(gamePath);
+export const GetGamesRunState = (gamePaths: string[]) => window.go.main.App.GetGamesRunState(gamePaths);
+export const VerifyGame = (gamePath: string) => window.go.main.App.VerifyGame(gamePath);
+export const RepairGame = (gamePath: string) => window.go.main.App.RepairGame(gamePath);
+export const ExportInstalledGames = (remotePath: string, format: string) => window.go.main.App.ExportInstalledGames(remotePath, format);
+export const GetStorageUsage = (remotePath: string) => window.go.main.App.GetStorageUsage(remotePath);
+export const CloneGame = (gamePath: string, targetHost: string) => window.go.main.App.CloneGame(gamePath, targetHost);
+export const ArchiveGame = (gamePath: string, compress: boolean) => window.go.main.App.ArchiveGame(gamePath, compress);
+export const EditGameShortcut = (gamePath: string, edit: any) => window.go.main.App.EditGameShortcut(gamePath, edit);
+export const GetGameLog = (gamePath: string, lines: number, previous: boolean) => window.go.main.App.GetGameLog(gamePath, lines, previous);
+export const StartGameLogStream = (gamePath: string) => window.go.main.App.StartGameLogStream(gamePath);
+export const StopGameLogStream = () => window.go.main.App.StopGameLogStream();
+export const SaveGameLog = (gameName: string, content: string) => window.go.main.App.SaveGameLog(gameName, content);
+export const GetProtonPrefix = (gamePath: string) => window.go.main.App.GetProtonPrefix(gamePath);
+export const OpenProtonPrefix = (gamePath: string) => window.go.main.App.OpenProtonPrefix(gamePath);
+export const RunPrefixVerbs = (gamePath: string, verbs: string[]) => window.go.main.App.RunPrefixVerbs(gamePath, verbs);
+export const ResetProtonPrefix = (gamePath: string) => window.go.main.App.ResetProtonPrefix(gamePath);
+export const GetCompatTools = () => window.go.main.App.GetCompatTools();
+export const GetGEProtonReleases = () => window.go.main.App.GetGEProtonReleases();
+export const InstallGEProton = (tag: string) => window.go.main.App.InstallGEProton(tag);
+export const GetGameCompatTool = (gamePath: string) => window.go.main.App.GetGameCompatTool(gamePath);
+export const SetGameCompatTool = (gamePath: string, name: string) => window.go.main.App.SetGameCompatTool(gamePath, name);
+export const GetGameDetails = (gamePath: string) => window.go.main.App.GetGameDetails(gamePath);
+export const SetGameNotes = (gamePath: string, notes: string) => window.go.main.App.SetGameNotes(gamePath, notes);
+export const GetGameReleases = (gamePath: string) => window.go.main.App.GetGameReleases(gamePath);
+export const RollbackGame = (gamePath: string, release: string) => window.go.main.App.RollbackGame(gamePath, release);
+export const ListGameFiles = (gamePath: string, dir: string) => window.go.main.App.ListGameFiles(gamePath, dir);
+export const DownloadGameFile = (gamePath: string, file: string) => window.go.main.App.DownloadGameFile(gamePath, file);
+export const ReplaceGameFile = (gamePath: string, file: string) => window.go.main.App.ReplaceGameFile(gamePath, file);
+export const DeleteGameFile = (gamePath: string, file: string) => window.go.main.App.DeleteGameFile(gamePath, file);
+export const GetShortcuts = () => window.go.main.App.GetShortcuts();
+export const SuggestArtwork = (entries: any[]) => window.go.main.App.SuggestArtwork(entries);
+export const ApplyBatchArtwork = (items: any[]) => window.go.main.App.ApplyBatchArtwork(items);
 
 // Settings functions
 export const GetSteamGridDBAPIKey = () => window.go.main.App.GetSteamGridDBAPIKey();
 export const SetSteamGridDBAPIKey = (key: string) => window.go.main.App.SetSteamGridDBAPIKey(key);
+export const TestSteamGridDBAPIKey = (key: string) => window.go.main.App.TestSteamGridDBAPIKey(key);
+export const GetIGDBCredentials = () => window.go.main.App.GetIGDBCredentials();
+export const SetIGDBCredentials = (creds: any) => window.go.main.App.SetIGDBCredentials(creds);
 export const GetCacheSize = () => window.go.main.App.GetCacheSize();
 export const ClearImageCache = () => window.go.main.App.ClearImageCache();
 export const OpenCacheFolder = () => window.go.main.App.OpenCacheFolder();
+export const GetImageCacheSettings = () => window.go.main.App.GetImageCacheSettings();
+export const SetImageCacheSettings = (settings: any) => window.go.main.App.SetImageCacheSettings(settings);
+export const GetPerformanceSettings = () => window.go.main.App.GetPerformanceSettings();
+export const SetPerformanceSettings = (settings: any) => window.go.main.App.SetPerformanceSettings(settings);
+export const GetAppearance = () => window.go.main.App.GetAppearance();
+export const GetDefaultLaunchOptions = () => window.go.main.App.GetDefaultLaunchOptions();
+export const SetDefaultLaunchOptions = (options: string) => window.go.main.App.SetDefaultLaunchOptions(options);
+export const GetNetworkSettings = () => window.go.main.App.GetNetworkSettings();
+export const GetHubLogs = (level: string) => window.go.main.App.GetHubLogs(level);
+export const ExportConfig = () => window.go.main.App.ExportConfig();
+export const ImportConfig = () => window.go.main.App.ImportConfig();
+export const OpenLogFolder = () => window.go.main.App.OpenLogFolder();
+export const CreateSupportBundle = () => window.go.main.App.CreateSupportBundle();
+export const CheckForUpdate = () => window.go.main.App.CheckForUpdate();
+export const GetAvailableUpdate = () => window.go.main.App.GetAvailableUpdate();
+export const DownloadUpdate = () => window.go.main.App.DownloadUpdate();
+export const SkipUpdate = (version: string) => window.go.main.App.SkipUpdate(version);
+export const GetCheckForUpdates = () => window.go.main.App.GetCheckForUpdates();
+export const SetCheckForUpdates = (enabled: boolean) => window.go.main.App.SetCheckForUpdates(enabled);
+export const GetVersion = () => window.go.main.App.GetVersion();
+export const SetNetworkSettings = (settings: any) => window.go.main.App.SetNetworkSettings(settings);
+export const GetAutomationSettings = () => window.go.main.App.GetAutomationSettings();
+export const SetAutomationSettings = (settings: any) => window.go.main.App.SetAutomationSettings(settings);
+export const RegenerateAutomationToken = () => window.go.main.App.RegenerateAutomationToken();
+export const GetQuitOnClose = () => window.go.main.App.GetQuitOnClose();
+export const SetQuitOnClose = (quit: boolean) => window.go.main.App.SetQuitOnClose(quit);
+export const IsTrayAvailable = () => window.go.main.App.IsTrayAvailable();
+export const GetKeyBindings = () => window.go.main.App.GetKeyBindings();
+export const SetKeyBindings = (bindings: any) => window.go.main.App.SetKeyBindings(bindings);
+export const SetAppearance = (appearance: any) => window.go.main.App.SetAppearance(appearance);
+export const GetIconUpscale = () => window.go.main.App.GetIconUpscale();
+export const SetIconUpscale = (method: string) => window.go.main.App.SetIconUpscale(method);
+
+// Performance functions
+export const GetMangoHudConfig = () => window.go.main.App.GetMangoHudConfig();
+export const SetMangoHudConfig = (content: string) => window.go.main.App.SetMangoHudConfig(content);
+export const GetMangoHudGames = () => window.go.main.App.GetMangoHudGames();
+export const SetGameMangoHud = (appID: number, enabled: boolean) => window.go.main.App.SetGameMangoHud(appID, enabled);
+export const StartPerformanceMonitor = () => window.go.main.App.StartPerformanceMonitor();
+export const StopPerformanceMonitor = () => window.go.main.App.StopPerformanceMonitor();
+export const CaptureScreenshot = () => window.go.main.App.CaptureScreenshot();
+export const SaveScreenshot = () => window.go.main.App.SaveScreenshot();
 
 // SteamGridDB functions
 export const SearchGames = (query: string) => window.go.main.App.SearchGames(query);
+export const GetGameByID = (gameID: number) => window.go.main.App.GetGameByID(gameID);
 export const GetGrids = (gameID: number, filters: any, page: number) => window.go.main.App.GetGrids(gameID, filters, page);
 export const GetHeroes = (gameID: number, filters: any, page: number) => window.go.main.App.GetHeroes(gameID, filters, page);
 export const GetLogos = (gameID: number, filters: any, page: number) => window.go.main.App.GetLogos(gameID, filters, page);
 export const GetIcons = (gameID: number, filters: any, page: number) => window.go.main.App.GetIcons(gameID, filters, page);
+export const GetArtworkHistory = () => window.go.main.App.GetArtworkHistory();
+export const GetArtworkSelection = (gameName: string) => window.go.main.App.GetArtworkSelection(gameName);
+export const SaveArtworkSelection = (gameName: string, selection: any) => window.go.main.App.SaveArtworkSelection(gameName, selection);
+export const ToggleFavoriteArtwork = (ref: any) => window.go.main.App.ToggleFavoriteArtwork(ref);
+export const GetOfficialArtwork = (gameID: number) => window.go.main.App.GetOfficialArtwork(gameID);
+export const GetArtworkProviders = () => window.go.main.App.GetArtworkProviders();
+export const SearchArtworkProvider = (provider: string, term: string) => window.go.main.App.SearchArtworkProvider(provider, term);
+export const GetProviderArtwork = (provider: string, gameID: number) => window.go.main.App.GetProviderArtwork(provider, gameID);
 export const ProxyImage = (imageURL: string) => window.go.main.App.ProxyImage(imageURL);
+export const CancelImageLoads = () => window.go.main.App.CancelImageLoads();
+export const SetOfflineMode = (enabled: boolean) => window.go.main.App.SetOfflineMode(enabled);
+export const IsOfflineMode = () => window.go.main.App.IsOfflineMode();
+export const GetOfflineGames = () => window.go.main.App.GetOfflineGames();
+export const ExportArtwork = (gameName: string, selection: any) => window.go.main.App.ExportArtwork(gameName, selection);
+export const SaveGeneratedArtwork = (slot: string, dataURL: string) => window.go.main.App.SaveGeneratedArtwork(slot, dataURL);
+export const GetCurrentArtwork = (gameName: string) => window.go.main.App.GetCurrentArtwork(gameName);
+export const GetArtworkToTranscode = (selection: any) => window.go.main.App.GetArtworkToTranscode(selection);
+export const SaveTranscodedArtwork = (sourceURL: string, dataURL: string) => window.go.main.App.SaveTranscodedArtwork(sourceURL, dataURL);
+export const GetArtworkFilters = () => window.go.main.App.GetArtworkFilters();
+export const SetArtworkFilter = (assetType: string, filter: any) => window.go.main.App.SetArtworkFilter(assetType, filter);
 
 // Runtime events
 export const EventsOn = (event: string, callback: (...args: any[]) => void) => window.runtime.EventsOn(event, callback);
@@ -0,0 +1,213 @@
+package main
+
+import (
+	"fmt"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/lobinuxsoft/capydeploy/internal/deploy"
+	"github.com/lobinuxsoft/capydeploy/internal/device"
+	"github.com/lobinuxsoft/capydeploy/internal/shortcuts"
+)
+
+// =============================================================================
+// Game Launch and Stop
+// =============================================================================
+
+// processListCmd lists every process on the device as "pid ppid elapsed-seconds args"
+const processListCmd = "ps -eo pid=,ppid=,etimes=,args="
+
+// stopGracePeriod is how long a game gets to exit after SIGTERM before SIGKILL
+const stopGracePeriod = 3 * time.Second
+
+// LaunchGame starts a deployed game through its Steam shortcut on the device
+func (a *App) LaunchGame(gamePath string) error {
+	client, deviceCfg, err := a.connectedClient()
+	if err != nil {
+		return err
+	}
+
+	game := &InstalledGame{Name: path.Base(gamePath), Path: gamePath}
+	if manifest, ok := deploy.ReadManifest(client, gamePath); ok {
+		game.AppID = manifest.AppID
+	}
+	list, err := shortcuts.ListShortcuts(remoteConfig(client, deviceCfg))
+	if err != nil {
+		return fmt.Errorf("failed to list shortcuts: %w", err)
+	}
+	sc := gameShortcut(list, game)
+	if sc == nil {
+		return fmt.Errorf("%s has no Steam shortcut to launch", game.Name)
+	}
+
+	// Non-Steam games are addressed by their 64-bit game ID
+	gameID := uint64(uint32(sc.AppID))<<32 | 0x02000000
+	cmd := fmt.Sprintf("DISPLAY=${DISPLAY:-:0} nohup steam -ifrunning steam://rungameid/%d >/dev/null 2>&1 &", gameID)
+	if _, err := client.RunCommand(cmd); err != nil {
+		return fmt.Errorf("failed to launch game: %w", err)
+	}
+	return nil
+}
+
+// StopGame kills the process tree of a running game, asking politely first
+func (a *App) StopGame(gamePath string) error {
+	client, _, err := a.connectedClient()
+	if err != nil {
+		return err
+	}
+
+	pids, err := runningGamePIDs(client, gamePath)
+	if err != nil {
+		return err
+	}
+	if len(pids) == 0 {
+		return nil
+	}
+
+	client.RunCommand("kill -TERM " + joinPIDs(pids) + " 2>/dev/null")
+	time.Sleep(stopGracePeriod)
+
+	pids, err = runningGamePIDs(client, gamePath)
+	if err != nil || len(pids) == 0 {
+		return err
+	}
+	if _, err := client.RunCommand("kill -KILL " + joinPIDs(pids) + " 2>/dev/null"); err != nil {
+		return fmt.Errorf("failed to stop game: %w", err)
+	}
+	return nil
+}
+
+// GameRunState is whether an installed game is running, and since when
+type GameRunState struct {
+	Path    string `json:"path"`
+	Running bool   `json:"running"`
+	PID     int    `json:"pid,omitempty"`    // oldest process of the game
+	Uptime  int64  `json:"uptime,omitempty"` // seconds
+}
+
+// GetGamesRunState checks the running state of several installed games with a
+// single process listing, for polling the whole Games tab
+func (a *App) GetGamesRunState(gamePaths []string) ([]GameRunState, error) {
+	client, _, err := a.connectedClient()
+	if err != nil {
+		return nil, err
+	}
+	homeDir, err := client.GetHomeDir()
+	if err != nil {
+		return nil, err
+	}
+
+	output, err := client.RunCommand(processListCmd)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list processes: %w", err)
+	}
+
+	states := make([]GameRunState, 0, len(gamePaths))
+	for _, gamePath := range gamePaths {
+		state := GameRunState{Path: gamePath}
+		if clean, err := cleanGamePath(gamePath, homeDir); err == nil {
+			state.Running, state.PID, state.Uptime = runState(gameProcesses(output, clean))
+		}
+		states = append(states, state)
+	}
+	return states, nil
+}
+
+// IsGameRunning reports whether any process was started from the game directory
+func (a *App) IsGameRunning(gamePath string) (bool, error) {
+	client, _, err := a.connectedClient()
+	if err != nil {
+		return false, err
+	}
+	pids, err := runningGamePIDs(client, gamePath)
+	return len(pids) > 0, err
+}
+
+func runningGamePIDs(client *device.Client, gamePath string) ([]int, error) {
+	// An empty or root path would match every process on the device
+	homeDir, err := client.GetHomeDir()
+	if err != nil {
+		return nil, err
+	}
+	gamePath, err = validateGamePath(client, gamePath, homeDir)
+	if err != nil {
+		return nil, err
+	}
+
+	output, err := client.RunCommand(processListCmd)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list processes: %w", err)
+	}
+
+	var pids []int
+	for _, p := range gameProcesses(output, gamePath) {
+		pids = append(pids, p.pid)
+	}
+	return pids, nil
+}
+
+// process is a line of processListCmd
+type process struct {
+	pid    int
+	ppid   int
+	uptime int64 // seconds
+}
+
+// gameProcesses returns the processes whose command line references gamePath,
+// along with all their descendants (Proton and wine children included)
+func gameProcesses(psOutput, gamePath string) []process {
+	byPID := make(map[int]process)
+	children := make(map[int][]int)
+	var roots []int
+	for _, line := range strings.Split(psOutput, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) < 4 {
+			continue
+		}
+		pid, err1 := strconv.Atoi(fields[0])
+		ppid, err2 := strconv.Atoi(fields[1])
+		uptime, err3 := strconv.ParseInt(fields[2], 10, 64)
+		if err1 != nil || err2 != nil || err3 != nil {
+			continue
+		}
+		byPID[pid] = process{pid: pid, ppid: ppid, uptime: uptime}
+		children[ppid] = append(children[ppid], pid)
+		if strings.Contains(strings.Join(fields[3:], " "), gamePath+"/") {
+			roots = append(roots, pid)
+		}
+	}
+
+	seen := make(map[int]bool)
+	var procs []process
+	for len(roots) > 0 {
+		pid := roots[0]
+		roots = roots[1:]
+		if seen[pid] {
+			continue
+		}
+		seen[pid] = true
+		procs = append(procs, byPID[pid])
+		roots = append(roots, children[pid]...)
+	}
+	return procs
+}
+
+// runState summarizes the processes of a game by its oldest one
+func runState(procs []process) (running bool, pid int, uptime int64) {
+	for _, p := range procs {
+		if p.uptime >= uptime {
+			pid, uptime = p.pid, p.uptime
+		}
+	}
+	return len(procs) > 0, pid, uptime
+}
+
+func joinPIDs(pids []int) string {
+	parts := make([]string, len(pids))
+	for i, pid := range pids {
+		parts[i] = strconv.Itoa(pid)
+	}
+	return strings.Join(parts, " ")
+}
@@ -0,0 +1,144 @@
+package main
+
+import (
+	"fmt"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/lobinuxsoft/capydeploy/internal/deploy"
+	"github.com/lobinuxsoft/capydeploy/internal/shortcuts"
+	"github.com/lobinuxsoft/capydeploy/pkg/config"
+)
+
+// =============================================================================
+// Installed Game Details
+// =============================================================================
+
+// GameDetails is what the Installed Games detail pane shows about a game
+type GameDetails struct {
+	Name          string   `json:"name"`
+	Path          string   `json:"path"`
+	AppID         uint32   `json:"appId,omitempty"`
+	Executable    string   `json:"executable,omitempty"`
+	LaunchOptions string   `json:"launchOptions,omitempty"`
+	StartDir      string   `json:"startDir,omitempty"`
+	Tags          []string `json:"tags"`
+	Version       string   `json:"version,omitempty"`
+	DeployedAt    string   `json:"deployedAt,omitempty"` // RFC 3339
+	SetupName     string   `json:"setupName,omitempty"`
+	Notes         string   `json:"notes,omitempty"`
+	// Earlier deploys, newest first
+	History []DeployHistoryEntry `json:"history"`
+	// Artwork URLs, shown from the image cache through ProxyImage
+	Capsule string `json:"capsule,omitempty"`
+	Hero    string `json:"hero,omitempty"`
+}
+
+// DeployHistoryEntry is an earlier deploy of an installed game
+type DeployHistoryEntry struct {
+	Version    string `json:"version,omitempty"`
+	DeployedAt string `json:"deployedAt"` // RFC 3339
+	Notes      string `json:"notes,omitempty"`
+}
+
+// GetGameDetails gathers the deploy record, Steam shortcut and artwork of an
+// installed game
+func (a *App) GetGameDetails(gamePath string) (*GameDetails, error) {
+	client, deviceCfg, err := a.connectedClient()
+	if err != nil {
+		return nil, err
+	}
+
+	details := &GameDetails{Name: path.Base(gamePath), Path: gamePath, Tags: []string{}, History: []DeployHistoryEntry{}}
+	game := &InstalledGame{Name: details.Name, Path: gamePath}
+
+	var setup *config.GameSetup
+	var deployed *deploy.ManifestArtwork
+	if manifest, ok := deploy.ReadManifest(client, gamePath); ok {
+		details.AppID = manifest.AppID
+		details.Executable = manifest.Executable
+		details.Version = manifest.Version
+		details.DeployedAt = manifest.DeployedAt.Format(time.RFC3339)
+		details.LaunchOptions = manifest.LaunchOptions
+		details.Notes = manifest.Notes
+		for _, record := range manifest.History {
+			details.History = append(details.History, DeployHistoryEntry{
+				Version:    record.Version,
+				DeployedAt: record.DeployedAt.Format(time.RFC3339),
+				Notes:      record.Notes,
+			})
+		}
+		deployed = manifest.Artwork
+		game.AppID = manifest.AppID
+		setup = findGameSetup(manifest.SetupID)
+	}
+
+	if list, err := shortcuts.ListShortcuts(remoteConfig(client, deviceCfg)); err == nil {
+		if sc := gameShortcut(list, game); sc != nil {
+			details.AppID = uint32(sc.AppID)
+			details.Executable = strings.Trim(sc.Exe, `"`)
+			details.LaunchOptions = sc.LaunchOptions
+			details.StartDir = strings.Trim(sc.StartDir, `"`)
+		}
+	}
+
+	if setup != nil {
+		details.SetupName = setup.Name
+		details.Tags = append(details.Tags, shortcuts.ParseTags(setup.Tags)...)
+		details.Capsule = setup.GridPortrait
+		details.Hero = setup.HeroImage
+	}
+	// Artwork recorded on the device, for games deployed from another hub
+	if details.Capsule == "" && details.Hero == "" && deployed != nil {
+		details.Capsule = deployed.Capsule
+		details.Hero = deployed.Hero
+	}
+	// Artwork picked for the game but not saved in a setup
+	if details.Capsule == "" && details.Hero == "" {
+		if sel, err := config.GetArtworkSelection(details.Name); err == nil && sel != nil {
+			details.Capsule = sel.GridPortrait
+			details.Hero = sel.HeroImage
+		}
+	}
+	return details, nil
+}
+
+// maxNotesLength caps the notes stored in a deploy manifest
+const maxNotesLength = 8000
+
+// SetGameNotes replaces the notes of the current deploy of an installed game,
+// so testers can annotate a build after it was deployed
+func (a *App) SetGameNotes(gamePath, notes string) error {
+	client, _, err := a.connectedClient()
+	if err != nil {
+		return err
+	}
+	if len(notes) > maxNotesLength {
+		return fmt.Errorf("notes are too long (%d characters, at most %d)", len(notes), maxNotesLength)
+	}
+
+	manifest, ok := deploy.ReadManifest(client, gamePath)
+	if !ok {
+		return fmt.Errorf("%s has no deploy manifest, redeploy it to attach notes", path.Base(gamePath))
+	}
+	manifest.Notes = strings.TrimSpace(notes)
+	return deploy.WriteManifest(client, gamePath, *manifest)
+}
+
+// findGameSetup returns the game setup with this ID, or nil
+func findGameSetup(id string) *config.GameSetup {
+	if id == "" {
+		return nil
+	}
+	setups, err := config.GetGameSetups()
+	if err != nil {
+		return nil
+	}
+	for i := range setups {
+		if setups[i].ID == id {
+			return &setups[i]
+		}
+	}
+	return nil
+}
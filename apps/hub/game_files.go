@@ -0,0 +1,148 @@
+package main
+
+import (
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/wailsapp/wails/v2/pkg/runtime"
+
+	"github.com/lobinuxsoft/capydeploy/internal/device"
+)
+
+// =============================================================================
+// Game File Browser
+// =============================================================================
+
+// GameFile is an entry of an installed game's directory
+type GameFile struct {
+	Name    string `json:"name"`
+	Path    string `json:"path"` // relative to the game directory
+	IsDir   bool   `json:"isDir"`
+	Size    int64  `json:"size"`
+	ModTime string `json:"modTime"` // RFC 3339
+}
+
+// ListGameFiles returns the entries of dir, relative to the game directory.
+// Directories come first.
+func (a *App) ListGameFiles(gamePath, dir string) ([]GameFile, error) {
+	client, _, err := a.connectedClient()
+	if err != nil {
+		return nil, err
+	}
+	remoteDir, rel, err := resolveGameFile(client, gamePath, dir)
+	if err != nil {
+		return nil, err
+	}
+
+	entries, err := client.ReadDir(remoteDir)
+	if err != nil {
+		return nil, err
+	}
+
+	files := make([]GameFile, 0, len(entries))
+	for _, entry := range entries {
+		files = append(files, GameFile{
+			Name:    entry.Name(),
+			Path:    path.Join(rel, entry.Name()),
+			IsDir:   entry.IsDir(),
+			Size:    entry.Size(),
+			ModTime: entry.ModTime().UTC().Format(time.RFC3339),
+		})
+	}
+	sort.Slice(files, func(i, j int) bool {
+		if files[i].IsDir != files[j].IsDir {
+			return files[i].IsDir
+		}
+		return strings.ToLower(files[i].Name) < strings.ToLower(files[j].Name)
+	})
+	return files, nil
+}
+
+// DownloadGameFile saves a file of the game to a location chosen by the user.
+// Returns the local path, or "" if the dialog was cancelled.
+func (a *App) DownloadGameFile(gamePath, file string) (string, error) {
+	client, _, err := a.connectedClient()
+	if err != nil {
+		return "", err
+	}
+	remotePath, _, err := resolveGameFile(client, gamePath, file)
+	if err != nil {
+		return "", err
+	}
+
+	localPath, err := runtime.SaveFileDialog(a.ctx, runtime.SaveDialogOptions{
+		Title:           "Save File As",
+		DefaultFilename: path.Base(remotePath),
+	})
+	if err != nil || localPath == "" {
+		return "", err
+	}
+	if err := client.DownloadFile(remotePath, localPath); err != nil {
+		return "", err
+	}
+	return localPath, nil
+}
+
+// ReplaceGameFile overwrites a file of the game with a local file chosen by the
+// user. Returns false if the dialog was cancelled.
+func (a *App) ReplaceGameFile(gamePath, file string) (bool, error) {
+	client, _, err := a.connectedClient()
+	if err != nil {
+		return false, err
+	}
+	remotePath, _, err := resolveGameFile(client, gamePath, file)
+	if err != nil {
+		return false, err
+	}
+
+	localPath, err := runtime.OpenFileDialog(a.ctx, runtime.OpenDialogOptions{
+		Title: fmt.Sprintf("Replace %s", path.Base(remotePath)),
+	})
+	if err != nil || localPath == "" {
+		return false, err
+	}
+	if err := client.UploadFile(localPath, remotePath); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// DeleteGameFile removes a file or directory of the game
+func (a *App) DeleteGameFile(gamePath, file string) error {
+	client, _, err := a.connectedClient()
+	if err != nil {
+		return err
+	}
+	remotePath, rel, err := resolveGameFile(client, gamePath, file)
+	if err != nil {
+		return err
+	}
+	if rel == "" {
+		return fmt.Errorf("use Uninstall to remove the whole game")
+	}
+
+	if _, err := client.RunCommand(fmt.Sprintf("rm -rf -- %s", device.ShellQuote(remotePath))); err != nil {
+		return fmt.Errorf("failed to delete %s: %w", rel, err)
+	}
+	return nil
+}
+
+// resolveGameFile joins a path relative to the game directory, keeping it inside
+// the directory. Returns the remote path and the cleaned relative path.
+func resolveGameFile(client *device.Client, gamePath, rel string) (string, string, error) {
+	homeDir, err := client.GetHomeDir()
+	if err != nil {
+		return "", "", err
+	}
+	gamePath, err = validateGamePath(client, gamePath, homeDir)
+	if err != nil {
+		return "", "", err
+	}
+
+	// Cleaning the path as if rooted drops any leading "..", so it can't escape
+	rel = strings.TrimPrefix(path.Clean("/"+strings.ReplaceAll(rel, "\\", "/")), "/")
+	return path.Join(gamePath, rel), rel, nil
+}
@@ -0,0 +1,225 @@
+package main
+
+import (
+	"fmt"
+	"log/slog"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/lobinuxsoft/capydeploy/internal/deploy"
+	"github.com/lobinuxsoft/capydeploy/internal/device"
+	"github.com/lobinuxsoft/capydeploy/internal/upload"
+)
+
+// =============================================================================
+// Installed Game Integrity
+// =============================================================================
+
+// IntegrityReport lists how the files of an installed game differ from its local build
+type IntegrityReport struct {
+	Name     string   `json:"name"`
+	Path     string   `json:"path"`
+	Checked  int      `json:"checked"`
+	Missing  []string `json:"missing"`
+	Modified []string `json:"modified"`
+	Extra    []string `json:"extra"`
+	// "device" when the files were hashed on the device, "manifest" when the
+	// hashes recorded on deploy were used instead
+	Source string `json:"source"`
+}
+
+// VerifyGame compares the files of an installed game against the local folder
+// of the setup that deployed it
+func (a *App) VerifyGame(gamePath string) (*IntegrityReport, error) {
+	client, _, err := a.connectedClient()
+	if err != nil {
+		return nil, err
+	}
+
+	report, _, err := verifyGame(client, gamePath)
+	return report, err
+}
+
+// RepairGame uploads the missing and modified files of an installed game.
+// Extra files on the device are left alone, they may be saves or configs
+func (a *App) RepairGame(gamePath string) (*IntegrityReport, error) {
+	client, _, err := a.connectedClient()
+	if err != nil {
+		return nil, err
+	}
+
+	report, local, err := verifyGame(client, gamePath)
+	if err != nil {
+		return nil, err
+	}
+	setup, manifest, err := deployedSetup(client, gamePath)
+	if err != nil {
+		return nil, err
+	}
+	if upload.IsArchive(setup.LocalPath) {
+		return nil, fmt.Errorf("%s is deployed from an archive, deploy it again to repair it", setup.Name)
+	}
+
+	filesPath := deploy.ReleaseFilesPath(gamePath, manifest)
+	for _, relPath := range append(append([]string{}, report.Missing...), report.Modified...) {
+		localPath := filepath.Join(setup.LocalPath, filepath.FromSlash(relPath))
+		remotePath := path.Join(filesPath, relPath)
+		client.MkdirAll(path.Dir(remotePath))
+		if manifest.Release != "" {
+			// Earlier releases may share the file, see unlinkPending
+			client.Remove(remotePath)
+		}
+		if err := client.UploadFile(localPath, remotePath); err != nil {
+			return nil, fmt.Errorf("failed to upload %s: %w", relPath, err)
+		}
+		// The file may come from a newer build than its recorded blocks
+		delete(manifest.Blocks, relPath)
+	}
+	slog.Info("Repaired game", "game", report.Name, "missing", len(report.Missing), "modified", len(report.Modified))
+
+	manifest.Files = local
+	if err := deploy.WriteManifest(client, gamePath, *manifest); err != nil {
+		slog.Warn("Failed to update deploy manifest", "error", err)
+	}
+	return report, nil
+}
+
+// verifyGame builds the integrity report of gamePath and returns the local hashes it used
+func verifyGame(client *device.Client, gamePath string) (*IntegrityReport, map[string]string, error) {
+	setup, manifest, err := deployedSetup(client, gamePath)
+	if err != nil {
+		return nil, nil, err
+	}
+	if _, err := os.Stat(setup.LocalPath); err != nil {
+		return nil, nil, fmt.Errorf("local folder of %s is not available: %w", setup.Name, err)
+	}
+
+	local, err := localFileHashes(setup.LocalPath, setup.Exclude)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to hash local files: %w", err)
+	}
+
+	report := &IntegrityReport{Name: setup.Name, Path: gamePath, Checked: len(local), Source: "device"}
+	filesPath := deploy.ReleaseFilesPath(gamePath, manifest)
+	remote, err := remoteFileHashes(client, filesPath)
+	if err != nil {
+		// Devices without sha256sum still have the hashes of the last deploy
+		slog.Warn("Failed to hash files on device, using deploy manifest", "error", err)
+		remote, err = manifestFileHashes(client, filesPath, manifest, setup.LocalPath)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to list remote files: %w", err)
+		}
+		report.Source = "manifest"
+	}
+
+	report.Missing, report.Modified, report.Extra = compareFileHashes(local, remote)
+	return report, local, nil
+}
+
+// localFileHashes hashes every deployed file under root, or in the game archive
+// root names, keyed by slash separated relative path
+func localFileHashes(root string, exclude []string) (map[string]string, error) {
+	if upload.IsArchive(root) {
+		entries, err := upload.ReadArchive(root)
+		if err != nil {
+			return nil, err
+		}
+		hashes := make(map[string]string, len(entries))
+		for _, entry := range entries {
+			hashes[entry.RelPath] = entry.Hash
+		}
+		return hashes, nil
+	}
+
+	files, err := upload.ListFiles(root, exclude)
+	if err != nil {
+		return nil, err
+	}
+
+	hashes := make(map[string]string, len(files))
+	for _, file := range files {
+		// Recreated symlinks aren't files on the device
+		if _, ok := upload.LinkTarget(root, file); ok {
+			continue
+		}
+		relPath, _ := filepath.Rel(root, file)
+		hash, err := upload.HashFile(file)
+		if err != nil {
+			return nil, err
+		}
+		hashes[filepath.ToSlash(relPath)] = hash
+	}
+	return hashes, nil
+}
+
+// remoteFileHashes hashes every file of a game directory on the device
+func remoteFileHashes(client *device.Client, gamePath string) (map[string]string, error) {
+	cmd := fmt.Sprintf("cd %s && find . -type f ! -name %s ! -name %s -exec sha256sum {} +",
+		device.ShellQuote(gamePath), device.ShellQuote(deploy.ManifestName), device.ShellQuote(deploy.LegacyManifestName))
+	output, err := client.RunCommand(cmd)
+	if err != nil {
+		return nil, err
+	}
+
+	hashes := make(map[string]string)
+	for _, line := range strings.Split(output, "\n") {
+		// "<hash>  ./<path>"; names sha256sum had to escape start with a backslash
+		hash, name, ok := strings.Cut(line, "  ")
+		if !ok || len(hash) != 64 {
+			continue
+		}
+		hashes[strings.TrimPrefix(name, "./")] = hash
+	}
+	return hashes, nil
+}
+
+// manifestFileHashes trusts the hashes recorded on deploy for files still on the
+// device with the size of the local copy. Files that can't be vouched for get an
+// empty hash so they are reported as modified
+func manifestFileHashes(client *device.Client, gamePath string, manifest *deploy.Manifest, localRoot string) (map[string]string, error) {
+	index, err := upload.RemoteIndex(client, gamePath)
+	if err != nil {
+		return nil, err
+	}
+
+	hashes := make(map[string]string, len(index))
+	for relPath, remote := range index {
+		if relPath == deploy.ManifestName || relPath == deploy.LegacyManifestName {
+			continue
+		}
+		info, err := os.Stat(filepath.Join(localRoot, filepath.FromSlash(relPath)))
+		if err == nil && info.Size() == remote.Size {
+			hashes[relPath] = manifest.Files[relPath]
+		} else {
+			hashes[relPath] = ""
+		}
+	}
+	return hashes, nil
+}
+
+// compareFileHashes returns the sorted paths missing from, different on, and only
+// present on the remote side
+func compareFileHashes(local, remote map[string]string) (missing, modified, extra []string) {
+	missing, modified, extra = []string{}, []string{}, []string{}
+	for relPath, hash := range local {
+		remoteHash, ok := remote[relPath]
+		switch {
+		case !ok:
+			missing = append(missing, relPath)
+		case remoteHash != hash:
+			modified = append(modified, relPath)
+		}
+	}
+	for relPath := range remote {
+		if _, ok := local[relPath]; !ok {
+			extra = append(extra, relPath)
+		}
+	}
+	sort.Strings(missing)
+	sort.Strings(modified)
+	sort.Strings(extra)
+	return missing, modified, extra
+}
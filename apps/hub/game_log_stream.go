@@ -0,0 +1,201 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/wailsapp/wails/v2/pkg/runtime"
+
+	"github.com/lobinuxsoft/capydeploy/internal/deploy"
+	"github.com/lobinuxsoft/capydeploy/internal/device"
+)
+
+// =============================================================================
+// Live Game Log
+// =============================================================================
+
+// logStreamBacklog is how many lines of each log the live view starts with
+const logStreamBacklog = 200
+
+// maxLogLineLength splits runaway lines, such as binary output, in the live view
+const maxLogLineLength = 4096
+
+// journalPrefix tags journal lines, which interleave with the tailed files
+const journalPrefix = "[journal] "
+
+// GameLogLine is a line of the live log of a game
+type GameLogLine struct {
+	Source string `json:"source"` // "output", "proton" or "journal"
+	Text   string `json:"text"`
+}
+
+// StartGameLogStream follows the logs of an installed game as it runs: the
+// output captured by the log wrapper, the Proton log (PROTON_LOG=1) and the
+// journal entries of its process, such as crashes. Lines are emitted as
+// "gamelog:lines" events; "gamelog:stopped" reports a stream that ended
+// without StopGameLogStream.
+func (a *App) StartGameLogStream(gamePath string) error {
+	client, _, err := a.connectedClient()
+	if err != nil {
+		return err
+	}
+	homeDir, err := client.GetHomeDir()
+	if err != nil {
+		return err
+	}
+	gamePath, err = validateGamePath(client, gamePath, homeDir)
+	if err != nil {
+		return err
+	}
+
+	sources := map[string]string{deploy.GameLogPath(homeDir, path.Base(gamePath)): "output"}
+	var process string
+	if manifest, ok := deploy.ReadManifest(client, gamePath); ok {
+		if manifest.LogFile != "" {
+			sources = map[string]string{manifest.LogFile: "output"}
+		}
+		if manifest.AppID != 0 {
+			// Proton names the log after SteamGameId, which is the 64-bit game ID
+			// for non-Steam shortcuts
+			sources[path.Join(homeDir, fmt.Sprintf("steam-%d.log", manifest.AppID))] = "proton"
+			sources[path.Join(homeDir, fmt.Sprintf("steam-%d.log", uint64(manifest.AppID)<<32|0x02000000))] = "proton"
+		}
+		process = processName(manifest.Executable)
+	}
+
+	a.StopGameLogStream()
+	ctx, cancel := context.WithCancel(a.ctx)
+	a.mu.Lock()
+	a.cancelLogStream = cancel
+	a.mu.Unlock()
+
+	go a.streamGameLog(ctx, client, sources, process)
+	return nil
+}
+
+// StopGameLogStream stops following the logs of a game
+func (a *App) StopGameLogStream() {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if a.cancelLogStream != nil {
+		a.cancelLogStream()
+		a.cancelLogStream = nil
+	}
+}
+
+// SaveGameLog writes the lines shown in the live log to a location chosen by
+// the user. Returns the saved path, or "" if the dialog was cancelled.
+func (a *App) SaveGameLog(gameName, content string) (string, error) {
+	localPath, err := runtime.SaveFileDialog(a.ctx, runtime.SaveDialogOptions{
+		Title:           "Save Game Log",
+		DefaultFilename: fmt.Sprintf("%s-%s.log", sanitizeFilename(gameName), time.Now().Format("2006-01-02-150405")),
+		Filters:         []runtime.FileFilter{{DisplayName: "Log files", Pattern: "*.log;*.txt"}},
+	})
+	if err != nil || localPath == "" {
+		return "", err
+	}
+	if err := os.WriteFile(localPath, []byte(content), 0644); err != nil {
+		return "", fmt.Errorf("failed to write %s: %w", localPath, err)
+	}
+	return localPath, nil
+}
+
+func (a *App) streamGameLog(ctx context.Context, client *device.Client, sources map[string]string, process string) {
+	files := make([]string, 0, len(sources))
+	for file := range sources {
+		files = append(files, device.ShellQuote(file))
+	}
+	// tail -F waits for logs that don't exist yet; the journal is filtered by
+	// the process name, which is how crashes and core dumps name the game.
+	// Closing stdin ends both.
+	cmd := fmt.Sprintf("tail -n %d -F -- %s 2>/dev/null &\n", logStreamBacklog, strings.Join(files, " "))
+	if process != "" {
+		cmd += fmt.Sprintf("journalctl -f -n %d -o short-iso 2>/dev/null | grep --line-buffered -F -e %s | sed -u 's/^/%s/' &\n",
+			logStreamBacklog, device.ShellQuote(process), journalPrefix)
+	}
+	cmd += "read _\nkill 0"
+
+	w := &logStreamWriter{
+		sources: sources,
+		emit: func(lines []GameLogLine) {
+			runtime.EventsEmit(a.ctx, "gamelog:lines", lines)
+		},
+	}
+	err := client.StreamCommandContext(ctx, cmd, w)
+	if ctx.Err() != nil {
+		// Stopped from the hub
+		return
+	}
+	reason := "the log stream ended"
+	if err != nil {
+		slog.Warn("Game log stream failed", "error", err)
+		reason = err.Error()
+	}
+	runtime.EventsEmit(a.ctx, "gamelog:stopped", reason)
+}
+
+// logStreamWriter splits the output of the log stream into lines tagged with
+// the log they come from. tail announces each file with a "==> file <==" header.
+type logStreamWriter struct {
+	sources map[string]string
+	emit    func([]GameLogLine)
+	current string
+	partial []byte
+}
+
+func (w *logStreamWriter) Write(p []byte) (int, error) {
+	w.partial = append(w.partial, p...)
+	var lines []GameLogLine
+split:
+	for len(w.partial) > 0 {
+		end := bytes.IndexByte(w.partial, '\n')
+		next := end + 1
+		switch {
+		case end >= 0:
+		case len(w.partial) > maxLogLineLength:
+			// No line end in sight, let the line through in pieces
+			end, next = maxLogLineLength, maxLogLineLength
+		default:
+			break split
+		}
+		line := strings.TrimRight(string(w.partial[:end]), "\r")
+		w.partial = w.partial[next:]
+
+		if file, ok := strings.CutPrefix(line, "==> "); ok && strings.HasSuffix(file, " <==") {
+			w.current = w.sources[strings.TrimSuffix(file, " <==")]
+			continue
+		}
+		// tail separates the files with blank lines
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		source := w.current
+		if text, ok := strings.CutPrefix(line, journalPrefix); ok {
+			source, line = "journal", text
+		}
+		lines = append(lines, GameLogLine{Source: source, Text: strings.ToValidUTF8(line, "?")})
+	}
+	if len(lines) > 0 {
+		w.emit(lines)
+	}
+	return len(p), nil
+}
+
+// processName returns the name the kernel gives the process of an executable,
+// its file name cut to 15 bytes
+func processName(executable string) string {
+	name := path.Base(strings.ReplaceAll(executable, "\\", "/"))
+	if name == "." || name == "/" {
+		return ""
+	}
+	if len(name) > 15 {
+		name = name[:15]
+	}
+	return name
+}
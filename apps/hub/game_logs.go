@@ -0,0 +1,49 @@
+package main
+
+import (
+	"fmt"
+	"path"
+
+	"github.com/lobinuxsoft/capydeploy/internal/deploy"
+	"github.com/lobinuxsoft/capydeploy/internal/device"
+)
+
+// =============================================================================
+// Game Output Logs
+// =============================================================================
+
+// maxLogLines caps how much of a log GetGameLog returns
+const maxLogLines = 5000
+
+// GetGameLog returns the last lines of the output log of an installed game.
+// previous selects the log of the run before the last one.
+func (a *App) GetGameLog(gamePath string, lines int, previous bool) (string, error) {
+	client, _, err := a.connectedClient()
+	if err != nil {
+		return "", err
+	}
+	homeDir, err := client.GetHomeDir()
+	if err != nil {
+		return "", err
+	}
+	gamePath, err = validateGamePath(client, gamePath, homeDir)
+	if err != nil {
+		return "", err
+	}
+
+	logPath := deploy.GameLogPath(homeDir, path.Base(gamePath))
+	if manifest, ok := deploy.ReadManifest(client, gamePath); ok && manifest.LogFile != "" {
+		logPath = manifest.LogFile
+	}
+	if previous {
+		logPath += ".1"
+	}
+	if !client.FileExists(logPath) {
+		return "", fmt.Errorf("no log yet at %s, enable Capture Logs in the game setup, deploy and launch the game", logPath)
+	}
+
+	if lines <= 0 || lines > maxLogLines {
+		lines = maxLogLines
+	}
+	return client.RunCommand(fmt.Sprintf("tail -n %d -- %s", lines, device.ShellQuote(logPath)))
+}
@@ -0,0 +1,131 @@
+package main
+
+import (
+	"fmt"
+	"log/slog"
+	"path"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/lobinuxsoft/capydeploy/internal/deploy"
+	"github.com/lobinuxsoft/capydeploy/internal/device"
+	"github.com/lobinuxsoft/capydeploy/internal/shortcuts"
+	"github.com/lobinuxsoft/capydeploy/pkg/config"
+)
+
+// =============================================================================
+// Build Releases
+// =============================================================================
+
+// GameRelease is a build kept on the device that a game can roll back to
+type GameRelease struct {
+	Name       string `json:"name"`
+	Version    string `json:"version,omitempty"`
+	DeployedAt string `json:"deployedAt,omitempty"` // RFC 3339
+	Notes      string `json:"notes,omitempty"`
+	Current    bool   `json:"current"`
+}
+
+// validateKeepReleases checks the number of builds a setup keeps on the device
+func validateKeepReleases(keep int) error {
+	if keep < 0 || keep > config.MaxKeepReleases {
+		return fmt.Errorf("builds kept on the device must be between 0 and %d", config.MaxKeepReleases)
+	}
+	return nil
+}
+
+// GetGameReleases returns the builds of an installed game kept on the device,
+// newest first. Games deployed in place have none.
+func (a *App) GetGameReleases(gamePath string) ([]GameRelease, error) {
+	client, _, err := a.connectedClient()
+	if err != nil {
+		return nil, err
+	}
+	return listReleases(client, gamePath)
+}
+
+// listReleases reads the manifest of each release of a game
+func listReleases(client *device.Client, gamePath string) ([]GameRelease, error) {
+	output, err := client.RunCommand(fmt.Sprintf("cd %s 2>/dev/null || exit 0; echo \"$(readlink %s)\"; ls -1 %s 2>/dev/null",
+		device.ShellQuote(gamePath), deploy.CurrentReleaseName, deploy.ReleasesDirName))
+	if err != nil {
+		return nil, fmt.Errorf("failed to list releases: %w", err)
+	}
+	lines := strings.Split(strings.TrimSpace(output), "\n")
+	current := path.Base(strings.TrimSpace(lines[0]))
+
+	releases := []GameRelease{}
+	for _, name := range lines[1:] {
+		name = strings.TrimSpace(name)
+		if _, err := time.Parse(deploy.ReleaseTimeFormat, name); err != nil {
+			continue
+		}
+		release := GameRelease{Name: name, Current: name == current}
+		if manifest, ok := deploy.ReadManifest(client, path.Join(gamePath, deploy.ReleasesDirName, name)); ok {
+			release.Version = manifest.Version
+			release.DeployedAt = manifest.DeployedAt.Format(time.RFC3339)
+			release.Notes = manifest.Notes
+		}
+		releases = append(releases, release)
+	}
+	slices.Reverse(releases)
+	return releases, nil
+}
+
+// RollbackGame makes an earlier release of an installed game the current one.
+// The shortcut launches through the current symlink, so it only changes when
+// the release has another executable.
+func (a *App) RollbackGame(gamePath, release string) error {
+	client, deviceCfg, err := a.connectedClient()
+	if err != nil {
+		return err
+	}
+	homeDir, err := client.GetHomeDir()
+	if err != nil {
+		return err
+	}
+	if gamePath, err = validateGamePath(client, gamePath, homeDir); err != nil {
+		return err
+	}
+
+	releases, err := listReleases(client, gamePath)
+	if err != nil {
+		return err
+	}
+	i := slices.IndexFunc(releases, func(r GameRelease) bool { return r.Name == release })
+	if i < 0 {
+		return fmt.Errorf("release %s of %s not found", release, path.Base(gamePath))
+	}
+	if releases[i].Current {
+		return fmt.Errorf("release %s is already the current one", release)
+	}
+
+	previous, _ := deploy.ReadManifest(client, gamePath)
+	cmd := fmt.Sprintf("cd %s && ln -sfn %s %s.tmp && mv -T %s.tmp %s",
+		device.ShellQuote(gamePath), device.ShellQuote(path.Join(deploy.ReleasesDirName, release)), deploy.CurrentReleaseName, deploy.CurrentReleaseName, deploy.CurrentReleaseName)
+	if _, err := client.RunCommand(cmd); err != nil {
+		return fmt.Errorf("failed to roll back: %w", err)
+	}
+	slog.Info("Rolled back game", "game", path.Base(gamePath), "release", release, "version", releases[i].Version)
+
+	manifest, ok := deploy.ReadManifest(client, gamePath)
+	if !ok || previous == nil || manifest.Executable == previous.Executable {
+		return nil
+	}
+	remoteCfg := remoteConfig(client, deviceCfg)
+	list, err := shortcuts.ListShortcuts(remoteCfg)
+	if err != nil {
+		return fmt.Errorf("failed to list shortcuts: %w", err)
+	}
+	sc := gameShortcut(list, &InstalledGame{Name: path.Base(gamePath), Path: gamePath, AppID: previous.AppID})
+	if sc == nil {
+		return nil
+	}
+	currentPath := path.Join(gamePath, deploy.CurrentReleaseName)
+	if err := shortcuts.UpdateShortcut(remoteCfg, sc.AppID, path.Join(currentPath, manifest.Executable), currentPath, sc.LaunchOptions); err != nil {
+		return fmt.Errorf("failed to update shortcut: %w", err)
+	}
+	shortcuts.RefreshSteamLibrary(remoteCfg)
+	return nil
+}
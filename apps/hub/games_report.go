@@ -0,0 +1,101 @@
+package main
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/wailsapp/wails/v2/pkg/runtime"
+)
+
+// =============================================================================
+// Installed Games Report
+// =============================================================================
+
+// gamesReportEntry is one installed game in an exported report
+type gamesReportEntry struct {
+	Device     string `json:"device"`
+	Host       string `json:"host"`
+	Game       string `json:"game"`
+	Version    string `json:"version"`
+	Size       int64  `json:"size"` // bytes
+	DeployedAt string `json:"deployed_at"`
+	AppID      uint32 `json:"app_id"`
+	Path       string `json:"path"`
+}
+
+// ExportInstalledGames writes the games in remotePath on the connected device to
+// a CSV or JSON file chosen by the user. Returns the saved path, or "" if the
+// dialog was cancelled.
+func (a *App) ExportInstalledGames(remotePath, format string) (string, error) {
+	if format != "csv" && format != "json" {
+		return "", fmt.Errorf("unsupported report format: %s", format)
+	}
+
+	_, deviceCfg, err := a.connectedClient()
+	if err != nil {
+		return "", err
+	}
+	games, err := a.GetInstalledGames(remotePath)
+	if err != nil {
+		return "", err
+	}
+
+	localPath, err := runtime.SaveFileDialog(a.ctx, runtime.SaveDialogOptions{
+		Title:           "Export Installed Games",
+		DefaultFilename: fmt.Sprintf("games-%s-%s.%s", sanitizeFilename(deviceCfg.Name), time.Now().Format("2006-01-02"), format),
+		Filters:         []runtime.FileFilter{{DisplayName: format + " files", Pattern: "*." + format}},
+	})
+	if err != nil || localPath == "" {
+		return "", err
+	}
+
+	entries := make([]gamesReportEntry, len(games))
+	for i, game := range games {
+		entries[i] = gamesReportEntry{
+			Device:     game.Device,
+			Host:       deviceCfg.Host,
+			Game:       game.Name,
+			Version:    game.Version,
+			Size:       game.Size,
+			DeployedAt: game.DeployedAt,
+			AppID:      game.AppID,
+			Path:       game.Path,
+		}
+	}
+
+	f, err := os.Create(localPath)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	if err := writeGamesReport(f, format, entries); err != nil {
+		return "", fmt.Errorf("failed to write report: %w", err)
+	}
+	return localPath, nil
+}
+
+func writeGamesReport(w io.Writer, format string, entries []gamesReportEntry) error {
+	if format == "json" {
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(entries)
+	}
+
+	cw := csv.NewWriter(w)
+	cw.Write([]string{"device", "host", "game", "version", "size", "deployed_at", "app_id", "path"})
+	for _, e := range entries {
+		cw.Write([]string{
+			e.Device, e.Host, e.Game, e.Version,
+			strconv.FormatInt(e.Size, 10), e.DeployedAt,
+			strconv.FormatUint(uint64(e.AppID), 10), e.Path,
+		})
+	}
+	cw.Flush()
+	return cw.Error()
+}
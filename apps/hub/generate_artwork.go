@@ -0,0 +1,104 @@
+package main
+
+import (
+	"encoding/base64"
+	"fmt"
+	"strings"
+
+	"github.com/lobinuxsoft/capydeploy/internal/shortcuts"
+	"github.com/lobinuxsoft/capydeploy/pkg/artwork"
+)
+
+// =============================================================================
+// Generated Artwork
+// =============================================================================
+
+// maxGeneratedSize bounds the PNG accepted from the frontend generator
+const maxGeneratedSize = 20 * 1024 * 1024
+
+// SaveGeneratedArtwork stores a PNG rendered by the frontend from a screenshot
+// and returns the URL to use in the artwork selection.
+// slot is one of capsule, wide or hero; dataURL must be a base64 PNG data URL.
+func (a *App) SaveGeneratedArtwork(slot, dataURL string) (string, error) {
+	if a.generated == nil {
+		return "", fmt.Errorf("generated artwork storage unavailable")
+	}
+
+	const prefix = "data:image/png;base64,"
+	if !strings.HasPrefix(dataURL, prefix) {
+		return "", fmt.Errorf("expected a PNG data URL")
+	}
+	encoded := strings.TrimPrefix(dataURL, prefix)
+	if base64.StdEncoding.DecodedLen(len(encoded)) > maxGeneratedSize {
+		return "", fmt.Errorf("generated image too large")
+	}
+
+	data, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return "", fmt.Errorf("invalid image data: %w", err)
+	}
+	return a.generated.Save(slot, data)
+}
+
+// localImage returns an image without network access: generated artwork from
+// its store, anything else from the disk cache
+func (a *App) localImage(imageURL string) ([]byte, string, error) {
+	if artwork.IsGenerated(imageURL) {
+		if a.generated == nil {
+			return nil, "", fmt.Errorf("generated artwork storage unavailable")
+		}
+		data, err := a.generated.Get(imageURL)
+		if err != nil {
+			return nil, "", err
+		}
+		return data, "image/png", nil
+	}
+
+	if a.imageCache == nil {
+		return nil, "", fmt.Errorf("image cache unavailable")
+	}
+	data, contentType, ok := a.imageCache.Get(imageURL)
+	if !ok {
+		return nil, "", fmt.Errorf("image not cached: %s", imageURL)
+	}
+	return data, contentType, nil
+}
+
+// splitLocalArtwork separates the images the device can download from the ones
+// that must be copied from this machine. With all set every image is local.
+// Either result is nil when it has no images.
+func splitLocalArtwork(art *shortcuts.ArtworkConfig, all bool) (remote, local *shortcuts.ArtworkConfig) {
+	if art == nil {
+		return nil, nil
+	}
+	if all {
+		return nil, art
+	}
+
+	remote, local = &shortcuts.ArtworkConfig{}, &shortcuts.ArtworkConfig{}
+	split := func(url string, remoteSlot, localSlot *string) {
+		if artwork.IsGenerated(url) {
+			*localSlot = url
+		} else {
+			*remoteSlot = url
+		}
+	}
+	split(art.GridPortrait, &remote.GridPortrait, &local.GridPortrait)
+	split(art.GridLandscape, &remote.GridLandscape, &local.GridLandscape)
+	split(art.HeroImage, &remote.HeroImage, &local.HeroImage)
+	split(art.LogoImage, &remote.LogoImage, &local.LogoImage)
+	split(art.IconImage, &remote.IconImage, &local.IconImage)
+
+	if isEmptyArtwork(remote) {
+		remote = nil
+	}
+	if isEmptyArtwork(local) {
+		local = nil
+	}
+	return remote, local
+}
+
+func isEmptyArtwork(art *shortcuts.ArtworkConfig) bool {
+	return art.GridPortrait == "" && art.GridLandscape == "" && art.HeroImage == "" &&
+		art.LogoImage == "" && art.IconImage == ""
+}
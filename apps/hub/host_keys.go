@@ -0,0 +1,102 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+
+	"golang.org/x/crypto/ssh"
+
+	"github.com/lobinuxsoft/capydeploy/internal/device"
+	"github.com/lobinuxsoft/capydeploy/pkg/config"
+)
+
+// =============================================================================
+// Host Keys
+// =============================================================================
+
+// HostKeyPrompt is the host key of a device waiting for the user to confirm
+// its fingerprint before the hub connects to it
+type HostKeyPrompt struct {
+	Host        string `json:"host"`
+	KeyType     string `json:"keyType"`
+	Fingerprint string `json:"fingerprint"` // SHA256:<base64>, like ssh-keygen -lf
+	// Set when another key was trusted for the device, which is expected
+	// after reinstalling it and suspicious otherwise
+	Changed bool `json:"changed"`
+}
+
+// pendingHostKey is a host key a connection was refused for
+type pendingHostKey struct {
+	addr    string // host:port dialed
+	key     ssh.PublicKey
+	changed bool
+}
+
+// recordHostKey keeps the host key a connection to a device was refused for,
+// so the user can be asked to trust it
+func (a *App) recordHostKey(host string, err error) {
+	var pending pendingHostKey
+	var unknown *device.UnknownHostKeyError
+	var changed *device.HostKeyChangedError
+	switch {
+	case errors.As(err, &unknown):
+		pending = pendingHostKey{addr: unknown.Host, key: unknown.Key}
+	case errors.As(err, &changed):
+		pending = pendingHostKey{addr: changed.Host, key: changed.Key, changed: true}
+		slog.Warn("Device host key changed", "host", host, "fingerprint", ssh.FingerprintSHA256(changed.Key))
+	default:
+		return
+	}
+	a.mu.Lock()
+	if a.hostKeys == nil {
+		a.hostKeys = make(map[string]pendingHostKey)
+	}
+	a.hostKeys[host] = pending
+	a.mu.Unlock()
+}
+
+// GetPendingHostKey returns the host key of a device that the last connection
+// refused, nil when none is waiting
+func (a *App) GetPendingHostKey(host string) *HostKeyPrompt {
+	a.mu.RLock()
+	pending, ok := a.hostKeys[host]
+	a.mu.RUnlock()
+	if !ok {
+		return nil
+	}
+	return &HostKeyPrompt{
+		Host:        host,
+		KeyType:     pending.key.Type(),
+		Fingerprint: ssh.FingerprintSHA256(pending.key),
+		Changed:     pending.changed,
+	}
+}
+
+// TrustHostKey adds the pending host key of a device to the known hosts,
+// replacing the one trusted before. The fingerprint must be the one the user
+// was shown, so a key that changed in between isn't trusted blindly.
+func (a *App) TrustHostKey(host, fingerprint string) error {
+	a.mu.RLock()
+	pending, ok := a.hostKeys[host]
+	a.mu.RUnlock()
+	if !ok {
+		return fmt.Errorf("no host key of %s is waiting to be trusted", host)
+	}
+	if ssh.FingerprintSHA256(pending.key) != fingerprint {
+		return fmt.Errorf("the host key of %s is not the one shown, connect again to check it", host)
+	}
+
+	path, err := config.KnownHostsPath()
+	if err != nil {
+		return err
+	}
+	if err := device.TrustHostKey(path, pending.addr, pending.key); err != nil {
+		return err
+	}
+	a.mu.Lock()
+	delete(a.hostKeys, host)
+	a.mu.Unlock()
+	slog.Info("Trusted device host key", "host", host, "fingerprint", fingerprint, "replaced", pending.changed)
+	return nil
+}
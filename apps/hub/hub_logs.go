@@ -0,0 +1,66 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+
+	"github.com/wailsapp/wails/v2/pkg/runtime"
+
+	"github.com/lobinuxsoft/capydeploy/pkg/config"
+	"github.com/lobinuxsoft/capydeploy/pkg/logging"
+)
+
+// =============================================================================
+// Hub Logs
+// =============================================================================
+
+// initLogging sends every log record to a rotating file in the config
+// directory, and keeps the latest in memory for the Logs tab
+func (a *App) initLogging() {
+	a.logs = logging.NewRecent(logging.DefaultRecent)
+
+	var out io.Writer = os.Stderr
+	if dir, err := logDir(); err == nil {
+		file, err := logging.OpenRotatingFile(filepath.Join(dir, "hub.log"), logging.DefaultMaxBytes, logging.DefaultBackups)
+		if err == nil {
+			a.logFile = file
+			out = io.MultiWriter(os.Stderr, file)
+		} else {
+			fmt.Fprintf(os.Stderr, "failed to open log file: %v\n", err)
+		}
+	}
+
+	slog.SetDefault(slog.New(logging.NewHandler(out, slog.LevelDebug, a.logs)))
+	slog.Info("CapyDeploy Hub started")
+}
+
+// logDir returns the directory the hub log files are written to
+func logDir() (string, error) {
+	configPath, err := config.GetConfigPath()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(filepath.Dir(configPath), "logs"), nil
+}
+
+// GetHubLogs returns the recent hub log entries at or above level
+// ("debug", "info", "warn" or "error")
+func (a *App) GetHubLogs(level string) []logging.Entry {
+	if a.logs == nil {
+		return []logging.Entry{}
+	}
+	return a.logs.Entries(logging.ParseLevel(level))
+}
+
+// OpenLogFolder opens the folder holding the hub log files
+func (a *App) OpenLogFolder() error {
+	dir, err := logDir()
+	if err != nil {
+		return err
+	}
+	runtime.BrowserOpenURL(a.ctx, "file://"+dir)
+	return nil
+}
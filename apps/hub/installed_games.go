@@ -0,0 +1,137 @@
+package main
+
+import (
+	"fmt"
+	"log/slog"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/lobinuxsoft/capydeploy/internal/deploy"
+	"github.com/lobinuxsoft/capydeploy/internal/device"
+	"github.com/lobinuxsoft/capydeploy/internal/shortcuts"
+)
+
+// =============================================================================
+// Installed Games Inventory
+// =============================================================================
+
+// InstalledGame represents a game installed on the remote device
+type InstalledGame struct {
+	Name      string `json:"name"`
+	Path      string `json:"path"`
+	Size      int64  `json:"size"` // bytes
+	FileCount int    `json:"fileCount"`
+	// RFC 3339 time of the last deploy, or of the last change to the directory
+	// for games deployed without a manifest
+	DeployedAt  string `json:"deployedAt"`
+	HasShortcut bool   `json:"hasShortcut"`
+	Running     bool   `json:"running"`
+	PID         int    `json:"pid,omitempty"`    // oldest process when running
+	Uptime      int64  `json:"uptime,omitempty"` // seconds
+	AppID       uint32 `json:"appId,omitempty"`
+	SetupID     string `json:"setupId,omitempty"`
+	Version     string `json:"version,omitempty"`
+	Device      string `json:"device"` // name of the device the game is on
+}
+
+// GetInstalledGames returns the games in remotePath on the connected device with
+// their size, file count, deploy time, Steam shortcut and whether they are running
+func (a *App) GetInstalledGames(remotePath string) ([]InstalledGame, error) {
+	client, deviceCfg, err := a.connectedClient()
+	if err != nil {
+		return nil, err
+	}
+
+	remotePath, err = deploy.ExpandRemotePath(client, remotePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to expand path: %w", err)
+	}
+
+	// One command for the whole directory, every SSH round trip is slow
+	cmd := fmt.Sprintf(`cd %s 2>/dev/null || exit 0
+for d in */; do
+	d="${d%%/}"
+	[ -d "$d" ] || continue
+	printf '%%s\t%%s\t%%s\t%%s\n' "$d" "$(du -sb -- "$d" 2>/dev/null | cut -f1)" "$(find "$d" -type f ! -name %s ! -name %s 2>/dev/null | wc -l)" "$(stat -c %%Y -- "$d" 2>/dev/null)"
+done`, device.ShellQuote(remotePath), device.ShellQuote(deploy.ManifestName), device.ShellQuote(deploy.LegacyManifestName))
+	output, err := client.RunCommand(cmd)
+	if err != nil {
+		return nil, fmt.Errorf("failed to scan games: %w", err)
+	}
+
+	games := parseInventory(output, remotePath)
+
+	list, err := shortcuts.ListShortcuts(remoteConfig(client, deviceCfg))
+	if err != nil {
+		slog.Warn("Failed to list shortcuts", "error", err)
+	}
+	processes, err := client.RunCommand(processListCmd)
+	if err != nil {
+		slog.Warn("Failed to list processes", "error", err)
+	}
+
+	for i := range games {
+		game := &games[i]
+		game.Device = deviceCfg.Name
+		game.Running, game.PID, game.Uptime = runState(gameProcesses(processes, game.Path))
+		if manifest, ok := deploy.ReadManifest(client, game.Path); ok {
+			game.SetupID = manifest.SetupID
+			game.AppID = manifest.AppID
+			game.Version = manifest.Version
+			game.DeployedAt = manifest.DeployedAt.Format(time.RFC3339)
+		}
+		if sc := gameShortcut(list, game); sc != nil {
+			game.HasShortcut = true
+			game.AppID = uint32(sc.AppID)
+		}
+	}
+
+	sort.Slice(games, func(i, j int) bool {
+		return strings.ToLower(games[i].Name) < strings.ToLower(games[j].Name)
+	})
+	return games, nil
+}
+
+// parseInventory parses the tab separated name, size, file count and mtime lines of the scan
+func parseInventory(output, remotePath string) []InstalledGame {
+	games := []InstalledGame{}
+	for _, line := range strings.Split(output, "\n") {
+		fields := strings.Split(strings.TrimRight(line, "\r"), "\t")
+		if len(fields) != 4 || fields[0] == "" {
+			continue
+		}
+
+		game := InstalledGame{Name: fields[0], Path: path.Join(remotePath, fields[0])}
+		game.Size, _ = strconv.ParseInt(strings.TrimSpace(fields[1]), 10, 64)
+		game.FileCount, _ = strconv.Atoi(strings.TrimSpace(fields[2]))
+		if mtime, err := strconv.ParseInt(strings.TrimSpace(fields[3]), 10, 64); err == nil {
+			game.DeployedAt = time.Unix(mtime, 0).UTC().Format(time.RFC3339)
+		}
+		games = append(games, game)
+	}
+	return games
+}
+
+// gameShortcut returns the shortcut that launches the game, matched by app ID,
+// start directory or name
+func gameShortcut(list []shortcuts.ShortcutInfo, game *InstalledGame) *shortcuts.ShortcutInfo {
+	if game.AppID != 0 {
+		for i := range list {
+			if uint32(list[i].AppID) == game.AppID {
+				return &list[i]
+			}
+		}
+	}
+	// Extra shortcuts of the game start in its folder too, so the app ID is
+	// looked for first
+	for i := range list {
+		sc := &list[i]
+		if strings.Trim(sc.StartDir, `"`) == game.Path || sc.Name == game.Name {
+			return sc
+		}
+	}
+	return nil
+}
@@ -14,6 +14,9 @@ import (
 //go:embed all:frontend/dist
 var assets embed.FS
 
+// Version is set at build time.
+var Version = "dev"
+
 func main() {
 	app := NewApp()
 
@@ -29,6 +32,12 @@ func main() {
 		BackgroundColour: &options.RGBA{R: 27, G: 38, B: 54, A: 1},
 		OnStartup:        app.startup,
 		OnShutdown:       app.shutdown,
+		OnBeforeClose:    app.beforeClose,
+		// Launching the Hub again brings back the one running in the tray
+		SingleInstanceLock: &options.SingleInstanceLock{
+			UniqueId:               "com.lobinuxsoft.capydeploy.hub",
+			OnSecondInstanceLaunch: app.onSecondInstance,
+		},
 		Bind: []interface{}{
 			app,
 		},
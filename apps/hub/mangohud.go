@@ -0,0 +1,153 @@
+package main
+
+import (
+	"fmt"
+	"log/slog"
+	"path"
+	"strings"
+
+	"github.com/lobinuxsoft/capydeploy/internal/deploy"
+	"github.com/lobinuxsoft/capydeploy/internal/shortcuts"
+)
+
+// =============================================================================
+// MangoHud
+// =============================================================================
+
+// maxMangoHudConfig bounds the size of the MangoHud config pushed to a device
+const maxMangoHudConfig = 64 * 1024
+
+// MangoHudConfig is the MangoHud setup of the connected device
+type MangoHudConfig struct {
+	Installed bool   `json:"installed"`
+	Path      string `json:"path"`
+	Config    string `json:"config"` // empty when the device has none
+}
+
+// MangoHudGame is a Steam shortcut on the device and whether it shows the overlay
+type MangoHudGame struct {
+	AppID   int64  `json:"appId"`
+	Name    string `json:"name"`
+	Enabled bool   `json:"enabled"`
+}
+
+// GetMangoHudConfig reads the MangoHud config of the connected device
+func (a *App) GetMangoHudConfig() (MangoHudConfig, error) {
+	client, _, err := a.connectedClient()
+	if err != nil {
+		return MangoHudConfig{}, err
+	}
+	homeDir, err := client.GetHomeDir()
+	if err != nil {
+		return MangoHudConfig{}, err
+	}
+
+	cfg := MangoHudConfig{Path: mangoHudConfigPath(homeDir)}
+	_, err = client.RunCommand("command -v mangohud")
+	cfg.Installed = err == nil
+	if data, err := client.ReadFile(cfg.Path); err == nil {
+		cfg.Config = string(data)
+	}
+	return cfg, nil
+}
+
+// SetMangoHudConfig writes the MangoHud config of the connected device. It
+// applies to every game launched with the overlay from then on.
+func (a *App) SetMangoHudConfig(content string) error {
+	if len(content) > maxMangoHudConfig {
+		return fmt.Errorf("MangoHud config is too large (%d bytes, max %d)", len(content), maxMangoHudConfig)
+	}
+	if strings.ContainsRune(content, 0) {
+		return fmt.Errorf("MangoHud config must be text")
+	}
+	client, _, err := a.connectedClient()
+	if err != nil {
+		return err
+	}
+	homeDir, err := client.GetHomeDir()
+	if err != nil {
+		return err
+	}
+
+	configPath := mangoHudConfigPath(homeDir)
+	if err := client.MkdirAll(path.Dir(configPath)); err != nil {
+		return err
+	}
+	content = strings.ReplaceAll(content, "\r\n", "\n")
+	if content != "" && !strings.HasSuffix(content, "\n") {
+		content += "\n"
+	}
+	if err := client.WriteFile(configPath, []byte(content), 0644); err != nil {
+		return fmt.Errorf("failed to write %s: %w", configPath, err)
+	}
+	slog.Info("MangoHud config updated", "path", configPath)
+	return nil
+}
+
+// GetMangoHudGames lists the Steam shortcuts on the connected device and
+// whether their launch options turn the overlay on
+func (a *App) GetMangoHudGames() ([]MangoHudGame, error) {
+	client, deviceCfg, err := a.connectedClient()
+	if err != nil {
+		return nil, err
+	}
+	list, err := shortcuts.ListShortcuts(remoteConfig(client, deviceCfg))
+	if err != nil {
+		return nil, fmt.Errorf("failed to list shortcuts: %w", err)
+	}
+	games := []MangoHudGame{}
+	for _, sc := range list {
+		games = append(games, MangoHudGame{AppID: sc.AppID, Name: sc.Name, Enabled: deploy.HasMangoHud(sc.LaunchOptions)})
+	}
+	return games, nil
+}
+
+// SetGameMangoHud turns the overlay on or off in the launch options of a Steam
+// shortcut and restarts Steam to load them
+func (a *App) SetGameMangoHud(appID int64, enabled bool) error {
+	client, deviceCfg, err := a.connectedClient()
+	if err != nil {
+		return err
+	}
+	remoteCfg := remoteConfig(client, deviceCfg)
+	list, err := shortcuts.ListShortcuts(remoteCfg)
+	if err != nil {
+		return fmt.Errorf("failed to list shortcuts: %w", err)
+	}
+	var sc *shortcuts.ShortcutInfo
+	for i := range list {
+		if list[i].AppID == appID {
+			sc = &list[i]
+			break
+		}
+	}
+	if sc == nil {
+		return fmt.Errorf("no Steam shortcut with app ID %d", appID)
+	}
+	if deploy.HasMangoHud(sc.LaunchOptions) == enabled {
+		return nil
+	}
+
+	launchOptions := deploy.WithMangoHud(sc.LaunchOptions, enabled)
+	exe, startDir := strings.Trim(sc.Exe, `"`), strings.Trim(sc.StartDir, `"`)
+	if err := shortcuts.UpdateShortcut(remoteCfg, sc.AppID, exe, startDir, launchOptions); err != nil {
+		return fmt.Errorf("failed to update shortcut: %w", err)
+	}
+
+	// Keep the deploy record in sync so copies of the game launch the same way
+	if manifest, ok := deploy.ReadManifest(client, startDir); ok {
+		manifest.LaunchOptions = deploy.WithMangoHud(manifest.LaunchOptions, enabled)
+		if err := deploy.WriteManifest(client, startDir, *manifest); err != nil {
+			slog.Warn("Failed to update deploy manifest", "error", err)
+		}
+	}
+
+	slog.Info("MangoHud overlay toggled", "game", sc.Name, "enabled", enabled)
+	shortcuts.RefreshSteamLibrary(remoteCfg)
+	return nil
+}
+
+// mangoHudConfigPath returns where MangoHud reads its config on the device
+func mangoHudConfigPath(homeDir string) string {
+	return path.Join(homeDir, ".config", "MangoHud", "MangoHud.conf")
+}
@@ -0,0 +1,194 @@
+package main
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/wailsapp/wails/v2/pkg/runtime"
+
+	"github.com/lobinuxsoft/capydeploy/internal/device"
+	"github.com/lobinuxsoft/capydeploy/pkg/config"
+	"github.com/lobinuxsoft/capydeploy/pkg/transfer"
+)
+
+// =============================================================================
+// Multi-Device Deploys
+// =============================================================================
+
+// DeviceProgress is the progress of the deploy to one device of a multi-device
+// deploy, sent as "upload:device" events
+type DeviceProgress struct {
+	Host string `json:"host"`
+	Name string `json:"name"`
+	UploadProgress
+}
+
+// multiDeploy sums the progress of the devices of a multi-device deploy into
+// the progress of the whole deploy
+type multiDeploy struct {
+	mu       sync.Mutex
+	devices  []config.DeviceConfig
+	progress map[string]UploadProgress // latest progress of each device, by host
+}
+
+// UploadGameToDevices deploys a game setup to several saved devices in
+// parallel. The connected device keeps its connection, the others are
+// connected for the deploy. The whole deploy reports "upload:progress" as
+// usual and each device "upload:device".
+func (a *App) UploadGameToDevices(setupID string, hosts []string) error {
+	if len(hosts) == 0 {
+		return fmt.Errorf("no devices selected")
+	}
+	if a.deploying() {
+		return fmt.Errorf("a deploy is already running")
+	}
+	setup := findGameSetup(setupID)
+	if setup == nil {
+		return fmt.Errorf("game setup not found: %s", setupID)
+	}
+
+	saved, err := config.GetDevices()
+	if err != nil {
+		return fmt.Errorf("failed to get devices: %w", err)
+	}
+	var devices []config.DeviceConfig
+	for _, host := range hosts {
+		i := slices.IndexFunc(saved, func(d config.DeviceConfig) bool { return d.Host == host })
+		if i < 0 {
+			return fmt.Errorf("device not found: %s", host)
+		}
+		if !slices.ContainsFunc(devices, func(d config.DeviceConfig) bool { return d.Host == host }) {
+			devices = append(devices, saved[i])
+		}
+	}
+
+	go a.performMultiUpload(setup, devices)
+	return nil
+}
+
+// performMultiUpload builds a setup once and deploys it to every device at the
+// same time. A device that fails doesn't stop the others.
+func (a *App) performMultiUpload(setup *config.GameSetup, devices []config.DeviceConfig) {
+	ctx, done := a.beginUpload()
+	defer done()
+
+	targets := make([]string, len(devices))
+	for i, d := range devices {
+		targets[i] = fmt.Sprintf("%s (%s)", d.Host, d.Name)
+	}
+	a.startDeployLog(fmt.Sprintf("Deploying %s from %s to %s on %s, delta: %v",
+		setup.DeployName(), setup.LocalPath, setup.RemotePath, strings.Join(targets, ", "), !setup.FullUpload))
+	a.startUploadJob(setup, devices)
+	a.emitUploadProgress(UploadProgress{Status: "Preparing upload..."})
+
+	setup, ok := a.buildForDeploy(ctx, setup, a.emitUploadProgress)
+	if !ok {
+		return
+	}
+
+	m := &multiDeploy{devices: devices, progress: make(map[string]UploadProgress)}
+	var wg sync.WaitGroup
+	for _, deviceCfg := range devices {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			emit := func(progress UploadProgress) {
+				runtime.EventsEmit(a.ctx, "upload:device", DeviceProgress{Host: deviceCfg.Host, Name: deviceCfg.Name, UploadProgress: progress})
+				a.logDeviceProgress(deviceCfg.Name, progress)
+				m.update(deviceCfg.Host, progress, a.emitUploadProgress)
+			}
+
+			emit(UploadProgress{Status: "Connecting..."})
+			client, release, err := a.deployClient(deviceCfg)
+			if err != nil {
+				emit(UploadProgress{Error: err.Error(), Done: true})
+				return
+			}
+			defer release()
+			a.deploySetup(ctx, client, &deviceCfg, setup, !setup.FullUpload, emit)
+		}()
+	}
+	wg.Wait()
+}
+
+// deployClient returns a client to deploy to a device: the connected device's
+// own, or a new connection that the returned function closes
+func (a *App) deployClient(deviceCfg config.DeviceConfig) (*device.Client, func(), error) {
+	if client, connected, err := a.connectedClient(); err == nil && connected.Host == deviceCfg.Host {
+		return client, func() {}, nil
+	}
+	client, err := newDeviceClient(deviceCfg)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to create client: %w", err)
+	}
+	if err := client.Connect(); err != nil {
+		return nil, nil, fmt.Errorf("connection failed: %w", err)
+	}
+	return client, func() { client.Close() }, nil
+}
+
+// logDeviceProgress records the progress of one device of a multi-device
+// deploy in the transfer log, which stays open for the other devices
+func (a *App) logDeviceProgress(name string, progress UploadProgress) {
+	progress.Status = name + ": " + progress.Status
+	progress.Done = false
+	a.mu.Lock()
+	a.writeDeployLog(progress)
+	a.mu.Unlock()
+}
+
+// update records the progress of a device and emits the progress of the whole
+// deploy. Emitting under the lock keeps a device's last report from being
+// overtaken by an older one of another device.
+func (m *multiDeploy) update(host string, progress UploadProgress, emit func(UploadProgress)) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.progress[host] = progress
+	emit(m.overall())
+}
+
+// overall sums the latest progress of the devices: the average of their
+// progress and the sum of their transfers, done once every device is
+func (m *multiDeploy) overall() UploadProgress {
+	var overall UploadProgress
+	var stats transfer.Stats
+	var failed []string
+	done := 0
+	for _, d := range m.devices {
+		p := m.progress[d.Host]
+		if p.Done {
+			done++
+			overall.Progress++
+			if p.Error != "" {
+				failed = append(failed, fmt.Sprintf("%s: %s", d.Name, p.Error))
+			}
+			for _, file := range p.Retried {
+				overall.Retried = append(overall.Retried, fmt.Sprintf("%s: %s", d.Name, file))
+			}
+			continue
+		}
+		overall.Progress += p.Progress
+		if p.Transfer != nil {
+			stats.SentBytes += p.Transfer.SentBytes
+			stats.TotalBytes += p.Transfer.TotalBytes
+			stats.BytesPerSecond += p.Transfer.BytesPerSecond
+			stats.ETASeconds = max(stats.ETASeconds, p.Transfer.ETASeconds)
+			overall.Transfer = &stats
+		}
+	}
+	overall.Progress /= float64(len(m.devices))
+
+	if done < len(m.devices) {
+		overall.Status = fmt.Sprintf("Deploying to %d devices, %d done", len(m.devices), done)
+		overall.Retried = nil
+		return overall
+	}
+	overall.Done = true
+	overall.Status = fmt.Sprintf("Deployed to %d of %d devices", done-len(failed), len(m.devices))
+	if len(failed) > 0 {
+		overall.Error = "Failed on " + strings.Join(failed, "; ")
+	}
+	return overall
+}
@@ -0,0 +1,54 @@
+package main
+
+import (
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/lobinuxsoft/capydeploy/pkg/config"
+)
+
+// =============================================================================
+// Network Settings
+// =============================================================================
+
+// GetNetworkSettings returns the proxy and request timeout
+func (a *App) GetNetworkSettings() (config.NetworkSettings, error) {
+	return config.GetNetworkSettings()
+}
+
+// SetNetworkSettings saves the proxy and request timeout and applies them to
+// every HTTP client the hub uses
+func (a *App) SetNetworkSettings(settings config.NetworkSettings) error {
+	if err := config.SetNetworkSettings(settings); err != nil {
+		return err
+	}
+	if a.imageFetcher != nil {
+		a.imageFetcher.SetHTTPClient(newHTTPClient())
+	}
+
+	// Recreated with the new client on next use
+	a.mu.Lock()
+	a.igdb = nil
+	a.mu.Unlock()
+	return nil
+}
+
+// newHTTPClient creates an HTTP client that honors the configured proxy and
+// timeout. Without a configured proxy HTTP_PROXY and HTTPS_PROXY are used.
+func newHTTPClient() *http.Client {
+	settings, _ := config.GetNetworkSettings()
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = http.ProxyFromEnvironment
+	if settings.ProxyURL != "" {
+		if proxyURL, err := url.Parse(settings.ProxyURL); err == nil {
+			transport.Proxy = http.ProxyURL(proxyURL)
+		}
+	}
+
+	return &http.Client{
+		Transport: transport,
+		Timeout:   time.Duration(settings.TimeoutSeconds) * time.Second,
+	}
+}
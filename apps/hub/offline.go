@@ -0,0 +1,208 @@
+package main
+
+import (
+	"fmt"
+	"log/slog"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/lobinuxsoft/capydeploy/internal/device"
+	"github.com/lobinuxsoft/capydeploy/internal/shortcuts"
+	"github.com/lobinuxsoft/capydeploy/pkg/steam"
+	"github.com/lobinuxsoft/capydeploy/pkg/steamgriddb"
+)
+
+// =============================================================================
+// Offline Artwork
+// =============================================================================
+
+// OfflineGame is a game whose artwork can be browsed from the disk cache
+type OfflineGame struct {
+	ID        int       `json:"id"`
+	Name      string    `json:"name"`
+	Images    int       `json:"images"`
+	UpdatedAt time.Time `json:"updatedAt"`
+}
+
+// SetOfflineMode switches artwork browsing between SteamGridDB and the local cache
+func (a *App) SetOfflineMode(enabled bool) {
+	a.mu.Lock()
+	a.offline = enabled
+	a.mu.Unlock()
+}
+
+// IsOfflineMode reports whether artwork is served from the local cache
+func (a *App) IsOfflineMode() bool {
+	return a.isOffline()
+}
+
+func (a *App) isOffline() bool {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	return a.offline
+}
+
+// GetOfflineGames returns the games with cached artwork, most recently browsed first
+func (a *App) GetOfflineGames() ([]OfflineGame, error) {
+	if a.results == nil {
+		return []OfflineGame{}, nil
+	}
+
+	snaps, err := a.results.List()
+	if err != nil {
+		return nil, err
+	}
+
+	games := []OfflineGame{}
+	for _, snap := range snaps {
+		cached := a.cachedOnly(&snap)
+		count := len(cached.Grids) + len(cached.Heroes) + len(cached.Logos) + len(cached.Icons)
+		if count == 0 {
+			continue
+		}
+		name := snap.Game.Name
+		if name == "" {
+			name = fmt.Sprintf("SteamGridDB #%d", snap.Game.ID)
+		}
+		games = append(games, OfflineGame{ID: snap.Game.ID, Name: name, Images: count, UpdatedAt: snap.UpdatedAt})
+	}
+	return games, nil
+}
+
+// rememberGameNames keeps search result names so stored results can be listed by name
+func (a *App) rememberGameNames(results []steamgriddb.SearchResult) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if a.gameNames == nil {
+		a.gameNames = make(map[int]string)
+	}
+	for _, r := range results {
+		a.gameNames[r.ID] = r.Name
+	}
+}
+
+// recordResults stores browse results for offline use
+func (a *App) recordResults(snap steamgriddb.GameSnapshot) {
+	if a.results == nil {
+		return
+	}
+
+	a.mu.RLock()
+	if name, ok := a.gameNames[snap.Game.ID]; ok && snap.Game.Name == "" {
+		snap.Game.Name = name
+	}
+	a.mu.RUnlock()
+
+	if err := a.results.Merge(snap); err != nil {
+		slog.Warn("Failed to store artwork results", "error", err)
+	}
+}
+
+// offlineSnapshot returns the stored results of a game limited to cached images
+func (a *App) offlineSnapshot(gameID int) (*steamgriddb.GameSnapshot, error) {
+	if a.results == nil {
+		return nil, fmt.Errorf("no cached results available")
+	}
+
+	snap, err := a.results.Get(gameID)
+	if err != nil {
+		return nil, err
+	}
+	if snap == nil {
+		return &steamgriddb.GameSnapshot{Game: steamgriddb.SearchResult{ID: gameID}}, nil
+	}
+	return a.cachedOnly(snap), nil
+}
+
+// cachedOnly drops the images whose full size file is not in the disk cache,
+// since those can't be shown or applied without network access
+func (a *App) cachedOnly(snap *steamgriddb.GameSnapshot) *steamgriddb.GameSnapshot {
+	result := &steamgriddb.GameSnapshot{Game: snap.Game, UpdatedAt: snap.UpdatedAt}
+	if a.imageCache == nil {
+		return result
+	}
+
+	for _, g := range snap.Grids {
+		if a.imageCache.Has(g.URL) {
+			result.Grids = append(result.Grids, g)
+		}
+	}
+	images := func(list []steamgriddb.ImageData) []steamgriddb.ImageData {
+		var cached []steamgriddb.ImageData
+		for _, img := range list {
+			if a.imageCache.Has(img.URL) {
+				cached = append(cached, img)
+			}
+		}
+		return cached
+	}
+	result.Heroes = images(snap.Heroes)
+	result.Logos = images(snap.Logos)
+	result.Icons = images(snap.Icons)
+	return result
+}
+
+// offlinePageInfo describes cached results, which are always returned as a single page
+func offlinePageInfo(count int) steamgriddb.PageInfo {
+	return steamgriddb.PageInfo{Page: 0, Limit: count, Total: count, TotalPages: 1}
+}
+
+// writeLocalArtwork copies cached or generated artwork straight into the grid folder
+// of every Steam user on the device, for images the device can't download itself.
+func (a *App) writeLocalArtwork(client *device.Client, appID uint64, art *shortcuts.ArtworkConfig) error {
+	gridDirs, err := shortcuts.GridDirs(client)
+	if err != nil {
+		return err
+	}
+
+	slots := []struct {
+		url     string
+		artType steam.ArtworkType
+	}{
+		{art.GridPortrait, steam.ArtworkPortrait},
+		{art.GridLandscape, steam.ArtworkGrid},
+		{art.HeroImage, steam.ArtworkHero},
+		{art.LogoImage, steam.ArtworkLogo},
+		{art.IconImage, steam.ArtworkIcon},
+	}
+
+	for _, slot := range slots {
+		if slot.url == "" {
+			continue
+		}
+		data, contentType, err := a.localImage(slot.url)
+		if err != nil {
+			return err
+		}
+		filename := steam.ArtworkFilename(uint32(appID), slot.artType, artworkExt(slot.url, contentType))
+
+		for _, gridDir := range gridDirs {
+			if err := client.MkdirAll(gridDir); err != nil {
+				return err
+			}
+			if err := client.WriteFile(path.Join(gridDir, filename), data, 0644); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// artworkExt returns the file extension for an image, from its URL or content type
+func artworkExt(imageURL, contentType string) string {
+	if ext := strings.TrimPrefix(path.Ext(strings.SplitN(imageURL, "?", 2)[0]), "."); ext != "" {
+		return strings.ToLower(ext)
+	}
+	switch contentType {
+	case "image/jpeg":
+		return "jpg"
+	case "image/webp":
+		return "webp"
+	case "image/vnd.microsoft.icon", "image/x-icon":
+		return "ico"
+	default:
+		return "png"
+	}
+}
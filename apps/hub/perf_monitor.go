@@ -0,0 +1,185 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/wailsapp/wails/v2/pkg/runtime"
+
+	"github.com/lobinuxsoft/capydeploy/internal/device"
+)
+
+// =============================================================================
+// Performance Monitor
+// =============================================================================
+
+// perfSampleInterval is how often the device is sampled while monitoring
+const perfSampleInterval = time.Second
+
+// perfSampleScript prints the counters a sample is computed from: the cpu line
+// of /proc/stat, the memory totals, the GPU load and every hwmon temperature.
+// Missing sensors print nothing.
+const perfSampleScript = `head -n 1 /proc/stat
+grep -E '^(MemTotal|MemAvailable):' /proc/meminfo
+for f in /sys/class/drm/card*/device/gpu_busy_percent; do [ -r "$f" ] && echo "gpu $(cat "$f")" && break; done
+for h in /sys/class/hwmon/hwmon*; do [ -r "$h/temp1_input" ] && echo "temp $(cat "$h/name") $(cat "$h/temp1_input")"; done
+true`
+
+// cpuSensors and gpuSensors are the hwmon drivers reporting CPU and GPU temperatures
+var (
+	cpuSensors = map[string]bool{"k10temp": true, "zenpower": true, "coretemp": true, "cpu_thermal": true}
+	gpuSensors = map[string]bool{"amdgpu": true, "nouveau": true, "i915": true}
+)
+
+// PerfSample is one reading of the connected device's load. Values the device
+// has no sensor for are left out.
+type PerfSample struct {
+	Time     time.Time `json:"time"`
+	CPU      *float64  `json:"cpu,omitempty"`     // percent, nil on the first sample
+	GPU      *float64  `json:"gpu,omitempty"`     // percent
+	RAMUsed  int64     `json:"ramUsed"`           // bytes
+	RAMTotal int64     `json:"ramTotal"`          // bytes
+	CPUTemp  *float64  `json:"cpuTemp,omitempty"` // degrees Celsius
+	GPUTemp  *float64  `json:"gpuTemp,omitempty"` // degrees Celsius
+}
+
+// cpuTimes are the cumulative jiffies of the cpu line of /proc/stat
+type cpuTimes struct {
+	busy, total uint64
+}
+
+// StartPerformanceMonitor samples the connected device every second and emits
+// each sample as a "perf:sample" event until StopPerformanceMonitor is called
+// or the device disconnects. Does nothing if the monitor is already running.
+func (a *App) StartPerformanceMonitor() error {
+	client, _, err := a.connectedClient()
+	if err != nil {
+		return err
+	}
+
+	a.mu.Lock()
+	if a.cancelPerf != nil {
+		a.mu.Unlock()
+		return nil
+	}
+	ctx, cancel := context.WithCancel(a.ctx)
+	a.cancelPerf = cancel
+	a.mu.Unlock()
+
+	go a.monitorPerformance(ctx, client)
+	return nil
+}
+
+// StopPerformanceMonitor stops sampling the device
+func (a *App) StopPerformanceMonitor() {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if a.cancelPerf != nil {
+		a.cancelPerf()
+		a.cancelPerf = nil
+	}
+}
+
+// monitorPerformance samples client until ctx is cancelled or sampling fails,
+// then emits "perf:stopped" with the reason, empty when stopped by the user
+func (a *App) monitorPerformance(ctx context.Context, client *device.Client) {
+	ticker := time.NewTicker(perfSampleInterval)
+	defer ticker.Stop()
+
+	var prev *cpuTimes
+	for {
+		output, err := client.RunCommand(perfSampleScript)
+		if ctx.Err() != nil {
+			runtime.EventsEmit(a.ctx, "perf:stopped", "")
+			return
+		}
+		if err != nil {
+			slog.Warn("Performance sampling failed", "error", err)
+			a.StopPerformanceMonitor()
+			runtime.EventsEmit(a.ctx, "perf:stopped", fmt.Sprintf("sampling failed: %v", err))
+			return
+		}
+		var sample PerfSample
+		sample, prev = parsePerfSample(output, prev)
+		runtime.EventsEmit(a.ctx, "perf:sample", sample)
+
+		select {
+		case <-ctx.Done():
+			runtime.EventsEmit(a.ctx, "perf:stopped", "")
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// parsePerfSample reads the output of perfSampleScript. CPU load is the busy
+// share of the jiffies since prev, so it needs the previous sample's times.
+func parsePerfSample(output string, prev *cpuTimes) (PerfSample, *cpuTimes) {
+	sample := PerfSample{Time: time.Now()}
+	var times *cpuTimes
+	var memAvailable int64
+
+	for _, line := range strings.Split(output, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) < 2 {
+			continue
+		}
+		switch fields[0] {
+		case "cpu":
+			// user nice system idle iowait irq softirq steal
+			var t cpuTimes
+			for i, f := range fields[1:] {
+				if i >= 8 {
+					break
+				}
+				n, _ := strconv.ParseUint(f, 10, 64)
+				t.total += n
+				if i != 3 && i != 4 {
+					t.busy += n
+				}
+			}
+			times = &t
+		case "MemTotal:":
+			sample.RAMTotal = parseKB(fields[1])
+		case "MemAvailable:":
+			memAvailable = parseKB(fields[1])
+		case "gpu":
+			if v, err := strconv.ParseFloat(fields[1], 64); err == nil {
+				sample.GPU = &v
+			}
+		case "temp":
+			if len(fields) < 3 {
+				continue
+			}
+			milli, err := strconv.ParseFloat(fields[2], 64)
+			if err != nil {
+				continue
+			}
+			celsius := milli / 1000
+			if cpuSensors[fields[1]] && sample.CPUTemp == nil {
+				sample.CPUTemp = &celsius
+			} else if gpuSensors[fields[1]] && sample.GPUTemp == nil {
+				sample.GPUTemp = &celsius
+			}
+		}
+	}
+
+	if sample.RAMTotal > 0 {
+		sample.RAMUsed = sample.RAMTotal - memAvailable
+	}
+	if times != nil && prev != nil && times.total > prev.total && times.busy >= prev.busy {
+		load := float64(times.busy-prev.busy) / float64(times.total-prev.total) * 100
+		sample.CPU = &load
+	}
+	return sample, times
+}
+
+// parseKB converts a kB value of /proc/meminfo to bytes
+func parseKB(s string) int64 {
+	n, _ := strconv.ParseInt(s, 10, 64)
+	return n * 1024
+}
@@ -0,0 +1,183 @@
+package main
+
+import (
+	"fmt"
+	"log/slog"
+	"path"
+	"regexp"
+
+	"github.com/lobinuxsoft/capydeploy/internal/deploy"
+	"github.com/lobinuxsoft/capydeploy/internal/device"
+	"github.com/lobinuxsoft/capydeploy/internal/shortcuts"
+)
+
+// =============================================================================
+// Proton Prefix Tools
+// =============================================================================
+
+// ProtonPrefix is the Wine prefix Steam created for a game run under Proton
+type ProtonPrefix struct {
+	AppID  uint32 `json:"appId"`
+	Path   string `json:"path"`
+	Exists bool   `json:"exists"`
+	Size   int64  `json:"size"`
+	// Which tool RunPrefixVerbs will use, "" when neither is installed
+	Tool string `json:"tool"`
+}
+
+// prefixVerbPattern matches winetricks verbs and settings such as "vcrun2022" or "win10"
+var prefixVerbPattern = regexp.MustCompile(`^[A-Za-z0-9_.=-]+$`)
+
+// GetProtonPrefix locates the Proton prefix of an installed game
+func (a *App) GetProtonPrefix(gamePath string) (*ProtonPrefix, error) {
+	client, _, err := a.connectedClient()
+	if err != nil {
+		return nil, err
+	}
+	prefix, err := a.protonPrefix(client, gamePath)
+	if err != nil {
+		return nil, err
+	}
+
+	if prefix.Exists {
+		prefix.Size = remoteSize(client, prefix.Path)
+	}
+	prefix.Tool = deploy.PrefixTool(client)
+	return prefix, nil
+}
+
+// OpenProtonPrefix opens the prefix's drive_c in the file manager on the device
+func (a *App) OpenProtonPrefix(gamePath string) error {
+	client, _, err := a.connectedClient()
+	if err != nil {
+		return err
+	}
+	prefix, err := a.protonPrefix(client, gamePath)
+	if err != nil {
+		return err
+	}
+	if !prefix.Exists {
+		return fmt.Errorf("the game has no Proton prefix yet, launch it once first")
+	}
+
+	driveC := path.Join(prefix.Path, "pfx", "drive_c")
+	cmd := fmt.Sprintf("DISPLAY=${DISPLAY:-:0} nohup xdg-open %s >/dev/null 2>&1 &", device.ShellQuote(driveC))
+	if _, err := client.RunCommand(cmd); err != nil {
+		return fmt.Errorf("failed to open prefix: %w", err)
+	}
+	return nil
+}
+
+// RunPrefixVerbs installs winetricks verbs into the game's prefix, through
+// protontricks when available so the game's Proton version is used. Returns
+// the tool's output.
+func (a *App) RunPrefixVerbs(gamePath string, verbs []string) (string, error) {
+	if len(verbs) == 0 {
+		return "", fmt.Errorf("no verbs given")
+	}
+	if err := validatePrefixVerbs(verbs); err != nil {
+		return "", err
+	}
+
+	prefix, err := a.GetProtonPrefix(gamePath)
+	if err != nil {
+		return "", err
+	}
+	if !prefix.Exists {
+		return "", fmt.Errorf("the game has no Proton prefix yet, launch it once first")
+	}
+
+	client, _, err := a.connectedClient()
+	if err != nil {
+		return "", err
+	}
+	cmd, err := deploy.VerbsCommand(prefix.Tool, prefix.AppID, prefix.Path, verbs)
+	if err != nil {
+		return "", err
+	}
+	output, err := client.RunCommand(cmd + " 2>&1")
+	if err != nil {
+		return output, fmt.Errorf("%s failed: %w", prefix.Tool, err)
+	}
+	return output, nil
+}
+
+// validatePrefixVerbs rejects anything but plain winetricks verbs, which are
+// passed to a shell on the device
+func validatePrefixVerbs(verbs []string) error {
+	for _, verb := range verbs {
+		if !prefixVerbPattern.MatchString(verb) {
+			return fmt.Errorf("invalid verb: %q", verb)
+		}
+	}
+	return nil
+}
+
+// ResetProtonPrefix deletes the prefix of a game so Steam creates a fresh one on
+// the next launch. Saves stored inside the prefix are lost.
+func (a *App) ResetProtonPrefix(gamePath string) error {
+	client, _, err := a.connectedClient()
+	if err != nil {
+		return err
+	}
+	prefix, err := a.protonPrefix(client, gamePath)
+	if err != nil {
+		return err
+	}
+	if !prefix.Exists {
+		return nil
+	}
+
+	// A prefix in use is never deleted, even when that can't be checked
+	pids, err := runningGamePIDs(client, gamePath)
+	if err != nil {
+		return fmt.Errorf("failed to check whether the game is running: %w", err)
+	}
+	if len(pids) > 0 {
+		return fmt.Errorf("stop the game before resetting its prefix")
+	}
+	if _, err := client.RunCommand(fmt.Sprintf("rm -rf -- %s", device.ShellQuote(prefix.Path))); err != nil {
+		return fmt.Errorf("failed to remove prefix: %w", err)
+	}
+
+	// The fresh prefix gets the setup's verbs again on the next deploy
+	if manifest, ok := deploy.ReadManifest(client, gamePath); ok && len(manifest.PrefixVerbs) > 0 {
+		manifest.PrefixVerbs = nil
+		if err := deploy.WriteManifest(client, gamePath, *manifest); err != nil {
+			slog.Warn("Failed to update deploy manifest", "error", err)
+		}
+	}
+	return nil
+}
+
+// protonPrefix resolves the compatdata directory of the game's shortcut
+func (a *App) protonPrefix(client *device.Client, gamePath string) (*ProtonPrefix, error) {
+	_, deviceCfg, err := a.connectedClient()
+	if err != nil {
+		return nil, err
+	}
+	homeDir, err := client.GetHomeDir()
+	if err != nil {
+		return nil, err
+	}
+	gamePath, err = validateGamePath(client, gamePath, homeDir)
+	if err != nil {
+		return nil, err
+	}
+
+	game := &InstalledGame{Name: path.Base(gamePath), Path: gamePath}
+	if manifest, ok := deploy.ReadManifest(client, gamePath); ok {
+		game.AppID = manifest.AppID
+	}
+	if list, err := shortcuts.ListShortcuts(remoteConfig(client, deviceCfg)); err == nil {
+		if sc := gameShortcut(list, game); sc != nil {
+			game.AppID = uint32(sc.AppID)
+		}
+	}
+	if game.AppID == 0 {
+		return nil, fmt.Errorf("%s has no Steam shortcut, so it has no Proton prefix", game.Name)
+	}
+
+	prefixPath := deploy.CompatDataPath(homeDir, game.AppID)
+	return &ProtonPrefix{AppID: game.AppID, Path: prefixPath, Exists: client.FileExists(prefixPath)}, nil
+}
@@ -0,0 +1,55 @@
+package main
+
+import (
+	"fmt"
+	"path"
+
+	"github.com/lobinuxsoft/capydeploy/internal/deploy"
+	"github.com/lobinuxsoft/capydeploy/internal/device"
+	"github.com/lobinuxsoft/capydeploy/pkg/config"
+)
+
+// =============================================================================
+// Redeploy From Installed Games
+// =============================================================================
+
+// UpdateGame redeploys an installed game from the local folder of the setup that
+// deployed it, uploading only the files that changed
+func (a *App) UpdateGame(gamePath string) error {
+	client, deviceCfg, err := a.connectedClient()
+	if err != nil {
+		return err
+	}
+
+	setup, _, err := deployedSetup(client, gamePath)
+	if err != nil {
+		return err
+	}
+
+	go a.performUpload(client, &deviceCfg, setup, true)
+	return nil
+}
+
+// deployedSetup returns the game setup that deployed gamePath, along with its
+// deploy manifest
+func deployedSetup(client *device.Client, gamePath string) (*config.GameSetup, *deploy.Manifest, error) {
+	manifest, ok := deploy.ReadManifest(client, gamePath)
+	if !ok || manifest.SetupID == "" {
+		return nil, nil, fmt.Errorf("no deploy profile recorded for %s, upload it once from Upload Game", path.Base(gamePath))
+	}
+
+	setup := findGameSetup(manifest.SetupID)
+	if setup == nil {
+		return nil, nil, fmt.Errorf("deploy profile of %s no longer exists", manifest.Name)
+	}
+
+	// The setup may have been edited since; never deploy it somewhere else
+	remotePath, err := deploy.ExpandRemotePath(client, setup.RemotePath)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to expand path: %w", err)
+	}
+	if target := path.Join(remotePath, setup.DeployName()); target != path.Clean(gamePath) {
+		return nil, nil, fmt.Errorf("deploy profile %q now targets %s", setup.DeployName(), target)
+	}
+	return setup, manifest, nil
+}
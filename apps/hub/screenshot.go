@@ -0,0 +1,128 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"log/slog"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/wailsapp/wails/v2/pkg/runtime"
+)
+
+// =============================================================================
+// Remote Screenshot
+// =============================================================================
+
+// screenshotScript captures the screen of the device into a temporary PNG and
+// prints its path. Game Mode runs under gamescope, the desktop under KDE; grim
+// covers other wlroots desktops. The SSH session has no display of its own, so
+// the user's session is assumed.
+const screenshotScript = `out=$(mktemp --suffix=.png) || exit 1
+rm -f "$out"
+export XDG_RUNTIME_DIR="${XDG_RUNTIME_DIR:-/run/user/$(id -u)}"
+if pgrep -x 'gamescope|gamescope-wl' >/dev/null && command -v gamescopectl >/dev/null; then
+	gamescopectl screenshot "$out" >/dev/null 2>&1
+elif command -v spectacle >/dev/null; then
+	WAYLAND_DISPLAY="${WAYLAND_DISPLAY:-wayland-0}" DISPLAY="${DISPLAY:-:0}" spectacle -b -n -f -o "$out" >/dev/null 2>&1
+elif command -v grim >/dev/null; then
+	WAYLAND_DISPLAY="${WAYLAND_DISPLAY:-wayland-0}" grim "$out" >/dev/null 2>&1
+else
+	echo "no screenshot tool found on the device (gamescopectl, spectacle or grim)"
+	exit 1
+fi
+# gamescope saves the image after the command returns
+for i in $(seq 50); do [ -s "$out" ] && break; sleep 0.1; done
+if [ ! -s "$out" ]; then
+	rm -f "$out"
+	echo "the screenshot tool saved no image"
+	exit 1
+fi
+echo "$out"`
+
+// maxScreenshotSize bounds the screenshot pulled from the device
+const maxScreenshotSize = 32 * 1024 * 1024
+
+// pngMagic starts every PNG file
+var pngMagic = []byte("\x89PNG\r\n\x1a\n")
+
+// Screenshot is a capture of the connected device's screen
+type Screenshot struct {
+	DataURL    string    `json:"dataUrl"`
+	DeviceName string    `json:"deviceName"`
+	TakenAt    time.Time `json:"takenAt"`
+}
+
+// CaptureScreenshot grabs what the connected device shows right now. The image
+// is kept so SaveScreenshot can write it.
+func (a *App) CaptureScreenshot() (*Screenshot, error) {
+	client, deviceCfg, err := a.connectedClient()
+	if err != nil {
+		return nil, err
+	}
+
+	output, err := client.RunCommand(screenshotScript)
+	if err != nil {
+		return nil, fmt.Errorf("screenshot failed: %w", err)
+	}
+	lines := strings.Split(strings.TrimSpace(output), "\n")
+	remotePath := strings.TrimSpace(lines[len(lines)-1])
+	if !strings.HasPrefix(remotePath, "/tmp/") || !strings.HasSuffix(remotePath, ".png") || strings.Contains(remotePath, "..") {
+		return nil, fmt.Errorf("screenshot failed: unexpected output %q", output)
+	}
+	defer func() {
+		if err := client.Remove(remotePath); err != nil {
+			slog.Warn("Failed to remove screenshot from device", "path", remotePath, "error", err)
+		}
+	}()
+
+	if size, err := client.FileSize(remotePath); err != nil {
+		return nil, err
+	} else if size > maxScreenshotSize {
+		return nil, fmt.Errorf("screenshot is too large (%d bytes)", size)
+	}
+	data, err := client.ReadFile(remotePath)
+	if err != nil {
+		return nil, err
+	}
+	if !bytes.HasPrefix(data, pngMagic) {
+		return nil, fmt.Errorf("screenshot is not a PNG image")
+	}
+
+	shot := &Screenshot{
+		DataURL:    toDataURL(data, "image/png"),
+		DeviceName: deviceCfg.Name,
+		TakenAt:    time.Now(),
+	}
+	a.mu.Lock()
+	a.screenshot = data
+	a.screenshotAt = shot.TakenAt
+	a.mu.Unlock()
+	slog.Info("Screenshot captured", "device", deviceCfg.Name, "size", len(data))
+	return shot, nil
+}
+
+// SaveScreenshot writes the last captured screenshot to a location chosen by
+// the user. Returns the saved path, or "" if the dialog was cancelled.
+func (a *App) SaveScreenshot() (string, error) {
+	a.mu.RLock()
+	data, takenAt := a.screenshot, a.screenshotAt
+	a.mu.RUnlock()
+	if data == nil {
+		return "", fmt.Errorf("no screenshot captured yet")
+	}
+
+	localPath, err := runtime.SaveFileDialog(a.ctx, runtime.SaveDialogOptions{
+		Title:           "Save Screenshot",
+		DefaultFilename: fmt.Sprintf("screenshot-%s.png", takenAt.Format("2006-01-02-150405")),
+		Filters:         []runtime.FileFilter{{DisplayName: "PNG images", Pattern: "*.png"}},
+	})
+	if err != nil || localPath == "" {
+		return "", err
+	}
+	if err := os.WriteFile(localPath, data, 0644); err != nil {
+		return "", fmt.Errorf("failed to write %s: %w", localPath, err)
+	}
+	return localPath, nil
+}
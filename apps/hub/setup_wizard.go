@@ -0,0 +1,85 @@
+package main
+
+import (
+	"fmt"
+	"log/slog"
+	"net"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/lobinuxsoft/capydeploy/internal/device"
+	"github.com/lobinuxsoft/capydeploy/pkg/config"
+)
+
+// =============================================================================
+// First Run Setup
+// =============================================================================
+
+// DeviceTestResult is what testing a device found on it
+type DeviceTestResult struct {
+	HomeDir string `json:"homeDir"`
+	// Steam is installed for the user, without it games can't be added to Steam
+	SteamFound bool `json:"steamFound"`
+}
+
+// NeedsFirstRunSetup reports whether the first run wizard should be shown
+func (a *App) NeedsFirstRunSetup() (bool, error) {
+	return config.NeedsFirstRunSetup()
+}
+
+// CompleteFirstRunSetup records that the first run wizard was finished or
+// skipped, so it is not shown again
+func (a *App) CompleteFirstRunSetup() error {
+	return config.CompleteFirstRunSetup()
+}
+
+// TestDeviceConnection connects to a device that may not be saved yet, to
+// check its address and credentials before adding it
+func (a *App) TestDeviceConnection(dev config.DeviceConfig) (DeviceTestResult, error) {
+	if err := validateDeviceConfig(dev); err != nil {
+		return DeviceTestResult{}, err
+	}
+
+	// A short plain dial first: SSH waits much longer for hosts that don't answer
+	addr := net.JoinHostPort(dev.Host, strconv.Itoa(dev.Port))
+	conn, err := net.DialTimeout("tcp", addr, 5*time.Second)
+	if err != nil {
+		return DeviceTestResult{}, fmt.Errorf("nothing answers on %s, check the address and that SSH is enabled on the device", addr)
+	}
+	conn.Close()
+
+	client, err := newDeviceClient(dev)
+	if err != nil {
+		return DeviceTestResult{}, err
+	}
+	if err := client.Connect(); err != nil {
+		a.recordHostKey(dev.Host, err)
+		return DeviceTestResult{}, err
+	}
+	defer client.Close()
+
+	homeDir, err := client.GetHomeDir()
+	if err != nil {
+		return DeviceTestResult{}, err
+	}
+	_, err = client.RunCommand(fmt.Sprintf("test -d %s", device.ShellQuote(homeDir+"/.steam/steam")))
+	result := DeviceTestResult{HomeDir: homeDir, SteamFound: err == nil}
+	slog.Info("Device connection tested", "host", dev.Host, "steam", result.SteamFound)
+	return result, nil
+}
+
+// validateDeviceConfig checks the fields needed to reach a device
+func validateDeviceConfig(dev config.DeviceConfig) error {
+	switch {
+	case dev.Host == "" || strings.ContainsAny(dev.Host, " \t/\\@"):
+		return fmt.Errorf("invalid host: %q", dev.Host)
+	case dev.Port < 1 || dev.Port > 65535:
+		return fmt.Errorf("invalid port: %d", dev.Port)
+	case dev.User == "" || strings.ContainsAny(dev.User, " \t:@"):
+		return fmt.Errorf("invalid user: %q", dev.User)
+	case dev.Password == "" && dev.KeyFile == "":
+		return fmt.Errorf("a password or an SSH key is required")
+	}
+	return nil
+}
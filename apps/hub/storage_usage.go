@@ -0,0 +1,153 @@
+package main
+
+import (
+	"fmt"
+	"log/slog"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/lobinuxsoft/capydeploy/internal/deploy"
+	"github.com/lobinuxsoft/capydeploy/internal/device"
+)
+
+// =============================================================================
+// Device Storage Usage
+// =============================================================================
+
+// StorageUsage is the disk space breakdown of the connected device
+type StorageUsage struct {
+	Disks     []DiskUsage    `json:"disks"`
+	Games     []StorageEntry `json:"games"`     // deployed games, largest first
+	Libraries []StorageEntry `json:"libraries"` // Steam library folders
+}
+
+// DiskUsage is the space of a filesystem holding games or a Steam library
+type DiskUsage struct {
+	Mount string `json:"mount"`
+	Total int64  `json:"total"` // bytes
+	Used  int64  `json:"used"`
+	Free  int64  `json:"free"`
+}
+
+// StorageEntry is a directory and the space it takes
+type StorageEntry struct {
+	Name string `json:"name"`
+	Path string `json:"path"`
+	Size int64  `json:"size"` // bytes
+}
+
+// GetStorageUsage measures the deployed games in remotePath, the Steam libraries
+// and the filesystems they live on
+func (a *App) GetStorageUsage(remotePath string) (*StorageUsage, error) {
+	client, _, err := a.connectedClient()
+	if err != nil {
+		return nil, err
+	}
+
+	remotePath, err = deploy.ExpandRemotePath(client, remotePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to expand path: %w", err)
+	}
+	homeDir, err := client.GetHomeDir()
+	if err != nil {
+		return nil, err
+	}
+
+	usage := &StorageUsage{Games: []StorageEntry{}, Libraries: []StorageEntry{}}
+
+	output, err := client.RunCommand(fmt.Sprintf("cd %s 2>/dev/null && du -sb -- */ 2>/dev/null", device.ShellQuote(remotePath)))
+	if err != nil {
+		return nil, fmt.Errorf("failed to measure games: %w", err)
+	}
+	for _, entry := range parseDiskUsage(output) {
+		name := strings.TrimSuffix(entry.Path, "/")
+		usage.Games = append(usage.Games, StorageEntry{Name: name, Path: path.Join(remotePath, name), Size: entry.Size})
+	}
+
+	vdf := path.Join(homeDir, ".steam", "steam", "steamapps", "libraryfolders.vdf")
+	output, _ = client.RunCommand(fmt.Sprintf(`grep -o '"path"[[:space:]]*"[^"]*"' %s 2>/dev/null`, device.ShellQuote(vdf)))
+	for _, lib := range parseLibraryFolders(output) {
+		usage.Libraries = append(usage.Libraries, StorageEntry{
+			Name: lib,
+			Path: lib,
+			Size: remoteSize(client, path.Join(lib, "steamapps")),
+		})
+	}
+
+	mounts := []string{remotePath}
+	for _, lib := range usage.Libraries {
+		mounts = append(mounts, lib.Path)
+	}
+	quoted := make([]string, len(mounts))
+	for i, m := range mounts {
+		quoted[i] = device.ShellQuote(m)
+	}
+	output, err = client.RunCommand("df -B1 --output=target,size,used,avail " + strings.Join(quoted, " ") + " 2>/dev/null")
+	if err != nil && output == "" {
+		slog.Warn("Failed to read disk usage", "error", err)
+	}
+	usage.Disks = parseDiskFree(output)
+
+	sort.Slice(usage.Games, func(i, j int) bool { return usage.Games[i].Size > usage.Games[j].Size })
+	return usage, nil
+}
+
+// parseDiskUsage parses "size<TAB>path" lines of du -sb
+func parseDiskUsage(output string) []StorageEntry {
+	var entries []StorageEntry
+	for _, line := range strings.Split(output, "\n") {
+		size, name, ok := strings.Cut(strings.TrimRight(line, "\r"), "\t")
+		if !ok {
+			continue
+		}
+		n, err := strconv.ParseInt(size, 10, 64)
+		if err != nil {
+			continue
+		}
+		entries = append(entries, StorageEntry{Path: name, Size: n})
+	}
+	return entries
+}
+
+// parseLibraryFolders extracts the library paths of `"path" "<dir>"` lines
+// grepped from libraryfolders.vdf
+func parseLibraryFolders(output string) []string {
+	var libs []string
+	seen := make(map[string]bool)
+	for _, line := range strings.Split(output, "\n") {
+		_, value, ok := strings.Cut(line, `"path"`)
+		if !ok {
+			continue
+		}
+		lib := strings.Trim(strings.TrimSpace(value), `"`)
+		lib = strings.ReplaceAll(lib, `\\`, `\`)
+		if lib != "" && !seen[lib] {
+			seen[lib] = true
+			libs = append(libs, lib)
+		}
+	}
+	return libs
+}
+
+// parseDiskFree parses df output, one entry per distinct mount point
+func parseDiskFree(output string) []DiskUsage {
+	disks := []DiskUsage{}
+	seen := make(map[string]bool)
+	for _, line := range strings.Split(output, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) != 4 || seen[fields[0]] {
+			continue
+		}
+		total, err1 := strconv.ParseInt(fields[1], 10, 64)
+		used, err2 := strconv.ParseInt(fields[2], 10, 64)
+		free, err3 := strconv.ParseInt(fields[3], 10, 64)
+		if err1 != nil || err2 != nil || err3 != nil {
+			continue // header
+		}
+		seen[fields[0]] = true
+		disks = append(disks, DiskUsage{Mount: fields[0], Total: total, Used: used, Free: free})
+	}
+	return disks
+}
@@ -0,0 +1,197 @@
+package main
+
+import (
+	"archive/zip"
+	"bytes"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	goruntime "runtime"
+	"strings"
+	"time"
+
+	"github.com/wailsapp/wails/v2/pkg/runtime"
+
+	"github.com/lobinuxsoft/capydeploy/pkg/config"
+	"github.com/lobinuxsoft/capydeploy/pkg/logging"
+)
+
+// =============================================================================
+// Support Bundle
+// =============================================================================
+
+// deployLogName is the transfer log of the last deploy, next to the hub logs
+const deployLogName = "last-deploy.log"
+
+// startDeployLog starts the transfer log of a deploy, replacing the last one
+func (a *App) startDeployLog(header string) {
+	dir, err := logDir()
+	if err != nil {
+		return
+	}
+	file, err := os.Create(filepath.Join(dir, deployLogName))
+	if err != nil {
+		slog.Warn("Failed to create deploy log", "error", err)
+		return
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if a.deployLog != nil {
+		a.deployLog.Close()
+	}
+	a.deployLog = file
+	fmt.Fprintf(file, "%s %s\n", time.Now().Format(time.RFC3339), header)
+}
+
+// writeDeployLog records deploy progress in the transfer log and closes it
+// when the deploy is done. Called with a.mu held.
+func (a *App) writeDeployLog(progress UploadProgress) {
+	if a.deployLog == nil {
+		return
+	}
+	status := progress.Status
+	if progress.Error != "" {
+		status = strings.TrimSpace(status + " ERROR " + progress.Error)
+	}
+	fmt.Fprintf(a.deployLog, "%s %3.0f%% %s\n", time.Now().Format("15:04:05.000"), progress.Progress*100, status)
+	if progress.Done {
+		a.deployLog.Close()
+		a.deployLog = nil
+	}
+}
+
+// CreateSupportBundle writes a zip with what an issue report needs: the hub
+// logs, the configuration without secrets, the transfer log of the last deploy
+// and a description of the system. Returns the saved path, or "" if the dialog
+// was cancelled.
+func (a *App) CreateSupportBundle() (string, error) {
+	localPath, err := runtime.SaveFileDialog(a.ctx, runtime.SaveDialogOptions{
+		Title:           "Create Support Bundle",
+		DefaultFilename: fmt.Sprintf("capydeploy-support-%s.zip", time.Now().Format("2006-01-02-150405")),
+		Filters:         []runtime.FileFilter{{DisplayName: "Zip files", Pattern: "*.zip"}},
+	})
+	if err != nil || localPath == "" {
+		return "", err
+	}
+
+	data, err := a.supportBundle()
+	if err != nil {
+		return "", err
+	}
+	if err := os.WriteFile(localPath, data, 0600); err != nil {
+		return "", fmt.Errorf("failed to write %s: %w", localPath, err)
+	}
+	slog.Info("Support bundle created", "path", localPath)
+	return localPath, nil
+}
+
+// supportBundle builds the support bundle zip. Parts that can't be read are
+// listed in environment.txt instead of failing the whole bundle.
+func (a *App) supportBundle() ([]byte, error) {
+	var missing []string
+	var secrets []string
+	if cfg, err := config.Load(); err == nil {
+		secrets = cfg.Secrets()
+	} else {
+		missing = append(missing, fmt.Sprintf("secrets could not be scrubbed from the logs: %v", err))
+	}
+
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	add := func(name string, data []byte) error {
+		w, err := zw.CreateHeader(&zip.FileHeader{Name: name, Method: zip.Deflate, Modified: time.Now()})
+		if err != nil {
+			return err
+		}
+		_, err = w.Write(data)
+		return err
+	}
+
+	// Logs, newest first, scrubbed of any secret that made it into a message
+	if dir, err := logDir(); err == nil {
+		names := []string{"hub.log"}
+		for i := 1; i <= logging.DefaultBackups; i++ {
+			names = append(names, fmt.Sprintf("hub.log.%d", i))
+		}
+		names = append(names, deployLogName)
+		for _, name := range names {
+			data, err := os.ReadFile(filepath.Join(dir, name))
+			if err != nil {
+				if !os.IsNotExist(err) {
+					missing = append(missing, fmt.Sprintf("%s: %v", name, err))
+				}
+				continue
+			}
+			if err := add("logs/"+name, scrubSecrets(data, secrets)); err != nil {
+				return nil, err
+			}
+		}
+	} else {
+		missing = append(missing, fmt.Sprintf("logs: %v", err))
+	}
+
+	// The same shareable copy as Export Configuration
+	if data, err := config.ExportBundle(); err == nil {
+		if err := add("config.json", data); err != nil {
+			return nil, err
+		}
+	} else {
+		missing = append(missing, fmt.Sprintf("config.json: %v", err))
+	}
+
+	if err := add("environment.txt", []byte(a.environmentInfo(missing))); err != nil {
+		return nil, err
+	}
+	if err := zw.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// environmentInfo describes the hub and the system it runs on
+func (a *App) environmentInfo(missing []string) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "CapyDeploy Hub %s\n", Version)
+	fmt.Fprintf(&b, "Created: %s\n", time.Now().Format(time.RFC3339))
+	fmt.Fprintf(&b, "OS: %s/%s\n", goruntime.GOOS, goruntime.GOARCH)
+	fmt.Fprintf(&b, "Go: %s\n", goruntime.Version())
+	if goruntime.GOOS == "linux" {
+		fmt.Fprintf(&b, "Desktop: %s (%s)\n", os.Getenv("XDG_CURRENT_DESKTOP"), os.Getenv("XDG_SESSION_TYPE"))
+	}
+	fmt.Fprintf(&b, "System tray: %v\n", a.IsTrayAvailable())
+	fmt.Fprintf(&b, "Offline artwork: %v\n", a.isOffline())
+
+	status := a.GetConnectionStatus()
+	if status.Connected {
+		fmt.Fprintf(&b, "Connected device: %s (%s:%d)\n", status.DeviceName, status.Host, status.Port)
+	} else {
+		fmt.Fprintln(&b, "Connected device: none")
+	}
+	a.mu.RLock()
+	if a.uploadStatus != nil {
+		fmt.Fprintf(&b, "Last deploy: %s", a.uploadStatus.Status)
+		if a.uploadStatus.Error != "" {
+			fmt.Fprintf(&b, " (error: %s)", a.uploadStatus.Error)
+		}
+		fmt.Fprintln(&b)
+	}
+	a.mu.RUnlock()
+
+	if len(missing) > 0 {
+		fmt.Fprintln(&b, "\nNot included:")
+		for _, m := range missing {
+			fmt.Fprintf(&b, "  %s\n", m)
+		}
+	}
+	return b.String()
+}
+
+// scrubSecrets replaces every secret in data
+func scrubSecrets(data []byte, secrets []string) []byte {
+	for _, secret := range secrets {
+		data = bytes.ReplaceAll(data, []byte(secret), []byte("[redacted]"))
+	}
+	return data
+}
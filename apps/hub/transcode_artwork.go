@@ -0,0 +1,110 @@
+package main
+
+import (
+	"encoding/base64"
+	"fmt"
+	"strings"
+
+	"github.com/lobinuxsoft/capydeploy/internal/shortcuts"
+	"github.com/lobinuxsoft/capydeploy/pkg/artwork"
+	"github.com/lobinuxsoft/capydeploy/pkg/config"
+	"github.com/lobinuxsoft/capydeploy/pkg/steam"
+)
+
+// =============================================================================
+// Animated Artwork Transcoding
+// =============================================================================
+
+// maxTranscodedSize bounds converted animations, which are much larger than the WebP source
+const maxTranscodedSize = 100 * 1024 * 1024
+
+// GetArtworkToTranscode returns the selected images that are animated WebP files in
+// a slot where Steam only animates APNG, and have not been converted yet.
+// The frontend converts them and hands the result to SaveTranscodedArtwork.
+func (a *App) GetArtworkToTranscode(selection config.ArtworkSelection) ([]string, error) {
+	urls := []string{}
+	if a.generated == nil {
+		return urls, nil
+	}
+
+	for _, slot := range []struct {
+		url     string
+		artType steam.ArtworkType
+	}{
+		{selection.HeroImage, steam.ArtworkHero},
+		{selection.LogoImage, steam.ArtworkLogo},
+	} {
+		if _, done := a.generated.Transcoded(slot.url); done {
+			continue
+		}
+		if a.needsTranscode(slot.url, slot.artType) {
+			urls = append(urls, slot.url)
+		}
+	}
+	return urls, nil
+}
+
+// SaveTranscodedArtwork stores the APNG the frontend converted from sourceURL
+func (a *App) SaveTranscodedArtwork(sourceURL, dataURL string) error {
+	if a.generated == nil {
+		return fmt.Errorf("generated artwork storage unavailable")
+	}
+
+	const prefix = "data:image/png;base64,"
+	if !strings.HasPrefix(dataURL, prefix) {
+		return fmt.Errorf("expected a PNG data URL")
+	}
+	encoded := strings.TrimPrefix(dataURL, prefix)
+	if base64.StdEncoding.DecodedLen(len(encoded)) > maxTranscodedSize {
+		return fmt.Errorf("converted image too large")
+	}
+
+	data, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return fmt.Errorf("invalid image data: %w", err)
+	}
+	_, err = a.generated.SaveTranscoded(sourceURL, data)
+	return err
+}
+
+// resolveTranscoded swaps animated WebP images in slots that need APNG for their
+// converted copy. Returns a warning for each image that will show up static.
+func (a *App) resolveTranscoded(art *shortcuts.ArtworkConfig) []string {
+	var warnings []string
+	for _, slot := range []struct {
+		url     *string
+		name    string
+		artType steam.ArtworkType
+	}{
+		{&art.HeroImage, "hero", steam.ArtworkHero},
+		{&art.LogoImage, "logo", steam.ArtworkLogo},
+	} {
+		if !a.needsTranscode(*slot.url, slot.artType) {
+			continue
+		}
+		if a.generated != nil {
+			if converted, ok := a.generated.Transcoded(*slot.url); ok {
+				*slot.url = converted
+				continue
+			}
+		}
+		warnings = append(warnings, fmt.Sprintf("Animated WebP %s was not converted to APNG and will be static on the device", slot.name))
+	}
+	return warnings
+}
+
+// needsTranscode reports whether url is an animated WebP that Steam won't animate in the slot
+func (a *App) needsTranscode(url string, artType steam.ArtworkType) bool {
+	if url == "" || artwork.IsGenerated(url) || steam.AnimatesWebP(artType) || artworkExt(url, "") != "webp" {
+		return false
+	}
+
+	var data []byte
+	var err error
+	if a.isOffline() {
+		data, _, err = a.localImage(url)
+	} else {
+		data, _, err = a.imageFetcher.Fetch(url)
+	}
+	return err == nil && artwork.IsAnimatedWebP(data)
+}
@@ -0,0 +1,169 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+
+	"github.com/wailsapp/wails/v2/pkg/options"
+	"github.com/wailsapp/wails/v2/pkg/runtime"
+
+	"github.com/lobinuxsoft/capydeploy/internal/tray"
+	"github.com/lobinuxsoft/capydeploy/pkg/config"
+)
+
+// =============================================================================
+// System Tray
+// =============================================================================
+
+// startTray shows the tray icon. Without one, closing the window quits as before.
+func (a *App) startTray() {
+	t, err := tray.New(tray.Options{
+		ID:         "capydeploy-hub",
+		Title:      "CapyDeploy Hub",
+		Tooltip:    "CapyDeploy Hub",
+		OnActivate: a.showWindow,
+	})
+	if err != nil {
+		slog.Warn("System tray not available", "error", err)
+		return
+	}
+	a.mu.Lock()
+	a.tray = t
+	a.mu.Unlock()
+	a.refreshTray()
+}
+
+// beforeClose keeps the Hub running in the tray when the window is closed,
+// so deploys and the automation API carry on in the background
+func (a *App) beforeClose(ctx context.Context) bool {
+	a.mu.RLock()
+	inTray := a.tray != nil && !a.quitting
+	a.mu.RUnlock()
+	if !inTray {
+		return false
+	}
+	if quit, err := config.GetQuitOnClose(); err != nil || quit {
+		return false
+	}
+	runtime.WindowHide(ctx)
+	return true
+}
+
+// showWindow brings the window back from the tray
+func (a *App) showWindow() {
+	runtime.WindowShow(a.ctx)
+}
+
+// onSecondInstance shows the running Hub instead of starting another one
+func (a *App) onSecondInstance(data options.SecondInstanceData) {
+	a.showWindow()
+}
+
+// quit closes the Hub for good, even when closing to the tray
+func (a *App) quit() {
+	a.mu.Lock()
+	a.quitting = true
+	a.mu.Unlock()
+	runtime.Quit(a.ctx)
+}
+
+// refreshTray rebuilds the tray menu from the connection, the running deploy
+// and the recently deployed game setups
+func (a *App) refreshTray() {
+	a.mu.RLock()
+	t := a.tray
+	a.mu.RUnlock()
+	if t == nil {
+		return
+	}
+	deploying := a.deploying()
+
+	status := a.GetConnectionStatus()
+	connection := "No device connected"
+	if status.Connected {
+		connection = "Connected to " + status.DeviceName
+	}
+	t.SetTooltip("CapyDeploy Hub - " + connection)
+
+	items := []tray.Item{
+		{Label: "Open CapyDeploy Hub", OnClick: a.showWindow},
+		{},
+		{Label: connection},
+	}
+	if deploying {
+		items = append(items, tray.Item{Label: "Deploy in progress..."}, tray.Item{Label: "Cancel Deploy", OnClick: a.CancelUpload})
+	}
+
+	if setups := recentSetups(); len(setups) > 0 {
+		items = append(items, tray.Item{}, tray.Item{Label: "Quick Deploy"})
+		for _, setup := range setups {
+			item := tray.Item{Label: "    " + setup.Name}
+			if status.Connected && !deploying {
+				id := setup.ID
+				item.OnClick = func() { a.quickDeploy(id) }
+			}
+			items = append(items, item)
+		}
+	}
+
+	items = append(items, tray.Item{}, tray.Item{Label: "Quit", OnClick: a.quit})
+	t.SetMenu(items)
+}
+
+// recentSetups returns the recently deployed game setups that still exist
+func recentSetups() []config.GameSetup {
+	ids, err := config.GetRecentDeploys()
+	if err != nil {
+		return nil
+	}
+	setups, err := config.GetGameSetups()
+	if err != nil {
+		return nil
+	}
+	var recent []config.GameSetup
+	for _, id := range ids {
+		for _, s := range setups {
+			if s.ID == id {
+				recent = append(recent, s)
+				break
+			}
+		}
+	}
+	return recent
+}
+
+// quickDeploy deploys a game setup from the tray menu
+func (a *App) quickDeploy(setupID string) {
+	if a.deploying() {
+		return
+	}
+	if err := a.UploadGame(setupID); err != nil {
+		slog.Warn("Quick deploy failed", "setup", setupID, "error", err)
+		a.emitUploadProgress(UploadProgress{Error: fmt.Sprintf("Quick deploy failed: %v", err), Done: true})
+		return
+	}
+	slog.Info("Deploy started from the tray", "setup", setupID)
+}
+
+// =============================================================================
+// Background Settings
+// =============================================================================
+
+// GetQuitOnClose reports whether closing the window quits the Hub instead of
+// keeping it in the tray
+func (a *App) GetQuitOnClose() (bool, error) {
+	return config.GetQuitOnClose()
+}
+
+// SetQuitOnClose saves whether closing the window quits the Hub
+func (a *App) SetQuitOnClose(quit bool) error {
+	return config.SetQuitOnClose(quit)
+}
+
+// IsTrayAvailable reports whether the Hub has an icon in the system tray
+func (a *App) IsTrayAvailable() bool {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	return a.tray != nil
+}
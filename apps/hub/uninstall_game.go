@@ -0,0 +1,250 @@
+package main
+
+import (
+	"fmt"
+	"log/slog"
+	"path"
+	"strconv"
+	"strings"
+
+	"github.com/lobinuxsoft/capydeploy/internal/deploy"
+	"github.com/lobinuxsoft/capydeploy/internal/device"
+	"github.com/lobinuxsoft/capydeploy/internal/shortcuts"
+	"github.com/lobinuxsoft/capydeploy/pkg/config"
+	"github.com/lobinuxsoft/capydeploy/pkg/steam"
+)
+
+// =============================================================================
+// Game Uninstall
+// =============================================================================
+
+// UninstallPlan lists everything uninstalling a game removes from the device
+type UninstallPlan struct {
+	Name string `json:"name"`
+	Path string `json:"path"`
+	Size int64  `json:"size"` // bytes
+	// Name of the Steam shortcut that launches the game, if any
+	Shortcut  string   `json:"shortcut,omitempty"`
+	AppID     uint32   `json:"appId,omitempty"`
+	GridFiles []string `json:"gridFiles"`
+	// Shortcuts of the game's other executables, e.g. a dedicated server
+	ExtraShortcuts []string `json:"extraShortcuts,omitempty"`
+	// Proton prefix of the shortcut, only removed when asked for
+	CompatData     string `json:"compatData,omitempty"`
+	CompatDataSize int64  `json:"compatDataSize"`
+}
+
+// GetUninstallPlan returns what UninstallGame would remove for the game in gamePath
+func (a *App) GetUninstallPlan(gamePath string) (*UninstallPlan, error) {
+	client, deviceCfg, err := a.connectedClient()
+	if err != nil {
+		return nil, err
+	}
+	return uninstallPlan(client, remoteConfig(client, deviceCfg), gamePath)
+}
+
+// UninstallGame removes a game's files, its Steam shortcut and grid artwork, and
+// optionally its Proton prefix
+func (a *App) UninstallGame(gamePath string, removeCompatData bool) error {
+	client, deviceCfg, err := a.connectedClient()
+	if err != nil {
+		return err
+	}
+	remoteCfg := remoteConfig(client, deviceCfg)
+
+	// The plan is rebuilt here rather than trusted from the frontend
+	plan, err := uninstallPlan(client, remoteCfg, gamePath)
+	if err != nil {
+		return err
+	}
+
+	if plan.Shortcut != "" {
+		if err := shortcuts.RemoveShortcut(remoteCfg, plan.Shortcut); err != nil {
+			return fmt.Errorf("failed to remove shortcut: %w", err)
+		}
+	}
+	for _, name := range plan.ExtraShortcuts {
+		if err := shortcuts.RemoveShortcut(remoteCfg, name); err != nil {
+			slog.Warn("Failed to remove shortcut", "name", name, "error", err)
+		}
+	}
+
+	for _, file := range plan.GridFiles {
+		if err := client.Remove(file); err != nil {
+			slog.Warn("Failed to remove file", "file", file, "error", err)
+		}
+	}
+
+	if _, err := client.RunCommand(fmt.Sprintf("rm -rf -- %s", device.ShellQuote(plan.Path))); err != nil {
+		return fmt.Errorf("failed to delete game files: %w", err)
+	}
+
+	if removeCompatData && plan.CompatData != "" {
+		if _, err := client.RunCommand(fmt.Sprintf("rm -rf -- %s", device.ShellQuote(plan.CompatData))); err != nil {
+			return fmt.Errorf("failed to delete Proton prefix: %w", err)
+		}
+	}
+
+	shortcuts.RefreshSteamLibrary(remoteCfg)
+	return nil
+}
+
+func uninstallPlan(client *device.Client, remoteCfg *shortcuts.RemoteConfig, gamePath string) (*UninstallPlan, error) {
+	homeDir, err := client.GetHomeDir()
+	if err != nil {
+		return nil, err
+	}
+	gamePath, err = validateGamePath(client, gamePath, homeDir)
+	if err != nil {
+		return nil, err
+	}
+	if !client.FileExists(gamePath) {
+		return nil, fmt.Errorf("game directory not found: %s", gamePath)
+	}
+
+	plan := &UninstallPlan{Name: path.Base(gamePath), Path: gamePath, GridFiles: []string{}}
+	plan.Size = remoteSize(client, gamePath)
+
+	game := &InstalledGame{Name: plan.Name, Path: gamePath}
+	if manifest, ok := deploy.ReadManifest(client, gamePath); ok {
+		game.AppID = manifest.AppID
+		plan.ExtraShortcuts = manifest.ExtraShortcuts
+	}
+	if list, err := shortcuts.ListShortcuts(remoteCfg); err == nil {
+		if sc := gameShortcut(list, game); sc != nil {
+			plan.Shortcut = sc.Name
+			game.AppID = uint32(sc.AppID)
+		}
+	}
+	plan.AppID = game.AppID
+	if plan.AppID == 0 {
+		return plan, nil
+	}
+
+	plan.GridFiles = gridFilesFor(client, plan.AppID)
+
+	compatData := deploy.CompatDataPath(homeDir, plan.AppID)
+	if client.FileExists(compatData) {
+		plan.CompatData = compatData
+		plan.CompatDataSize = remoteSize(client, compatData)
+	}
+	return plan, nil
+}
+
+// validateGamePath checks a game path before it is deleted or changed: it must
+// be strictly inside a folder games are deployed to, or hold the manifest of a
+// deploy for games deployed elsewhere. Returns the cleaned path.
+func validateGamePath(client *device.Client, gamePath, homeDir string) (string, error) {
+	gamePath, err := cleanGamePath(gamePath, homeDir)
+	if err != nil {
+		return "", err
+	}
+	if inGameRoot(gamePath, gameRoots(homeDir)) {
+		return gamePath, nil
+	}
+	for _, name := range []string{deploy.ManifestName, deploy.LegacyManifestName} {
+		if client.FileExists(path.Join(gamePath, name)) {
+			return gamePath, nil
+		}
+	}
+	return "", fmt.Errorf("%s is not a deployed game", gamePath)
+}
+
+// cleanGamePath rejects paths that would delete more than a game directory,
+// such as the root, the home directory or one of its parents
+func cleanGamePath(gamePath, homeDir string) (string, error) {
+	if gamePath == "" || !strings.HasPrefix(gamePath, "/") {
+		return "", fmt.Errorf("invalid game path: %q", gamePath)
+	}
+	gamePath = path.Clean(gamePath)
+	if holdsHome(gamePath, homeDir) {
+		return "", fmt.Errorf("refusing to delete %s", gamePath)
+	}
+	return gamePath, nil
+}
+
+// holdsHome reports whether dir is the root, the home directory or one of its parents
+func holdsHome(dir, homeDir string) bool {
+	return dir == "/" || strings.HasPrefix(path.Clean(homeDir)+"/", dir+"/")
+}
+
+// gameRoots returns the folders games are deployed to on the device: the
+// default remote path and the remote path of every setup, with ~ expanded.
+// Folders holding the home directory are left out, anything would be a game.
+func gameRoots(homeDir string) []string {
+	paths := []string{config.DefaultRemotePath}
+	if cfg, err := config.Load(); err == nil {
+		paths = append(paths, cfg.DefaultRemotePath)
+		for _, setup := range cfg.GameSetups {
+			paths = append(paths, setup.RemotePath)
+		}
+	} else {
+		slog.Warn("Failed to load game folders", "error", err)
+	}
+
+	var roots []string
+	for _, p := range paths {
+		if strings.HasPrefix(p, "~") {
+			p = strings.Replace(p, "~", homeDir, 1)
+		}
+		if !strings.HasPrefix(p, "/") {
+			continue
+		}
+		if p = path.Clean(p); !holdsHome(p, homeDir) {
+			roots = append(roots, p)
+		}
+	}
+	return roots
+}
+
+// inGameRoot reports whether gamePath is strictly inside one of roots
+func inGameRoot(gamePath string, roots []string) bool {
+	for _, root := range roots {
+		if strings.HasPrefix(gamePath, root+"/") {
+			return true
+		}
+	}
+	return false
+}
+
+// gridFilesFor returns the artwork and logo position files of an app in every
+// Steam user's grid directory
+func gridFilesFor(client *device.Client, appID uint32) []string {
+	gridDirs, err := shortcuts.GridDirs(client)
+	if err != nil {
+		return nil
+	}
+
+	prefixes := []string{steam.LogoPositionFilename(appID)}
+	for _, slot := range artworkSlots {
+		prefixes = append(prefixes, strings.TrimSuffix(steam.ArtworkFilename(appID, slot.artType, "x"), "x"))
+	}
+
+	var files []string
+	for _, gridDir := range gridDirs {
+		names, err := client.ListDir(gridDir)
+		if err != nil {
+			continue
+		}
+		for _, name := range names {
+			for _, prefix := range prefixes {
+				// The logo position file is matched whole, artwork by extension
+				if name == prefix || (strings.HasPrefix(name, prefix) && isImageExt(strings.TrimPrefix(name, prefix))) {
+					files = append(files, path.Join(gridDir, name))
+					break
+				}
+			}
+		}
+	}
+	return files
+}
+
+// remoteSize returns the disk usage of a remote path in bytes, or 0 if unknown
+func remoteSize(client *device.Client, remotePath string) int64 {
+	output, err := client.RunCommand(fmt.Sprintf("du -sb -- %s 2>/dev/null | cut -f1", device.ShellQuote(remotePath)))
+	if err != nil {
+		return 0
+	}
+	size, _ := strconv.ParseInt(strings.TrimSpace(output), 10, 64)
+	return size
+}
@@ -0,0 +1,54 @@
+package main
+
+import "testing"
+
+func TestCleanGamePath(t *testing.T) {
+	tests := []struct {
+		name    string
+		path    string
+		want    string
+		wantErr bool
+	}{
+		{"game folder", "/home/deck/devkit-games/MyGame", "/home/deck/devkit-games/MyGame", false},
+		{"cleaned", "/home/deck/devkit-games/MyGame/", "/home/deck/devkit-games/MyGame", false},
+		{"empty", "", "", true},
+		{"relative", "devkit-games/MyGame", "", true},
+		{"root", "/", "", true},
+		{"home", "/home/deck", "", true},
+		{"home parent", "/home", "", true},
+		{"home through dots", "/home/deck/devkit-games/..", "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := cleanGamePath(tt.path, "/home/deck")
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("cleanGamePath(%q) error = %v, wantErr %v", tt.path, err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("cleanGamePath(%q) = %q, want %q", tt.path, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestInGameRoot(t *testing.T) {
+	roots := []string{"/home/deck/devkit-games", "/run/media/sd/games"}
+	tests := []struct {
+		path string
+		want bool
+	}{
+		{"/home/deck/devkit-games/MyGame", true},
+		{"/run/media/sd/games/Other", true},
+		{"/home/deck/devkit-games/MyGame/Saves", true},
+		{"/home/deck/devkit-games", false},
+		{"/home/deck/devkit-games-old/MyGame", false},
+		{"/home/deck/.steam", false},
+		{"/home/deck/Documents", false},
+		{"/usr", false},
+	}
+	for _, tt := range tests {
+		if got := inGameRoot(tt.path, roots); got != tt.want {
+			t.Errorf("inGameRoot(%q) = %v, want %v", tt.path, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,264 @@
+package main
+
+import (
+	"cmp"
+	"fmt"
+	"log/slog"
+	goruntime "runtime"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/wailsapp/wails/v2/pkg/runtime"
+
+	"github.com/lobinuxsoft/capydeploy/pkg/config"
+)
+
+// =============================================================================
+// Updates
+// =============================================================================
+
+// hubReleasesURL lists the CapyDeploy releases on GitHub
+const hubReleasesURL = "https://api.github.com/repos/lobinuxsoft/bazzite-devkit/releases"
+
+// hubReleasePrefix is where every release page and download must be
+const hubReleasePrefix = "https://github.com/lobinuxsoft/bazzite-devkit/releases/"
+
+// maxChangelog bounds the release notes shown in the update banner
+const maxChangelog = 4000
+
+// UpdateInfo describes a Hub release newer than the running one
+type UpdateInfo struct {
+	Version        string    `json:"version"`
+	CurrentVersion string    `json:"currentVersion"`
+	PublishedAt    time.Time `json:"publishedAt"`
+	// Notes of every release since the running one, newest first
+	Changelog string `json:"changelog"`
+
+	// Release page, or the Hub download for this system when the release has one
+	downloadURL string
+}
+
+type hubRelease struct {
+	TagName     string    `json:"tag_name"`
+	Body        string    `json:"body"`
+	HTMLURL     string    `json:"html_url"`
+	Draft       bool      `json:"draft"`
+	Prerelease  bool      `json:"prerelease"`
+	PublishedAt time.Time `json:"published_at"`
+	Assets      []struct {
+		Name string `json:"name"`
+		URL  string `json:"browser_download_url"`
+	} `json:"assets"`
+}
+
+// checkUpdatesOnStartup looks for a newer release when the user opted in and
+// announces it with an "update:available" event
+func (a *App) checkUpdatesOnStartup() {
+	if enabled, err := config.GetCheckForUpdates(); err != nil || !enabled {
+		return
+	}
+	update, err := a.CheckForUpdate()
+	if err != nil {
+		slog.Warn("Update check failed", "error", err)
+		return
+	}
+	if update == nil {
+		return
+	}
+	if skipped, _ := config.GetSkippedUpdate(); skipped == update.Version {
+		return
+	}
+	slog.Info("Update available", "version", update.Version, "current", Version)
+	runtime.EventsEmit(a.ctx, "update:available", update)
+}
+
+// CheckForUpdate looks up the latest release. Returns nil when the Hub is up
+// to date or is a development build.
+func (a *App) CheckForUpdate() (*UpdateInfo, error) {
+	current, ok := parseVersion(Version)
+	if !ok {
+		return nil, nil
+	}
+
+	var releases []hubRelease
+	if err := fetchGitHubJSON(hubReleasesURL+"?per_page=20", &releases); err != nil {
+		return nil, err
+	}
+
+	var update *UpdateInfo
+	var latest version
+	var notes []string
+	for _, r := range releases {
+		v, ok := parseVersion(r.TagName)
+		if r.Draft || r.Prerelease || !ok || compareVersions(v, current) <= 0 || !strings.HasPrefix(r.HTMLURL, hubReleasePrefix) {
+			continue
+		}
+		if update == nil || compareVersions(v, latest) > 0 {
+			latest = v
+			update = &UpdateInfo{
+				Version:        r.TagName,
+				CurrentVersion: Version,
+				PublishedAt:    r.PublishedAt,
+				downloadURL:    releaseDownload(r),
+			}
+		}
+		if body := strings.TrimSpace(r.Body); body != "" {
+			notes = append(notes, "## "+r.TagName+"\n\n"+body)
+		}
+	}
+	if update == nil {
+		a.setUpdate(nil)
+		return nil, nil
+	}
+
+	// GitHub lists releases newest first
+	changelog := strings.Join(notes, "\n\n")
+	if len(changelog) > maxChangelog {
+		changelog = strings.TrimSpace(changelog[:maxChangelog]) + "\n\n..."
+	}
+	update.Changelog = changelog
+	a.setUpdate(update)
+	return update, nil
+}
+
+// GetAvailableUpdate returns the newer release found by the last check, nil
+// if there is none
+func (a *App) GetAvailableUpdate() *UpdateInfo {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	return a.update
+}
+
+func (a *App) setUpdate(update *UpdateInfo) {
+	a.mu.Lock()
+	a.update = update
+	a.mu.Unlock()
+}
+
+// DownloadUpdate opens the download of the newer release in the browser
+func (a *App) DownloadUpdate() error {
+	update := a.GetAvailableUpdate()
+	if update == nil {
+		return fmt.Errorf("no update available")
+	}
+	runtime.BrowserOpenURL(a.ctx, update.downloadURL)
+	return nil
+}
+
+// SkipUpdate stops announcing a release on startup
+func (a *App) SkipUpdate(version string) error {
+	return config.SetSkippedUpdate(version)
+}
+
+// GetCheckForUpdates reports whether the Hub looks for a newer release on startup
+func (a *App) GetCheckForUpdates() (bool, error) {
+	return config.GetCheckForUpdates()
+}
+
+// SetCheckForUpdates saves whether the Hub looks for a newer release on startup
+func (a *App) SetCheckForUpdates(enabled bool) error {
+	return config.SetCheckForUpdates(enabled)
+}
+
+// GetVersion returns the version of the running Hub
+func (a *App) GetVersion() string {
+	return Version
+}
+
+// releaseDownload returns the Hub download of a release for this system,
+// or its release page
+func releaseDownload(r hubRelease) string {
+	for _, asset := range r.Assets {
+		name := strings.ToLower(asset.Name)
+		if strings.Contains(name, "hub") && strings.Contains(name, goruntime.GOOS) &&
+			strings.HasPrefix(asset.URL, hubReleasePrefix+"download/") {
+			return asset.URL
+		}
+	}
+	return r.HTMLURL
+}
+
+// version is a parsed release version
+type version struct {
+	parts []int
+	pre   []string // pre-release identifiers, empty for a release
+}
+
+// parseVersion reads a version such as "v1.4.2" or "1.5.0-rc.1". The build
+// suffix after "+" is ignored.
+func parseVersion(s string) (version, bool) {
+	s = strings.TrimPrefix(strings.TrimSpace(s), "v")
+	if i := strings.IndexByte(s, '+'); i >= 0 {
+		s = s[:i]
+	}
+	var v version
+	if i := strings.IndexByte(s, '-'); i >= 0 {
+		v.pre = strings.Split(s[i+1:], ".")
+		s = s[:i]
+		for _, id := range v.pre {
+			if id == "" {
+				return version{}, false
+			}
+		}
+	}
+	if s == "" {
+		return version{}, false
+	}
+	for _, field := range strings.Split(s, ".") {
+		n, err := strconv.Atoi(field)
+		if err != nil || n < 0 {
+			return version{}, false
+		}
+		v.parts = append(v.parts, n)
+	}
+	return v, true
+}
+
+// compareVersions returns -1, 0 or 1 as a is older, equal or newer than b.
+// Missing parts count as zero, so 1.2 equals 1.2.0. A pre-release is older
+// than its release, so 1.5.0-rc1 comes before 1.5.0.
+func compareVersions(a, b version) int {
+	for i := 0; i < len(a.parts) || i < len(b.parts); i++ {
+		var x, y int
+		if i < len(a.parts) {
+			x = a.parts[i]
+		}
+		if i < len(b.parts) {
+			y = b.parts[i]
+		}
+		if c := cmp.Compare(x, y); c != 0 {
+			return c
+		}
+	}
+	switch {
+	case len(a.pre) == 0 && len(b.pre) == 0:
+		return 0
+	case len(a.pre) == 0:
+		return 1
+	case len(b.pre) == 0:
+		return -1
+	}
+	for i := 0; i < len(a.pre) && i < len(b.pre); i++ {
+		if c := comparePreRelease(a.pre[i], b.pre[i]); c != 0 {
+			return c
+		}
+	}
+	return cmp.Compare(len(a.pre), len(b.pre))
+}
+
+// comparePreRelease compares pre-release identifiers the way semver does:
+// numbers by value and before words, words alphabetically
+func comparePreRelease(a, b string) int {
+	x, errA := strconv.Atoi(a)
+	y, errB := strconv.Atoi(b)
+	switch {
+	case errA == nil && errB == nil:
+		return cmp.Compare(x, y)
+	case errA == nil:
+		return -1
+	case errB == nil:
+		return 1
+	}
+	return strings.Compare(a, b)
+}
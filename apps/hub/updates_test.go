@@ -0,0 +1,81 @@
+package main
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestParseVersion(t *testing.T) {
+	tests := []struct {
+		in    string
+		parts []int
+		pre   []string
+		ok    bool
+	}{
+		{"v1.4.2", []int{1, 4, 2}, nil, true},
+		{"1.5.0", []int{1, 5, 0}, nil, true},
+		{" v2.0 ", []int{2, 0}, nil, true},
+		{"1.5.0-rc1", []int{1, 5, 0}, []string{"rc1"}, true},
+		{"v1.5.0-beta.2", []int{1, 5, 0}, []string{"beta", "2"}, true},
+		{"1.5.0+build.7", []int{1, 5, 0}, nil, true},
+		{"1.5.0-rc.1+build.7", []int{1, 5, 0}, []string{"rc", "1"}, true},
+		{"", nil, nil, false},
+		{"v", nil, nil, false},
+		{"dev", nil, nil, false},
+		{"1.x.0", nil, nil, false},
+		{"1.-2", nil, nil, false},
+		{"1.5.0-", nil, nil, false},
+		{"1.5.0-rc..1", nil, nil, false},
+		{"-rc1", nil, nil, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			v, ok := parseVersion(tt.in)
+			if ok != tt.ok {
+				t.Fatalf("parseVersion(%q) ok = %v, want %v", tt.in, ok, tt.ok)
+			}
+			if !slices.Equal(v.parts, tt.parts) || !slices.Equal(v.pre, tt.pre) {
+				t.Errorf("parseVersion(%q) = %v %q, want %v %q", tt.in, v.parts, v.pre, tt.parts, tt.pre)
+			}
+		})
+	}
+}
+
+func TestCompareVersions(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want int
+	}{
+		{"1.4.2", "1.4.2", 0},
+		{"1.2", "1.2.0", 0},
+		{"v1.4.2", "1.4.2", 0},
+		{"1.4.2", "1.4.3", -1},
+		{"1.10.0", "1.9.0", 1},
+		{"2.0.0", "1.99.99", 1},
+		{"1.5.0+build.7", "1.5.0", 0},
+		{"1.5.0-rc1", "1.5.0", -1},
+		{"1.5.0", "1.5.0-rc1", 1},
+		{"1.5.0-rc1", "1.4.9", 1},
+		{"1.5.0-rc1", "1.5.0-rc2", -1},
+		{"1.5.0-alpha", "1.5.0-beta", -1},
+		{"1.5.0-rc.2", "1.5.0-rc.10", -1},
+		{"1.5.0-1", "1.5.0-alpha", -1},
+		{"1.5.0-rc", "1.5.0-rc.1", -1},
+		{"1.5.0-rc.1", "1.5.0-rc.1", 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.a+"_"+tt.b, func(t *testing.T) {
+			a, ok := parseVersion(tt.a)
+			if !ok {
+				t.Fatalf("parseVersion(%q) failed", tt.a)
+			}
+			b, ok := parseVersion(tt.b)
+			if !ok {
+				t.Fatalf("parseVersion(%q) failed", tt.b)
+			}
+			if got := compareVersions(a, b); got != tt.want {
+				t.Errorf("compareVersions(%q, %q) = %d, want %d", tt.a, tt.b, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,55 @@
+package main
+
+import (
+	"time"
+
+	"github.com/lobinuxsoft/capydeploy/pkg/config"
+)
+
+// =============================================================================
+// Background Deploys
+// =============================================================================
+
+// UploadJob is the deploy running in the background, or the last one, shown
+// in the header whatever tab is open. It is sent as "upload:job" events with
+// each progress update.
+type UploadJob struct {
+	SetupID   string         `json:"setupId"`
+	Name      string         `json:"name"`
+	Devices   []string       `json:"devices"` // device names
+	StartedAt time.Time      `json:"startedAt"`
+	Progress  UploadProgress `json:"progress"`
+}
+
+// startUploadJob records the setup and devices of the deploy that starts, its
+// progress follows from emitUploadProgress
+func (a *App) startUploadJob(setup *config.GameSetup, devices []config.DeviceConfig) {
+	job := &UploadJob{SetupID: setup.ID, Name: setup.DeployName(), StartedAt: time.Now()}
+	for _, d := range devices {
+		job.Devices = append(job.Devices, d.Name)
+	}
+	a.mu.Lock()
+	a.uploadJob = job
+	a.mu.Unlock()
+}
+
+// GetUploadJob returns the running or last deploy, nil before the first one.
+// Tabs opened during a deploy pick up its progress from it.
+func (a *App) GetUploadJob() *UploadJob {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	return a.uploadJobLocked()
+}
+
+// uploadJobLocked returns a copy of the deploy with its latest progress. The
+// caller holds a.mu.
+func (a *App) uploadJobLocked() *UploadJob {
+	if a.uploadJob == nil {
+		return nil
+	}
+	job := *a.uploadJob
+	if a.uploadStatus != nil {
+		job.Progress = *a.uploadStatus
+	}
+	return &job
+}
@@ -0,0 +1,71 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/lobinuxsoft/capydeploy/internal/shortcuts"
+	"github.com/lobinuxsoft/capydeploy/pkg/artwork"
+	"github.com/lobinuxsoft/capydeploy/pkg/config"
+)
+
+// =============================================================================
+// Icon Upscaling
+// =============================================================================
+
+// GetIconUpscale returns the upscaling method for small icons ("" when disabled)
+func (a *App) GetIconUpscale() (string, error) {
+	return config.GetIconUpscale()
+}
+
+// SetIconUpscale saves the upscaling method for small icons
+func (a *App) SetIconUpscale(method string) error {
+	if !artwork.ValidUpscaleMethod(artwork.UpscaleMethod(method)) {
+		return fmt.Errorf("unknown upscale method: %s", method)
+	}
+	return config.SetIconUpscale(method)
+}
+
+// resolveIconUpscale swaps a small icon for an upscaled copy when icon upscaling
+// is enabled. Returns a warning when the icon could not be upscaled.
+func (a *App) resolveIconUpscale(art *shortcuts.ArtworkConfig) string {
+	if art.IconImage == "" || artwork.IsGenerated(art.IconImage) || a.generated == nil {
+		return ""
+	}
+
+	setting, err := config.GetIconUpscale()
+	method := artwork.UpscaleMethod(setting)
+	if err != nil || method == artwork.UpscaleNone {
+		return ""
+	}
+
+	if upscaled, ok := a.generated.Upscaled(art.IconImage, method); ok {
+		art.IconImage = upscaled
+		return ""
+	}
+
+	var data []byte
+	if a.isOffline() {
+		data, _, err = a.localImage(art.IconImage)
+	} else {
+		data, _, err = a.imageFetcher.Fetch(art.IconImage)
+	}
+	if err != nil {
+		return fmt.Sprintf("Icon could not be upscaled: %v", err)
+	}
+
+	scaled, err := artwork.UpscaleIcon(data, method)
+	if err != nil {
+		return fmt.Sprintf("Icon could not be upscaled: %v", err)
+	}
+	if scaled == nil {
+		// Already large enough
+		return ""
+	}
+
+	upscaled, err := a.generated.SaveUpscaled(art.IconImage, method, scaled)
+	if err != nil {
+		return fmt.Sprintf("Icon could not be upscaled: %v", err)
+	}
+	art.IconImage = upscaled
+	return ""
+}
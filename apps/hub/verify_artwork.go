@@ -0,0 +1,107 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/lobinuxsoft/capydeploy/internal/device"
+	"github.com/lobinuxsoft/capydeploy/internal/shortcuts"
+)
+
+// =============================================================================
+// Applied Artwork Verification
+// =============================================================================
+
+// verifyAttempts bounds how many times the grid folder is checked, since Steam
+// may still be writing the files when the apply command returns
+const (
+	verifyAttempts = 3
+	verifyDelay    = time.Second
+)
+
+// ArtworkCheck is the result of looking for an applied slot on the device
+type ArtworkCheck struct {
+	Slot  string `json:"slot"`
+	File  string `json:"file,omitempty"`
+	Size  int64  `json:"size"`
+	OK    bool   `json:"ok"`
+	Error string `json:"error,omitempty"`
+}
+
+// verifyArtwork checks that every slot set in art has a non-empty grid file
+// named after appID on the device
+func verifyArtwork(client *device.Client, appID uint32, art *shortcuts.ArtworkConfig) []ArtworkCheck {
+	if art == nil {
+		return nil
+	}
+
+	var checks []ArtworkCheck
+	for attempt := 1; attempt <= verifyAttempts; attempt++ {
+		checks = checkGridFiles(client, appID, art)
+		if len(failedSlots(checks)) == 0 {
+			break
+		}
+		if attempt < verifyAttempts {
+			time.Sleep(verifyDelay)
+		}
+	}
+	return checks
+}
+
+func checkGridFiles(client *device.Client, appID uint32, art *shortcuts.ArtworkConfig) []ArtworkCheck {
+	files, err := currentGridFiles(client, appID)
+
+	var checks []ArtworkCheck
+	for _, slot := range artworkSlots {
+		if *artworkSlotURL(art, slot.name) == "" {
+			continue
+		}
+
+		check := ArtworkCheck{Slot: slot.name, File: files[slot.name]}
+		switch {
+		case err != nil:
+			check.Error = fmt.Sprintf("grid folder not readable: %v", err)
+		case check.File == "":
+			check.Error = fmt.Sprintf("no %d file in the grid folder", appID)
+		default:
+			size, statErr := client.FileSize(check.File)
+			check.Size = size
+			switch {
+			case statErr != nil:
+				check.Error = statErr.Error()
+			case size == 0:
+				check.Error = "file is empty"
+			default:
+				check.OK = true
+			}
+		}
+		checks = append(checks, check)
+	}
+	return checks
+}
+
+// failedSlots describes the checks that failed, e.g. "logo (file is empty)"
+func failedSlots(checks []ArtworkCheck) []string {
+	var failed []string
+	for _, check := range checks {
+		if !check.OK {
+			failed = append(failed, fmt.Sprintf("%s (%s)", check.Slot, check.Error))
+		}
+	}
+	return failed
+}
+
+// artworkCheckSummary returns a one line report of the checks, and whether all passed
+func artworkCheckSummary(checks []ArtworkCheck) (string, bool) {
+	failed := failedSlots(checks)
+	if len(failed) > 0 {
+		return "Artwork missing on device: " + strings.Join(failed, ", "), false
+	}
+
+	slots := make([]string, 0, len(checks))
+	for _, check := range checks {
+		slots = append(slots, check.Slot)
+	}
+	return "Artwork verified: " + strings.Join(slots, ", "), true
+}
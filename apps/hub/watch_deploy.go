@@ -0,0 +1,203 @@
+package main
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/wailsapp/wails/v2/pkg/runtime"
+
+	"github.com/lobinuxsoft/capydeploy/internal/upload"
+)
+
+// =============================================================================
+// Watch Mode
+// =============================================================================
+
+// watchPollInterval is how often a watched folder is scanned. Comparing sizes
+// and modification times works the same on every platform the Hub runs on,
+// network shares included, where change notifications are unreliable.
+const watchPollInterval = 2 * time.Second
+
+// watchSettleTime is how long a watched folder must stay unchanged before it
+// is deployed, so an export that writes for a while deploys once
+const watchSettleTime = 3 * time.Second
+
+// WatchStatus describes the watch mode, sent as "watch:status" events
+type WatchStatus struct {
+	SetupID   string `json:"setupId"` // "" when no folder is watched
+	Changed   bool   `json:"changed"` // changes are waiting to settle or for the device
+	Deploying bool   `json:"deploying"`
+	// When the last automatic deploy finished and its error, if it failed
+	LastDeploy string `json:"lastDeploy,omitempty"`
+	Error      string `json:"error,omitempty"`
+}
+
+// StartWatchDeploy watches the folder of a game setup and deploys it to the
+// connected device, uploading only the changed files, whenever it changes.
+// Watching another setup stops watching the previous one.
+func (a *App) StartWatchDeploy(setupID string) error {
+	setup := findGameSetup(setupID)
+	if setup == nil {
+		return fmt.Errorf("game setup not found: %s", setupID)
+	}
+	if setup.Build != nil {
+		// The build would write to the folder and deploy again, endlessly
+		return fmt.Errorf("watch mode deploys the folder as it changes, turn off the build step of %s and export into its folder instead", setup.Name)
+	}
+	baseline, err := folderSignature(setup.LocalPath, setup.Exclude)
+	if err != nil {
+		return fmt.Errorf("failed to scan %s: %w", setup.LocalPath, err)
+	}
+
+	a.StopWatchDeploy()
+	ctx, cancel := context.WithCancel(a.ctx)
+	a.mu.Lock()
+	a.cancelWatch = cancel
+	a.watchStatus = WatchStatus{SetupID: setupID}
+	a.mu.Unlock()
+
+	slog.Info("Watching game folder", "setup", setup.Name, "path", setup.LocalPath)
+	a.emitWatchStatus()
+	go a.watchFolder(ctx, setupID, baseline)
+	return nil
+}
+
+// StopWatchDeploy stops watching. A deploy it started carries on.
+func (a *App) StopWatchDeploy() {
+	a.mu.Lock()
+	cancel := a.cancelWatch
+	a.cancelWatch = nil
+	a.watchStatus = WatchStatus{}
+	a.mu.Unlock()
+	if cancel != nil {
+		cancel()
+		a.emitWatchStatus()
+	}
+}
+
+// GetWatchStatus returns the state of the watch mode
+func (a *App) GetWatchStatus() WatchStatus {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	return a.watchStatus
+}
+
+// emitWatchStatus sends the watch status to the frontend
+func (a *App) emitWatchStatus() {
+	runtime.EventsEmit(a.ctx, "watch:status", a.GetWatchStatus())
+}
+
+// updateWatchStatus applies update to the watch status and sends it, unless
+// the setup is no longer watched
+func (a *App) updateWatchStatus(setupID string, update func(*WatchStatus)) {
+	a.mu.Lock()
+	if a.watchStatus.SetupID != setupID {
+		a.mu.Unlock()
+		return
+	}
+	update(&a.watchStatus)
+	a.mu.Unlock()
+	a.emitWatchStatus()
+}
+
+// watchFolder deploys a setup each time its folder changes and then stays
+// unchanged for watchSettleTime. deployed is the state of the folder on the
+// device when watching starts.
+func (a *App) watchFolder(ctx context.Context, setupID, deployed string) {
+	ticker := time.NewTicker(watchPollInterval)
+	defer ticker.Stop()
+
+	var pending string
+	var changedAt time.Time
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		// The setup is read again each time so edits to it apply
+		setup := findGameSetup(setupID)
+		if setup == nil {
+			slog.Info("Watched game setup was deleted, stopping")
+			a.StopWatchDeploy()
+			return
+		}
+		signature, err := folderSignature(setup.LocalPath, setup.Exclude)
+		if err != nil {
+			// The folder may be in the middle of being replaced
+			continue
+		}
+		if signature == deployed {
+			if pending != "" {
+				// Changed back before it was deployed
+				pending = ""
+				a.updateWatchStatus(setupID, func(s *WatchStatus) { s.Changed = false })
+			}
+			continue
+		}
+		if signature != pending {
+			pending, changedAt = signature, time.Now()
+			a.updateWatchStatus(setupID, func(s *WatchStatus) { s.Changed = true })
+			continue
+		}
+		if time.Since(changedAt) < watchSettleTime || a.deploying() {
+			continue
+		}
+		client, deviceCfg, err := a.connectedClient()
+		if err != nil {
+			// Deployed once a device connects
+			continue
+		}
+
+		a.updateWatchStatus(setupID, func(s *WatchStatus) { s.Deploying = true })
+		slog.Info("Watched folder changed, deploying", "setup", setup.Name)
+		a.performUpload(client, &deviceCfg, setup, !setup.FullUpload)
+		deployed, pending = signature, ""
+
+		var deployErr string
+		a.mu.RLock()
+		if a.uploadStatus != nil {
+			deployErr = a.uploadStatus.Error
+		}
+		a.mu.RUnlock()
+		a.updateWatchStatus(setupID, func(s *WatchStatus) {
+			s.Changed, s.Deploying = false, false
+			s.LastDeploy = time.Now().Format("15:04:05")
+			s.Error = deployErr
+		})
+	}
+}
+
+// folderSignature summarizes the files of a game folder that would be
+// deployed by their paths, sizes and modification times, or a game archive by
+// its own
+func folderSignature(root string, exclude []string) (string, error) {
+	if upload.IsArchive(root) {
+		info, err := os.Stat(root)
+		if err != nil {
+			return "", err
+		}
+		return fmt.Sprintf("%d\x00%d", info.Size(), info.ModTime().UnixNano()), nil
+	}
+	files, err := upload.ListFiles(root, exclude)
+	if err != nil {
+		return "", err
+	}
+	h := sha256.New()
+	for _, file := range files {
+		info, err := os.Lstat(file)
+		if err != nil {
+			return "", err
+		}
+		relPath, _ := filepath.Rel(root, file)
+		fmt.Fprintf(h, "%s\x00%d\x00%d\n", filepath.ToSlash(relPath), info.Size(), info.ModTime().UnixNano())
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
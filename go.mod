@@ -3,6 +3,7 @@ module github.com/lobinuxsoft/capydeploy
 go 1.24.0
 
 require (
+	github.com/godbus/dbus/v5 v5.1.0
 	github.com/google/uuid v1.6.0
 	github.com/hashicorp/mdns v1.0.5
 	github.com/pkg/sftp v1.13.6
@@ -17,7 +18,6 @@ replace github.com/shadowblip/steam-shortcut-manager => ./steam-shortcut-manager
 require (
 	github.com/bep/debounce v1.2.1 // indirect
 	github.com/go-ole/go-ole v1.3.0 // indirect
-	github.com/godbus/dbus/v5 v5.1.0 // indirect
 	github.com/gorilla/websocket v1.5.3 // indirect
 	github.com/jchv/go-winloader v0.0.0-20210711035445-715c2860da7e // indirect
 	github.com/kr/fs v0.1.0 // indirect
@@ -0,0 +1,161 @@
+@tool
+extends EditorPlugin
+
+# Adds a "Deploy" button to the editor toolbar that exports the configured
+# preset and asks the CapyDeploy Hub to deploy and launch it.
+#
+# Project settings (shared with the team):
+#   capydeploy/export_preset  Export preset to run, "Linux" by default
+#   capydeploy/game_setup     Hub game setup whose local folder holds the export
+#   capydeploy/device         Device name or host, empty uses the connected one
+# Editor settings (per machine, never committed):
+#   capydeploy/api_url        Hub automation API, http://127.0.0.1:17380 by default
+#   capydeploy/token          Token shown in the Hub under Settings > Automation API
+
+const POLL_SECONDS := 1.0
+
+var _button: Button
+var _http: HTTPRequest
+var _poll: Timer
+
+
+func _enter_tree() -> void:
+	_add_project_setting("capydeploy/export_preset", "Linux")
+	_add_project_setting("capydeploy/game_setup", ProjectSettings.get_setting("application/config/name", ""))
+	_add_project_setting("capydeploy/device", "")
+	_add_editor_setting("capydeploy/api_url", "http://127.0.0.1:17380")
+	_add_editor_setting("capydeploy/token", "")
+
+	_button = Button.new()
+	_button.text = "Deploy"
+	_button.tooltip_text = "Export and deploy to the device with CapyDeploy"
+	_button.pressed.connect(_deploy)
+	add_control_to_container(CONTAINER_TOOLBAR, _button)
+
+	_http = HTTPRequest.new()
+	add_child(_http)
+
+	_poll = Timer.new()
+	_poll.wait_time = POLL_SECONDS
+	_poll.timeout.connect(_check_status)
+	add_child(_poll)
+
+
+func _exit_tree() -> void:
+	remove_control_from_container(CONTAINER_TOOLBAR, _button)
+	_button.queue_free()
+	_http.queue_free()
+	_poll.queue_free()
+
+
+func _deploy() -> void:
+	var preset: String = ProjectSettings.get_setting("capydeploy/export_preset")
+	var export_path := _preset_export_path(preset)
+	if export_path.is_empty():
+		push_error("CapyDeploy: export preset \"%s\" not found or has no export path" % preset)
+		return
+
+	_set_busy("Exporting...")
+	var args := ["--headless", "--path", ProjectSettings.globalize_path("res://"), "--export-release", preset, export_path]
+	var output := []
+	if OS.execute(OS.get_executable_path(), args, output, true) != 0:
+		push_error("CapyDeploy: export failed\n%s" % "\n".join(output))
+		_set_idle()
+		return
+
+	var body := {"setup": ProjectSettings.get_setting("capydeploy/game_setup"), "launch": true}
+	var device: String = ProjectSettings.get_setting("capydeploy/device")
+	if not device.is_empty():
+		body["device"] = device
+
+	_set_busy("Deploying...")
+	var result: Dictionary = await _request("/api/v1/deploy", HTTPClient.METHOD_POST, JSON.stringify(body))
+	if result.has("error"):
+		push_error("CapyDeploy: %s" % result["error"])
+		_set_idle()
+		return
+	_poll.start()
+
+
+func _check_status() -> void:
+	if _http.get_http_client_status() != HTTPClient.STATUS_DISCONNECTED:
+		return
+	var status: Dictionary = await _request("/api/v1/deploy", HTTPClient.METHOD_GET, "")
+	if status.has("error") and not status.get("done", false):
+		# Request failure rather than a deploy error
+		push_error("CapyDeploy: %s" % status["error"])
+		_poll.stop()
+		_set_idle()
+		return
+
+	_button.text = "%d%%" % int(status.get("progress", 0.0) * 100)
+	if not status.get("done", false):
+		return
+	if not status.get("error", "").is_empty():
+		push_error("CapyDeploy: %s" % status["error"])
+	elif status.get("launched", false):
+		print("CapyDeploy: %s Game launched." % status.get("status", ""))
+	else:
+		# The hub launches the game right after reporting the deploy done
+		return
+	_poll.stop()
+	_set_idle()
+
+
+# _request sends a request to the hub and returns the decoded JSON response,
+# or {"error": ...} when it fails
+func _request(endpoint: String, method: int, body: String) -> Dictionary:
+	var settings := EditorInterface.get_editor_settings()
+	var url: String = settings.get_setting("capydeploy/api_url")
+	var headers := [
+		"Authorization: Bearer %s" % settings.get_setting("capydeploy/token"),
+		"Content-Type: application/json",
+	]
+	if _http.request(url.trim_suffix("/") + endpoint, headers, method, body) != OK:
+		return {"error": "could not reach the hub at %s" % url}
+
+	var response: Array = await _http.request_completed
+	if response[0] != HTTPRequest.RESULT_SUCCESS:
+		return {"error": "could not reach the hub at %s, is it running with the automation API enabled?" % url}
+	var data = JSON.parse_string(response[3].get_string_from_utf8())
+	if typeof(data) != TYPE_DICTIONARY:
+		return {"error": "unexpected response from the hub (HTTP %d)" % response[1]}
+	return data
+
+
+# _preset_export_path returns the absolute export path of a preset from
+# export_presets.cfg, or "" when the preset doesn't exist
+func _preset_export_path(preset: String) -> String:
+	var presets := ConfigFile.new()
+	if presets.load("res://export_presets.cfg") != OK:
+		return ""
+	for section in presets.get_sections():
+		if section.count(".") == 1 and presets.get_value(section, "name", "") == preset:
+			var export_path: String = presets.get_value(section, "export_path", "")
+			if export_path.is_empty():
+				return ""
+			return ProjectSettings.globalize_path("res://".path_join(export_path)) if export_path.is_relative_path() else export_path
+	return ""
+
+
+func _set_busy(text: String) -> void:
+	_button.disabled = true
+	_button.text = text
+
+
+func _set_idle() -> void:
+	_button.disabled = false
+	_button.text = "Deploy"
+
+
+func _add_project_setting(name: String, default_value: Variant) -> void:
+	if not ProjectSettings.has_setting(name):
+		ProjectSettings.set_setting(name, default_value)
+	ProjectSettings.set_initial_value(name, default_value)
+
+
+func _add_editor_setting(name: String, default_value: Variant) -> void:
+	var settings := EditorInterface.get_editor_settings()
+	if not settings.has_setting(name):
+		settings.set_setting(name, default_value)
+	settings.set_initial_value(name, default_value, false)
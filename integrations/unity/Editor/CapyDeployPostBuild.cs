@@ -0,0 +1,71 @@
+// Deploys every Linux or Windows player build to the device with the
+// CapyDeploy Hub as soon as Unity finishes building it.
+//
+// Environment variables (kept out of the project so they are never committed):
+//   CAPYDEPLOY_TOKEN    Token shown in the Hub under Settings > Automation API, required
+//   CAPYDEPLOY_API_URL  Hub automation API, http://127.0.0.1:17380 by default
+//   CAPYDEPLOY_DEVICE   Device name or host, empty uses the connected one
+//   CAPYDEPLOY_LAUNCH   "0" deploys without launching the game
+
+using System;
+using System.IO;
+using System.Text;
+using UnityEditor;
+using UnityEditor.Callbacks;
+using UnityEngine;
+using UnityEngine.Networking;
+
+public static class CapyDeployPostBuild
+{
+    [Serializable]
+    class BuildDeployRequest
+    {
+        public string folder;
+        public string name;
+        public string device;
+        public bool launch;
+    }
+
+    [PostProcessBuild(1000)]
+    public static void OnPostprocessBuild(BuildTarget target, string pathToBuiltProject)
+    {
+        if (target != BuildTarget.StandaloneLinux64 && target != BuildTarget.StandaloneWindows64)
+            return;
+
+        var token = Environment.GetEnvironmentVariable("CAPYDEPLOY_TOKEN");
+        if (string.IsNullOrEmpty(token))
+        {
+            Debug.Log("CapyDeploy: CAPYDEPLOY_TOKEN is not set, skipping deploy");
+            return;
+        }
+        var url = Environment.GetEnvironmentVariable("CAPYDEPLOY_API_URL");
+        if (string.IsNullOrEmpty(url))
+            url = "http://127.0.0.1:17380";
+
+        // Unity passes the player executable, the Hub wants the build folder
+        var body = new BuildDeployRequest
+        {
+            folder = Path.GetFullPath(Path.GetDirectoryName(pathToBuiltProject)),
+            name = PlayerSettings.productName,
+            device = Environment.GetEnvironmentVariable("CAPYDEPLOY_DEVICE") ?? "",
+            launch = Environment.GetEnvironmentVariable("CAPYDEPLOY_LAUNCH") != "0",
+        };
+
+        using (var request = new UnityWebRequest(url.TrimEnd('/') + "/api/v1/deploy/build", "POST"))
+        {
+            request.uploadHandler = new UploadHandlerRaw(Encoding.UTF8.GetBytes(JsonUtility.ToJson(body)));
+            request.downloadHandler = new DownloadHandlerBuffer();
+            request.SetRequestHeader("Authorization", "Bearer " + token);
+            request.SetRequestHeader("Content-Type", "application/json");
+
+            var operation = request.SendWebRequest();
+            while (!operation.isDone)
+                System.Threading.Thread.Sleep(50);
+
+            if (request.result != UnityWebRequest.Result.Success)
+                Debug.LogError("CapyDeploy: deploy request failed: " + request.error + " " + request.downloadHandler.text);
+            else
+                Debug.Log("CapyDeploy: deploy started, follow it in the Hub");
+        }
+    }
+}
@@ -0,0 +1,509 @@
+// Package deploy sends a game setup to a device and sets it up there: the
+// files of its build, its releases, the post-deploy command, its Steam
+// shortcuts and prefix verbs, and the manifest that records the deploy. The
+// hub and the CLI deploy through it.
+package deploy
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"path"
+	"strconv"
+	"strings"
+
+	"github.com/lobinuxsoft/capydeploy/internal/device"
+	"github.com/lobinuxsoft/capydeploy/internal/shortcuts"
+	"github.com/lobinuxsoft/capydeploy/internal/upload"
+	"github.com/lobinuxsoft/capydeploy/pkg/config"
+	"github.com/lobinuxsoft/capydeploy/pkg/ignore"
+	"github.com/lobinuxsoft/capydeploy/pkg/transfer"
+)
+
+// diskSpaceReserve is left free on the device by uploads, for the system and
+// the game's saves and shader cache
+const diskSpaceReserve = 256 << 20
+
+// ErrDiskFull is returned when the device hasn't room for the upload
+var ErrDiskFull = errors.New("not enough space on the device")
+
+// ErrCancelled is returned when the context of a deploy is cancelled
+var ErrCancelled = errors.New("upload cancelled")
+
+// Stage is the part of a deploy an error comes from
+type Stage string
+
+const (
+	StageConnection   Stage = "connection"   // the device stopped answering
+	StageBuild        Stage = "build"        // the local build couldn't be read
+	StageTransfer     Stage = "transfer"     // files couldn't be written to the device
+	StagePostDeploy   Stage = "post-deploy"  // the post-deploy command failed
+	StageShortcut     Stage = "shortcut"     // Steam shortcuts couldn't be created
+	StageVerification Stage = "verification" // files on the device don't match the build
+)
+
+// Error is a failed deploy and the stage it failed at
+type Error struct {
+	Stage Stage
+	Err   error
+}
+
+func (e *Error) Error() string { return e.Err.Error() }
+func (e *Error) Unwrap() error { return e.Err }
+
+// failed tags err with the stage of the deploy it comes from
+func failed(stage Stage, err error) error {
+	return &Error{Stage: stage, Err: err}
+}
+
+// Progress is a step of a running deploy
+type Progress struct {
+	Progress float64 // share of the deploy done, from 0 to 1
+	Status   string
+	Transfer *transfer.Stats // bytes sent, speed and time left while the files upload
+}
+
+// Artwork resolves the artwork of the shortcut of a deploy and applies what
+// the device can't download itself
+type Artwork interface {
+	// Resolve returns the artwork the device downloads while the shortcut is
+	// created, nil for none
+	Resolve(client *device.Client, appID uint32, art *shortcuts.ArtworkConfig) *shortcuts.ArtworkConfig
+	// Applied runs once the shortcut has the downloaded artwork
+	Applied(client *device.Client, appID uint32, downloaded *shortcuts.ArtworkConfig)
+}
+
+// Options tune a single deploy
+type Options struct {
+	// Skip files the device already has
+	Delta bool
+	// Check the hash of every uploaded file on the device
+	Verify bool
+	// Leave Steam running, the library shows the shortcut once it restarts
+	KeepSteamRunning bool
+}
+
+// Result is what a finished deploy did
+type Result struct {
+	AppID     uint32
+	GamePath  string // folder the game is launched from
+	Total     int    // files in the build
+	Uploaded  int    // files sent
+	Unchanged int    // files not sent again, resumed ones included
+	Removed   int    // files a mirror deploy removed from the device
+	// Files that uploaded only after retries, with their retries
+	Retried []string
+	// Per verb result of installing the setup's prefix verbs
+	Verbs []VerbResult
+}
+
+// Deployer deploys game setups to a connected device
+type Deployer struct {
+	Client *device.Client
+	Device config.DeviceConfig
+	// Progress receives each step of a deploy, nil ignores them
+	Progress func(Progress)
+	// Artwork handles the artwork of the shortcut, nil lets the device
+	// download the images of the setup from their URLs
+	Artwork Artwork
+	// PostDeployOutput returns where the output of the post-deploy command
+	// goes, closed once the command ends. Nil discards it.
+	PostDeployOutput func(command string) io.WriteCloser
+}
+
+// progress reports a step of the deploy
+func (d *Deployer) progress(progress float64, status string) {
+	if d.Progress != nil {
+		d.Progress(Progress{Progress: progress, Status: status})
+	}
+}
+
+// Run uploads a setup whose build is ready to the device and creates its
+// shortcuts. Cancelling ctx stops it, the next deploy resumes the upload.
+func (d *Deployer) Run(ctx context.Context, setup *config.GameSetup, opts Options) (*Result, error) {
+	client := d.Client
+	remotePath, err := ExpandRemotePath(client, setup.RemotePath)
+	if err != nil {
+		return nil, failed(StageConnection, fmt.Errorf("failed to expand remote path: %w", err))
+	}
+
+	// Deploys with releases upload to a release inside the game folder
+	gameRoot := path.Join(remotePath, setup.DeployName())
+	gamePath := gameRoot
+	releases := setup.KeepReleases > 0
+
+	d.progress(0.05, "Creating remote directory...")
+	if err := client.MkdirAll(gamePath); err != nil {
+		return nil, failed(StageTransfer, fmt.Errorf("failed to create directory: %w", err))
+	}
+
+	d.progress(0.1, "Scanning files...")
+	build, err := upload.Scan(setup.LocalPath, setup.Exclude)
+	if err != nil {
+		return nil, failed(StageBuild, fmt.Errorf("failed to scan files: %w", err))
+	}
+	if releases {
+		d.progress(0.1, "Preparing the new release...")
+		gamePath, err = prepareRelease(client, gameRoot, d.Device.Host, setup.ID, !build.Archive)
+		if err != nil {
+			return nil, failed(StageTransfer, err)
+		}
+	}
+
+	// A deploy of the same setup to the same place that didn't finish resumes
+	// from the files it uploaded
+	session, err := config.LoadUploadSession(d.Device.Host, gamePath)
+	if err != nil {
+		slog.Warn("Failed to read upload session, starting over", "error", err)
+	}
+	if session == nil || session.SetupID != setup.ID {
+		session = config.NewUploadSession(d.Device.Host, gamePath, setup.ID)
+	}
+
+	// The previous deploy's hashes drive delta uploads, its record the history.
+	// The files on the device also tell the space a full upload frees.
+	previous, _ := ReadManifest(client, gameRoot)
+	if !releases && previous != nil && previous.Release != "" {
+		if err := flattenReleases(client, gameRoot); err != nil {
+			return nil, failed(StageTransfer, err)
+		}
+	}
+	remoteFiles, err := upload.RemoteIndex(client, gamePath)
+	if err != nil {
+		slog.Warn("Failed to list remote files, uploading everything", "error", err)
+	}
+
+	// Hash every file and work out which ones the device still needs
+	d.progress(0.1, "Checking files...")
+	uploadOpts := upload.Options{
+		Name:        setup.DeployName(),
+		RemoteDir:   gamePath,
+		Delta:       opts.Delta,
+		Releases:    releases,
+		Rsync:       setup.Rsync,
+		Compressed:  setup.CompressedUpload,
+		ChunkStore:  setup.ChunkStore,
+		BlockPatch:  setup.BlockPatch,
+		RemoteFiles: remoteFiles,
+		Session:     session,
+	}
+	if previous != nil {
+		uploadOpts.PreviousFiles = previous.Files
+		uploadOpts.PreviousBlocks = previous.Blocks
+	}
+	plan, err := upload.NewPlan(ctx, build, uploadOpts)
+	if ctx.Err() != nil {
+		return nil, failed(StageBuild, ErrCancelled)
+	}
+	if err != nil {
+		return nil, failed(StageBuild, fmt.Errorf("failed to read %w", err))
+	}
+	if plan.Resumed > 0 {
+		slog.Info("Resuming upload", "game", setup.DeployName(), "uploaded", plan.Resumed, "remaining", plan.Pending())
+		d.progress(0.1, fmt.Sprintf("Resuming: %d files were uploaded before the interruption", plan.Resumed))
+	}
+
+	// Refuse to start an upload that would fill the device halfway through
+	if plan.Needed > 0 {
+		free, err := freeSpace(client, gamePath)
+		if err != nil {
+			slog.Warn("Failed to check free space on the device", "error", err)
+		} else if plan.Needed+diskSpaceReserve > free {
+			return nil, failed(StageTransfer, fmt.Errorf("%w: the upload needs %s but only %s is free on %s",
+				ErrDiskFull, formatSize(plan.Needed), formatSize(free), remotePath))
+		}
+	}
+
+	// The file upload spans from 10% to 85% of the deploy
+	perf, _ := config.GetPerformanceSettings()
+	retried, err := upload.Transfer(ctx, client, plan, upload.TransferOptions{
+		Workers:     perf.TransferWorkers,
+		Retries:     perf.UploadRetries,
+		RetryBudget: perf.UploadRetryBudget,
+	}, func(p upload.Progress) {
+		if d.Progress != nil {
+			d.Progress(Progress{Progress: 0.1 + p.Share*0.75, Status: p.Status, Transfer: &p.Stats})
+		}
+	})
+	if ctx.Err() != nil {
+		slog.Info("Upload cancelled", "game", setup.DeployName())
+		return nil, failed(StageTransfer, fmt.Errorf("%w, deploying again resumes where it stopped", ErrCancelled))
+	}
+	if err != nil {
+		return nil, failed(StageTransfer, fmt.Errorf("failed to upload %w", err))
+	}
+
+	if opts.Delta {
+		slog.Info("Delta upload", "game", setup.DeployName(), "unchanged", plan.Unchanged-plan.Resumed, "uploaded", plan.Pending())
+	}
+	if len(retried) > 0 {
+		slog.Info("Files uploaded after retries", "game", setup.DeployName(), "files", strings.Join(retried, ", "))
+	}
+
+	if plan.Symlinks() > 0 {
+		d.progress(0.85, fmt.Sprintf("Creating %d symlinks...", plan.Symlinks()))
+	}
+	if err := upload.FinishFiles(client, plan); err != nil {
+		return nil, failed(StageTransfer, fmt.Errorf("failed to create %w", err))
+	}
+
+	if opts.Verify && plan.Pending() > 0 {
+		d.progress(0.85, "Verifying files on the device...")
+		mismatched, err := upload.Verify(client, plan)
+		if err != nil {
+			return nil, failed(StageVerification, err)
+		}
+		if len(mismatched) > 0 {
+			// The next deploy sends them again
+			config.RemoveUploadSession(d.Device.Host, session.RemotePath)
+			return nil, failed(StageVerification, fmt.Errorf("%d files on the device don't match the build: %s",
+				len(mismatched), strings.Join(mismatched, ", ")))
+		}
+	}
+
+	// Mirror deploys remove what the build no longer has, using the listing
+	// taken before the upload
+	removed := 0
+	if setup.Mirror && remoteFiles == nil {
+		slog.Warn("Skipping mirror, the remote files couldn't be listed", "game", setup.DeployName())
+	} else if setup.Mirror {
+		var ignored *ignore.Matcher
+		if !build.Archive {
+			ignored, _ = ignore.Load(setup.LocalPath)
+		}
+		stale := staleFiles(remoteFiles, plan.Hashes, setup.Exclude, ignored)
+		if len(stale) > 0 {
+			d.progress(0.85, fmt.Sprintf("Removing %d files no longer in the build...", len(stale)))
+			if err := removeStaleFiles(ctx, client, gamePath, stale); err != nil {
+				return nil, failed(StageTransfer, err)
+			}
+			slog.Info("Removed stale files", "game", setup.DeployName(), "files", len(stale))
+			removed = len(stale)
+		}
+	}
+
+	d.progress(0.85, "Setting executable permissions...")
+	chmodCmd := fmt.Sprintf("chmod +x -- %s", device.ShellQuote(path.Join(gamePath, setup.Executable)))
+	if _, err := client.RunCommand(chmodCmd); err != nil {
+		return nil, failed(StageTransfer, fmt.Errorf("failed to set permissions: %w", err))
+	}
+
+	// Set executable permissions on common executable files
+	chmodAllCmd := fmt.Sprintf("find %s -type f \\( -name '*.sh' -o -name '*.x86_64' -o -name '*.x86' \\) -exec chmod +x {} \\;", device.ShellQuote(gamePath))
+	client.RunCommand(chmodAllCmd)
+
+	// Engine builds start binaries without a known extension, e.g. Unreal's
+	// <Game>/Binaries/Linux/<Game>-Linux-Shipping
+	if err := upload.MarkExecutable(client, gamePath, plan.Binaries); err != nil {
+		slog.Warn("Failed to set permissions on game binaries", "error", err)
+	}
+
+	// The shortcut launches the current release, switching to the new one
+	// makes it the build that runs
+	var release string
+	if releases {
+		d.progress(0.86, "Switching to the new release...")
+		gamePath, release, err = finishRelease(client, gameRoot, setup.KeepReleases)
+		if err != nil {
+			return nil, failed(StageTransfer, err)
+		}
+	}
+
+	if strings.TrimSpace(setup.PostDeploy) != "" {
+		d.progress(0.86, "Running post-deploy command...")
+		err := d.runPostDeploy(ctx, setup, gamePath)
+		if ctx.Err() != nil {
+			return nil, failed(StagePostDeploy, ErrCancelled)
+		}
+		if err != nil {
+			return nil, failed(StagePostDeploy, err)
+		}
+	}
+
+	d.progress(0.87, "Checking steam-shortcut-manager binary...")
+	binaryPath, err := EnsureShortcutManager(client, remotePath)
+	if err != nil {
+		return nil, failed(StageShortcut, fmt.Errorf("failed to upload binary: %w", err))
+	}
+
+	d.progress(0.9, "Creating Steam shortcut...")
+	exePath := path.Join(gamePath, setup.Executable)
+	appID := uint32(shortcuts.ShortcutAppID(exePath, setup.DeployName()))
+	artworkCfg := setupArtwork(setup)
+	downloaded := artworkCfg
+	if artworkCfg != nil && d.Artwork != nil {
+		downloaded = d.Artwork.Resolve(client, appID, artworkCfg)
+	}
+
+	launchOptions := setup.LaunchOptions
+	if setup.MangoHud {
+		launchOptions = WithMangoHud(launchOptions, true)
+	}
+	var logFile string
+	if setup.CaptureLogs {
+		if wrapper, err := EnsureLogWrapper(client); err != nil {
+			slog.Warn("Failed to install log wrapper, output won't be captured", "error", err)
+		} else {
+			homeDir, _ := client.GetHomeDir()
+			logFile = GameLogPath(homeDir, setup.DeployName())
+			launchOptions = WithLogWrapper(launchOptions, wrapper, logFile)
+		}
+	}
+
+	remoteCfg := remoteConfig(client, d.Device)
+	tags := shortcuts.ParseTags(setup.Tags)
+	if err := shortcuts.AddShortcutWithArtwork(remoteCfg, setup.DeployName(), exePath, gamePath, launchOptions, tags, downloaded, binaryPath); err != nil {
+		return nil, failed(StageShortcut, fmt.Errorf("failed to create shortcut: %w", err))
+	}
+
+	var extraShortcuts []string
+	if len(setup.ExtraShortcuts) > 0 || (previous != nil && len(previous.ExtraShortcuts) > 0) {
+		d.progress(0.91, "Creating extra shortcuts...")
+		extraShortcuts, err = deployExtraShortcuts(client, remoteCfg, setup, gamePath, tags, previous, binaryPath)
+		if err != nil {
+			return nil, failed(StageShortcut, err)
+		}
+	}
+
+	var installedVerbs []string
+	if previous != nil {
+		installedVerbs = previous.PrefixVerbs
+	}
+	var verbResults []VerbResult
+	if len(setup.PrefixVerbs) > 0 {
+		d.progress(0.92, "Installing prefix dependencies...")
+		verbResults, installedVerbs = installVerbs(client, appID, setup.PrefixVerbs, installedVerbs)
+	}
+
+	manifest := newManifest(setup, appID, plan.Hashes)
+	manifest.LogFile = logFile
+	manifest.History = history(previous)
+	manifest.PrefixVerbs = installedVerbs
+	manifest.ExtraShortcuts = extraShortcuts
+	manifest.Release = release
+	if len(plan.Blocks) > 0 {
+		manifest.Blocks = plan.Blocks
+	}
+	if err := WriteManifest(client, gamePath, manifest); err != nil {
+		slog.Warn("Failed to write deploy manifest", "error", err)
+	}
+
+	if setup.LogoImage != "" && setup.LogoPosition != nil {
+		if err := writeLogoPosition(client, appID, *setup.LogoPosition); err != nil {
+			slog.Warn("Failed to write logo position", "error", err)
+		}
+	}
+	if artworkCfg != nil && d.Artwork != nil {
+		d.Artwork.Applied(client, appID, downloaded)
+	}
+
+	if !opts.KeepSteamRunning {
+		shortcuts.RefreshSteamLibrary(remoteCfg)
+	}
+	if err := config.RemoveUploadSession(d.Device.Host, session.RemotePath); err != nil {
+		slog.Warn("Failed to remove upload session", "error", err)
+	}
+
+	return &Result{
+		AppID:     appID,
+		GamePath:  gamePath,
+		Total:     plan.Total,
+		Uploaded:  plan.Pending(),
+		Unchanged: plan.Unchanged,
+		Removed:   removed,
+		Retried:   retried,
+		Verbs:     verbResults,
+	}, nil
+}
+
+// runPostDeploy runs the post-deploy command of a setup on the device, in the
+// game folder. Cancelling ctx ends it.
+func (d *Deployer) runPostDeploy(ctx context.Context, setup *config.GameSetup, gamePath string) error {
+	// The command may hold credentials, only its setup is logged
+	slog.Info("Running post-deploy command", "setup", setup.Name, "dir", gamePath)
+	var output io.WriteCloser = nopCloser{io.Discard}
+	if d.PostDeployOutput != nil {
+		output = d.PostDeployOutput(setup.PostDeploy)
+	}
+
+	// No input, so the command can't wait for a prompt that never comes
+	cmd := fmt.Sprintf("cd %s && { %s\n} </dev/null 2>&1", device.ShellQuote(gamePath), setup.PostDeploy)
+	err := d.Client.StreamCommandContext(ctx, cmd, output)
+	output.Close()
+	if err != nil {
+		return fmt.Errorf("post-deploy command: %w", err)
+	}
+	return nil
+}
+
+// nopCloser is a writer with nothing to close
+type nopCloser struct {
+	io.Writer
+}
+
+func (nopCloser) Close() error { return nil }
+
+// setupArtwork returns the artwork slots set in a setup, nil for none
+func setupArtwork(setup *config.GameSetup) *shortcuts.ArtworkConfig {
+	if setup.GridPortrait == "" && setup.GridLandscape == "" && setup.HeroImage == "" &&
+		setup.LogoImage == "" && setup.IconImage == "" {
+		return nil
+	}
+	slog.Debug("Setup artwork config",
+		"game", setup.Name,
+		"gridDBGameID", setup.GridDBGameID,
+		"gridPortrait", setup.GridPortrait,
+		"gridLandscape", setup.GridLandscape,
+		"hero", setup.HeroImage,
+		"logo", setup.LogoImage,
+		"icon", setup.IconImage)
+	return &shortcuts.ArtworkConfig{
+		GridPortrait:  setup.GridPortrait,
+		GridLandscape: setup.GridLandscape,
+		HeroImage:     setup.HeroImage,
+		LogoImage:     setup.LogoImage,
+		IconImage:     setup.IconImage,
+	}
+}
+
+// ExpandRemotePath replaces a leading ~ with the remote home directory
+func ExpandRemotePath(client *device.Client, remotePath string) (string, error) {
+	if !strings.HasPrefix(remotePath, "~") {
+		return remotePath, nil
+	}
+	homeDir, err := client.GetHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return strings.Replace(remotePath, "~", homeDir, 1), nil
+}
+
+// freeSpace returns the bytes available to the user on the filesystem of a
+// remote directory
+func freeSpace(client *device.Client, dir string) (int64, error) {
+	output, err := client.RunCommand(fmt.Sprintf("df -B1 --output=avail %s", device.ShellQuote(dir)))
+	if err != nil {
+		return 0, err
+	}
+	lines := strings.Fields(output)
+	if len(lines) == 0 {
+		return 0, fmt.Errorf("unexpected df output %q", output)
+	}
+	return strconv.ParseInt(lines[len(lines)-1], 10, 64)
+}
+
+// formatSize formats a byte count for messages, e.g. "1.5 GB"
+func formatSize(bytes int64) string {
+	const unit = 1024
+	if bytes < unit {
+		return fmt.Sprintf("%d B", bytes)
+	}
+	div, exp := int64(unit), 0
+	for n := bytes / unit; n >= unit; n /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %cB", float64(bytes)/float64(div), "KMGTPE"[exp])
+}
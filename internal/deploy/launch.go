@@ -0,0 +1,117 @@
+package deploy
+
+import (
+	"fmt"
+	"path"
+	"regexp"
+	"strings"
+
+	"github.com/lobinuxsoft/capydeploy/internal/device"
+	"github.com/lobinuxsoft/capydeploy/internal/upload"
+)
+
+// mangoHudEnv turns the MangoHud overlay on for a Vulkan or OpenGL game,
+// which covers Proton games through DXVK and VKD3D
+const mangoHudEnv = "MANGOHUD=1"
+
+// mangoHudPattern matches the overlay turned on in launch options, by the
+// environment variable or the mangohud wrapper
+var mangoHudPattern = regexp.MustCompile(`(^|\s)(MANGOHUD=1|mangohud)(\s+|$)`)
+
+// HasMangoHud reports whether launch options turn the overlay on
+func HasMangoHud(launchOptions string) bool {
+	return mangoHudPattern.MatchString(launchOptions)
+}
+
+// WithMangoHud turns the overlay on or off in launch options, leaving the
+// rest of them as the user wrote them
+func WithMangoHud(launchOptions string, enabled bool) string {
+	if HasMangoHud(launchOptions) == enabled {
+		return launchOptions
+	}
+	if enabled {
+		if strings.Contains(launchOptions, "%command%") {
+			return mangoHudEnv + " " + launchOptions
+		}
+		return strings.TrimSpace(mangoHudEnv + " %command% " + launchOptions)
+	}
+
+	stripped := launchOptions
+	for mangoHudPattern.MatchString(stripped) {
+		stripped = mangoHudPattern.ReplaceAllString(stripped, "$1")
+	}
+	stripped = strings.TrimSpace(stripped)
+	// A bare "%command%" is what Steam runs anyway
+	if rest, ok := strings.CutPrefix(stripped, "%command%"); ok && !strings.Contains(rest, "%command%") {
+		return strings.TrimSpace(rest)
+	}
+	return stripped
+}
+
+// logWrapperScript runs a game with its stdout and stderr kept in a log file.
+// It is used as a launch option prefix ("<wrapper> <log> %command%") so it wraps
+// Proton launches as well as native ones. The previous log is kept as <log>.1
+const logWrapperScript = `#!/bin/sh
+# Written by the devkit hub: runs a game keeping its output in a log file
+log="$1"
+shift
+mkdir -p "$(dirname "$log")"
+[ -f "$log" ] && mv -f "$log" "$log.1"
+echo "=== $(date -Iseconds) $*" >"$log"
+exec "$@" >>"$log" 2>&1
+`
+
+// EnsureLogWrapper writes the log wrapper to the device and returns its path
+func EnsureLogWrapper(client *device.Client) (string, error) {
+	homeDir, err := client.GetHomeDir()
+	if err != nil {
+		return "", err
+	}
+	wrapper := LogWrapperPath(homeDir)
+	if err := client.MkdirAll(path.Dir(wrapper)); err != nil {
+		return "", err
+	}
+	if err := client.WriteFile(wrapper, []byte(logWrapperScript), 0755); err != nil {
+		return "", err
+	}
+	return wrapper, nil
+}
+
+// LogWrapperPath returns where the log wrapper is installed on the device
+func LogWrapperPath(homeDir string) string {
+	return path.Join(homeDir, upload.DataDir, "log-wrapper.sh")
+}
+
+// GameLogPath returns where the output of a game is logged on the device
+func GameLogPath(homeDir, gameName string) string {
+	name := strings.Map(func(r rune) rune {
+		if (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z') || (r >= '0' && r <= '9') || r == '-' || r == '.' {
+			return r
+		}
+		return '_'
+	}, gameName)
+	return path.Join(homeDir, upload.DataDir, "logs", name+".log")
+}
+
+// WithLogWrapper prefixes the game command of launch options with the log wrapper
+func WithLogWrapper(launchOptions, wrapper, logPath string) string {
+	prefix := fmt.Sprintf("%q %q %%command%%", wrapper, logPath)
+	if strings.Contains(launchOptions, "%command%") {
+		return strings.Replace(launchOptions, "%command%", prefix, 1)
+	}
+	return strings.TrimSpace(prefix + " " + launchOptions)
+}
+
+// StripLogWrapper undoes WithLogWrapper, leaving the launch options the user wrote
+func StripLogWrapper(launchOptions, wrapper, logPath string) string {
+	prefix := fmt.Sprintf("%q %q %%command%%", wrapper, logPath)
+	if !strings.Contains(launchOptions, prefix) {
+		return launchOptions
+	}
+	stripped := strings.Replace(launchOptions, prefix, "%command%", 1)
+	// A bare "%command%" is what Steam runs anyway
+	if rest, ok := strings.CutPrefix(stripped, "%command%"); ok && !strings.Contains(rest, "%command%") {
+		return strings.TrimSpace(rest)
+	}
+	return stripped
+}
@@ -0,0 +1,51 @@
+package deploy
+
+import "testing"
+
+func TestLogWrapper(t *testing.T) {
+	const (
+		wrapper = "/home/deck/.local/share/devkit/log-wrapper.sh"
+		logPath = "/home/deck/.local/share/devkit/logs/My_Game.log"
+		prefix  = `"` + wrapper + `" "` + logPath + `" %command%`
+	)
+	tests := []struct {
+		name          string
+		launchOptions string
+		wrapped       string
+		stripped      string
+	}{
+		{"no options", "", prefix, ""},
+		// A bare %command% is the same as no options for Steam
+		{"bare command", "%command%", prefix, ""},
+		{"arguments only", "-windowed -nosound", prefix + " -windowed -nosound", "-windowed -nosound"},
+		{"command with arguments", "%command% -dx11", prefix + " -dx11", "-dx11"},
+		{"environment before the command", "PROTON_LOG=1 %command% -dx11", "PROTON_LOG=1 " + prefix + " -dx11", "PROTON_LOG=1 %command% -dx11"},
+		{"wrapped by another tool", "gamemoderun %command%", "gamemoderun " + prefix, "gamemoderun %command%"},
+		{"only the first command is wrapped", "%command% ; %command%", prefix + " ; %command%", "%command% ; %command%"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := WithLogWrapper(tt.launchOptions, wrapper, logPath)
+			if got != tt.wrapped {
+				t.Errorf("WithLogWrapper(%q) = %q, want %q", tt.launchOptions, got, tt.wrapped)
+			}
+			if back := StripLogWrapper(got, wrapper, logPath); back != tt.stripped {
+				t.Errorf("StripLogWrapper(%q) = %q, want %q", got, back, tt.stripped)
+			}
+		})
+	}
+}
+
+func TestStripLogWrapper_Untouched(t *testing.T) {
+	const wrapper, logPath = "/w.sh", "/logs/a.log"
+	for _, options := range []string{
+		"",
+		"%command% -dx11",
+		// Another game's log is left alone
+		`"/w.sh" "/logs/b.log" %command%`,
+	} {
+		if got := StripLogWrapper(options, wrapper, logPath); got != options {
+			t.Errorf("StripLogWrapper(%q) = %q, want it unchanged", options, got)
+		}
+	}
+}
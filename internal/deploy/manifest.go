@@ -0,0 +1,140 @@
+package deploy
+
+import (
+	"encoding/json"
+	"path"
+	"time"
+
+	"github.com/lobinuxsoft/capydeploy/internal/device"
+	"github.com/lobinuxsoft/capydeploy/pkg/config"
+)
+
+// ManifestName is written in every deployed game directory to describe the deploy
+const ManifestName = "devkit.json"
+
+// LegacyManifestName is the manifest name used by earlier builds, still read
+const LegacyManifestName = ".capydeploy.json"
+
+// Manifest records what was deployed to a game directory and where it came
+// from, so the Games tab keeps working without the hub's config
+type Manifest struct {
+	SetupID       string    `json:"setup_id"`
+	Name          string    `json:"name"`
+	Version       string    `json:"version,omitempty"`
+	Channel       string    `json:"channel,omitempty"`
+	Executable    string    `json:"executable"`
+	LaunchOptions string    `json:"launch_options,omitempty"`
+	AppID         uint32    `json:"app_id"`
+	DeployedAt    time.Time `json:"deployed_at"`
+	LogFile       string    `json:"log_file,omitempty"` // output log, when captured
+	Notes         string    `json:"notes,omitempty"`    // changelog of this build
+	// Earlier deploys to the same directory, newest first
+	History []Record `json:"history,omitempty"`
+	// SHA-256 of every deployed file, keyed by slash separated relative path
+	Files   map[string]string `json:"files,omitempty"`
+	Artwork *ManifestArtwork  `json:"artwork,omitempty"`
+	// Winetricks verbs installed into the game's prefix by earlier deploys
+	PrefixVerbs []string `json:"prefix_verbs,omitempty"`
+	// Steam shortcuts of the game's other executables
+	ExtraShortcuts []string `json:"extra_shortcuts,omitempty"`
+	// Hashes of the blocks of large files, to patch them on the next deploy
+	Blocks map[string][]string `json:"blocks,omitempty"`
+	// Release directory of the deploy, for games deployed with releases
+	Release string `json:"release,omitempty"`
+}
+
+// Record is what the history keeps of an earlier deploy
+type Record struct {
+	Version    string    `json:"version,omitempty"`
+	DeployedAt time.Time `json:"deployed_at"`
+	Notes      string    `json:"notes,omitempty"`
+}
+
+// MaxHistory caps how many earlier deploys a manifest remembers
+const MaxHistory = 20
+
+// ManifestArtwork is the source of each artwork image applied on deploy
+type ManifestArtwork struct {
+	GridDBGameID int    `json:"griddb_game_id,omitempty"`
+	Capsule      string `json:"capsule,omitempty"`
+	Wide         string `json:"wide,omitempty"`
+	Hero         string `json:"hero,omitempty"`
+	Logo         string `json:"logo,omitempty"`
+	Icon         string `json:"icon,omitempty"`
+}
+
+// newManifest describes a deploy of setup with the given file hashes
+func newManifest(setup *config.GameSetup, appID uint32, files map[string]string) Manifest {
+	manifest := Manifest{
+		SetupID:       setup.ID,
+		Name:          setup.DeployName(),
+		Channel:       setup.Channel,
+		Version:       setup.Version,
+		Executable:    setup.Executable,
+		LaunchOptions: setup.LaunchOptions,
+		Notes:         setup.Notes,
+		AppID:         appID,
+		DeployedAt:    time.Now().UTC(),
+		Files:         files,
+	}
+	art := ManifestArtwork{
+		GridDBGameID: setup.GridDBGameID,
+		Capsule:      setup.GridPortrait,
+		Wide:         setup.GridLandscape,
+		Hero:         setup.HeroImage,
+		Logo:         setup.LogoImage,
+		Icon:         setup.IconImage,
+	}
+	if art != (ManifestArtwork{}) {
+		manifest.Artwork = &art
+	}
+	return manifest
+}
+
+// history returns the history of the next deploy: the previous deploy
+// followed by its own history
+func history(previous *Manifest) []Record {
+	if previous == nil || previous.DeployedAt.IsZero() {
+		return nil
+	}
+	records := append([]Record{{
+		Version:    previous.Version,
+		DeployedAt: previous.DeployedAt,
+		Notes:      previous.Notes,
+	}}, previous.History...)
+	if len(records) > MaxHistory {
+		records = records[:MaxHistory]
+	}
+	return records
+}
+
+// WriteManifest records a deploy in the game directory
+func WriteManifest(client *device.Client, gamePath string, manifest Manifest) error {
+	data, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := client.WriteFile(path.Join(gamePath, ManifestName), data, 0644); err != nil {
+		return err
+	}
+	if client.FileExists(path.Join(gamePath, LegacyManifestName)) {
+		client.Remove(path.Join(gamePath, LegacyManifestName))
+	}
+	return nil
+}
+
+// ReadManifest reads the deploy record of a game directory, if it has one
+func ReadManifest(client *device.Client, gamePath string) (*Manifest, bool) {
+	for _, name := range []string{ManifestName, LegacyManifestName} {
+		data, err := client.ReadFile(path.Join(gamePath, name))
+		if err != nil {
+			continue
+		}
+		var manifest Manifest
+		if err := json.Unmarshal(data, &manifest); err != nil {
+			return nil, false
+		}
+		return &manifest, true
+	}
+	return nil, false
+}
@@ -0,0 +1,57 @@
+package deploy
+
+import (
+	"fmt"
+	"testing"
+	"time"
+)
+
+func TestHistory(t *testing.T) {
+	deployedAt := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	records := func(n int) []Record {
+		history := make([]Record, n)
+		for i := range history {
+			history[i] = Record{Version: fmt.Sprintf("0.%d", n-i), DeployedAt: deployedAt.Add(-time.Duration(i+1) * time.Hour)}
+		}
+		return history
+	}
+
+	tests := []struct {
+		name     string
+		previous *Manifest
+		want     int    // records kept
+		first    string // version of the newest record
+	}{
+		{name: "first deploy", previous: nil},
+		{name: "manifest without a deploy time", previous: &Manifest{Version: "1.0"}},
+		{
+			name:     "previous deploy goes first",
+			previous: &Manifest{Version: "1.0", Notes: "fixes", DeployedAt: deployedAt, History: records(2)},
+			want:     3,
+			first:    "1.0",
+		},
+		{
+			name:     "oldest records are dropped",
+			previous: &Manifest{Version: "2.0", DeployedAt: deployedAt, History: records(MaxHistory)},
+			want:     MaxHistory,
+			first:    "2.0",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := history(tt.previous)
+			if len(got) != tt.want {
+				t.Fatalf("history() kept %d records, want %d", len(got), tt.want)
+			}
+			if tt.want == 0 {
+				return
+			}
+			if got[0].Version != tt.first || !got[0].DeployedAt.Equal(tt.previous.DeployedAt) || got[0].Notes != tt.previous.Notes {
+				t.Errorf("newest record = %+v, want the previous deploy", got[0])
+			}
+			if got[1] != tt.previous.History[0] {
+				t.Errorf("second record = %+v, want %+v", got[1], tt.previous.History[0])
+			}
+		})
+	}
+}
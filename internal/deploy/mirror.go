@@ -0,0 +1,85 @@
+package deploy
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/lobinuxsoft/capydeploy/internal/device"
+	"github.com/lobinuxsoft/capydeploy/internal/upload"
+	"github.com/lobinuxsoft/capydeploy/pkg/engine"
+	"github.com/lobinuxsoft/capydeploy/pkg/ignore"
+)
+
+// staleFiles returns the files of a game folder on the device that the build
+// no longer has, sorted. Files matching the exclude patterns of the setup or
+// its .devkitignore are kept, they are how files the game writes next to
+// itself, like saves, survive a mirror deploy.
+func staleFiles(remoteFiles map[string]upload.RemoteFile, deployed map[string]string, exclude []string, ignored *ignore.Matcher) []string {
+	var stale []string
+	for relPath := range remoteFiles {
+		if _, ok := deployed[relPath]; ok {
+			continue
+		}
+		if relPath == ManifestName || relPath == LegacyManifestName || relPath == upload.ArchiveUploadName {
+			continue
+		}
+		if keptOnDevice(relPath, exclude, ignored) {
+			continue
+		}
+		stale = append(stale, relPath)
+	}
+	sort.Strings(stale)
+	return stale
+}
+
+// keptOnDevice reports whether a file on the device is left out of the deploy,
+// itself or through one of its folders
+func keptOnDevice(relPath string, exclude []string, ignored *ignore.Matcher) bool {
+	if engine.Excluded(relPath, exclude) {
+		return true
+	}
+	elems := strings.Split(relPath, "/")
+	for i := 1; i <= len(elems); i++ {
+		if ignored.Match(strings.Join(elems[:i], "/"), i < len(elems)) {
+			return true
+		}
+	}
+	return false
+}
+
+// removeStaleFiles deletes files from a game folder on the device, then the
+// folders they leave empty. Folders that were already empty are left alone.
+func removeStaleFiles(ctx context.Context, client *device.Client, gamePath string, stale []string) error {
+	if len(stale) == 0 {
+		return nil
+	}
+	var files, dirs bytes.Buffer
+	seen := make(map[string]bool)
+	for _, relPath := range stale {
+		files.WriteString(relPath)
+		files.WriteByte(0)
+		if dir := path.Dir(relPath); dir != "." && !seen[dir] {
+			seen[dir] = true
+			dirs.WriteString(dir)
+			dirs.WriteByte(0)
+		}
+	}
+	root := device.ShellQuote(gamePath)
+	if err := client.PipeCommand(ctx, fmt.Sprintf("cd %s && xargs -0 rm -f --", root), &files); err != nil {
+		return fmt.Errorf("failed to remove files no longer in the build: %w", err)
+	}
+	if dirs.Len() == 0 {
+		return nil
+	}
+	// rmdir -p climbs the relative path, so it stops at the game folder. A
+	// folder may be gone already, removed as the parent of another one.
+	cmd := fmt.Sprintf("cd %s && xargs -0 rmdir -p --ignore-fail-on-non-empty -- 2>/dev/null; true", root)
+	if err := client.PipeCommand(ctx, cmd, &dirs); err != nil {
+		return fmt.Errorf("failed to remove empty folders: %w", err)
+	}
+	return nil
+}
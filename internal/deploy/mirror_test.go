@@ -0,0 +1,105 @@
+package deploy
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/lobinuxsoft/capydeploy/internal/upload"
+	"github.com/lobinuxsoft/capydeploy/pkg/ignore"
+)
+
+func TestStaleFiles(t *testing.T) {
+	ignored, err := ignore.Parse(strings.NewReader("saves/\n/config.ini\n*.log\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	remote := func(paths ...string) map[string]upload.RemoteFile {
+		files := make(map[string]upload.RemoteFile)
+		for _, p := range paths {
+			files[p] = upload.RemoteFile{}
+		}
+		return files
+	}
+	deployed := map[string]string{"game.x86_64": "h1", "data/level1.pak": "h2"}
+
+	tests := []struct {
+		name    string
+		remote  map[string]upload.RemoteFile
+		exclude []string
+		want    []string
+	}{
+		{
+			name:   "files still in the build",
+			remote: remote("game.x86_64", "data/level1.pak"),
+		},
+		{
+			name:   "files removed from the build, sorted",
+			remote: remote("game.x86_64", "data/old.pak", "data/level1.pak", "bin/tool"),
+			want:   []string{"bin/tool", "data/old.pak"},
+		},
+		{
+			name:   "deploy files",
+			remote: remote(ManifestName, LegacyManifestName, upload.ArchiveUploadName),
+		},
+		{
+			name:    "exclude pattern on a file or a folder",
+			remote:  remote("debug_DoNotShip.pdb", "Tools_DoNotShip/editor", "data/old.pak"),
+			exclude: []string{"*_DoNotShip*"},
+			want:    []string{"data/old.pak"},
+		},
+		{
+			name:   "ignored folder, anchored file and pattern",
+			remote: remote("saves/slot1.sav", "saves/auto/slot2.sav", "config.ini", "logs/run.log", "data/config.ini"),
+			want:   []string{"data/config.ini"},
+		},
+		{
+			name:   "paths that only look like kept ones",
+			remote: remote("saves.bak", "data/saves", "game.x86_64.old"),
+			want:   []string{"data/saves", "game.x86_64.old", "saves.bak"},
+		},
+		{
+			// A backslash is part of a Linux file name, not a separator
+			name:   "backslash names",
+			remote: remote(`data\level1.pak`, `saves\slot1.sav`),
+			want:   []string{`data\level1.pak`, `saves\slot1.sav`},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := staleFiles(tt.remote, deployed, tt.exclude, ignored)
+			if strings.Join(got, ",") != strings.Join(tt.want, ",") {
+				t.Errorf("staleFiles() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestKeptOnDevice(t *testing.T) {
+	ignored, err := ignore.Parse(strings.NewReader("saves/\n!saves/keep.me\nShaderCache/**\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		relPath string
+		exclude []string
+		want    bool
+	}{
+		{"saves/slot1.sav", nil, true},
+		// A file in an ignored folder can't be included again
+		{"saves/keep.me", nil, true},
+		{"ShaderCache/a/b.bin", nil, true},
+		{"game.x86_64", nil, false},
+		{"data/saves.pak", nil, false},
+		{"data/Screenshots/1.png", []string{"Screenshots"}, true},
+		{"data/level.pak", []string{"Screenshots"}, false},
+	}
+	for _, tt := range tests {
+		if got := keptOnDevice(tt.relPath, tt.exclude, ignored); got != tt.want {
+			t.Errorf("keptOnDevice(%q, %q) = %v, want %v", tt.relPath, tt.exclude, got, tt.want)
+		}
+	}
+
+	if keptOnDevice("saves/slot1.sav", nil, nil) {
+		t.Error("a nil matcher should keep nothing")
+	}
+}
@@ -0,0 +1,88 @@
+package deploy
+
+import (
+	"fmt"
+	"path"
+	"time"
+
+	"github.com/lobinuxsoft/capydeploy/internal/device"
+	"github.com/lobinuxsoft/capydeploy/pkg/config"
+)
+
+// ReleasesDirName holds the builds of a game deployed with releases, one
+// directory per deploy named after its time
+const ReleasesDirName = "releases"
+
+// CurrentReleaseName is the symlink to the release the shortcut launches
+const CurrentReleaseName = "current"
+
+// stagingReleaseName is the release being uploaded, kept after an interrupted
+// deploy so the next one resumes it
+const stagingReleaseName = ".staging"
+
+// ReleaseTimeFormat names release directories, which sort by time by name
+const ReleaseTimeFormat = "20060102-150405"
+
+// ReleaseFilesPath returns where the files of a deployed game are: its
+// current release when it is deployed with releases, or the game folder
+func ReleaseFilesPath(gamePath string, manifest *Manifest) string {
+	if manifest != nil && manifest.Release != "" {
+		return path.Join(gamePath, CurrentReleaseName)
+	}
+	return gamePath
+}
+
+// prepareRelease returns the staging release a deploy uploads to. One left by
+// an interrupted deploy of the same setup is resumed. A new one starts as hard
+// links to the current build, or to the files of a game deployed in place, so
+// only the changed files upload; archives are extracted whole instead.
+func prepareRelease(client *device.Client, gameRoot, host, setupID string, seed bool) (string, error) {
+	staging := path.Join(gameRoot, ReleasesDirName, stagingReleaseName)
+	if session, _ := config.LoadUploadSession(host, staging); session != nil && session.SetupID == setupID && client.FileExists(staging) {
+		return staging, nil
+	}
+
+	dir := device.ShellQuote(staging)
+	cmd := fmt.Sprintf("rm -rf %s && mkdir -p %s", dir, dir)
+	if seed {
+		cmd += fmt.Sprintf(" && cd %s && if [ -d %s ]; then cp -al %s/. %s/; else find . -mindepth 1 -maxdepth 1 ! -name %s ! -name %s -exec cp -al {} %s/ \\;; fi",
+			device.ShellQuote(gameRoot), CurrentReleaseName, CurrentReleaseName, dir, ReleasesDirName, CurrentReleaseName, dir)
+		// Every release gets its own manifest
+		cmd += fmt.Sprintf(" && rm -f %s/%s %s/%s", dir, ManifestName, dir, LegacyManifestName)
+	}
+	if _, err := client.RunCommand(cmd); err != nil {
+		return "", fmt.Errorf("failed to prepare the new release: %w", err)
+	}
+	return staging, nil
+}
+
+// finishRelease names the staging release after the current time and makes it
+// the current one, then removes the oldest releases past keep. A game deployed
+// in place until now loses its old files, which the release has. The game's
+// manifest becomes a link to the one of its current release. Returns the path
+// the current build is launched from and the name of the release.
+func finishRelease(client *device.Client, gameRoot string, keep int) (string, string, error) {
+	name := time.Now().UTC().Format(ReleaseTimeFormat)
+	release := path.Join(ReleasesDirName, name)
+	cmd := fmt.Sprintf(`cd %[1]s && mv -T %[2]s/%[3]s %[4]s && \
+if [ ! -L %[5]s ]; then find . -mindepth 1 -maxdepth 1 ! -name %[2]s -exec rm -rf {} +; fi && \
+ln -sfn %[4]s %[5]s.tmp && mv -T %[5]s.tmp %[5]s && \
+ln -sfn %[5]s/%[6]s %[6]s && \
+cd %[2]s && ls -1 | sort -r | tail -n +%[7]d | xargs -r rm -rf --`,
+		device.ShellQuote(gameRoot), ReleasesDirName, stagingReleaseName, release, CurrentReleaseName, ManifestName, keep+1)
+	if _, err := client.RunCommand(cmd); err != nil {
+		return "", "", fmt.Errorf("failed to switch to the new release: %w", err)
+	}
+	return path.Join(gameRoot, CurrentReleaseName), name, nil
+}
+
+// flattenReleases moves the current release of a game back into its folder
+// and removes the others, for a setup that no longer keeps releases
+func flattenReleases(client *device.Client, gameRoot string) error {
+	cmd := fmt.Sprintf("cd %s && rm -f %s && cp -al %s/. . && rm -rf %s %s",
+		device.ShellQuote(gameRoot), ManifestName, CurrentReleaseName, CurrentReleaseName, ReleasesDirName)
+	if _, err := client.RunCommand(cmd); err != nil {
+		return fmt.Errorf("failed to move the current release back into the game folder: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,98 @@
+package deploy
+
+import (
+	"fmt"
+	"log/slog"
+	"path"
+	"slices"
+	"strings"
+
+	"github.com/lobinuxsoft/capydeploy/internal/device"
+	"github.com/lobinuxsoft/capydeploy/internal/embedded"
+	"github.com/lobinuxsoft/capydeploy/internal/shortcuts"
+	"github.com/lobinuxsoft/capydeploy/pkg/config"
+	"github.com/lobinuxsoft/capydeploy/pkg/steam"
+)
+
+// EnsureShortcutManager makes sure the steam-shortcut-manager binary is present in
+// remoteDir and returns its path
+func EnsureShortcutManager(client *device.Client, remoteDir string) (string, error) {
+	binaryRemotePath := path.Join(remoteDir, embedded.SteamShortcutManagerName)
+	if shortcuts.EnsureBinaryExists(client, binaryRemotePath) {
+		return binaryRemotePath, nil
+	}
+	if err := client.MkdirAll(remoteDir); err != nil {
+		return "", err
+	}
+	if err := shortcuts.UploadBinary(client, embedded.SteamShortcutManager, binaryRemotePath); err != nil {
+		return "", err
+	}
+	return binaryRemotePath, nil
+}
+
+// remoteConfig returns the shortcut manager config of a connected device
+func remoteConfig(client *device.Client, deviceCfg config.DeviceConfig) *shortcuts.RemoteConfig {
+	return &shortcuts.RemoteConfig{
+		User:     deviceCfg.User,
+		Password: deviceCfg.Password,
+		KeyFile:  deviceCfg.KeyFile,
+		Device:   client,
+	}
+}
+
+// deployExtraShortcuts creates the Steam shortcuts of the extra executables
+// of a deployed setup and removes the ones dropped from it since the previous
+// deploy. It returns the names of the shortcuts it created.
+func deployExtraShortcuts(client *device.Client, remoteCfg *shortcuts.RemoteConfig, setup *config.GameSetup, gamePath string, tags []string, previous *Manifest, binaryPath string) ([]string, error) {
+	var names []string
+	for _, extra := range setup.ExtraShortcuts {
+		exePath := path.Join(gamePath, strings.ReplaceAll(extra.Executable, "\\", "/"))
+		if _, err := client.RunCommand(fmt.Sprintf("chmod +x %s", device.ShellQuote(exePath))); err != nil {
+			return nil, fmt.Errorf("executable %s of %s not found in the game folder", extra.Executable, extra.Name)
+		}
+
+		launchOptions := extra.LaunchOptions
+		if setup.MangoHud {
+			launchOptions = WithMangoHud(launchOptions, true)
+		}
+		name := setup.ShortcutName(extra)
+		if err := shortcuts.AddShortcutWithArtwork(remoteCfg, name, exePath, gamePath, launchOptions, tags, nil, binaryPath); err != nil {
+			return nil, fmt.Errorf("failed to create shortcut %s: %w", name, err)
+		}
+		names = append(names, name)
+	}
+
+	if previous != nil {
+		for _, name := range previous.ExtraShortcuts {
+			if slices.Contains(names, name) {
+				continue
+			}
+			if err := shortcuts.RemoveShortcut(remoteCfg, name); err != nil {
+				slog.Warn("Failed to remove shortcut", "name", name, "error", err)
+			}
+		}
+	}
+	return names, nil
+}
+
+// writeLogoPosition writes the logo placement file Steam reads from the grid directory
+func writeLogoPosition(client *device.Client, appID uint32, pos steam.LogoPosition) error {
+	data, err := steam.MarshalLogoPosition(pos)
+	if err != nil {
+		return err
+	}
+
+	gridDirs, err := shortcuts.GridDirs(client)
+	if err != nil {
+		return err
+	}
+	for _, gridDir := range gridDirs {
+		if err := client.MkdirAll(gridDir); err != nil {
+			return err
+		}
+		if err := client.WriteFile(path.Join(gridDir, steam.LogoPositionFilename(appID)), data, 0644); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,107 @@
+package deploy
+
+import (
+	"fmt"
+	"log/slog"
+	"path"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/lobinuxsoft/capydeploy/internal/device"
+)
+
+// VerbResult is the outcome of installing one verb into a game's prefix on deploy
+type VerbResult struct {
+	Verb string `json:"verb"`
+	OK   bool   `json:"ok"`
+	// Why the verb failed or was left for a later deploy
+	Error string `json:"error,omitempty"`
+}
+
+// installVerbs installs the verbs a setup declares that the game's prefix
+// doesn't have yet, one at a time so each gets its own result. Returns the
+// results and every verb now installed. Steam only creates the prefix on the
+// first launch under Proton, until then the verbs are left for the next deploy.
+func installVerbs(client *device.Client, appID uint32, verbs, installed []string) ([]VerbResult, []string) {
+	var pending []string
+	for _, verb := range verbs {
+		if !slices.Contains(installed, verb) {
+			pending = append(pending, verb)
+		}
+	}
+	if len(pending) == 0 {
+		return nil, installed
+	}
+
+	homeDir, err := client.GetHomeDir()
+	if err != nil {
+		return skippedVerbs(pending, err.Error()), installed
+	}
+	prefixPath := CompatDataPath(homeDir, appID)
+	if !client.FileExists(prefixPath) {
+		return skippedVerbs(pending, "no Proton prefix yet, launch the game once and deploy again"), installed
+	}
+	tool := PrefixTool(client)
+
+	results := make([]VerbResult, 0, len(pending))
+	for _, verb := range pending {
+		cmd, err := VerbsCommand(tool, appID, prefixPath, []string{verb})
+		if err != nil {
+			return append(results, skippedVerbs(pending[len(results):], err.Error())...), installed
+		}
+		if output, err := client.RunCommand(cmd + " 2>&1"); err != nil {
+			slog.Warn("Failed to install prefix verb", "verb", verb, "error", err, "output", LastLine(output))
+			results = append(results, VerbResult{Verb: verb, Error: LastLine(output)})
+			continue
+		}
+		results = append(results, VerbResult{Verb: verb, OK: true})
+		installed = append(installed, verb)
+	}
+	return results, installed
+}
+
+// skippedVerbs reports the same reason for every verb
+func skippedVerbs(verbs []string, reason string) []VerbResult {
+	results := make([]VerbResult, len(verbs))
+	for i, verb := range verbs {
+		results[i] = VerbResult{Verb: verb, Error: reason}
+	}
+	return results
+}
+
+// PrefixTool returns the tool that installs verbs on the device, "" when
+// neither protontricks nor winetricks is installed
+func PrefixTool(client *device.Client) string {
+	output, _ := client.RunCommand(`command -v protontricks >/dev/null && echo protontricks ||
+{ flatpak info com.github.Matoking.protontricks >/dev/null 2>&1 && echo protontricks-flatpak; } ||
+{ command -v winetricks >/dev/null && echo winetricks; } || true`)
+	return strings.TrimSpace(output)
+}
+
+// VerbsCommand returns the command that installs verbs into the prefix of an
+// app ID with the given tool
+func VerbsCommand(tool string, appID uint32, prefixPath string, verbs []string) (string, error) {
+	joined := strings.Join(verbs, " ")
+	switch tool {
+	case "protontricks":
+		return fmt.Sprintf("protontricks %d -q %s", appID, joined), nil
+	case "protontricks-flatpak":
+		return fmt.Sprintf("flatpak run com.github.Matoking.protontricks %d -q %s", appID, joined), nil
+	case "winetricks":
+		return fmt.Sprintf("WINEPREFIX=%s winetricks -q %s", device.ShellQuote(path.Join(prefixPath, "pfx")), joined), nil
+	}
+	return "", fmt.Errorf("neither protontricks nor winetricks is installed on the device")
+}
+
+// LastLine returns the last non-empty line of a command's output, which is
+// where winetricks reports why it failed
+func LastLine(output string) string {
+	lines := strings.Split(strings.TrimSpace(output), "\n")
+	return strings.TrimSpace(lines[len(lines)-1])
+}
+
+// CompatDataPath returns the Proton prefix directory Steam uses for an app ID
+func CompatDataPath(homeDir string, appID uint32) string {
+	return path.Join(homeDir, ".steam", "steam", "steamapps", "compatdata", strconv.FormatUint(uint64(appID), 10))
+}
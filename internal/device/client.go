@@ -1,11 +1,17 @@
 package device
 
 import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"fmt"
 	"io"
+	"log/slog"
 	"os"
 	"path/filepath"
 	"strings"
+	"sync/atomic"
 
 	"github.com/pkg/sftp"
 	"golang.org/x/crypto/ssh"
@@ -18,8 +24,14 @@ type Client struct {
 	user       string
 	password   string
 	keyFile    string
+	maxPacket  int
+	knownHosts string // see SetKnownHosts
 	sshClient  *ssh.Client
 	sftpClient *sftp.Client
+	// Bandwidth cap of uploads, see SetUploadLimit
+	uploadLimit limiter
+	// See SetUploadCounter
+	uploadCounter atomic.Pointer[func(n int64)]
 }
 
 // NewClient creates a new device client
@@ -33,11 +45,21 @@ func NewClient(host string, port int, user, password, keyFile string) (*Client,
 	}, nil
 }
 
+// SetMaxPacket sets the SFTP packet size in bytes used by the next Connect.
+// Larger packets speed up transfers on fast links; 0 keeps the sftp default.
+func (c *Client) SetMaxPacket(size int) {
+	c.maxPacket = size
+}
+
 // Connect establishes SSH and SFTP connections
 func (c *Client) Connect() error {
+	addr := fmt.Sprintf("%s:%d", c.host, c.port)
 	config := &ssh.ClientConfig{
 		User:            c.user,
-		HostKeyCallback: ssh.InsecureIgnoreHostKey(),
+		HostKeyCallback: c.hostKeyCallback(),
+	}
+	if c.knownHosts != "" {
+		config.HostKeyAlgorithms = c.hostKeyAlgorithms(addr)
 	}
 
 	// Try key-based auth first
@@ -58,7 +80,6 @@ func (c *Client) Connect() error {
 	}
 
 	// Connect SSH
-	addr := fmt.Sprintf("%s:%d", c.host, c.port)
 	sshClient, err := ssh.Dial("tcp", addr, config)
 	if err != nil {
 		return fmt.Errorf("SSH connection failed: %w", err)
@@ -66,7 +87,11 @@ func (c *Client) Connect() error {
 	c.sshClient = sshClient
 
 	// Create SFTP client
-	sftpClient, err := sftp.NewClient(sshClient)
+	var opts []sftp.ClientOption
+	if c.maxPacket > 0 {
+		opts = append(opts, sftp.MaxPacketUnchecked(c.maxPacket))
+	}
+	sftpClient, err := sftp.NewClient(sshClient, opts...)
 	if err != nil {
 		sshClient.Close()
 		return fmt.Errorf("SFTP connection failed: %w", err)
@@ -95,7 +120,21 @@ func (c *Client) MkdirAll(remotePath string) error {
 	return c.sftpClient.MkdirAll(remotePath)
 }
 
-// UploadFile uploads a single file to the remote host
+// ResumeMinSize is the size from which an upload is written to a partial file
+// next to its destination, which an interrupted upload of the same file
+// resumes from where it stopped. Smaller files start over.
+const ResumeMinSize = 32 << 20
+
+// partialSuffix names the file a large upload is written to until it is complete
+const partialSuffix = ".devkit-part"
+
+// resumeCheckSize is how much of the end of a partial file must match the
+// local file to resume after it
+const resumeCheckSize = 1 << 20
+
+// UploadFile uploads a single file to the remote host. Files of ResumeMinSize
+// and up replace the remote file once complete, resuming the partial upload
+// an earlier attempt left behind.
 func (c *Client) UploadFile(localPath, remotePath string) error {
 	// Normalize remote path for Unix
 	remotePath = strings.ReplaceAll(remotePath, "\\", "/")
@@ -113,26 +152,245 @@ func (c *Client) UploadFile(localPath, remotePath string) error {
 		return fmt.Errorf("failed to stat local file: %w", err)
 	}
 
-	// Create remote file
-	remoteFile, err := c.sftpClient.Create(remotePath)
+	target := remotePath
+	var offset int64
+	if localInfo.Size() >= ResumeMinSize {
+		target = remotePath + partialSuffix
+		offset = c.partialOffset(localFile, localInfo, target)
+	}
+
+	// Create remote file, or open the partial one to append to
+	var remoteFile *sftp.File
+	if offset > 0 {
+		remoteFile, err = c.sftpClient.OpenFile(target, os.O_WRONLY)
+		if err == nil {
+			_, err = remoteFile.Seek(offset, io.SeekStart)
+		}
+	} else {
+		remoteFile, err = c.sftpClient.Create(target)
+	}
 	if err != nil {
 		return fmt.Errorf("failed to create remote file: %w", err)
 	}
 	defer remoteFile.Close()
 
 	// Copy contents
-	_, err = io.Copy(remoteFile, localFile)
+	_, err = io.Copy(remoteFile, c.uploadReader(io.NewSectionReader(localFile, offset, localInfo.Size()-offset)))
 	if err != nil {
 		return fmt.Errorf("failed to copy file: %w", err)
 	}
+	if err := remoteFile.Close(); err != nil {
+		return fmt.Errorf("failed to write file: %w", err)
+	}
+	if target != remotePath {
+		if err := c.sftpClient.PosixRename(target, remotePath); err != nil {
+			return fmt.Errorf("failed to replace remote file: %w", err)
+		}
+	}
+
+	// Keep the local modification time so later deploys can skip unchanged files
+	if err := c.sftpClient.Chtimes(remotePath, localInfo.ModTime(), localInfo.ModTime()); err != nil {
+		slog.Warn("Failed to set modification time", "path", remotePath, "error", err)
+	}
 
 	// Set permissions (preserve executable bit)
 	mode := localInfo.Mode()
 	if err := c.sftpClient.Chmod(remotePath, mode); err != nil {
 		// Non-fatal, just log
-		fmt.Printf("Warning: failed to set permissions on %s: %v\n", remotePath, err)
+		slog.Warn("Failed to set permissions", "path", remotePath, "error", err)
+	}
+
+	return nil
+}
+
+// partialOffset returns where to resume the partial upload of a local file
+// left at partialPath, or 0 to start over
+func (c *Client) partialOffset(localFile *os.File, localInfo os.FileInfo, partialPath string) int64 {
+	partialInfo, err := c.sftpClient.Stat(partialPath)
+	if err != nil {
+		return 0
+	}
+	partialFile, err := c.sftpClient.Open(partialPath)
+	if err != nil {
+		return 0
+	}
+	defer partialFile.Close()
+	return resumeOffset(localFile, localInfo, partialFile, partialInfo)
+}
+
+// resumeOffset returns where to resume a partial upload of a local file, or 0
+// to start over. The local file must be of ResumeMinSize or more, the partial
+// file shorter than it, written after the local file last changed, and end
+// with the same bytes as the local file at that offset, compared by hash.
+func resumeOffset(local io.ReaderAt, localInfo os.FileInfo, partial io.ReaderAt, partialInfo os.FileInfo) int64 {
+	if localInfo.Size() < ResumeMinSize || partialInfo.Size() >= localInfo.Size() || partialInfo.ModTime().Before(localInfo.ModTime()) {
+		return 0
+	}
+	offset := partialInfo.Size()
+	checked := min(offset, resumeCheckSize)
+
+	remoteHash := sha256.New()
+	if _, err := io.Copy(remoteHash, io.NewSectionReader(partial, offset-checked, checked)); err != nil {
+		return 0
+	}
+	localHash := sha256.New()
+	if _, err := io.Copy(localHash, io.NewSectionReader(local, offset-checked, checked)); err != nil {
+		return 0
+	}
+	if !bytes.Equal(remoteHash.Sum(nil), localHash.Sum(nil)) {
+		return 0
+	}
+	return offset
+}
+
+// PatchFile writes a new version of a remote file from a local one, sending
+// only its blocks of blockSize bytes given by index. The remote file is copied
+// to a partial file next to it, where the blocks are rewritten, and the copy
+// replaces the remote file once it hashes to wantHash (hex SHA-256), so a
+// patch that stops halfway or doesn't match leaves the remote file as it was.
+// The local modification time is kept, like UploadFile.
+func (c *Client) PatchFile(localPath, remotePath string, blockSize int64, blocks []int, wantHash string) (err error) {
+	remotePath = strings.ReplaceAll(remotePath, "\\", "/")
+
+	localFile, err := os.Open(localPath)
+	if err != nil {
+		return fmt.Errorf("failed to open local file: %w", err)
+	}
+	defer localFile.Close()
+	localInfo, err := localFile.Stat()
+	if err != nil {
+		return fmt.Errorf("failed to stat local file: %w", err)
+	}
+
+	// The copy is dated before any build, so an upload of the whole file never
+	// resumes from a patch that stopped halfway
+	target := remotePath + partialSuffix
+	if _, err := c.RunCommand(fmt.Sprintf("cp --reflink=auto -- %s %s && touch -m -d @0 -- %s",
+		ShellQuote(remotePath), ShellQuote(target), ShellQuote(target))); err != nil {
+		return fmt.Errorf("failed to copy remote file: %w", err)
+	}
+	defer func() {
+		if err != nil {
+			c.sftpClient.Remove(target)
+		}
+	}()
+
+	remoteFile, err := c.sftpClient.OpenFile(target, os.O_WRONLY)
+	if err != nil {
+		return fmt.Errorf("failed to open remote file: %w", err)
+	}
+	defer remoteFile.Close()
+
+	for _, block := range blocks {
+		offset := int64(block) * blockSize
+		if _, err := remoteFile.Seek(offset, io.SeekStart); err != nil {
+			return fmt.Errorf("failed to seek remote file: %w", err)
+		}
+		if _, err := io.Copy(remoteFile, c.uploadReader(io.NewSectionReader(localFile, offset, blockSize))); err != nil {
+			return fmt.Errorf("failed to write block %d: %w", block, err)
+		}
+	}
+	if err := remoteFile.Truncate(localInfo.Size()); err != nil {
+		return fmt.Errorf("failed to truncate remote file: %w", err)
+	}
+	if err := remoteFile.Close(); err != nil {
+		return fmt.Errorf("failed to write file: %w", err)
+	}
+
+	output, err := c.RunCommand("sha256sum -- " + ShellQuote(target))
+	if err != nil {
+		return fmt.Errorf("failed to check patched file: %w", err)
+	}
+	if fields := strings.Fields(output); len(fields) == 0 || fields[0] != wantHash {
+		return fmt.Errorf("patched file doesn't match the local one")
+	}
+
+	if err := c.sftpClient.Chtimes(target, localInfo.ModTime(), localInfo.ModTime()); err != nil {
+		slog.Warn("Failed to set modification time", "path", target, "error", err)
+	}
+	if err := c.sftpClient.Chmod(target, localInfo.Mode()); err != nil {
+		slog.Warn("Failed to set permissions", "path", target, "error", err)
+	}
+	if err := c.sftpClient.PosixRename(target, remotePath); err != nil {
+		return fmt.Errorf("failed to replace remote file: %w", err)
+	}
+	return nil
+}
+
+// UploadRange uploads size bytes of a local file from offset to a new remote
+// file, which only appears once complete. The bytes sent must hash to
+// wantHash (hex SHA-256), otherwise the local file changed since it was
+// hashed and the remote file is discarded.
+func (c *Client) UploadRange(localPath string, offset, size int64, remotePath, wantHash string) error {
+	remotePath = strings.ReplaceAll(remotePath, "\\", "/")
+
+	localFile, err := os.Open(localPath)
+	if err != nil {
+		return fmt.Errorf("failed to open local file: %w", err)
+	}
+	defer localFile.Close()
+
+	target := remotePath + partialSuffix
+	remoteFile, err := c.sftpClient.Create(target)
+	if err != nil {
+		return fmt.Errorf("failed to create remote file: %w", err)
 	}
+	defer remoteFile.Close()
 
+	sent := sha256.New()
+	section := io.TeeReader(io.NewSectionReader(localFile, offset, size), sent)
+	if _, err := io.Copy(remoteFile, c.uploadReader(section)); err != nil {
+		return fmt.Errorf("failed to copy file: %w", err)
+	}
+	if err := remoteFile.Close(); err != nil {
+		return fmt.Errorf("failed to write file: %w", err)
+	}
+	if got := hex.EncodeToString(sent.Sum(nil)); got != wantHash {
+		c.sftpClient.Remove(target)
+		return fmt.Errorf("local file changed during upload: expected hash %s, sent %s", wantHash, got)
+	}
+	if err := c.sftpClient.PosixRename(target, remotePath); err != nil {
+		return fmt.Errorf("failed to rename remote file: %w", err)
+	}
+	return nil
+}
+
+// CopyFileTo streams a file from this host to another device, keeping its
+// permissions and modification time
+func (c *Client) CopyFileTo(remotePath string, dst *Client, dstPath string) error {
+	remotePath = strings.ReplaceAll(remotePath, "\\", "/")
+	dstPath = strings.ReplaceAll(dstPath, "\\", "/")
+
+	srcFile, err := c.sftpClient.Open(remotePath)
+	if err != nil {
+		return fmt.Errorf("failed to open source file: %w", err)
+	}
+	defer srcFile.Close()
+
+	srcInfo, err := srcFile.Stat()
+	if err != nil {
+		return fmt.Errorf("failed to stat source file: %w", err)
+	}
+
+	dstFile, err := dst.sftpClient.Create(dstPath)
+	if err != nil {
+		return fmt.Errorf("failed to create destination file: %w", err)
+	}
+	defer dstFile.Close()
+
+	if _, err := io.Copy(dstFile, dst.uploadReader(srcFile)); err != nil {
+		return fmt.Errorf("failed to copy file: %w", err)
+	}
+	if err := dstFile.Close(); err != nil {
+		return fmt.Errorf("failed to write file: %w", err)
+	}
+
+	if err := dst.sftpClient.Chtimes(dstPath, srcInfo.ModTime(), srcInfo.ModTime()); err != nil {
+		slog.Warn("Failed to set modification time", "path", dstPath, "error", err)
+	}
+	if err := dst.sftpClient.Chmod(dstPath, srcInfo.Mode()); err != nil {
+		slog.Warn("Failed to set permissions", "path", dstPath, "error", err)
+	}
 	return nil
 }
 
@@ -186,6 +444,121 @@ func (c *Client) RunCommand(cmd string) (string, error) {
 	return string(output), nil
 }
 
+// StreamCommand executes a command on the remote host and copies its standard
+// output to w as it is produced
+func (c *Client) StreamCommand(cmd string, w io.Writer) error {
+	session, err := c.sshClient.NewSession()
+	if err != nil {
+		return fmt.Errorf("failed to create session: %w", err)
+	}
+	defer session.Close()
+
+	var stderr strings.Builder
+	session.Stdout = w
+	session.Stderr = &stderr
+	if err := session.Run(cmd); err != nil {
+		return fmt.Errorf("command failed: %w\nOutput: %s", err, stderr.String())
+	}
+	return nil
+}
+
+// StreamCommandContext is StreamCommand for commands that run until stopped.
+// When ctx is cancelled the command's standard input is closed and the session
+// ends, so the command should exit once its input reaches EOF.
+func (c *Client) StreamCommandContext(ctx context.Context, cmd string, w io.Writer) error {
+	session, err := c.sshClient.NewSession()
+	if err != nil {
+		return fmt.Errorf("failed to create session: %w", err)
+	}
+	defer session.Close()
+
+	stdin, err := session.StdinPipe()
+	if err != nil {
+		return fmt.Errorf("failed to open stdin: %w", err)
+	}
+	var stderr strings.Builder
+	session.Stdout = w
+	session.Stderr = &stderr
+	if err := session.Start(cmd); err != nil {
+		return fmt.Errorf("failed to start command: %w", err)
+	}
+
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		select {
+		case <-ctx.Done():
+			stdin.Close()
+			session.Close()
+		case <-done:
+		}
+	}()
+
+	err = session.Wait()
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+	if err != nil {
+		return fmt.Errorf("command failed: %w\nOutput: %s", err, stderr.String())
+	}
+	return nil
+}
+
+// PipeCommand executes a command on the remote host with r as its standard
+// input, e.g. to extract an archive streamed from this computer. The session
+// is closed when ctx is cancelled.
+func (c *Client) PipeCommand(ctx context.Context, cmd string, r io.Reader) error {
+	session, err := c.sshClient.NewSession()
+	if err != nil {
+		return fmt.Errorf("failed to create session: %w", err)
+	}
+	defer session.Close()
+
+	var stderr strings.Builder
+	session.Stdin = c.uploadReader(r)
+	session.Stderr = &stderr
+	if err := session.Start(cmd); err != nil {
+		return fmt.Errorf("failed to start command: %w", err)
+	}
+
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		select {
+		case <-ctx.Done():
+			session.Close()
+		case <-done:
+		}
+	}()
+
+	err = session.Wait()
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+	if err != nil {
+		return fmt.Errorf("command failed: %w\nOutput: %s", err, stderr.String())
+	}
+	return nil
+}
+
+// Chmod changes the permissions of a file on the remote host
+func (c *Client) Chmod(remotePath string, mode os.FileMode) error {
+	remotePath = strings.ReplaceAll(remotePath, "\\", "/")
+	return c.sftpClient.Chmod(remotePath, mode)
+}
+
+// Symlink creates linkPath on the remote host pointing to target, replacing
+// a file already there
+func (c *Client) Symlink(target, linkPath string) error {
+	linkPath = strings.ReplaceAll(linkPath, "\\", "/")
+	if info, err := c.sftpClient.Lstat(linkPath); err == nil && !info.IsDir() {
+		if err := c.sftpClient.Remove(linkPath); err != nil {
+			return err
+		}
+	}
+	return c.sftpClient.Symlink(target, linkPath)
+}
+
 // FileExists checks if a file exists on the remote host
 func (c *Client) FileExists(remotePath string) bool {
 	remotePath = strings.ReplaceAll(remotePath, "\\", "/")
@@ -221,12 +594,72 @@ func (c *Client) WriteFile(remotePath string, data []byte, perm os.FileMode) err
 
 	// Set permissions
 	if err := c.sftpClient.Chmod(remotePath, perm); err != nil {
-		fmt.Printf("Warning: failed to set permissions on %s: %v\n", remotePath, err)
+		slog.Warn("Failed to set permissions", "path", remotePath, "error", err)
 	}
 
 	return nil
 }
 
+// ReadFile reads a whole file from the remote host
+func (c *Client) ReadFile(remotePath string) ([]byte, error) {
+	remotePath = strings.ReplaceAll(remotePath, "\\", "/")
+
+	remoteFile, err := c.sftpClient.Open(remotePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open remote file: %w", err)
+	}
+	defer remoteFile.Close()
+
+	return io.ReadAll(remoteFile)
+}
+
+// ListDir returns the names of the files in a remote directory
+func (c *Client) ListDir(remotePath string) ([]string, error) {
+	remotePath = strings.ReplaceAll(remotePath, "\\", "/")
+
+	entries, err := c.sftpClient.ReadDir(remotePath)
+	if err != nil {
+		return nil, err
+	}
+
+	var names []string
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			names = append(names, entry.Name())
+		}
+	}
+	return names, nil
+}
+
+// ReadDir returns the entries of a remote directory, including subdirectories
+func (c *Client) ReadDir(remotePath string) ([]os.FileInfo, error) {
+	remotePath = strings.ReplaceAll(remotePath, "\\", "/")
+	return c.sftpClient.ReadDir(remotePath)
+}
+
+// FileSize returns the size in bytes of a remote file
+func (c *Client) FileSize(remotePath string) (int64, error) {
+	remotePath = strings.ReplaceAll(remotePath, "\\", "/")
+
+	info, err := c.sftpClient.Stat(remotePath)
+	if err != nil {
+		return 0, err
+	}
+	return info.Size(), nil
+}
+
+// Remove deletes a remote file or empty directory
+func (c *Client) Remove(remotePath string) error {
+	remotePath = strings.ReplaceAll(remotePath, "\\", "/")
+	return c.sftpClient.Remove(remotePath)
+}
+
+// ShellQuote quotes s as a single word for the device's shell, for paths and
+// names interpolated into RunCommand and PipeCommand
+func ShellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
 // expandPath expands ~ to home directory
 func expandPath(path string) string {
 	if len(path) > 0 && path[0] == '~' {
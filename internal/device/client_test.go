@@ -0,0 +1,241 @@
+package device
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/pkg/sftp"
+)
+
+func TestShellQuote(t *testing.T) {
+	tests := []struct {
+		in   string
+		want string
+	}{
+		{"", `''`},
+		{"/home/deck/Games/My Game", `'/home/deck/Games/My Game'`},
+		{"it's", `'it'\''s'`},
+		{`$(rm -rf ~) "x" \n`, `'$(rm -rf ~) "x" \n'`},
+	}
+	for _, tt := range tests {
+		if got := ShellQuote(tt.in); got != tt.want {
+			t.Errorf("ShellQuote(%q) = %s, want %s", tt.in, got, tt.want)
+		}
+	}
+}
+
+// pattern is file content made up on read, so large files need no memory.
+// Different seeds give different bytes at every offset.
+type pattern struct{ seed byte }
+
+func (p pattern) ReadAt(b []byte, off int64) (int, error) {
+	for i := range b {
+		b[i] = byte((off+int64(i))%251) + p.seed
+	}
+	return len(b), nil
+}
+
+// fileInfo is the size and modification time of a file
+type fileInfo struct {
+	size    int64
+	modTime time.Time
+}
+
+func (f fileInfo) Name() string       { return "file" }
+func (f fileInfo) Size() int64        { return f.size }
+func (f fileInfo) Mode() fs.FileMode  { return 0644 }
+func (f fileInfo) ModTime() time.Time { return f.modTime }
+func (f fileInfo) IsDir() bool        { return false }
+func (f fileInfo) Sys() any           { return nil }
+
+func TestResumeOffset(t *testing.T) {
+	built := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	later := built.Add(time.Hour)
+	size := int64(ResumeMinSize + 3*resumeCheckSize)
+
+	tests := []struct {
+		name    string
+		local   fileInfo
+		partial fileInfo
+		content pattern
+		want    int64
+	}{
+		{
+			name:    "partial file of the same bytes",
+			local:   fileInfo{size, built},
+			partial: fileInfo{size / 2, later},
+			want:    size / 2,
+		},
+		{
+			name:    "partial file shorter than the hash check",
+			local:   fileInfo{size, built},
+			partial: fileInfo{resumeCheckSize / 4, later},
+			want:    resumeCheckSize / 4,
+		},
+		{
+			name:    "empty partial file",
+			local:   fileInfo{size, built},
+			partial: fileInfo{0, later},
+		},
+		{
+			name:    "trailing hash mismatch",
+			local:   fileInfo{size, built},
+			partial: fileInfo{size / 2, later},
+			content: pattern{seed: 1},
+		},
+		{
+			name:    "partial file as long as the local one",
+			local:   fileInfo{size, built},
+			partial: fileInfo{size, later},
+		},
+		{
+			name:    "partial file longer than the local one",
+			local:   fileInfo{size, built},
+			partial: fileInfo{size + 1, later},
+		},
+		{
+			name:    "local file changed after the partial upload",
+			local:   fileInfo{size, later},
+			partial: fileInfo{size / 2, built},
+		},
+		{
+			name:    "local file under ResumeMinSize",
+			local:   fileInfo{ResumeMinSize - 1, built},
+			partial: fileInfo{ResumeMinSize / 2, later},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := resumeOffset(pattern{}, tt.local, tt.content, tt.partial); got != tt.want {
+				t.Errorf("resumeOffset() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+// newTestClient returns a client whose SFTP connection is served by this
+// computer, so uploads write to local paths
+func newTestClient(t *testing.T) *Client {
+	t.Helper()
+	toServer, fromClient := io.Pipe()
+	toClient, fromServer := io.Pipe()
+	server, err := sftp.NewServer(struct {
+		io.Reader
+		io.WriteCloser
+	}{toServer, fromServer})
+	if err != nil {
+		t.Fatal(err)
+	}
+	go server.Serve()
+	client, err := sftp.NewClientPipe(toClient, fromClient)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		server.Close()
+		client.Close()
+	})
+	return &Client{sftpClient: client}
+}
+
+// writePattern writes size bytes of p to a file
+func writePattern(t *testing.T, name string, p pattern, size int64) []byte {
+	t.Helper()
+	data := make([]byte, size)
+	p.ReadAt(data, 0)
+	if err := os.WriteFile(name, data, 0644); err != nil {
+		t.Fatal(err)
+	}
+	return data
+}
+
+func TestUploadFile_Resume(t *testing.T) {
+	tests := []struct {
+		name    string
+		partial pattern
+	}{
+		{"resumes a partial file of the same bytes", pattern{}},
+		{"starts over after a trailing hash mismatch", pattern{seed: 1}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			local := filepath.Join(dir, "game.pak")
+			remote := filepath.Join(dir, "remote.pak")
+			want := writePattern(t, local, pattern{}, ResumeMinSize+resumeCheckSize)
+			writePattern(t, remote+partialSuffix, tt.partial, ResumeMinSize/2)
+			future := time.Now().Add(time.Hour)
+			os.Chtimes(remote+partialSuffix, future, future)
+
+			var sent int64
+			client := newTestClient(t)
+			client.SetUploadCounter(func(n int64) { sent += n })
+			if err := client.UploadFile(local, remote); err != nil {
+				t.Fatal(err)
+			}
+
+			got, err := os.ReadFile(remote)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.Equal(got, want) {
+				t.Error("uploaded file doesn't match the local one")
+			}
+			if _, err := os.Stat(remote + partialSuffix); !os.IsNotExist(err) {
+				t.Errorf("partial file left behind: %v", err)
+			}
+			wantSent := int64(len(want))
+			if tt.partial == (pattern{}) {
+				wantSent -= ResumeMinSize / 2
+			}
+			if sent != wantSent {
+				t.Errorf("sent %d bytes, want %d", sent, wantSent)
+			}
+		})
+	}
+}
+
+func TestUploadRange(t *testing.T) {
+	dir := t.TempDir()
+	local := filepath.Join(dir, "game.pak")
+	data := writePattern(t, local, pattern{}, 4096)
+	sum := sha256.Sum256(data[1024:3072])
+
+	tests := []struct {
+		name     string
+		wantHash string
+		wantErr  bool
+	}{
+		{"bytes match the hash", hex.EncodeToString(sum[:]), false},
+		{"local file changed since hashed", "0000", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			remote := filepath.Join(t.TempDir(), "chunk")
+			err := newTestClient(t).UploadRange(local, 1024, 2048, remote, tt.wantHash)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("UploadRange() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if _, err := os.Stat(remote + partialSuffix); !os.IsNotExist(err) {
+				t.Errorf("partial file left behind: %v", err)
+			}
+			got, err := os.ReadFile(remote)
+			if tt.wantErr {
+				if !os.IsNotExist(err) {
+					t.Errorf("remote file created for a range that didn't match: %v", err)
+				}
+				return
+			}
+			if !bytes.Equal(got, data[1024:3072]) {
+				t.Errorf("remote file = %d bytes that don't match the range", len(got))
+			}
+		})
+	}
+}
@@ -0,0 +1,185 @@
+package device
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+
+	"golang.org/x/crypto/ssh"
+	"golang.org/x/crypto/ssh/knownhosts"
+)
+
+// UnknownHostKeyError is returned by Connect for a device whose host key isn't
+// in the known hosts file yet. The user should confirm its fingerprint, e.g.
+// against ssh-keygen -lf on the device, before TrustHostKey adds it.
+type UnknownHostKeyError struct {
+	Host string // host:port dialed
+	Key  ssh.PublicKey
+}
+
+func (e *UnknownHostKeyError) Error() string {
+	return fmt.Sprintf("the host key of %s is not trusted yet (%s %s)", e.Host, e.Key.Type(), ssh.FingerprintSHA256(e.Key))
+}
+
+// HostKeyChangedError is returned by Connect when a device presents another
+// host key than the one trusted for it: either the device was reinstalled or
+// something is intercepting the connection.
+type HostKeyChangedError struct {
+	Host string // host:port dialed
+	Key  ssh.PublicKey
+}
+
+func (e *HostKeyChangedError) Error() string {
+	return fmt.Sprintf("the host key of %s has changed to %s %s; if the device wasn't reinstalled, someone may be intercepting the connection",
+		e.Host, e.Key.Type(), ssh.FingerprintSHA256(e.Key))
+}
+
+// SetKnownHosts sets the known_hosts file the host key of the device is
+// checked against on Connect. Without one any host key is accepted.
+func (c *Client) SetKnownHosts(path string) {
+	c.knownHosts = path
+}
+
+// hostKeyCallback checks host keys against the known hosts file of the client
+func (c *Client) hostKeyCallback() ssh.HostKeyCallback {
+	if c.knownHosts == "" {
+		return ssh.InsecureIgnoreHostKey()
+	}
+	return func(hostname string, remote net.Addr, key ssh.PublicKey) error {
+		if _, err := os.Stat(c.knownHosts); errors.Is(err, os.ErrNotExist) {
+			return &UnknownHostKeyError{Host: hostname, Key: key}
+		}
+		check, err := knownhosts.New(c.knownHosts)
+		if err != nil {
+			return fmt.Errorf("failed to read known hosts: %w", err)
+		}
+		err = check(hostname, remote, key)
+		var keyErr *knownhosts.KeyError
+		if errors.As(err, &keyErr) {
+			if len(keyErr.Want) == 0 {
+				return &UnknownHostKeyError{Host: hostname, Key: key}
+			}
+			return &HostKeyChangedError{Host: hostname, Key: key}
+		}
+		return err
+	}
+}
+
+// hostKeyAlgorithms returns the algorithms of the keys known for a host, so
+// the device is asked for a key that can be checked, nil for an unknown host
+func (c *Client) hostKeyAlgorithms(addr string) []string {
+	data, err := os.ReadFile(c.knownHosts)
+	if err != nil {
+		return nil
+	}
+	var algorithms []string
+	for _, entry := range knownHostEntries(data, knownhosts.Normalize(addr)) {
+		_, _, key, _, _, err := ssh.ParseKnownHosts([]byte(entry))
+		if err != nil {
+			continue
+		}
+		// RSA keys sign with SHA-2 algorithms named apart from the key type
+		switch key.Type() {
+		case ssh.KeyAlgoRSA:
+			algorithms = append(algorithms, ssh.KeyAlgoRSASHA512, ssh.KeyAlgoRSASHA256, ssh.KeyAlgoRSA)
+		default:
+			algorithms = append(algorithms, key.Type())
+		}
+	}
+	return algorithms
+}
+
+// TrustHostKey adds the host key of a device to a known_hosts file, replacing
+// the keys it had for the host. The file is only readable by its owner.
+func TrustHostKey(knownHostsPath, addr string, key ssh.PublicKey) error {
+	host := knownhosts.Normalize(addr)
+	data, err := os.ReadFile(knownHostsPath)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("failed to read known hosts: %w", err)
+	}
+
+	var out strings.Builder
+	for _, line := range strings.Split(string(data), "\n") {
+		if strings.TrimSpace(line) != "" && !listsHost(line, host) {
+			out.WriteString(line + "\n")
+		}
+	}
+	out.WriteString(knownhosts.Line([]string{host}, key) + "\n")
+
+	if err := os.MkdirAll(filepath.Dir(knownHostsPath), 0755); err != nil {
+		return err
+	}
+	if err := os.WriteFile(knownHostsPath, []byte(out.String()), 0600); err != nil {
+		return fmt.Errorf("failed to write known hosts: %w", err)
+	}
+	return nil
+}
+
+// knownHostEntries returns the lines of known_hosts data listing a host
+func knownHostEntries(data []byte, host string) []string {
+	var entries []string
+	for _, line := range strings.Split(string(data), "\n") {
+		if listsHost(line, host) {
+			entries = append(entries, line)
+		}
+	}
+	return entries
+}
+
+// listsHost reports whether a known_hosts line lists a host, normalized like
+// knownhosts.Normalize. Hashed hosts and marked lines are not matched.
+func listsHost(line, host string) bool {
+	fields := strings.Fields(line)
+	if len(fields) < 3 || strings.HasPrefix(fields[0], "#") || strings.HasPrefix(fields[0], "@") {
+		return false
+	}
+	return slices.Contains(strings.Split(fields[0], ","), host)
+}
+
+// TunnelSSH listens on a local port whose connections reach the SSH server of
+// the device through this client's connection, so another SSH client talks to
+// the device whose host key was checked rather than to whatever answers on the
+// network. Closing the listener stops the tunnel.
+func (c *Client) TunnelSSH() (net.Listener, error) {
+	if c.sshClient == nil {
+		return nil, fmt.Errorf("not connected")
+	}
+	port := c.port
+	if port == 0 {
+		port = 22
+	}
+	target := net.JoinHostPort("127.0.0.1", strconv.Itoa(port))
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return nil, fmt.Errorf("failed to open tunnel: %w", err)
+	}
+	sshClient := c.sshClient
+	go func() {
+		for {
+			local, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				defer local.Close()
+				remote, err := sshClient.Dial("tcp", target)
+				if err != nil {
+					return
+				}
+				defer remote.Close()
+				done := make(chan struct{}, 2)
+				go func() { io.Copy(remote, local); done <- struct{}{} }()
+				go func() { io.Copy(local, remote); done <- struct{}{} }()
+				<-done
+			}()
+		}
+	}()
+	return ln, nil
+}
@@ -0,0 +1,117 @@
+package device
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os/exec"
+	"strconv"
+	"strings"
+)
+
+// RsyncAvailable reports whether uploads can go through rsync: it must be
+// installed on this computer and on the device, and the device must take the
+// client's SSH key, since rsync opens its own connection with the ssh command.
+func (c *Client) RsyncAvailable() bool {
+	if c.keyFile == "" {
+		return false
+	}
+	for _, tool := range []string{"rsync", "ssh"} {
+		if _, err := exec.LookPath(tool); err != nil {
+			return false
+		}
+	}
+	_, err := c.RunCommand("command -v rsync")
+	return err == nil
+}
+
+// Rsync copies files, slash separated paths relative to localDir, to
+// remoteDir with rsync, which only sends the changed parts of each file and
+// keeps partially sent ones to resume. onProgress receives the share of the
+// transfer done. The upload limit of the client applies.
+func (c *Client) Rsync(ctx context.Context, localDir, remoteDir string, files []string, onProgress func(float64)) error {
+	remoteDir = strings.ReplaceAll(remoteDir, "\\", "/")
+
+	rsh := fmt.Sprintf("ssh -p %d -i %s -o BatchMode=yes -o StrictHostKeyChecking=accept-new",
+		c.port, rsyncQuote(expandPath(c.keyFile)))
+	if c.knownHosts != "" {
+		// Connect already checked the host key against the same file
+		rsh = fmt.Sprintf("ssh -p %d -i %s -o BatchMode=yes -o StrictHostKeyChecking=yes -o UserKnownHostsFile=%s",
+			c.port, rsyncQuote(expandPath(c.keyFile)), rsyncQuote(c.knownHosts))
+	}
+	args := []string{
+		"-a", "--partial", "--compress", "--protect-args",
+		"--files-from=-", "--info=progress2", "--no-human-readable",
+		"-e", rsh,
+	}
+	if rate := c.uploadLimit.bytesPerSecond(); rate > 0 {
+		args = append(args, fmt.Sprintf("--bwlimit=%d", max(rate/1024, 1)))
+	}
+
+	host := c.host
+	if strings.Contains(host, ":") {
+		host = "[" + host + "]"
+	}
+	// Running from the game folder keeps Windows paths out of rsync's way
+	args = append(args, ".", fmt.Sprintf("%s@%s:%s/", c.user, host, remoteDir))
+
+	cmd := exec.CommandContext(ctx, "rsync", args...)
+	cmd.Dir = localDir
+	cmd.Stdin = strings.NewReader(strings.Join(files, "\n") + "\n")
+	var stderr bytes.Buffer
+	cmd.Stdout = &rsyncProgressWriter{onProgress: onProgress}
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		return fmt.Errorf("rsync failed: %w\nOutput: %s", err, strings.TrimSpace(stderr.String()))
+	}
+	return nil
+}
+
+// rsyncQuote quotes an argument of the remote shell command rsync runs
+func rsyncQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+// rsyncProgressWriter reads the overall progress rsync prints with
+// --info=progress2, lines such as "  1234567  45%  1.23MB/s  0:00:12"
+// rewritten in place with carriage returns
+type rsyncProgressWriter struct {
+	onProgress func(float64)
+	partial    []byte
+}
+
+func (w *rsyncProgressWriter) Write(p []byte) (int, error) {
+	w.partial = append(w.partial, p...)
+	for {
+		end := bytes.IndexAny(w.partial, "\r\n")
+		if end < 0 {
+			break
+		}
+		line := string(w.partial[:end])
+		w.partial = w.partial[end+1:]
+		if progress, ok := parseRsyncProgress(line); ok && w.onProgress != nil {
+			w.onProgress(progress)
+		}
+	}
+	return len(p), nil
+}
+
+// parseRsyncProgress returns the share done in a progress line of rsync
+func parseRsyncProgress(line string) (float64, bool) {
+	for _, field := range strings.Fields(line) {
+		percent, ok := strings.CutSuffix(field, "%")
+		if !ok {
+			continue
+		}
+		value, err := strconv.Atoi(percent)
+		if err != nil {
+			return 0, false
+		}
+		return float64(value) / 100, true
+	}
+	return 0, false
+}
@@ -0,0 +1,85 @@
+package device
+
+import (
+	"io"
+	"sync"
+	"time"
+)
+
+// limiter paces the bytes sent to a device. It is shared by every transfer
+// of a client, so parallel uploads split the limit instead of each using it.
+type limiter struct {
+	mu   sync.Mutex
+	rate int64     // bytes per second, 0 = unlimited
+	next time.Time // when the bytes reserved so far have been sent at the rate
+}
+
+func (l *limiter) setRate(bytesPerSecond int64) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.rate = bytesPerSecond
+	l.next = time.Time{}
+}
+
+func (l *limiter) bytesPerSecond() int64 {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.rate
+}
+
+// wait blocks until n more bytes fit in the rate
+func (l *limiter) wait(n int) {
+	l.mu.Lock()
+	if l.rate <= 0 || n <= 0 {
+		l.mu.Unlock()
+		return
+	}
+	now := time.Now()
+	if l.next.Before(now) {
+		l.next = now
+	}
+	l.next = l.next.Add(time.Duration(n) * time.Second / time.Duration(l.rate))
+	delay := l.next.Sub(now)
+	l.mu.Unlock()
+
+	time.Sleep(delay)
+}
+
+// uploadReader reads what a client sends to the device, at its upload limit
+// and reporting the bytes to its upload counter
+type uploadReader struct {
+	r io.Reader
+	c *Client
+}
+
+func (u *uploadReader) Read(p []byte) (int, error) {
+	n, err := u.r.Read(p)
+	u.c.uploadLimit.wait(n)
+	if counter := u.c.uploadCounter.Load(); counter != nil && n > 0 {
+		(*counter)(int64(n))
+	}
+	return n, err
+}
+
+// uploadReader wraps r for a transfer to the device
+func (c *Client) uploadReader(r io.Reader) io.Reader {
+	return &uploadReader{r: r, c: c}
+}
+
+// SetUploadLimit caps the bytes per second sent to the device by uploads,
+// shared by all of them; 0 removes the limit. It applies to transfers that
+// start afterwards.
+func (c *Client) SetUploadLimit(bytesPerSecond int64) {
+	c.uploadLimit.setRate(bytesPerSecond)
+}
+
+// SetUploadCounter registers a function told of the bytes sent to the device
+// by uploads, e.g. to measure their speed; nil removes it. Parallel uploads
+// call it concurrently.
+func (c *Client) SetUploadCounter(counter func(n int64)) {
+	if counter == nil {
+		c.uploadCounter.Store(nil)
+		return
+	}
+	c.uploadCounter.Store(&counter)
+}
@@ -4,7 +4,11 @@ package shortcuts
 
 import (
 	"fmt"
+	"log/slog"
+	"net"
+	"path"
 	"strings"
+	"sync"
 
 	"github.com/lobinuxsoft/capydeploy/internal/device"
 	"github.com/shadowblip/steam-shortcut-manager/pkg/remote"
@@ -23,133 +27,190 @@ type ArtworkConfig struct {
 
 // RemoteConfig holds the SSH connection parameters
 type RemoteConfig struct {
-	Host     string
-	Port     int
 	User     string
 	Password string
 	KeyFile  string
+	// Connection to the device whose host key was checked. The shortcut
+	// manager connects through it, so the password only reaches that device.
+	Device *device.Client
 }
 
-// AddShortcut adds a Steam shortcut on a remote device
-func AddShortcut(cfg *RemoteConfig, name, exe, startDir, launchOpts string, tags []string) error {
-	return AddShortcutWithArtwork(cfg, name, exe, startDir, launchOpts, tags, nil, "")
-}
-
-// AddShortcutWithArtwork adds a Steam shortcut with custom artwork on a remote device.
-// If binaryPath is provided, it will use the remote binary to apply artwork via Steam CEF API.
-// If binaryPath is empty, artwork application will be skipped.
-func AddShortcutWithArtwork(cfg *RemoteConfig, name, exe, startDir, launchOpts string, tags []string, artwork *ArtworkConfig, binaryPath string) error {
-	// Create and connect remote client
+// connect opens a shortcut manager connection to the device through the
+// tunnel of cfg.Device. The returned function closes both.
+func connect(cfg *RemoteConfig) (*remote.Client, func(), error) {
+	if cfg.Device == nil {
+		return nil, nil, fmt.Errorf("failed to connect: no verified connection to the device")
+	}
+	tunnel, err := cfg.Device.TunnelSSH()
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to connect: %w", err)
+	}
 	client := remote.NewClient(&remote.Config{
-		Host:     cfg.Host,
-		Port:     cfg.Port,
+		Host:     "127.0.0.1",
+		Port:     tunnel.Addr().(*net.TCPAddr).Port,
 		User:     cfg.User,
 		Password: cfg.Password,
 		KeyFile:  cfg.KeyFile,
 	})
-
 	if err := client.Connect(); err != nil {
-		return fmt.Errorf("failed to connect: %w", err)
+		tunnel.Close()
+		return nil, nil, fmt.Errorf("failed to connect: %w", err)
 	}
-	defer client.Close()
-
-	// Set remote clients for library packages
-	shortcut.SetRemoteClient(client)
-	steam.SetRemoteClient(client)
+	return client, func() {
+		client.Close()
+		tunnel.Close()
+	}, nil
+}
 
-	// Get all Steam users on the remote device
-	users, err := steam.GetRemoteUsers()
-	if err != nil {
-		return fmt.Errorf("failed to get Steam users: %w", err)
+// library serializes the use of the steam-shortcut-manager packages, whose
+// remote client is package-level: calls for different devices running in
+// parallel would otherwise read and write through each other's connection
+var library sync.Mutex
+
+// Hooks replaced by tests
+var (
+	connectDevice    = connect
+	setLibraryClient = func(client *remote.Client) {
+		shortcut.SetRemoteClient(client)
+		steam.SetRemoteClient(client)
 	}
+)
 
-	if len(users) == 0 {
-		return fmt.Errorf("no Steam users found on remote device")
+// withLibrary connects to the device and runs fn with the steam-shortcut-manager
+// packages set to that connection, one device at a time
+func withLibrary(cfg *RemoteConfig, fn func(client *remote.Client) error) error {
+	client, closeClient, err := connectDevice(cfg)
+	if err != nil {
+		return err
 	}
+	defer closeClient()
 
-	// Format exe and startDir with quotes (Steam expects quoted paths)
-	quotedExe := fmt.Sprintf("\"%s\"", exe)
-	quotedStartDir := fmt.Sprintf("\"%s\"", startDir)
+	library.Lock()
+	defer library.Unlock()
+	setLibraryClient(client)
+	return fn(client)
+}
 
-	// Calculate appID for artwork naming using quoted exe (matches Steam's internal calculation)
-	appID := shortcut.CalculateAppID(quotedExe, name)
-	fmt.Printf("[DEBUG] Calculated AppID for '%s' (exe: %s): %d\n", name, quotedExe, appID)
+// AddShortcut adds a Steam shortcut on a remote device
+func AddShortcut(cfg *RemoteConfig, name, exe, startDir, launchOpts string, tags []string) error {
+	return AddShortcutWithArtwork(cfg, name, exe, startDir, launchOpts, tags, nil, "")
+}
 
-	// Add shortcut for all users
-	for _, user := range users {
-		shortcutsPath, err := steam.GetRemoteShortcutsPath(user)
+// AddShortcutWithArtwork adds a Steam shortcut with custom artwork on a remote device.
+// If binaryPath is provided, it will use the remote binary to apply artwork via Steam CEF API.
+// If binaryPath is empty, artwork application will be skipped.
+func AddShortcutWithArtwork(cfg *RemoteConfig, name, exe, startDir, launchOpts string, tags []string, artwork *ArtworkConfig, binaryPath string) error {
+	return withLibrary(cfg, func(client *remote.Client) error {
+		// Get all Steam users on the remote device
+		users, err := steam.GetRemoteUsers()
 		if err != nil {
-			continue
+			return fmt.Errorf("failed to get Steam users: %w", err)
 		}
 
-		// Load existing shortcuts or create new
-		var shortcuts *shortcut.Shortcuts
-		if steam.RemoteHasShortcuts(user) {
-			shortcuts, err = shortcut.Load(shortcutsPath)
+		if len(users) == 0 {
+			return fmt.Errorf("no Steam users found on remote device")
+		}
+
+		// Format exe and startDir with quotes (Steam expects quoted paths)
+		quotedExe := fmt.Sprintf("\"%s\"", exe)
+		quotedStartDir := fmt.Sprintf("\"%s\"", startDir)
+
+		// Calculate appID for artwork naming using quoted exe (matches Steam's internal calculation)
+		appID := ShortcutAppID(exe, name)
+		slog.Debug("Calculated AppID", "name", name, "exe", quotedExe, "appID", appID)
+
+		// Add shortcut for all users
+		for _, user := range users {
+			shortcutsPath, err := steam.GetRemoteShortcutsPath(user)
 			if err != nil {
-				return fmt.Errorf("failed to load shortcuts for user %s: %w", user, err)
+				continue
 			}
-		} else {
-			shortcuts = shortcut.NewShortcuts()
-		}
 
-		// Create new shortcut with quoted paths
-		newShortcut := shortcut.NewShortcut(name, quotedExe, func(s *shortcut.Shortcut) {
-			s.AllowDesktopConfig = 1
-			s.AllowOverlay = 1
-			s.StartDir = quotedStartDir
-			s.LaunchOptions = launchOpts
-			s.Appid = int64(appID)
-
-			// Add tags
-			s.Tags = map[string]interface{}{}
-			for i, tag := range tags {
-				s.Tags[fmt.Sprintf("%d", i)] = tag
+			// Load existing shortcuts or create new
+			var shortcuts *shortcut.Shortcuts
+			if steam.RemoteHasShortcuts(user) {
+				shortcuts, err = shortcut.Load(shortcutsPath)
+				if err != nil {
+					return fmt.Errorf("failed to load shortcuts for user %s: %w", user, err)
+				}
+			} else {
+				shortcuts = shortcut.NewShortcuts()
 			}
-		})
 
-		// Add to shortcuts collection
-		if err := shortcuts.Add(newShortcut); err != nil {
-			return fmt.Errorf("failed to add shortcut for user %s: %w", user, err)
-		}
+			// Create new shortcut with quoted paths
+			newShortcut := shortcut.NewShortcut(name, quotedExe, func(s *shortcut.Shortcut) {
+				s.AllowDesktopConfig = 1
+				s.AllowOverlay = 1
+				s.StartDir = quotedStartDir
+				s.LaunchOptions = launchOpts
+				s.Appid = int64(appID)
+
+				// Add tags
+				s.Tags = map[string]interface{}{}
+				for i, tag := range tags {
+					s.Tags[fmt.Sprintf("%d", i)] = tag
+				}
+			})
 
-		// Save shortcuts
-		if err := shortcut.Save(shortcuts, shortcutsPath); err != nil {
-			return fmt.Errorf("failed to save shortcuts for user %s: %w", user, err)
-		}
+			// Add to shortcuts collection
+			if err := shortcuts.Add(newShortcut); err != nil {
+				return fmt.Errorf("failed to add shortcut for user %s: %w", user, err)
+			}
 
-		// Verify the saved shortcut by re-reading it
-		verifyShortcuts, err := shortcut.Load(shortcutsPath)
-		if err != nil {
-			fmt.Printf("[DEBUG] Failed to re-read shortcuts for verification: %v\n", err)
-		} else {
-			if savedSC, err := verifyShortcuts.LookupByName(name); err == nil {
-				fmt.Printf("[DEBUG] VERIFICATION - Saved shortcut:\n")
-				fmt.Printf("  AppName: %s\n", savedSC.AppName)
-				fmt.Printf("  Exe: %s\n", savedSC.Exe)
-				fmt.Printf("  StartDir: %s\n", savedSC.StartDir)
-				fmt.Printf("  Appid (from file): %d\n", savedSC.Appid)
-				fmt.Printf("  Expected Appid: %d\n", appID)
-				if savedSC.Appid != int64(appID) {
-					fmt.Printf("[WARNING] AppID mismatch! File has %d, expected %d\n", savedSC.Appid, appID)
-				}
+			// Save shortcuts
+			if err := shortcut.Save(shortcuts, shortcutsPath); err != nil {
+				return fmt.Errorf("failed to save shortcuts for user %s: %w", user, err)
+			}
+
+			// Verify the saved shortcut by re-reading it
+			verifyShortcuts, err := shortcut.Load(shortcutsPath)
+			if err != nil {
+				slog.Debug("Failed to re-read shortcuts for verification", "error", err)
 			} else {
-				fmt.Printf("[DEBUG] Could not find saved shortcut by name: %v\n", err)
+				if savedSC, err := verifyShortcuts.LookupByName(name); err == nil {
+					slog.Debug("Saved shortcut",
+						"name", savedSC.AppName,
+						"exe", savedSC.Exe,
+						"startDir", savedSC.StartDir,
+						"appID", savedSC.Appid,
+						"expectedAppID", appID)
+					if savedSC.Appid != int64(appID) {
+						slog.Warn("AppID mismatch", "file", savedSC.Appid, "expected", appID)
+					}
+				} else {
+					slog.Debug("Could not find saved shortcut by name", "error", err)
+				}
 			}
 		}
-	}
 
-	// Apply artwork using the remote binary if provided
-	if artwork != nil && binaryPath != "" {
-		fmt.Printf("[DEBUG] Applying artwork for AppID %d using remote binary: %s\n", appID, binaryPath)
-		if err := applyArtworkViaBinary(client, binaryPath, appID, artwork); err != nil {
-			fmt.Printf("[WARNING] Failed to apply artwork via binary: %v\n", err)
+		// Apply artwork using the remote binary if provided
+		if artwork != nil && binaryPath != "" {
+			slog.Debug("Applying artwork", "appID", appID, "binary", binaryPath)
+			if err := applyArtworkViaBinary(client, binaryPath, appID, artwork); err != nil {
+				slog.Warn("Failed to apply artwork via binary", "error", err)
+			}
+		} else if artwork != nil {
+			slog.Warn("Artwork config provided but no binary path, skipping artwork application")
 		}
-	} else if artwork != nil {
-		fmt.Printf("[WARNING] Artwork config provided but no binary path, skipping artwork application\n")
+
+		return nil
+	})
+}
+
+// ShortcutAppID returns the app ID of a shortcut created by AddShortcutWithArtwork
+func ShortcutAppID(exe, name string) uint64 {
+	return shortcut.CalculateAppID(fmt.Sprintf("\"%s\"", exe), name)
+}
+
+// ApplyArtwork applies artwork to an existing shortcut using the remote binary
+func ApplyArtwork(cfg *RemoteConfig, appID uint64, artwork *ArtworkConfig, binaryPath string) error {
+	client, closeClient, err := connect(cfg)
+	if err != nil {
+		return err
 	}
+	defer closeClient()
 
-	return nil
+	return applyArtworkViaBinary(client, binaryPath, appID, artwork)
 }
 
 // applyArtworkViaBinary executes the steam-shortcut-manager binary on the remote device
@@ -161,24 +222,24 @@ func applyArtworkViaBinary(client *remote.Client, binaryPath string, appID uint6
 	args = append(args, fmt.Sprintf("--app-id=%d", appID))
 
 	if artwork.GridPortrait != "" {
-		args = append(args, fmt.Sprintf("--grid-portrait=%q", artwork.GridPortrait))
+		args = append(args, "--grid-portrait="+device.ShellQuote(artwork.GridPortrait))
 	}
 	if artwork.GridLandscape != "" {
-		args = append(args, fmt.Sprintf("--grid-landscape=%q", artwork.GridLandscape))
+		args = append(args, "--grid-landscape="+device.ShellQuote(artwork.GridLandscape))
 	}
 	if artwork.HeroImage != "" {
-		args = append(args, fmt.Sprintf("--hero=%q", artwork.HeroImage))
+		args = append(args, "--hero="+device.ShellQuote(artwork.HeroImage))
 	}
 	if artwork.LogoImage != "" {
-		args = append(args, fmt.Sprintf("--logo=%q", artwork.LogoImage))
+		args = append(args, "--logo="+device.ShellQuote(artwork.LogoImage))
 	}
 	if artwork.IconImage != "" {
-		args = append(args, fmt.Sprintf("--icon=%q", artwork.IconImage))
+		args = append(args, "--icon="+device.ShellQuote(artwork.IconImage))
 	}
 
 	// Build full command
-	cmd := fmt.Sprintf("%q %s", binaryPath, strings.Join(args, " "))
-	fmt.Printf("[DEBUG] Executing remote command: %s\n", cmd)
+	cmd := device.ShellQuote(binaryPath) + " " + strings.Join(args, " ")
+	slog.Debug("Executing remote command", "cmd", cmd)
 
 	// Execute on remote device
 	output, err := client.RunCommand(cmd)
@@ -186,14 +247,14 @@ func applyArtworkViaBinary(client *remote.Client, binaryPath string, appID uint6
 		return fmt.Errorf("command failed: %w (output: %s)", err, output)
 	}
 
-	fmt.Printf("[DEBUG] Remote command output:\n%s\n", output)
+	slog.Debug("Remote command finished", "output", output)
 	return nil
 }
 
 // EnsureBinaryExists checks if the steam-shortcut-manager binary exists on the remote device
 // at the specified path. Returns true if it exists, false otherwise.
 func EnsureBinaryExists(client *device.Client, remotePath string) bool {
-	cmd := fmt.Sprintf("test -x %q && echo 'exists'", remotePath)
+	cmd := fmt.Sprintf("test -x %s && echo 'exists'", device.ShellQuote(remotePath))
 	output, err := client.RunCommand(cmd)
 	if err != nil {
 		return false
@@ -203,7 +264,7 @@ func EnsureBinaryExists(client *device.Client, remotePath string) bool {
 
 // UploadBinary uploads the steam-shortcut-manager binary to the remote device
 func UploadBinary(client *device.Client, binaryData []byte, remotePath string) error {
-	fmt.Printf("[DEBUG] Uploading steam-shortcut-manager binary to %s (%d bytes)\n", remotePath, len(binaryData))
+	slog.Debug("Uploading steam-shortcut-manager binary", "path", remotePath, "bytes", len(binaryData))
 
 	// Write the binary file
 	if err := client.WriteFile(remotePath, binaryData, 0755); err != nil {
@@ -211,132 +272,182 @@ func UploadBinary(client *device.Client, binaryData []byte, remotePath string) e
 	}
 
 	// Verify it's executable
-	cmd := fmt.Sprintf("chmod +x %q && test -x %q && echo 'ok'", remotePath, remotePath)
+	cmd := fmt.Sprintf("chmod +x %[1]s && test -x %[1]s && echo 'ok'", device.ShellQuote(remotePath))
 	output, err := client.RunCommand(cmd)
 	if err != nil || strings.TrimSpace(output) != "ok" {
 		return fmt.Errorf("failed to set executable permissions")
 	}
 
-	fmt.Printf("[DEBUG] Binary uploaded and verified successfully\n")
+	slog.Debug("Binary uploaded and verified")
 	return nil
 }
 
-// RemoveShortcut removes a Steam shortcut from a remote device
-func RemoveShortcut(cfg *RemoteConfig, name string) error {
-	// Create and connect remote client
-	client := remote.NewClient(&remote.Config{
-		Host:     cfg.Host,
-		Port:     cfg.Port,
-		User:     cfg.User,
-		Password: cfg.Password,
-		KeyFile:  cfg.KeyFile,
-	})
-
-	if err := client.Connect(); err != nil {
-		return fmt.Errorf("failed to connect: %w", err)
+// GridDirs returns the grid artwork directory of every Steam user on the device
+func GridDirs(client *device.Client) ([]string, error) {
+	homeDir, err := client.GetHomeDir()
+	if err != nil {
+		return nil, err
 	}
-	defer client.Close()
+	userDataDir := path.Join(homeDir, ".steam", "steam", "userdata")
 
-	// Set remote clients for library packages
-	shortcut.SetRemoteClient(client)
-	steam.SetRemoteClient(client)
-
-	// Get all Steam users
-	users, err := steam.GetRemoteUsers()
+	output, err := client.RunCommand(fmt.Sprintf("ls -1 -- %s", device.ShellQuote(userDataDir)))
 	if err != nil {
-		return fmt.Errorf("failed to get Steam users: %w", err)
+		return nil, fmt.Errorf("failed to list Steam users: %w", err)
 	}
 
-	// Remove shortcut for all users
-	for _, user := range users {
-		if !steam.RemoteHasShortcuts(user) {
-			continue
+	var dirs []string
+	for _, line := range strings.Split(output, "\n") {
+		user := strings.TrimSpace(line)
+		if user != "" && user != "0" && strings.Trim(user, "0123456789") == "" {
+			dirs = append(dirs, path.Join(userDataDir, user, "config", "grid"))
 		}
+	}
+	if len(dirs) == 0 {
+		return nil, fmt.Errorf("no Steam users found on remote device")
+	}
+	return dirs, nil
+}
 
-		shortcutsPath, err := steam.GetRemoteShortcutsPath(user)
+// RemoveShortcut removes a Steam shortcut from a remote device
+func RemoveShortcut(cfg *RemoteConfig, name string) error {
+	return withLibrary(cfg, func(*remote.Client) error {
+		// Get all Steam users
+		users, err := steam.GetRemoteUsers()
 		if err != nil {
-			continue
+			return fmt.Errorf("failed to get Steam users: %w", err)
 		}
 
-		shortcuts, err := shortcut.Load(shortcutsPath)
-		if err != nil {
-			continue
-		}
+		// Remove shortcut for all users
+		for _, user := range users {
+			if !steam.RemoteHasShortcuts(user) {
+				continue
+			}
 
-		// Filter out the shortcut with the given name
-		newShortcuts := shortcut.NewShortcuts()
-		for _, sc := range shortcuts.Shortcuts {
-			if sc.AppName == name {
-				continue // Skip the one we're removing
+			shortcutsPath, err := steam.GetRemoteShortcutsPath(user)
+			if err != nil {
+				continue
+			}
+
+			shortcuts, err := shortcut.Load(shortcutsPath)
+			if err != nil {
+				continue
 			}
-			newShortcuts.Add(&sc)
-		}
 
-		// Save the updated shortcuts
-		if err := shortcut.Save(newShortcuts, shortcutsPath); err != nil {
-			return fmt.Errorf("failed to save shortcuts for user %s: %w", user, err)
+			// Filter out the shortcut with the given name
+			newShortcuts := shortcut.NewShortcuts()
+			for _, sc := range shortcuts.Shortcuts {
+				if sc.AppName == name {
+					continue // Skip the one we're removing
+				}
+				newShortcuts.Add(&sc)
+			}
+
+			// Save the updated shortcuts
+			if err := shortcut.Save(newShortcuts, shortcutsPath); err != nil {
+				return fmt.Errorf("failed to save shortcuts for user %s: %w", user, err)
+			}
 		}
-	}
 
-	return nil
+		return nil
+	})
 }
 
-// ListShortcuts returns all Steam shortcuts from a remote device
-func ListShortcuts(cfg *RemoteConfig) ([]ShortcutInfo, error) {
-	// Create and connect remote client
-	client := remote.NewClient(&remote.Config{
-		Host:     cfg.Host,
-		Port:     cfg.Port,
-		User:     cfg.User,
-		Password: cfg.Password,
-		KeyFile:  cfg.KeyFile,
-	})
+// UpdateShortcut changes the executable, start directory and launch options of
+// the shortcut with this app ID for every Steam user. The app ID itself is kept,
+// so the shortcut's artwork and play time stay attached to it.
+func UpdateShortcut(cfg *RemoteConfig, appID int64, exe, startDir, launchOpts string) error {
+	return withLibrary(cfg, func(*remote.Client) error {
+		// Get all Steam users
+		users, err := steam.GetRemoteUsers()
+		if err != nil {
+			return fmt.Errorf("failed to get Steam users: %w", err)
+		}
 
-	if err := client.Connect(); err != nil {
-		return nil, fmt.Errorf("failed to connect: %w", err)
-	}
-	defer client.Close()
+		updated := false
+		for _, user := range users {
+			if !steam.RemoteHasShortcuts(user) {
+				continue
+			}
 
-	// Set remote clients for library packages
-	shortcut.SetRemoteClient(client)
-	steam.SetRemoteClient(client)
+			shortcutsPath, err := steam.GetRemoteShortcutsPath(user)
+			if err != nil {
+				continue
+			}
 
-	// Get all Steam users
-	users, err := steam.GetRemoteUsers()
-	if err != nil {
-		return nil, fmt.Errorf("failed to get Steam users: %w", err)
-	}
+			shortcuts, err := shortcut.Load(shortcutsPath)
+			if err != nil {
+				continue
+			}
 
-	var result []ShortcutInfo
+			changed := false
+			for key, sc := range shortcuts.Shortcuts {
+				if sc.Appid != appID {
+					continue
+				}
+				// Steam expects quoted paths
+				sc.Exe = fmt.Sprintf("\"%s\"", exe)
+				sc.StartDir = fmt.Sprintf("\"%s\"", startDir)
+				sc.LaunchOptions = launchOpts
+				shortcuts.Shortcuts[key] = sc
+				changed = true
+			}
+			if !changed {
+				continue
+			}
 
-	// Get shortcuts from all users
-	for _, user := range users {
-		if !steam.RemoteHasShortcuts(user) {
-			continue
+			if err := shortcut.Save(shortcuts, shortcutsPath); err != nil {
+				return fmt.Errorf("failed to save shortcuts for user %s: %w", user, err)
+			}
+			updated = true
 		}
 
-		shortcutsPath, err := steam.GetRemoteShortcutsPath(user)
-		if err != nil {
-			continue
+		if !updated {
+			return fmt.Errorf("no shortcut with app ID %d found", appID)
 		}
+		return nil
+	})
+}
 
-		shortcuts, err := shortcut.Load(shortcutsPath)
+// ListShortcuts returns all Steam shortcuts from a remote device
+func ListShortcuts(cfg *RemoteConfig) ([]ShortcutInfo, error) {
+	var result []ShortcutInfo
+	err := withLibrary(cfg, func(*remote.Client) error {
+		// Get all Steam users
+		users, err := steam.GetRemoteUsers()
 		if err != nil {
-			continue
+			return fmt.Errorf("failed to get Steam users: %w", err)
 		}
 
-		for _, sc := range shortcuts.Shortcuts {
-			result = append(result, ShortcutInfo{
-				Name:          sc.AppName,
-				Exe:           sc.Exe,
-				StartDir:      sc.StartDir,
-				LaunchOptions: sc.LaunchOptions,
-				AppID:         sc.Appid,
-			})
+		// Get shortcuts from all users
+		for _, user := range users {
+			if !steam.RemoteHasShortcuts(user) {
+				continue
+			}
+
+			shortcutsPath, err := steam.GetRemoteShortcutsPath(user)
+			if err != nil {
+				continue
+			}
+
+			shortcuts, err := shortcut.Load(shortcutsPath)
+			if err != nil {
+				continue
+			}
+
+			for _, sc := range shortcuts.Shortcuts {
+				result = append(result, ShortcutInfo{
+					Name:          sc.AppName,
+					Exe:           sc.Exe,
+					StartDir:      sc.StartDir,
+					LaunchOptions: sc.LaunchOptions,
+					AppID:         sc.Appid,
+				})
+			}
 		}
-	}
 
-	return result, nil
+		return nil
+	})
+	return result, err
 }
 
 // ShortcutInfo represents basic shortcut information
@@ -367,19 +478,11 @@ func ParseTags(tagsStr string) []string {
 // RefreshSteamLibrary performs a soft restart of Steam to reload shortcuts
 // In Gaming Mode (Big Picture), Steam will automatically relaunch
 func RefreshSteamLibrary(cfg *RemoteConfig) error {
-	// Create and connect remote client
-	client := remote.NewClient(&remote.Config{
-		Host:     cfg.Host,
-		Port:     cfg.Port,
-		User:     cfg.User,
-		Password: cfg.Password,
-		KeyFile:  cfg.KeyFile,
-	})
-
-	if err := client.Connect(); err != nil {
-		return fmt.Errorf("failed to connect: %w", err)
+	client, closeClient, err := connect(cfg)
+	if err != nil {
+		return err
 	}
-	defer client.Close()
+	defer closeClient()
 
 	// Soft restart Steam - in Gaming Mode it will automatically relaunch
 	// We use steam -shutdown which gracefully closes Steam
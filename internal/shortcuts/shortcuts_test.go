@@ -0,0 +1,65 @@
+package shortcuts
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/shadowblip/steam-shortcut-manager/pkg/remote"
+)
+
+func TestWithLibrary_ConcurrentDevices(t *testing.T) {
+	var mu sync.Mutex
+	var using, opened, closed int // calls using the library, connections
+
+	connectBefore, setBefore := connectDevice, setLibraryClient
+	t.Cleanup(func() {
+		connectDevice, setLibraryClient = connectBefore, setBefore
+	})
+	connectDevice = func(cfg *RemoteConfig) (*remote.Client, func(), error) {
+		mu.Lock()
+		opened++
+		mu.Unlock()
+		return remote.NewClient(&remote.Config{Host: cfg.User}), func() {
+			mu.Lock()
+			closed++
+			mu.Unlock()
+		}, nil
+	}
+	setLibraryClient = func(client *remote.Client) {
+		mu.Lock()
+		defer mu.Unlock()
+		if using > 0 {
+			t.Error("library client replaced while another device was using it")
+		}
+	}
+
+	// Deploys to two devices add their shortcuts at the same time
+	var wg sync.WaitGroup
+	for i := range 2 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			cfg := &RemoteConfig{User: fmt.Sprintf("deck-%d", i)}
+			err := withLibrary(cfg, func(*remote.Client) error {
+				mu.Lock()
+				using++
+				mu.Unlock()
+				time.Sleep(10 * time.Millisecond)
+				mu.Lock()
+				using--
+				mu.Unlock()
+				return nil
+			})
+			if err != nil {
+				t.Errorf("%s: %v", cfg.User, err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if opened != 2 || closed != 2 {
+		t.Errorf("opened %d connections and closed %d, want 2", opened, closed)
+	}
+}
@@ -0,0 +1,46 @@
+// Package tray shows an icon with a menu in the system tray: a
+// StatusNotifierItem on Linux desktops and a notification area icon on Windows.
+package tray
+
+import "errors"
+
+// ErrUnsupported is returned by New when the platform or the desktop has no
+// system tray
+var ErrUnsupported = errors.New("system tray is not available")
+
+// Item is an entry of the tray menu. An item without OnClick is shown
+// disabled and an item without Label is a separator.
+type Item struct {
+	Label   string
+	OnClick func()
+}
+
+// Options configures a tray icon
+type Options struct {
+	ID      string // stable identifier of the application
+	Title   string
+	Tooltip string
+	// Called when the icon itself is clicked
+	OnActivate func()
+}
+
+// Tray is an icon in the system tray whose menu can be replaced at any time.
+// Callbacks run on their own goroutine.
+type Tray interface {
+	SetMenu(items []Item)
+	SetTooltip(text string)
+	Close()
+}
+
+// New shows an icon in the system tray
+func New(opts Options) (Tray, error) {
+	return newTray(opts)
+}
+
+// menuClick runs the callback of the item with a 1-based id, if enabled
+func menuClick(items []Item, id int) {
+	if id < 1 || id > len(items) || items[id-1].OnClick == nil {
+		return
+	}
+	go items[id-1].OnClick()
+}
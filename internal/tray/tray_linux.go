@@ -0,0 +1,343 @@
+//go:build linux
+
+package tray
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+
+	"github.com/godbus/dbus/v5"
+	"github.com/godbus/dbus/v5/prop"
+)
+
+// The icon is a StatusNotifierItem, shown by KDE, most other desktops and the
+// AppIndicator extension on GNOME. Its menu is exported with the dbusmenu protocol.
+const (
+	itemPath  = dbus.ObjectPath("/StatusNotifierItem")
+	itemIface = "org.kde.StatusNotifierItem"
+	menuPath  = dbus.ObjectPath("/MenuBar")
+	menuIface = "com.canonical.dbusmenu"
+)
+
+type sniTray struct {
+	conn  *dbus.Conn
+	props *prop.Properties
+	opts  Options
+
+	mu       sync.Mutex
+	items    []Item
+	revision uint32
+}
+
+// statusNotifierItem and dbusMenu hold the methods exported on each interface
+type (
+	statusNotifierItem sniTray
+	dbusMenu           sniTray
+)
+
+// pixmap is an ARGB32 image in network byte order
+type pixmap struct {
+	Width, Height int32
+	Data          []byte
+}
+
+type toolTip struct {
+	IconName    string
+	Icon        []pixmap
+	Title       string
+	Description string
+}
+
+type menuLayout struct {
+	ID         int32
+	Properties map[string]dbus.Variant
+	Children   []dbus.Variant
+}
+
+type menuItemProperties struct {
+	ID         int32
+	Properties map[string]dbus.Variant
+}
+
+type menuEvent struct {
+	ID        int32
+	EventID   string
+	Data      dbus.Variant
+	Timestamp uint32
+}
+
+func newTray(opts Options) (Tray, error) {
+	conn, err := dbus.ConnectSessionBus()
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrUnsupported, err)
+	}
+	t := &sniTray{conn: conn, opts: opts}
+	if err := t.export(); err != nil {
+		conn.Close()
+		return nil, err
+	}
+
+	name := fmt.Sprintf("org.kde.StatusNotifierItem-%d-1", os.Getpid())
+	reply, err := conn.RequestName(name, dbus.NameFlagDoNotQueue)
+	if err != nil || reply != dbus.RequestNameReplyPrimaryOwner {
+		conn.Close()
+		return nil, fmt.Errorf("failed to own %s: %v", name, err)
+	}
+
+	// Without a watcher no part of the desktop shows tray icons
+	watcher := conn.Object("org.kde.StatusNotifierWatcher", "/StatusNotifierWatcher")
+	if call := watcher.Call("org.kde.StatusNotifierWatcher.RegisterStatusNotifierItem", 0, name); call.Err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("%w: %v", ErrUnsupported, call.Err)
+	}
+	return t, nil
+}
+
+func (t *sniTray) export() error {
+	if err := t.conn.Export((*statusNotifierItem)(t), itemPath, itemIface); err != nil {
+		return err
+	}
+	if err := t.conn.Export((*dbusMenu)(t), menuPath, menuIface); err != nil {
+		return err
+	}
+
+	props, err := prop.Export(t.conn, itemPath, prop.Map{itemIface: {
+		"Category":          {Value: "ApplicationStatus", Emit: prop.EmitConst},
+		"Id":                {Value: t.opts.ID, Emit: prop.EmitConst},
+		"Title":             {Value: t.opts.Title, Emit: prop.EmitConst},
+		"Status":            {Value: "Active", Emit: prop.EmitConst},
+		"WindowId":          {Value: int32(0), Emit: prop.EmitConst},
+		"IconName":          {Value: "", Emit: prop.EmitConst},
+		"IconPixmap":        {Value: []pixmap{iconPixmap(32), iconPixmap(64)}, Emit: prop.EmitConst},
+		"OverlayIconName":   {Value: "", Emit: prop.EmitConst},
+		"AttentionIconName": {Value: "", Emit: prop.EmitConst},
+		"ToolTip":           {Value: t.toolTip(t.opts.Tooltip), Emit: prop.EmitTrue},
+		"ItemIsMenu":        {Value: false, Emit: prop.EmitConst},
+		"Menu":              {Value: menuPath, Emit: prop.EmitConst},
+	}})
+	if err != nil {
+		return err
+	}
+	t.props = props
+
+	_, err = prop.Export(t.conn, menuPath, prop.Map{menuIface: {
+		"Version":       {Value: uint32(3), Emit: prop.EmitConst},
+		"TextDirection": {Value: "ltr", Emit: prop.EmitConst},
+		"Status":        {Value: "normal", Emit: prop.EmitConst},
+		"IconThemePath": {Value: []string{}, Emit: prop.EmitConst},
+	}})
+	return err
+}
+
+func (t *sniTray) toolTip(text string) toolTip {
+	return toolTip{Icon: []pixmap{}, Title: t.opts.Title, Description: text}
+}
+
+func (t *sniTray) SetMenu(items []Item) {
+	t.mu.Lock()
+	t.items = items
+	t.revision++
+	revision := t.revision
+	t.mu.Unlock()
+	t.conn.Emit(menuPath, menuIface+".LayoutUpdated", revision, int32(0))
+}
+
+func (t *sniTray) SetTooltip(text string) {
+	t.props.SetMust(itemIface, "ToolTip", t.toolTip(text))
+	t.conn.Emit(itemPath, itemIface+".NewToolTip")
+}
+
+// Close releases the bus name, which removes the icon
+func (t *sniTray) Close() {
+	t.conn.Close()
+}
+
+// =============================================================================
+// org.kde.StatusNotifierItem
+// =============================================================================
+
+func (s *statusNotifierItem) Activate(x, y int32) *dbus.Error {
+	if s.opts.OnActivate != nil {
+		go s.opts.OnActivate()
+	}
+	return nil
+}
+
+func (s *statusNotifierItem) SecondaryActivate(x, y int32) *dbus.Error {
+	return nil
+}
+
+func (s *statusNotifierItem) ContextMenu(x, y int32) *dbus.Error {
+	return nil
+}
+
+func (s *statusNotifierItem) Scroll(delta int32, orientation string) *dbus.Error {
+	return nil
+}
+
+// =============================================================================
+// com.canonical.dbusmenu
+// =============================================================================
+
+// The menu is flat: item ids are 1-based indexes into items, 0 is the root
+
+func (m *dbusMenu) GetLayout(parentID, depth int32, names []string) (uint32, menuLayout, *dbus.Error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if parentID != 0 {
+		if parentID < 0 || int(parentID) > len(m.items) {
+			return 0, menuLayout{}, unknownItem(parentID)
+		}
+		return m.revision, menuLayout{ID: parentID, Properties: itemProperties(m.items[parentID-1]), Children: []dbus.Variant{}}, nil
+	}
+
+	root := menuLayout{
+		Properties: map[string]dbus.Variant{"children-display": dbus.MakeVariant("submenu")},
+		Children:   []dbus.Variant{},
+	}
+	if depth != 0 {
+		for i, item := range m.items {
+			root.Children = append(root.Children, dbus.MakeVariant(menuLayout{
+				ID:         int32(i + 1),
+				Properties: itemProperties(item),
+				Children:   []dbus.Variant{},
+			}))
+		}
+	}
+	return m.revision, root, nil
+}
+
+func (m *dbusMenu) GetGroupProperties(ids []int32, names []string) ([]menuItemProperties, *dbus.Error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	result := []menuItemProperties{}
+	for i, item := range m.items {
+		id := int32(i + 1)
+		if len(ids) == 0 || containsID(ids, id) {
+			result = append(result, menuItemProperties{ID: id, Properties: itemProperties(item)})
+		}
+	}
+	return result, nil
+}
+
+func (m *dbusMenu) GetProperty(id int32, name string) (dbus.Variant, *dbus.Error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if id < 1 || int(id) > len(m.items) {
+		return dbus.Variant{}, unknownItem(id)
+	}
+	value, ok := itemProperties(m.items[id-1])[name]
+	if !ok {
+		return dbus.Variant{}, dbus.MakeFailedError(fmt.Errorf("menu item %d has no property %q", id, name))
+	}
+	return value, nil
+}
+
+func (m *dbusMenu) Event(id int32, eventID string, data dbus.Variant, timestamp uint32) *dbus.Error {
+	if eventID == "clicked" {
+		m.mu.Lock()
+		items := m.items
+		m.mu.Unlock()
+		menuClick(items, int(id))
+	}
+	return nil
+}
+
+func (m *dbusMenu) EventGroup(events []menuEvent) ([]int32, *dbus.Error) {
+	for _, e := range events {
+		m.Event(e.ID, e.EventID, e.Data, e.Timestamp)
+	}
+	return []int32{}, nil
+}
+
+func (m *dbusMenu) AboutToShow(id int32) (bool, *dbus.Error) {
+	return false, nil
+}
+
+func (m *dbusMenu) AboutToShowGroup(ids []int32) ([]int32, []int32, *dbus.Error) {
+	return []int32{}, []int32{}, nil
+}
+
+func itemProperties(item Item) map[string]dbus.Variant {
+	if item.Label == "" {
+		return map[string]dbus.Variant{"type": dbus.MakeVariant("separator")}
+	}
+	return map[string]dbus.Variant{
+		// A single underscore marks an access key
+		"label":   dbus.MakeVariant(strings.ReplaceAll(item.Label, "_", "__")),
+		"enabled": dbus.MakeVariant(item.OnClick != nil),
+	}
+}
+
+func containsID(ids []int32, id int32) bool {
+	for _, i := range ids {
+		if i == id {
+			return true
+		}
+	}
+	return false
+}
+
+func unknownItem(id int32) *dbus.Error {
+	return dbus.MakeFailedError(fmt.Errorf("unknown menu item %d", id))
+}
+
+// =============================================================================
+// Icon
+// =============================================================================
+
+// iconPixmap draws the tray icon, a download arrow on an orange disc. Windows
+// builds use the icon embedded in the executable instead.
+func iconPixmap(size int) pixmap {
+	const samples = 4
+	data := make([]byte, 0, size*size*4)
+	for y := 0; y < size; y++ {
+		for x := 0; x < size; x++ {
+			var disc, arrow int
+			for sy := 0; sy < samples; sy++ {
+				for sx := 0; sx < samples; sx++ {
+					u := (float64(x) + (float64(sx)+0.5)/samples) / float64(size)
+					v := (float64(y) + (float64(sy)+0.5)/samples) / float64(size)
+					if (u-0.5)*(u-0.5)+(v-0.5)*(v-0.5) > 0.25 {
+						continue
+					}
+					disc++
+					if inArrow(u, v) {
+						arrow++
+					}
+				}
+			}
+			// Blend white over orange by arrow coverage
+			var f float64
+			if disc > 0 {
+				f = float64(arrow) / float64(disc)
+			}
+			alpha := byte(disc * 255 / (samples * samples))
+			data = append(data, alpha,
+				byte(0xE8+f*(0xFF-0xE8)), byte(0x91+f*(0xFF-0x91)), byte(0x3A+f*(0xFF-0x3A)))
+		}
+	}
+	return pixmap{Width: int32(size), Height: int32(size), Data: data}
+}
+
+// inArrow reports whether a point of the unit square lies on the arrow
+func inArrow(u, v float64) bool {
+	dx := u - 0.5
+	if dx < 0 {
+		dx = -dx
+	}
+	switch {
+	case v > 0.22 && v < 0.5 && dx < 0.08: // shaft
+		return true
+	case v >= 0.45 && v < 0.7 && dx < (0.7-v)*0.95: // head
+		return true
+	case v > 0.74 && v < 0.8 && dx < 0.22: // base
+		return true
+	}
+	return false
+}
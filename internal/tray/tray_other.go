@@ -0,0 +1,7 @@
+//go:build !linux && !windows
+
+package tray
+
+func newTray(opts Options) (Tray, error) {
+	return nil, ErrUnsupported
+}
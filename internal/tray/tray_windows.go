@@ -0,0 +1,329 @@
+//go:build windows
+
+package tray
+
+import (
+	"fmt"
+	"os"
+	"runtime"
+	"strings"
+	"sync"
+	"unsafe"
+
+	"golang.org/x/sys/windows"
+)
+
+var (
+	user32   = windows.NewLazySystemDLL("user32.dll")
+	shell32  = windows.NewLazySystemDLL("shell32.dll")
+	kernel32 = windows.NewLazySystemDLL("kernel32.dll")
+
+	procRegisterClassExW       = user32.NewProc("RegisterClassExW")
+	procCreateWindowExW        = user32.NewProc("CreateWindowExW")
+	procDefWindowProcW         = user32.NewProc("DefWindowProcW")
+	procDestroyWindow          = user32.NewProc("DestroyWindow")
+	procGetMessageW            = user32.NewProc("GetMessageW")
+	procTranslateMessage       = user32.NewProc("TranslateMessage")
+	procDispatchMessageW       = user32.NewProc("DispatchMessageW")
+	procPostMessageW           = user32.NewProc("PostMessageW")
+	procPostQuitMessage        = user32.NewProc("PostQuitMessage")
+	procRegisterWindowMessageW = user32.NewProc("RegisterWindowMessageW")
+	procCreatePopupMenu        = user32.NewProc("CreatePopupMenu")
+	procAppendMenuW            = user32.NewProc("AppendMenuW")
+	procTrackPopupMenu         = user32.NewProc("TrackPopupMenu")
+	procDestroyMenu            = user32.NewProc("DestroyMenu")
+	procGetCursorPos           = user32.NewProc("GetCursorPos")
+	procSetForegroundWindow    = user32.NewProc("SetForegroundWindow")
+	procLoadIconW              = user32.NewProc("LoadIconW")
+	procShellNotifyIconW       = shell32.NewProc("Shell_NotifyIconW")
+	procExtractIconW           = shell32.NewProc("ExtractIconW")
+	procGetModuleHandleW       = kernel32.NewProc("GetModuleHandleW")
+)
+
+const (
+	wmNull         = 0x0000
+	wmDestroy      = 0x0002
+	wmLButtonUp    = 0x0202
+	wmRButtonUp    = 0x0205
+	wmTrayCallback = 0x8000 + 1 // WM_APP + 1
+	wmTrayClose    = 0x8000 + 2
+
+	nimAdd     = 0
+	nimModify  = 1
+	nimDelete  = 2
+	nifMessage = 0x1
+	nifIcon    = 0x2
+	nifTip     = 0x4
+
+	mfGrayed       = 0x1
+	mfSeparator    = 0x800
+	tpmRightButton = 0x2
+	tpmNoNotify    = 0x80
+	tpmReturnCmd   = 0x100
+
+	idiApplication = 32512
+)
+
+// notifyIconData is NOTIFYICONDATAW
+type notifyIconData struct {
+	Size            uint32
+	Wnd             uintptr
+	ID              uint32
+	Flags           uint32
+	CallbackMessage uint32
+	Icon            uintptr
+	Tip             [128]uint16
+	State           uint32
+	StateMask       uint32
+	Info            [256]uint16
+	Version         uint32
+	InfoTitle       [64]uint16
+	InfoFlags       uint32
+	GUIDItem        windows.GUID
+	BalloonIcon     uintptr
+}
+
+// wndClassEx is WNDCLASSEXW
+type wndClassEx struct {
+	Size       uint32
+	Style      uint32
+	WndProc    uintptr
+	ClsExtra   int32
+	WndExtra   int32
+	Instance   uintptr
+	Icon       uintptr
+	Cursor     uintptr
+	Background uintptr
+	MenuName   *uint16
+	ClassName  *uint16
+	IconSm     uintptr
+}
+
+type point struct {
+	X, Y int32
+}
+
+type msg struct {
+	Hwnd    uintptr
+	Message uint32
+	WParam  uintptr
+	LParam  uintptr
+	Time    uint32
+	Pt      point
+	Private uint32
+}
+
+type winTray struct {
+	opts Options
+	hwnd uintptr
+
+	mu    sync.Mutex
+	data  notifyIconData
+	items []Item
+}
+
+var (
+	// The window procedure has no way to reach its tray but this one,
+	// a process shows a single tray icon
+	current *winTray
+	// Sent to every top level window when Explorer restarts
+	taskbarCreated uintptr
+)
+
+func newTray(opts Options) (Tray, error) {
+	t := &winTray{opts: opts}
+	ready := make(chan error, 1)
+	go t.run(ready)
+	if err := <-ready; err != nil {
+		return nil, err
+	}
+	return t, nil
+}
+
+// run creates the hidden window that receives the icon's messages and
+// dispatches them until the tray is closed
+func (t *winTray) run(ready chan<- error) {
+	// Messages go to the thread that created the window
+	runtime.LockOSThread()
+	defer runtime.UnlockOSThread()
+
+	if err := t.create(); err != nil {
+		ready <- err
+		return
+	}
+	ready <- nil
+
+	var m msg
+	for {
+		r, _, _ := procGetMessageW.Call(uintptr(unsafe.Pointer(&m)), 0, 0, 0)
+		if int32(r) <= 0 {
+			return
+		}
+		procTranslateMessage.Call(uintptr(unsafe.Pointer(&m)))
+		procDispatchMessageW.Call(uintptr(unsafe.Pointer(&m)))
+	}
+}
+
+func (t *winTray) create() error {
+	instance, _, _ := procGetModuleHandleW.Call(0)
+	className, err := windows.UTF16PtrFromString("TrayWindow_" + t.opts.ID)
+	if err != nil {
+		return err
+	}
+	title, err := windows.UTF16PtrFromString(t.opts.Title)
+	if err != nil {
+		return err
+	}
+
+	wc := wndClassEx{WndProc: windows.NewCallback(wndProc), Instance: instance, ClassName: className}
+	wc.Size = uint32(unsafe.Sizeof(wc))
+	if r, _, err := procRegisterClassExW.Call(uintptr(unsafe.Pointer(&wc))); r == 0 {
+		return fmt.Errorf("failed to register tray window: %w", err)
+	}
+
+	current = t
+	// A hidden top level window rather than a message-only one: only those
+	// can own a popup menu and receive TaskbarCreated
+	hwnd, _, err := procCreateWindowExW.Call(0, uintptr(unsafe.Pointer(className)), uintptr(unsafe.Pointer(title)),
+		0, 0, 0, 0, 0, 0, 0, instance, 0)
+	if hwnd == 0 {
+		return fmt.Errorf("failed to create tray window: %w", err)
+	}
+	t.hwnd = hwnd
+	if name, err := windows.UTF16PtrFromString("TaskbarCreated"); err == nil {
+		taskbarCreated, _, _ = procRegisterWindowMessageW.Call(uintptr(unsafe.Pointer(name)))
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.data = notifyIconData{
+		Wnd:             hwnd,
+		ID:              1,
+		Flags:           nifMessage | nifIcon | nifTip,
+		CallbackMessage: wmTrayCallback,
+		Icon:            loadIcon(instance),
+	}
+	t.data.Size = uint32(unsafe.Sizeof(t.data))
+	t.data.setTip(t.opts.Tooltip)
+	if r, _, err := procShellNotifyIconW.Call(nimAdd, uintptr(unsafe.Pointer(&t.data))); r == 0 {
+		procDestroyWindow.Call(hwnd)
+		return fmt.Errorf("%w: %v", ErrUnsupported, err)
+	}
+	return nil
+}
+
+// loadIcon returns the icon embedded in the executable, or the stock
+// application icon
+func loadIcon(instance uintptr) uintptr {
+	if exe, err := os.Executable(); err == nil {
+		if p, err := windows.UTF16PtrFromString(exe); err == nil {
+			// 1 means the file is not an executable, 0 that it has no icon
+			if icon, _, _ := procExtractIconW.Call(instance, uintptr(unsafe.Pointer(p)), 0); icon > 1 {
+				return icon
+			}
+		}
+	}
+	icon, _, _ := procLoadIconW.Call(0, idiApplication)
+	return icon
+}
+
+func (d *notifyIconData) setTip(text string) {
+	tip := windows.StringToUTF16(strings.ReplaceAll(text, "\x00", ""))
+	if len(tip) > len(d.Tip) {
+		tip = append(tip[:len(d.Tip)-1], 0)
+	}
+	d.Tip = [128]uint16{}
+	copy(d.Tip[:], tip)
+}
+
+func wndProc(hwnd, message, wParam, lParam uintptr) uintptr {
+	t := current
+	switch {
+	case t == nil:
+	case message == wmTrayCallback:
+		switch lParam & 0xFFFF {
+		case wmLButtonUp:
+			if t.opts.OnActivate != nil {
+				go t.opts.OnActivate()
+			}
+		case wmRButtonUp:
+			t.showMenu()
+		}
+		return 0
+	case message == wmTrayClose:
+		t.notify(nimDelete)
+		procDestroyWindow.Call(hwnd)
+		return 0
+	case message == wmDestroy:
+		procPostQuitMessage.Call(0)
+		return 0
+	case taskbarCreated != 0 && message == taskbarCreated:
+		// Explorer restarted and lost the icon
+		t.notify(nimAdd)
+		return 0
+	}
+	r, _, _ := procDefWindowProcW.Call(hwnd, message, wParam, lParam)
+	return r
+}
+
+func (t *winTray) notify(action uintptr) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	procShellNotifyIconW.Call(action, uintptr(unsafe.Pointer(&t.data)))
+}
+
+// showMenu pops the menu up at the cursor and runs the chosen item
+func (t *winTray) showMenu() {
+	t.mu.Lock()
+	items := t.items
+	t.mu.Unlock()
+
+	menu, _, _ := procCreatePopupMenu.Call()
+	if menu == 0 {
+		return
+	}
+	defer procDestroyMenu.Call(menu)
+	for i, item := range items {
+		if item.Label == "" {
+			procAppendMenuW.Call(menu, mfSeparator, 0, 0)
+			continue
+		}
+		// A single ampersand marks an access key
+		label, err := windows.UTF16PtrFromString(strings.ReplaceAll(item.Label, "&", "&&"))
+		if err != nil {
+			continue
+		}
+		var flags uintptr
+		if item.OnClick == nil {
+			flags = mfGrayed
+		}
+		procAppendMenuW.Call(menu, flags, uintptr(i+1), uintptr(unsafe.Pointer(label)))
+	}
+
+	var pt point
+	procGetCursorPos.Call(uintptr(unsafe.Pointer(&pt)))
+	// Otherwise the menu does not close when clicking elsewhere
+	procSetForegroundWindow.Call(t.hwnd)
+	id, _, _ := procTrackPopupMenu.Call(menu, tpmRightButton|tpmNoNotify|tpmReturnCmd,
+		uintptr(pt.X), uintptr(pt.Y), 0, t.hwnd, 0)
+	procPostMessageW.Call(t.hwnd, wmNull, 0, 0)
+	menuClick(items, int(id))
+}
+
+func (t *winTray) SetMenu(items []Item) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.items = items
+}
+
+func (t *winTray) SetTooltip(text string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.data.setTip(text)
+	procShellNotifyIconW.Call(nimModify, uintptr(unsafe.Pointer(&t.data)))
+}
+
+// Close removes the icon and ends the message loop
+func (t *winTray) Close() {
+	procPostMessageW.Call(t.hwnd, wmTrayClose, 0, 0)
+}
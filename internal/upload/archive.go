@@ -0,0 +1,338 @@
+package upload
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/lobinuxsoft/capydeploy/internal/device"
+)
+
+// ArchiveUploadName is where a zip waits on the device to be extracted, unzip
+// can't read from a stream
+const ArchiveUploadName = ".devkit-upload.zip"
+
+// ArchiveEntry is a file of a game archive
+type ArchiveEntry struct {
+	RelPath string // slash separated path inside the archive
+	Size    int64
+	Hash    string
+	ELF     bool
+}
+
+// IsArchive reports whether the local path of a setup is an archive to
+// extract on the device instead of a folder
+func IsArchive(localPath string) bool {
+	lower := strings.ToLower(localPath)
+	if !isZipArchive(lower) && !strings.HasSuffix(lower, ".tar.gz") && !strings.HasSuffix(lower, ".tgz") {
+		return false
+	}
+	info, err := os.Stat(localPath)
+	return err == nil && info.Mode().IsRegular()
+}
+
+// isZipArchive reports whether a game archive is a zip rather than a gzipped tar
+func isZipArchive(archivePath string) bool {
+	return strings.HasSuffix(strings.ToLower(archivePath), ".zip")
+}
+
+// ReadArchive lists and hashes the files of a game archive. Archives with
+// paths that would land outside the game folder are refused.
+func ReadArchive(archivePath string) ([]ArchiveEntry, error) {
+	if isZipArchive(archivePath) {
+		return readZip(archivePath)
+	}
+	return readTarGz(archivePath)
+}
+
+func readZip(archivePath string) ([]ArchiveEntry, error) {
+	r, err := zip.OpenReader(archivePath)
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+
+	var entries []ArchiveEntry
+	paths := newArchivePaths()
+	for _, f := range r.File {
+		relPath, err := archiveRelPath(f.Name)
+		if err != nil {
+			return nil, err
+		}
+		if relPath == "" {
+			continue
+		}
+		paths.addEntry(relPath)
+		if f.Mode().IsDir() {
+			continue
+		}
+		if f.Mode()&os.ModeSymlink != 0 {
+			if err := checkArchiveLink(f, relPath, paths); err != nil {
+				return nil, err
+			}
+			continue
+		}
+
+		rc, err := f.Open()
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", relPath, err)
+		}
+		entry, err := hashArchiveEntry(relPath, rc)
+		rc.Close()
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", relPath, err)
+		}
+		entries = append(entries, entry)
+	}
+	if err := paths.check(); err != nil {
+		return nil, err
+	}
+	return entries, nil
+}
+
+// checkArchiveLink refuses a zipped symlink that points outside the game
+// folder; zips store the target as the content of the link
+func checkArchiveLink(f *zip.File, relPath string, paths *archivePaths) error {
+	rc, err := f.Open()
+	if err != nil {
+		return fmt.Errorf("%s: %w", relPath, err)
+	}
+	defer rc.Close()
+	target, err := io.ReadAll(io.LimitReader(rc, 4096))
+	if err != nil {
+		return fmt.Errorf("%s: %w", relPath, err)
+	}
+	if err := checkLinkTarget(relPath, string(target)); err != nil {
+		return err
+	}
+	paths.addLink(relPath, string(target))
+	return nil
+}
+
+func readTarGz(archivePath string) ([]ArchiveEntry, error) {
+	f, err := os.Open(archivePath)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	gz, err := gzip.NewReader(f)
+	if err != nil {
+		return nil, err
+	}
+	defer gz.Close()
+
+	var entries []ArchiveEntry
+	paths := newArchivePaths()
+	tr := tar.NewReader(gz)
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			if err := paths.check(); err != nil {
+				return nil, err
+			}
+			return entries, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		relPath, err := archiveRelPath(header.Name)
+		if err != nil {
+			return nil, err
+		}
+		if relPath != "" {
+			paths.addEntry(relPath)
+		}
+
+		switch header.Typeflag {
+		case tar.TypeReg:
+			entry, err := hashArchiveEntry(relPath, tr)
+			if err != nil {
+				return nil, fmt.Errorf("%s: %w", relPath, err)
+			}
+			entries = append(entries, entry)
+		case tar.TypeSymlink:
+			if err := checkLinkTarget(relPath, header.Linkname); err != nil {
+				return nil, err
+			}
+			paths.addLink(relPath, header.Linkname)
+		case tar.TypeLink:
+			target, err := archiveRelPath(header.Linkname)
+			if err != nil {
+				return nil, err
+			}
+			paths.addHardLink(relPath, target)
+		}
+	}
+}
+
+// archiveRelPath cleans the name of an archive entry into a slash separated
+// path relative to the game folder, "" for the folder itself
+func archiveRelPath(name string) (string, error) {
+	name = strings.ReplaceAll(name, "\\", "/")
+	cleaned := path.Clean(name)
+	if path.IsAbs(name) || cleaned == ".." || strings.HasPrefix(cleaned, "../") {
+		return "", fmt.Errorf("archive entry %q is outside the game folder", name)
+	}
+	if cleaned == "." {
+		return "", nil
+	}
+	return cleaned, nil
+}
+
+// checkLinkTarget refuses a symlink that points outside the game folder,
+// files extracted through it could land anywhere on the device
+func checkLinkTarget(relPath, target string) error {
+	if path.IsAbs(target) {
+		return fmt.Errorf("archive symlink %s points outside the game folder", relPath)
+	}
+	if _, err := archiveRelPath(path.Join(path.Dir(relPath), target)); err != nil {
+		return fmt.Errorf("archive symlink %s points outside the game folder", relPath)
+	}
+	return nil
+}
+
+// archiveLinkDepth caps the symlinks followed to resolve a path of an archive
+const archiveLinkDepth = 40
+
+// archivePaths collects the symlinks of an archive and the paths resolved
+// through them. Where something lands through a symlink depends on the link
+// rather than on its name, e.g. a/l -> .. followed by a/l/x -> ../.. leaves
+// the game folder, so paths are resolved once the whole archive is read,
+// whatever the order of its entries.
+type archivePaths struct {
+	links  map[string]string // symlink -> target
+	checks []archivePath
+}
+
+// archivePath is a path an entry of an archive is resolved through
+type archivePath struct {
+	entry      string
+	components []string
+}
+
+func newArchivePaths() *archivePaths {
+	return &archivePaths{links: make(map[string]string)}
+}
+
+// addEntry records the folder an entry is created in
+func (p *archivePaths) addEntry(relPath string) {
+	p.add(relPath, path.Dir(relPath))
+}
+
+// addLink records a symlink and its target, resolved from the link's folder
+func (p *archivePaths) addLink(relPath, target string) {
+	target = strings.ReplaceAll(target, "\\", "/")
+	p.links[relPath] = target
+	p.add(relPath, path.Dir(relPath)+"/"+target)
+}
+
+// addHardLink records the target of a hard link, named from the game folder
+func (p *archivePaths) addHardLink(relPath, target string) {
+	p.add(relPath, target)
+}
+
+func (p *archivePaths) add(entry, slashPath string) {
+	p.checks = append(p.checks, archivePath{entry: entry, components: strings.Split(slashPath, "/")})
+}
+
+// check refuses an archive with a path that leaves the game folder once its
+// symlinks are followed
+func (p *archivePaths) check() error {
+	for _, c := range p.checks {
+		if _, err := p.resolve(c.components, 0); err != nil {
+			return fmt.Errorf("archive entry %s %w", c.entry, err)
+		}
+	}
+	return nil
+}
+
+// resolve follows path components from the game folder through the symlinks
+// of the archive, returning where they lead
+func (p *archivePaths) resolve(components []string, depth int) ([]string, error) {
+	if depth > archiveLinkDepth {
+		return nil, fmt.Errorf("goes through too many symlinks")
+	}
+	var current []string
+	for _, c := range components {
+		switch c {
+		case "", ".":
+			continue
+		case "..":
+			if len(current) == 0 {
+				return nil, fmt.Errorf("leads outside the game folder")
+			}
+			current = current[:len(current)-1]
+			continue
+		}
+		current = append(current, c)
+		target, ok := p.links[strings.Join(current, "/")]
+		if !ok {
+			continue
+		}
+		if path.IsAbs(target) {
+			return nil, fmt.Errorf("leads outside the game folder")
+		}
+		next := append(slices.Clone(current[:len(current)-1]), strings.Split(target, "/")...)
+		resolved, err := p.resolve(next, depth+1)
+		if err != nil {
+			return nil, err
+		}
+		current = resolved
+	}
+	return current, nil
+}
+
+// hashArchiveEntry reads a file of an archive into its entry
+func hashArchiveEntry(relPath string, r io.Reader) (ArchiveEntry, error) {
+	magic := make([]byte, 4)
+	n, err := io.ReadFull(r, magic)
+	if err != nil && err != io.EOF && err != io.ErrUnexpectedEOF {
+		return ArchiveEntry{}, err
+	}
+	h := sha256.New()
+	h.Write(magic[:n])
+	size, err := io.Copy(h, r)
+	if err != nil {
+		return ArchiveEntry{}, err
+	}
+	return ArchiveEntry{
+		RelPath: relPath,
+		Size:    size + int64(n),
+		Hash:    hex.EncodeToString(h.Sum(nil)),
+		ELF:     bytes.Equal(magic[:n], []byte("\x7fELF")),
+	}, nil
+}
+
+// uploadArchive streams a game archive to the device, which extracts it into
+// remoteDir as it arrives. A zip is saved first and extracted with unzip, or
+// Python where unzip is missing.
+func uploadArchive(ctx context.Context, client *device.Client, archivePath, remoteDir string) error {
+	f, err := os.Open(archivePath)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	dir := device.ShellQuote(remoteDir)
+	cmd := fmt.Sprintf("mkdir -p %s && tar -xzf - --no-same-owner -C %s", dir, dir)
+	if isZipArchive(archivePath) {
+		cmd = fmt.Sprintf("mkdir -p %[1]s && cd %[1]s && cat > %[2]s && "+
+			"{ unzip -oq %[2]s || python3 -m zipfile -e %[2]s .; }; status=$?; rm -f %[2]s; exit $status",
+			dir, ArchiveUploadName)
+	}
+	if err := client.PipeCommand(ctx, cmd, f); err != nil {
+		return fmt.Errorf("failed to extract %s on the device: %w", filepath.Base(archivePath), err)
+	}
+	return nil
+}
@@ -0,0 +1,222 @@
+package upload
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"compress/gzip"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestArchiveRelPath(t *testing.T) {
+	tests := []struct {
+		name    string
+		want    string
+		wantErr bool
+	}{
+		{"Game/game.x86_64", "Game/game.x86_64", false},
+		{"./Game/data.pak", "Game/data.pak", false},
+		{"Game/", "Game", false},
+		{".", "", false},
+		{"./", "", false},
+		{`Game\Data\level.pak`, "Game/Data/level.pak", false},
+		{"Game/../data.pak", "data.pak", false},
+		{"../evil.sh", "", true},
+		{"..", "", true},
+		{"Game/../../evil.sh", "", true},
+		{`..\evil.sh`, "", true},
+		{`Game\..\..\evil.sh`, "", true},
+		{"/etc/passwd", "", true},
+		{`\etc\passwd`, "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := archiveRelPath(tt.name)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("archiveRelPath(%q) error = %v, wantErr %v", tt.name, err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("archiveRelPath(%q) = %q, want %q", tt.name, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCheckLinkTarget(t *testing.T) {
+	tests := []struct {
+		relPath string
+		target  string
+		wantErr bool
+	}{
+		{"lib/libfoo.so", "libfoo.so.1", false},
+		{"lib/current", "../data", false},
+		{"a/l", "..", false},
+		{"bin/game", "../Game/game.x86_64", false},
+		{"lib/libfoo.so", "../../libfoo.so", true},
+		{"evil", "..", true},
+		{"evil", "/etc", true},
+		{"a/evil", `..\..\etc`, true},
+		{"a/l/x", "../../..", true},
+	}
+	for _, tt := range tests {
+		err := checkLinkTarget(tt.relPath, tt.target)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("checkLinkTarget(%q, %q) error = %v, wantErr %v", tt.relPath, tt.target, err, tt.wantErr)
+		}
+	}
+}
+
+// archiveFile is an entry of a test archive, a symlink when link is set
+type archiveFile struct {
+	name string
+	link string
+}
+
+func writeTestTarGz(t *testing.T, files []archiveFile) string {
+	t.Helper()
+	archivePath := filepath.Join(t.TempDir(), "build.tar.gz")
+	f, err := os.Create(archivePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	gz := gzip.NewWriter(f)
+	tw := tar.NewWriter(gz)
+	for _, file := range files {
+		header := &tar.Header{Name: file.name, Mode: 0644, Typeflag: tar.TypeReg}
+		if strings.HasSuffix(file.name, "/") {
+			header.Typeflag = tar.TypeDir
+		} else if file.link != "" {
+			header.Typeflag = tar.TypeSymlink
+			header.Linkname = file.link
+		}
+		if err := tw.WriteHeader(header); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := tw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := gz.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return archivePath
+}
+
+func writeTestZip(t *testing.T, files []archiveFile) string {
+	t.Helper()
+	archivePath := filepath.Join(t.TempDir(), "build.zip")
+	f, err := os.Create(archivePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	zw := zip.NewWriter(f)
+	for _, file := range files {
+		header := &zip.FileHeader{Name: file.name}
+		header.SetMode(0644)
+		content := ""
+		if file.link != "" {
+			header.SetMode(os.ModeSymlink | 0777)
+			content = file.link
+		}
+		w, err := zw.CreateHeader(header)
+		if err != nil {
+			t.Fatal(err)
+		}
+		w.Write([]byte(content))
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return archivePath
+}
+
+func TestReadArchive_Symlinks(t *testing.T) {
+	tests := []struct {
+		name    string
+		files   []archiveFile
+		wantErr bool
+	}{
+		{
+			name:  "links inside the folder",
+			files: []archiveFile{{name: "lib/libfoo.so.1"}, {name: "lib/libfoo.so", link: "libfoo.so.1"}},
+		},
+		{
+			name: "link through a link inside the folder",
+			files: []archiveFile{
+				{name: "Foo/Versions/A/Resources/data.pak"},
+				{name: "Foo/Versions/Current", link: "A"},
+				{name: "Foo/Resources", link: "Versions/Current/Resources"},
+			},
+		},
+		{
+			name:    "chained links leave the folder",
+			files:   []archiveFile{{name: "a/l", link: ".."}, {name: "a/l/x", link: "../.."}},
+			wantErr: true,
+		},
+		{
+			name:    "chained links in reverse order",
+			files:   []archiveFile{{name: "a/l/x", link: "../.."}, {name: "a/l", link: ".."}},
+			wantErr: true,
+		},
+		{
+			name:    "link target through a link",
+			files:   []archiveFile{{name: "a/l", link: ".."}, {name: "b", link: "a/l/.."}},
+			wantErr: true,
+		},
+		{
+			name:    "file written through a link that leaves the folder",
+			files:   []archiveFile{{name: "a/l", link: ".."}, {name: "a/m", link: "l/.."}, {name: "a/m/evil.sh"}},
+			wantErr: true,
+		},
+		{
+			name:    "link loop",
+			files:   []archiveFile{{name: "a", link: "b"}, {name: "b", link: "a"}, {name: "a/x"}},
+			wantErr: true,
+		},
+		{
+			name:    "absolute link",
+			files:   []archiveFile{{name: "etc", link: "/etc"}},
+			wantErr: true,
+		},
+		{
+			name:    "parent entry",
+			files:   []archiveFile{{name: "../evil.sh"}},
+			wantErr: true,
+		},
+		{
+			name:    "backslash parent entry",
+			files:   []archiveFile{{name: `..\evil.sh`}},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		for format, write := range map[string]func(*testing.T, []archiveFile) string{"tar.gz": writeTestTarGz, "zip": writeTestZip} {
+			t.Run(format+"/"+tt.name, func(t *testing.T) {
+				_, err := ReadArchive(write(t, tt.files))
+				if (err != nil) != tt.wantErr {
+					t.Errorf("ReadArchive() error = %v, wantErr %v", err, tt.wantErr)
+				}
+			})
+		}
+	}
+}
+
+func TestReadArchive_Entries(t *testing.T) {
+	archivePath := writeTestTarGz(t, []archiveFile{{name: "Game/"}, {name: "Game/game.x86_64"}, {name: "Game/run.sh", link: "game.x86_64"}})
+	entries, err := ReadArchive(archivePath)
+	if err != nil {
+		t.Fatalf("ReadArchive() error = %v", err)
+	}
+	var names []string
+	for _, e := range entries {
+		names = append(names, e.RelPath)
+	}
+	// Directories and symlinks are extracted but not uploaded as files
+	if got := strings.Join(names, ","); got != "Game/game.x86_64" {
+		t.Errorf("entries = %q, want %q", got, "Game/game.x86_64")
+	}
+}
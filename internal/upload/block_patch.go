@@ -0,0 +1,64 @@
+package upload
+
+import (
+	"log/slog"
+
+	"github.com/lobinuxsoft/capydeploy/internal/device"
+)
+
+// Block patching sends the fixed-size blocks of a large file that changed
+// since the build the device has. A block is compared with the one at the same
+// offset, so it suits files rewritten in place; data inserted or removed moves
+// every later block and the file is sent almost whole.
+
+// patchBlockSize is the size of the blocks a patch rewrites
+const patchBlockSize = 1 << 20
+
+// patchMinSize is the size from which files are patched, smaller ones are
+// uploaded whole
+const patchMinSize = 64 << 20
+
+// patchDigestSize is the bytes of the SHA-256 of a block that are recorded.
+// Half the digest keeps the manifest small, a patched file is checked against
+// the full hash anyway.
+const patchDigestSize = 16
+
+// patchBlocks returns the blocks of a file that changed since the build the
+// device has, or nil when the file must be uploaded whole: its previous blocks
+// are unknown, don't match the size of the file on the device, or all changed
+func patchBlocks(previous, current []string, remoteSize int64) []int {
+	if len(previous) == 0 || len(current) == 0 || int64(len(previous)) != (remoteSize+patchBlockSize-1)/patchBlockSize {
+		return nil
+	}
+	var changed []int
+	for i, hash := range current {
+		if i >= len(previous) || previous[i] != hash {
+			changed = append(changed, i)
+		}
+	}
+	if len(changed) == len(current) {
+		return nil
+	}
+	return changed
+}
+
+// patchSize returns the bytes a patch of a file sends
+func patchSize(u pendingUpload) int64 {
+	if u.blocks == nil {
+		return u.size
+	}
+	return min(int64(len(u.blocks))*patchBlockSize, u.size)
+}
+
+// patchFile sends a large file by rewriting its changed blocks in a copy of
+// the one on the device, which replaces it once it matches the local hash. A
+// patch that doesn't match, e.g. because the file was modified on the device,
+// or can't be checked is uploaded whole instead.
+func patchFile(client *device.Client, u pendingUpload) error {
+	err := client.PatchFile(u.local, u.remote, patchBlockSize, u.blocks, u.hash)
+	if err == nil {
+		return nil
+	}
+	slog.Warn("Failed to patch file, uploading it whole", "file", u.relPath, "error", err)
+	return client.UploadFile(u.local, u.remote)
+}
@@ -0,0 +1,100 @@
+package upload
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestPatchBlocks(t *testing.T) {
+	const block = patchBlockSize
+	tests := []struct {
+		name       string
+		previous   []string
+		current    []string
+		remoteSize int64
+		want       []int
+	}{
+		{
+			name:       "one block changed",
+			previous:   []string{"a", "b", "c"},
+			current:    []string{"a", "B", "c"},
+			remoteSize: 3 * block,
+			want:       []int{1},
+		},
+		{
+			name:       "short last block on the device",
+			previous:   []string{"a", "b", "c"},
+			current:    []string{"a", "b", "C"},
+			remoteSize: 2*block + 10,
+			want:       []int{2},
+		},
+		{
+			name:       "file grew",
+			previous:   []string{"a", "b"},
+			current:    []string{"a", "b", "c", "d"},
+			remoteSize: 2 * block,
+			want:       []int{2, 3},
+		},
+		{
+			name:       "file shrank",
+			previous:   []string{"a", "b", "c"},
+			current:    []string{"a", "b"},
+			remoteSize: 3 * block,
+		},
+		{
+			name:       "nothing changed",
+			previous:   []string{"a", "b"},
+			current:    []string{"a", "b"},
+			remoteSize: 2 * block,
+		},
+		{
+			name:       "every block changed",
+			previous:   []string{"a", "b"},
+			current:    []string{"A", "B"},
+			remoteSize: 2 * block,
+		},
+		{
+			// Inserted data moves every later block
+			name:       "data inserted at the start",
+			previous:   []string{"a", "b", "c"},
+			current:    []string{"x", "a'", "b'", "c'"},
+			remoteSize: 3 * block,
+		},
+		{
+			name:       "no previous blocks",
+			current:    []string{"a", "b"},
+			remoteSize: 2 * block,
+		},
+		{
+			name:       "file on the device isn't the previous build",
+			previous:   []string{"a", "b", "c"},
+			current:    []string{"a", "B", "c"},
+			remoteSize: 5 * block,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := patchBlocks(tt.previous, tt.current, tt.remoteSize)
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("patchBlocks() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPatchSize(t *testing.T) {
+	tests := []struct {
+		name string
+		u    pendingUpload
+		want int64
+	}{
+		{"whole file", pendingUpload{size: 100 << 20}, 100 << 20},
+		{"two blocks", pendingUpload{size: 100 << 20, blocks: []int{3, 7}}, 2 * patchBlockSize},
+		{"short last block", pendingUpload{size: patchBlockSize / 2, blocks: []int{0}}, patchBlockSize / 2},
+	}
+	for _, tt := range tests {
+		if got := patchSize(tt.u); got != tt.want {
+			t.Errorf("%s: patchSize() = %d, want %d", tt.name, got, tt.want)
+		}
+	}
+}
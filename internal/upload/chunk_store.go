@@ -0,0 +1,251 @@
+package upload
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"path"
+	"strings"
+	"sync"
+
+	"github.com/lobinuxsoft/capydeploy/internal/device"
+	"github.com/lobinuxsoft/capydeploy/pkg/transfer"
+)
+
+// chunkStoreDir holds the chunks of the files deployed with the chunk store,
+// named after their hash and shared by every game on the device
+const chunkStoreDir = "chunks"
+
+// chunkSize is the size of the chunks files are split into; the last chunk of
+// a file is shorter
+const chunkSize = 4 << 20
+
+// chunkStoreMaxDays is how long a chunk no deploy used stays on the device
+const chunkStoreMaxDays = 30
+
+// chunkQueryBatch caps the chunks looked up on the device per command
+const chunkQueryBatch = 500
+
+// chunkStorePath returns where the chunk store is on the device
+func chunkStorePath(homeDir string) string {
+	return path.Join(homeDir, DataDir, chunkStoreDir)
+}
+
+// chunkPath returns the path of a chunk relative to the store, spread over
+// folders by its first two hex digits
+func chunkPath(hash string) string {
+	return path.Join(hash[:2], hash)
+}
+
+// chunkedUpload is a pending upload split into chunks
+type chunkedUpload struct {
+	pendingUpload
+	info os.FileInfo
+}
+
+// chunkUpload is a chunk missing from the store, read from the first file that has it
+type chunkUpload struct {
+	hash   string
+	local  string
+	offset int64
+	size   int64
+}
+
+// fileChunks lists the chunks of the files once each, read from the first
+// file that has them
+func fileChunks(files []chunkedUpload) []chunkUpload {
+	var chunks []chunkUpload
+	seen := make(map[string]bool)
+	for _, f := range files {
+		for i, hash := range f.chunks {
+			if seen[hash] {
+				continue
+			}
+			seen[hash] = true
+			offset := int64(i) * chunkSize
+			chunks = append(chunks, chunkUpload{hash: hash, local: f.local, offset: offset, size: min(chunkSize, f.size-offset)})
+		}
+	}
+	return chunks
+}
+
+// uploadChunked deploys files through the chunk store: only the chunks the
+// device doesn't have yet are sent, from any game or build, then the files
+// are assembled from the store on the device. Chunks stay in the store until
+// no deploy used them for chunkStoreMaxDays.
+func uploadChunked(ctx context.Context, client *device.Client, uploads []pendingUpload, workers int, retrier *transfer.Retrier, status func(string), onDone func(u pendingUpload)) error {
+	homeDir, err := client.GetHomeDir()
+	if err != nil {
+		return err
+	}
+	store := chunkStorePath(homeDir)
+
+	files := make([]chunkedUpload, 0, len(uploads))
+	for _, u := range uploads {
+		info, err := os.Stat(u.local)
+		if err != nil {
+			return fmt.Errorf("%s: %w", u.relPath, err)
+		}
+		files = append(files, chunkedUpload{pendingUpload: u, info: info})
+	}
+	missing := fileChunks(files)
+
+	status("Looking up chunks on the device...")
+	stored, err := storedChunks(client, store, missing)
+	if err != nil {
+		return err
+	}
+	total := len(missing)
+	missing = withoutStored(missing, stored)
+	slog.Info("Chunk store upload", "chunks", total, "missing", len(missing))
+
+	status(fmt.Sprintf("Uploading %d of %d chunks, the others are on the device...", len(missing), total))
+	if err := uploadChunks(ctx, client, store, missing, workers, retrier); err != nil {
+		return err
+	}
+
+	status("Assembling files on the device...")
+	if err := client.PipeCommand(ctx, "sh -s", strings.NewReader(assembleScript(store, files))); err != nil {
+		return fmt.Errorf("failed to assemble files: %w", err)
+	}
+	for _, f := range files {
+		onDone(f.pendingUpload)
+	}
+
+	prune := fmt.Sprintf("find %s -type f -mtime +%d -delete", device.ShellQuote(store), chunkStoreMaxDays)
+	if _, err := client.RunCommand(prune); err != nil {
+		slog.Warn("Failed to prune chunk store", "error", err)
+	}
+	return nil
+}
+
+// storedChunks returns the chunks already in the store, in batches that keep
+// each command line short
+func storedChunks(client *device.Client, store string, chunks []chunkUpload) (map[string]bool, error) {
+	stored := make(map[string]bool)
+	for start := 0; start < len(chunks); start += chunkQueryBatch {
+		end := min(start+chunkQueryBatch, len(chunks))
+		paths := make([]string, 0, end-start)
+		for _, c := range chunks[start:end] {
+			paths = append(paths, chunkPath(c.hash))
+		}
+		output, err := client.RunCommand(fmt.Sprintf("mkdir -p %s && cd %s && for f in %s; do [ -e \"$f\" ] && echo \"$f\"; done; true",
+			device.ShellQuote(store), device.ShellQuote(store), strings.Join(paths, " ")))
+		if err != nil {
+			return nil, fmt.Errorf("failed to look up chunks: %w", err)
+		}
+		for _, line := range strings.Split(output, "\n") {
+			if line = strings.TrimSpace(line); line != "" {
+				stored[path.Base(line)] = true
+			}
+		}
+	}
+	return stored, nil
+}
+
+// withoutStored leaves out the chunks already in the store
+func withoutStored(chunks []chunkUpload, stored map[string]bool) []chunkUpload {
+	var missing []chunkUpload
+	for _, c := range chunks {
+		if !stored[c.hash] {
+			missing = append(missing, c)
+		}
+	}
+	return missing
+}
+
+// uploadChunks sends chunks to the store with parallel workers, retrying each
+// one like a file
+func uploadChunks(ctx context.Context, client *device.Client, store string, chunks []chunkUpload, workers int, retrier *transfer.Retrier) error {
+	workers = max(workers, 1)
+
+	// Create the folders up front so workers don't race on MkdirAll
+	dirs := make(map[string]bool)
+	for _, c := range chunks {
+		dir := path.Dir(chunkPath(c.hash))
+		if !dirs[dir] {
+			dirs[dir] = true
+			client.MkdirAll(path.Join(store, dir))
+		}
+	}
+
+	var (
+		mu       sync.Mutex
+		next     int
+		firstErr error
+		wg       sync.WaitGroup
+	)
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				mu.Lock()
+				if firstErr != nil || next >= len(chunks) || ctx.Err() != nil {
+					mu.Unlock()
+					return
+				}
+				c := chunks[next]
+				next++
+				mu.Unlock()
+
+				_, err := retrier.Do(ctx, func() error {
+					err := client.UploadRange(c.local, c.offset, c.size, path.Join(store, chunkPath(c.hash)), c.hash)
+					if err != nil && ctx.Err() == nil {
+						slog.Warn("Chunk upload failed", "chunk", c.hash, "error", err)
+					}
+					return err
+				})
+				if err != nil {
+					mu.Lock()
+					if firstErr == nil {
+						firstErr = fmt.Errorf("chunk %s: %w", c.hash, err)
+					}
+					mu.Unlock()
+					return
+				}
+			}
+		}()
+	}
+	wg.Wait()
+	if firstErr == nil {
+		firstErr = ctx.Err()
+	}
+	return firstErr
+}
+
+// assembleScript returns the shell script that writes each file from its
+// chunks, run in the store. A file replaces the deployed one once complete,
+// which also keeps files shared with earlier releases intact. Its chunks are
+// touched so pruning keeps them. A file that doesn't match its hash stops the
+// script and drops its chunks, so the next deploy sends them again instead of
+// reusing a corrupt one.
+func assembleScript(store string, files []chunkedUpload) string {
+	var script strings.Builder
+	fmt.Fprintf(&script, "set -e\ncd %s\n", device.ShellQuote(store))
+	dirs := make(map[string]bool)
+	for _, f := range files {
+		if dir := path.Dir(f.remote); !dirs[dir] {
+			dirs[dir] = true
+			fmt.Fprintf(&script, "mkdir -p -- %s\n", device.ShellQuote(dir))
+		}
+	}
+	for _, f := range files {
+		tmp := device.ShellQuote(f.remote + ".devkit-part")
+		chunks := make([]string, len(f.chunks))
+		for i, hash := range f.chunks {
+			chunks[i] = chunkPath(hash)
+		}
+		if len(chunks) == 0 {
+			fmt.Fprintf(&script, ": > %s\n", tmp)
+		} else {
+			fmt.Fprintf(&script, "cat -- %s > %s\ntouch -c -- %s\n", strings.Join(chunks, " "), tmp, strings.Join(chunks, " "))
+		}
+		fmt.Fprintf(&script, "if [ \"$(sha256sum < %s | cut -d ' ' -f 1)\" != %s ]; then rm -f -- %s %s; echo %s >&2; exit 1; fi\n",
+			tmp, device.ShellQuote(f.hash), tmp, strings.Join(chunks, " "), device.ShellQuote(f.relPath+": assembled file doesn't match its hash"))
+		fmt.Fprintf(&script, "chmod %o %s\ntouch -m -d @%d %s\nmv -f -- %s %s\n",
+			f.info.Mode().Perm(), tmp, f.info.ModTime().Unix(), tmp, tmp, device.ShellQuote(f.remote))
+	}
+	return script.String()
+}
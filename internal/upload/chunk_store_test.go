@@ -0,0 +1,208 @@
+package upload
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestChunkPath(t *testing.T) {
+	if got := chunkPath("ab12cd"); got != "ab/ab12cd" {
+		t.Errorf("chunkPath() = %s, want ab/ab12cd", got)
+	}
+}
+
+func TestFileChunks(t *testing.T) {
+	file := func(local string, size int64, chunks ...string) chunkedUpload {
+		return chunkedUpload{pendingUpload: pendingUpload{local: local, size: size, chunks: chunks}}
+	}
+
+	tests := []struct {
+		name  string
+		files []chunkedUpload
+		want  []chunkUpload
+	}{
+		{
+			name:  "short last chunk",
+			files: []chunkedUpload{file("a.pak", chunkSize+10, "h1", "h2")},
+			want: []chunkUpload{
+				{hash: "h1", local: "a.pak", offset: 0, size: chunkSize},
+				{hash: "h2", local: "a.pak", offset: chunkSize, size: 10},
+			},
+		},
+		{
+			name: "chunk shared by two files is read from the first",
+			files: []chunkedUpload{
+				file("a.pak", 2*chunkSize, "h1", "h2"),
+				file("b.pak", 2*chunkSize, "h3", "h1"),
+			},
+			want: []chunkUpload{
+				{hash: "h1", local: "a.pak", offset: 0, size: chunkSize},
+				{hash: "h2", local: "a.pak", offset: chunkSize, size: chunkSize},
+				{hash: "h3", local: "b.pak", offset: 0, size: chunkSize},
+			},
+		},
+		{
+			name:  "chunk repeated within a file",
+			files: []chunkedUpload{file("zeros.bin", 3*chunkSize, "h0", "h0", "h0")},
+			want:  []chunkUpload{{hash: "h0", local: "zeros.bin", offset: 0, size: chunkSize}},
+		},
+		{
+			name:  "empty file",
+			files: []chunkedUpload{file("empty.txt", 0)},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := fileChunks(tt.files); !slices.Equal(got, tt.want) {
+				t.Errorf("fileChunks() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWithoutStored(t *testing.T) {
+	chunks := []chunkUpload{{hash: "h1"}, {hash: "h2"}, {hash: "h3"}}
+	got := withoutStored(chunks, map[string]bool{"h1": true, "h3": true, "other": true})
+	if !slices.Equal(got, []chunkUpload{{hash: "h2"}}) {
+		t.Errorf("withoutStored() = %+v, want only h2", got)
+	}
+	if got := withoutStored(chunks, map[string]bool{"h1": true, "h2": true, "h3": true}); got != nil {
+		t.Errorf("withoutStored() = %+v, want nothing", got)
+	}
+}
+
+// storeChunks splits data into chunks, writes them to a store and returns
+// their hashes
+func storeChunks(t *testing.T, store string, data []byte) []string {
+	t.Helper()
+	var hashes []string
+	for start := 0; start < len(data); start += chunkSize {
+		chunk := data[start:min(start+chunkSize, len(data))]
+		sum := sha256.Sum256(chunk)
+		hash := hex.EncodeToString(sum[:])
+		name := filepath.Join(store, chunkPath(hash))
+		os.MkdirAll(filepath.Dir(name), 0755)
+		if err := os.WriteFile(name, chunk, 0644); err != nil {
+			t.Fatal(err)
+		}
+		hashes = append(hashes, hash)
+	}
+	return hashes
+}
+
+func TestAssembleScript(t *testing.T) {
+	for _, tool := range []string{"sh", "sha256sum"} {
+		if _, err := exec.LookPath(tool); err != nil {
+			t.Skipf("%s not available", tool)
+		}
+	}
+
+	tests := []struct {
+		name    string
+		data    map[string][]byte // relative path of each file and its content
+		corrupt string            // file with a chunk changed in the store
+	}{
+		{
+			name: "files of several chunks, shared chunks and an empty file",
+			data: map[string][]byte{
+				"game.pak":         bytes.Repeat([]byte("p"), chunkSize+100),
+				"data/level 1.pak": bytes.Repeat([]byte("p"), chunkSize),
+				"it's.txt":         []byte("quotes"),
+				"empty.txt":        nil,
+			},
+		},
+		{
+			name:    "file that doesn't match its hash",
+			data:    map[string][]byte{"game.pak": []byte("build")},
+			corrupt: "game.pak",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			store := filepath.Join(dir, "chunks")
+			local := filepath.Join(dir, "build")
+			remote := filepath.Join(dir, "game")
+
+			var files []chunkedUpload
+			for relPath, data := range tt.data {
+				name := filepath.Join(local, relPath)
+				os.MkdirAll(filepath.Dir(name), 0755)
+				if err := os.WriteFile(name, data, 0755); err != nil {
+					t.Fatal(err)
+				}
+				info, err := os.Stat(name)
+				if err != nil {
+					t.Fatal(err)
+				}
+				sum := sha256.Sum256(data)
+				chunks := storeChunks(t, store, data)
+				if relPath == tt.corrupt {
+					os.WriteFile(filepath.Join(store, chunkPath(chunks[0])), []byte("corrupt"), 0644)
+				}
+				files = append(files, chunkedUpload{
+					pendingUpload: pendingUpload{
+						relPath: relPath,
+						remote:  filepath.Join(remote, relPath),
+						hash:    hex.EncodeToString(sum[:]),
+						size:    info.Size(),
+						chunks:  chunks,
+					},
+					info: info,
+				})
+			}
+
+			cmd := exec.Command("sh", "-s")
+			cmd.Stdin = strings.NewReader(assembleScript(store, files))
+			output, err := cmd.CombinedOutput()
+
+			if tt.corrupt != "" {
+				if err == nil {
+					t.Fatal("script succeeded with a corrupt chunk")
+				}
+				if !strings.Contains(string(output), tt.corrupt+": assembled file doesn't match its hash") {
+					t.Errorf("output = %q, want the file that didn't match", output)
+				}
+				for _, f := range files {
+					if _, err := os.Stat(filepath.Join(store, chunkPath(f.chunks[0]))); !os.IsNotExist(err) {
+						t.Errorf("corrupt chunk kept in the store: %v", err)
+					}
+					if _, err := os.Stat(f.remote); !os.IsNotExist(err) {
+						t.Errorf("file that didn't match deployed: %v", err)
+					}
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("script failed: %v\n%s", err, output)
+			}
+			for _, f := range files {
+				got, err := os.ReadFile(f.remote)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if !bytes.Equal(got, tt.data[f.relPath]) {
+					t.Errorf("%s: assembled %d bytes that don't match the build", f.relPath, len(got))
+				}
+				info, err := os.Stat(f.remote)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if info.Mode().Perm() != f.info.Mode().Perm() || info.ModTime().Unix() != f.info.ModTime().Unix() {
+					t.Errorf("%s: mode %v, time %v, want %v, %v", f.relPath, info.Mode(), info.ModTime(), f.info.Mode(), f.info.ModTime())
+				}
+				if _, err := os.Stat(f.remote + ".devkit-part"); !os.IsNotExist(err) {
+					t.Errorf("%s: partial file left behind", f.relPath)
+				}
+			}
+		})
+	}
+}
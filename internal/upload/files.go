@@ -0,0 +1,115 @@
+package upload
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+
+	"github.com/lobinuxsoft/capydeploy/pkg/engine"
+	"github.com/lobinuxsoft/capydeploy/pkg/ignore"
+)
+
+// ListFiles lists the files of a game folder, leaving out the ones matching
+// the exclude patterns of the setup or its .devkitignore
+func ListFiles(root string, exclude []string) ([]string, error) {
+	ignored, err := ignore.Load(root)
+	if err != nil {
+		return nil, err
+	}
+
+	var files []string
+	err = filepath.Walk(root, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if path == root {
+			return nil
+		}
+		rel, _ := filepath.Rel(root, path)
+		rel = filepath.ToSlash(rel)
+		if rel == ignore.FileName || engine.Excluded(rel, exclude) || ignored.Match(rel, info.IsDir()) {
+			if info.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if info.Mode()&os.ModeSymlink != 0 && !uploadableLink(root, path) {
+			slog.Warn("Skipping symlink to a folder outside the game or to nothing", "path", rel)
+			return nil
+		}
+		if !info.IsDir() {
+			files = append(files, path)
+		}
+		return nil
+	})
+	return files, err
+}
+
+// HashFile returns the hex SHA-256 of a local file
+func HashFile(localPath string) (string, error) {
+	f, err := os.Open(localPath)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// hashFileBlocks returns the hex SHA-256 of a local file and the hashes of its
+// blocks of blockSize bytes, the last one shorter, in one read. A block hash is
+// the first digestSize bytes of its SHA-256.
+func hashFileBlocks(localPath string, blockSize, digestSize int) (string, []string, error) {
+	f, err := os.Open(localPath)
+	if err != nil {
+		return "", nil, err
+	}
+	defer f.Close()
+	return hashBlocks(f, blockSize, digestSize)
+}
+
+// hashBlocks is hashFileBlocks for what r reads
+func hashBlocks(r io.Reader, blockSize, digestSize int) (string, []string, error) {
+	whole := sha256.New()
+	var blocks []string
+	buf := make([]byte, blockSize)
+	for {
+		n, err := io.ReadFull(r, buf)
+		if n > 0 {
+			whole.Write(buf[:n])
+			sum := sha256.Sum256(buf[:n])
+			blocks = append(blocks, hex.EncodeToString(sum[:digestSize]))
+		}
+		if err == io.EOF || err == io.ErrUnexpectedEOF {
+			break
+		}
+		if err != nil {
+			return "", nil, err
+		}
+	}
+	return hex.EncodeToString(whole.Sum(nil)), blocks, nil
+}
+
+// IsELF reports whether a file is a Linux binary. Builds copied from Windows
+// machines lose the executable bit, so the header is checked instead of the mode
+func IsELF(p string) bool {
+	f, err := os.Open(p)
+	if err != nil {
+		return false
+	}
+	defer f.Close()
+
+	magic := make([]byte, 4)
+	if _, err := f.Read(magic); err != nil {
+		return false
+	}
+	return bytes.Equal(magic, []byte("\x7fELF"))
+}
@@ -0,0 +1,64 @@
+package upload
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+func TestHashBlocks(t *testing.T) {
+	const size = 8
+	digest := func(data string, n int) string {
+		sum := sha256.Sum256([]byte(data))
+		return hex.EncodeToString(sum[:n])
+	}
+
+	tests := []struct {
+		name       string
+		data       string
+		digestSize int
+		blocks     []string
+	}{
+		{"empty", "", sha256.Size, nil},
+		{"shorter than a block", "pak", sha256.Size, []string{digest("pak", sha256.Size)}},
+		{"whole blocks", "aaaaaaaabbbbbbbb", sha256.Size, []string{digest("aaaaaaaa", sha256.Size), digest("bbbbbbbb", sha256.Size)}},
+		{"short last block", "aaaaaaaax", sha256.Size, []string{digest("aaaaaaaa", sha256.Size), digest("x", sha256.Size)}},
+		{"half digests", "aaaaaaaax", patchDigestSize, []string{digest("aaaaaaaa", patchDigestSize), digest("x", patchDigestSize)}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			hash, blocks, err := hashBlocks(bytes.NewReader([]byte(tt.data)), size, tt.digestSize)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if want := digest(tt.data, sha256.Size); hash != want {
+				t.Errorf("hash = %s, want %s", hash, want)
+			}
+			if !slices.Equal(blocks, tt.blocks) {
+				t.Errorf("blocks = %q, want %q", blocks, tt.blocks)
+			}
+		})
+	}
+}
+
+func TestHashFileBlocks(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "game.pak")
+	if err := os.WriteFile(file, bytes.Repeat([]byte("pak"), 1000), 0644); err != nil {
+		t.Fatal(err)
+	}
+	want, err := HashFile(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	hash, blocks, err := hashFileBlocks(file, 1024, sha256.Size)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if hash != want || len(blocks) != 3 {
+		t.Errorf("hashFileBlocks() = %s, %d blocks, want %s, 3 blocks", hash, len(blocks), want)
+	}
+}
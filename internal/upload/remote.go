@@ -0,0 +1,86 @@
+package upload
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/lobinuxsoft/capydeploy/internal/device"
+)
+
+// RemoteFile is the size, modification time and permissions of a deployed file
+type RemoteFile struct {
+	Size  int64
+	MTime int64 // unix seconds
+	Perm  os.FileMode
+}
+
+// matches reports whether the local file has the same size and modification time
+func (r RemoteFile) matches(localPath string) bool {
+	info, err := os.Stat(localPath)
+	if err != nil {
+		return false
+	}
+	return info.Size() == r.Size && info.ModTime().Unix() == r.MTime
+}
+
+// RemoteIndex returns the files under a remote directory keyed by their
+// slash separated relative path
+func RemoteIndex(client *device.Client, remoteDir string) (map[string]RemoteFile, error) {
+	output, err := client.RunCommand(fmt.Sprintf(`find %s -type f -printf '%%P\t%%s\t%%T@\t%%m\n' 2>/dev/null`, device.ShellQuote(remoteDir)))
+	if err != nil {
+		return nil, err
+	}
+
+	index := make(map[string]RemoteFile)
+	for _, line := range strings.Split(output, "\n") {
+		fields := strings.Split(line, "\t")
+		if len(fields) != 4 {
+			continue
+		}
+		size, err := strconv.ParseInt(fields[1], 10, 64)
+		if err != nil {
+			continue
+		}
+		mtime, err := strconv.ParseFloat(strings.TrimSpace(fields[2]), 64)
+		if err != nil {
+			continue
+		}
+		perm, err := strconv.ParseUint(strings.TrimSpace(fields[3]), 8, 32)
+		if err != nil {
+			continue
+		}
+		index[fields[0]] = RemoteFile{Size: size, MTime: int64(mtime), Perm: os.FileMode(perm).Perm()}
+	}
+	return index, nil
+}
+
+// unchangedOnDevice reports whether a deployed file still matches the local one.
+// The hash recorded by the previous deploy is trusted over timestamps, which
+// change whenever the build is copied around; without one only size and mtime
+// are compared
+func unchangedOnDevice(previousFiles map[string]string, remote RemoteFile, relPath, hash, localPath string) bool {
+	if previousFiles != nil {
+		info, err := os.Stat(localPath)
+		return err == nil && previousFiles[relPath] == hash && info.Size() == remote.Size
+	}
+	return remote.matches(localPath)
+}
+
+// unlinkPending removes the files about to be uploaded from a release. They
+// are hard links shared with earlier releases, and uploads write into existing
+// files, which would change those releases too.
+func unlinkPending(ctx context.Context, client *device.Client, releasePath string, pending []pendingUpload) error {
+	if len(pending) == 0 {
+		return nil
+	}
+	var list bytes.Buffer
+	for _, u := range pending {
+		list.WriteString(u.relPath)
+		list.WriteByte(0)
+	}
+	return client.PipeCommand(ctx, fmt.Sprintf("cd %s && xargs -0 rm -f --", device.ShellQuote(releasePath)), &list)
+}
@@ -0,0 +1,75 @@
+package upload
+
+import (
+	"sync"
+	"time"
+
+	"github.com/lobinuxsoft/capydeploy/pkg/transfer"
+)
+
+// reportInterval is how often the speed of an upload is reported while no
+// file starts, e.g. during a single large file
+const reportInterval = time.Second
+
+// reporter emits the progress of an upload by bytes sent, along with its
+// speed and time left
+type reporter struct {
+	meter *transfer.Meter
+	emit  func(Progress)
+
+	mu     sync.Mutex
+	status string
+	stop   chan struct{}
+	done   chan struct{}
+}
+
+// newReporter starts reporting an upload of totalBytes
+func newReporter(totalBytes int64, emit func(Progress)) *reporter {
+	r := &reporter{
+		meter:  transfer.NewMeter(totalBytes),
+		emit:   emit,
+		status: "Uploading...",
+		stop:   make(chan struct{}),
+		done:   make(chan struct{}),
+	}
+	go func() {
+		defer close(r.done)
+		ticker := time.NewTicker(reportInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				r.report()
+			case <-r.stop:
+				return
+			}
+		}
+	}()
+	return r
+}
+
+// setStatus changes the status reported with the stats and reports it
+func (r *reporter) setStatus(status string) {
+	r.mu.Lock()
+	r.status = status
+	r.mu.Unlock()
+	r.report()
+}
+
+func (r *reporter) report() {
+	stats := r.meter.Stats()
+	share := 0.0
+	if stats.TotalBytes > 0 {
+		share = min(float64(stats.SentBytes)/float64(stats.TotalBytes), 1)
+	}
+	r.mu.Lock()
+	status := r.status
+	r.mu.Unlock()
+	r.emit(Progress{Share: share, Status: status, Stats: stats})
+}
+
+// close stops the periodic reports
+func (r *reporter) close() {
+	close(r.stop)
+	<-r.done
+}
@@ -0,0 +1,60 @@
+package upload
+
+import (
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// symlinkUpload is a symlink of a game folder recreated on the device
+type symlinkUpload struct {
+	relPath string // slash separated path of the link
+	target  string // what it points to, relative to the link
+}
+
+// permFix is an unchanged file whose permissions changed since the last deploy
+type permFix struct {
+	remote string
+	perm   os.FileMode
+}
+
+// LinkTarget returns the target of a symlink of a game folder that is
+// recreated on the device: a relative one that stays inside the folder, like
+// the versioned .so links of Linux builds. Other symlinks are uploaded as the
+// file they point to.
+func LinkTarget(root, file string) (string, bool) {
+	info, err := os.Lstat(file)
+	if err != nil || info.Mode()&os.ModeSymlink == 0 {
+		return "", false
+	}
+	target, err := os.Readlink(file)
+	if err != nil || filepath.IsAbs(target) {
+		return "", false
+	}
+	target = filepath.ToSlash(target)
+	if path.IsAbs(target) {
+		return "", false
+	}
+
+	relPath, err := filepath.Rel(root, file)
+	if err != nil {
+		return "", false
+	}
+	resolved := path.Join(path.Dir(filepath.ToSlash(relPath)), target)
+	if resolved == ".." || strings.HasPrefix(resolved, "../") {
+		return "", false
+	}
+	return target, true
+}
+
+// uploadableLink reports whether a symlink met walking a game folder can be
+// deployed: it is recreated, or points to a file uploaded in its place.
+// Links to folders outside the game and broken links are left out.
+func uploadableLink(root, file string) bool {
+	if _, ok := LinkTarget(root, file); ok {
+		return true
+	}
+	info, err := os.Stat(file)
+	return err == nil && !info.IsDir()
+}
@@ -0,0 +1,94 @@
+package upload
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"context"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/lobinuxsoft/capydeploy/internal/device"
+)
+
+// uploadTarStream sends files as a single gzipped tar streamed over SSH and
+// extracted on the device, one round trip for the whole game instead of
+// several per file. onProgress reports the share of bytes sent and the file
+// being packed.
+func uploadTarStream(ctx context.Context, client *device.Client, remoteDir string, uploads []pendingUpload, onProgress func(progress float64, relPath string)) error {
+	var total int64
+	for _, u := range uploads {
+		total += u.size
+	}
+
+	pr, pw := io.Pipe()
+	go func() {
+		pw.CloseWithError(writeTarStream(ctx, pw, uploads, total, onProgress))
+	}()
+
+	cmd := fmt.Sprintf("mkdir -p %s && tar -xzf - --no-same-owner -C %s", device.ShellQuote(remoteDir), device.ShellQuote(remoteDir))
+	err := client.PipeCommand(ctx, cmd, pr)
+	// Unblock the writer if the device stopped reading
+	pr.CloseWithError(io.ErrClosedPipe)
+	if err != nil {
+		return fmt.Errorf("compressed upload failed: %w", err)
+	}
+	return nil
+}
+
+// writeTarStream packs uploads into a gzipped tar written to w
+func writeTarStream(ctx context.Context, w io.Writer, uploads []pendingUpload, total int64, onProgress func(float64, string)) error {
+	gz, err := gzip.NewWriterLevel(w, gzip.BestSpeed)
+	if err != nil {
+		return err
+	}
+	tw := tar.NewWriter(gz)
+
+	var sent int64
+	for _, u := range uploads {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		progress := 0.0
+		if total > 0 {
+			progress = float64(sent) / float64(total)
+		}
+		onProgress(progress, u.relPath)
+
+		if err := addTarFile(tw, u); err != nil {
+			return fmt.Errorf("%s: %w", u.relPath, err)
+		}
+		sent += u.size
+	}
+
+	if err := tw.Close(); err != nil {
+		return err
+	}
+	return gz.Close()
+}
+
+// addTarFile writes a local file to tw under its relative path
+func addTarFile(tw *tar.Writer, u pendingUpload) error {
+	f, err := os.Open(u.local)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	info, err := f.Stat()
+	if err != nil {
+		return err
+	}
+
+	header := &tar.Header{
+		Typeflag: tar.TypeReg,
+		Name:     u.relPath,
+		Size:     info.Size(),
+		Mode:     int64(info.Mode().Perm()),
+		ModTime:  info.ModTime(),
+	}
+	if err := tw.WriteHeader(header); err != nil {
+		return err
+	}
+	_, err = io.CopyN(tw, f, info.Size())
+	return err
+}
@@ -0,0 +1,513 @@
+// Package upload works out which files of a build a device still needs and
+// sends them over SFTP, rsync, a compressed stream, the chunk store or as an
+// archive extracted on the device
+package upload
+
+import (
+	"context"
+	"crypto/sha256"
+	"fmt"
+	"log/slog"
+	"os"
+	"path"
+	"path/filepath"
+	goruntime "runtime"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/lobinuxsoft/capydeploy/internal/device"
+	"github.com/lobinuxsoft/capydeploy/pkg/config"
+	"github.com/lobinuxsoft/capydeploy/pkg/transfer"
+)
+
+// DataDir is where devkit keeps its files on the device, relative to the
+// home folder
+const DataDir = ".local/share/devkit"
+
+// sessionInterval is how often a running upload saves its upload session
+const sessionInterval = 2 * time.Second
+
+// Wait before the first retry of a file that failed to upload, doubled after
+// each retry up to retryMaxDelay
+const (
+	retryDelay    = time.Second
+	retryMaxDelay = 30 * time.Second
+)
+
+// commandBatch caps the files passed to one command on the device, to keep
+// its command line short
+const commandBatch = 100
+
+// pendingUpload is a local file the device doesn't have yet
+type pendingUpload struct {
+	local   string
+	relPath string
+	remote  string
+	hash    string
+	size    int64
+	blocks  []int    // changed blocks to patch, nil uploads the whole file
+	chunks  []string // SHA-256 of each chunk, for the chunk store
+}
+
+// Build is a local build ready to plan: the files of a folder or the entries
+// of an archive
+type Build struct {
+	Path    string
+	Archive bool
+
+	files   []string
+	entries []ArchiveEntry
+}
+
+// Scan lists the files of a build folder, leaving out the excluded ones, or
+// reads a build archive
+func Scan(localPath string, exclude []string) (*Build, error) {
+	build := &Build{Path: localPath, Archive: IsArchive(localPath)}
+	var err error
+	if build.Archive {
+		build.entries, err = ReadArchive(localPath)
+	} else {
+		build.files, err = ListFiles(localPath, exclude)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return build, nil
+}
+
+// Options describe where a build goes and how it is sent
+type Options struct {
+	Name      string // of the game, for the logs
+	RemoteDir string // folder the build is written to
+	// Skip files the device already has: their hash is checked against the
+	// one recorded by the previous deploy, or their size and modification
+	// time without one
+	Delta bool
+	// The upload goes to a new release that shares unchanged files with
+	// earlier ones
+	Releases   bool
+	Rsync      bool
+	Compressed bool
+	ChunkStore bool
+	BlockPatch bool
+	// Hashes of the files and of the blocks of large files recorded by the
+	// previous deploy, nil without one
+	PreviousFiles  map[string]string
+	PreviousBlocks map[string][]string
+	// Files in RemoteDir before the upload, nil when they couldn't be listed
+	RemoteFiles map[string]RemoteFile
+	// Files an interrupted upload to the same place already sent
+	Session *config.UploadSession
+}
+
+// Plan is what an upload of a build sends
+type Plan struct {
+	build     *Build
+	opts      Options
+	chunked   bool
+	pending   []pendingUpload
+	symlinks  []symlinkUpload
+	permFixes []permFix
+
+	Hashes    map[string]string   // SHA-256 of every file of the build
+	Blocks    map[string][]string // hashes of the blocks of large files
+	Binaries  []string            // Linux binaries of the build
+	Total     int                 // files in the build
+	Unchanged int                 // files not sent again, resumed ones included
+	Resumed   int                 // files an interrupted upload already sent
+	Bytes     int64               // bytes to send
+	Needed    int64               // space the upload takes on the device
+}
+
+// NewPlan hashes every file of a build and works out the ones the device
+// still needs
+func NewPlan(ctx context.Context, build *Build, opts Options) (*Plan, error) {
+	archive := build.Archive
+	p := &Plan{
+		build:  build,
+		opts:   opts,
+		Hashes: make(map[string]string, len(build.files)+len(build.entries)),
+		Blocks: make(map[string][]string),
+		Total:  len(build.files) + len(build.entries),
+	}
+	// Unchanged files whose permissions changed, e.g. a binary made executable;
+	// Windows has no permissions to carry over
+	checkPerms := goruntime.GOOS != "windows"
+	// Large files are hashed by blocks too, to patch them on later deploys.
+	// The chunk store sends only chunks the device lacks, including changed blocks.
+	p.chunked = opts.ChunkStore && !archive && !opts.Compressed
+	patching := opts.BlockPatch && !archive && !opts.Rsync && !opts.Compressed && !p.chunked
+	for _, file := range build.files {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		relPath, _ := filepath.Rel(build.Path, file)
+		relPath = strings.ReplaceAll(relPath, "\\", "/")
+		if target, ok := LinkTarget(build.Path, file); ok {
+			p.symlinks = append(p.symlinks, symlinkUpload{relPath: relPath, target: target})
+			continue
+		}
+
+		info, err := os.Stat(file)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", relPath, err)
+		}
+		// One read hashes the file and the blocks or chunks it is sent in
+		var hash string
+		var blocks, chunks []string
+		switch {
+		case p.chunked:
+			hash, chunks, err = hashFileBlocks(file, chunkSize, sha256.Size)
+		case patching && info.Size() >= patchMinSize:
+			hash, blocks, err = hashFileBlocks(file, patchBlockSize, patchDigestSize)
+		default:
+			hash, err = HashFile(file)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", relPath, err)
+		}
+		p.Hashes[relPath] = hash
+		if blocks != nil {
+			p.Blocks[relPath] = blocks
+		}
+		if IsELF(file) {
+			p.Binaries = append(p.Binaries, relPath)
+		}
+
+		upload := pendingUpload{local: file, relPath: relPath, remote: path.Join(opts.RemoteDir, relPath), hash: hash, size: info.Size(), chunks: chunks}
+		if remote, ok := opts.RemoteFiles[relPath]; ok {
+			if opts.Session.Uploaded(relPath, hash) && remote.Size == info.Size() {
+				p.Resumed++
+				continue
+			}
+			if opts.Delta && unchangedOnDevice(opts.PreviousFiles, remote, relPath, hash, file) {
+				if checkPerms && remote.Perm != info.Mode().Perm() {
+					p.permFixes = append(p.permFixes, permFix{remote: path.Join(opts.RemoteDir, relPath), perm: info.Mode().Perm()})
+				}
+				continue
+			}
+			if blocks != nil {
+				upload.blocks = patchBlocks(opts.PreviousBlocks[relPath], blocks, remote.Size)
+			}
+		}
+		p.pending = append(p.pending, upload)
+	}
+	// An archive is extracted whole, its files are only listed
+	for _, entry := range build.entries {
+		p.Hashes[entry.RelPath] = entry.Hash
+		if entry.ELF {
+			p.Binaries = append(p.Binaries, entry.RelPath)
+		}
+		p.pending = append(p.pending, pendingUpload{relPath: entry.RelPath, remote: path.Join(opts.RemoteDir, entry.RelPath), hash: entry.Hash, size: entry.Size})
+	}
+	p.Unchanged = p.Total - len(p.pending) - len(p.symlinks)
+
+	for _, u := range p.pending {
+		p.Bytes += patchSize(u)
+	}
+	// Replaced files free their old size as they are rewritten, unless an
+	// earlier release keeps them. Large files are written next to the old one
+	// until they are complete, so it needs room for both. The chunk store keeps
+	// a copy of each file on top of it, at most its size when no chunk is there.
+	for _, u := range p.pending {
+		p.Needed += u.size
+		if p.chunked {
+			p.Needed += u.size
+		} else if remote, ok := opts.RemoteFiles[u.relPath]; ok && !opts.Releases && u.size < device.ResumeMinSize {
+			p.Needed -= min(remote.Size, u.size)
+		}
+	}
+	if archive {
+		var archiveSize int64
+		if info, err := os.Stat(build.Path); err == nil {
+			archiveSize = info.Size()
+		}
+		// A zip is saved on the device before it is extracted
+		if isZipArchive(build.Path) {
+			p.Needed += archiveSize
+		}
+		// What is sent is the archive itself
+		p.Bytes = archiveSize
+	}
+	return p, nil
+}
+
+// Pending returns how many files the upload sends
+func (p *Plan) Pending() int {
+	return len(p.pending)
+}
+
+// Progress is a report of a running transfer
+type Progress struct {
+	Share  float64 // of the bytes to send, from 0 to 1
+	Status string
+	Stats  transfer.Stats
+}
+
+// TransferOptions tune how files are sent
+type TransferOptions struct {
+	Workers     int // files uploaded in parallel
+	Retries     int // retries of each file
+	RetryBudget int // retries of the whole transfer
+}
+
+// Transfer sends the files of a plan to the device, reporting progress
+// through onProgress, and returns the files that uploaded only after retries,
+// with their retries. The upload session of the plan records every file sent,
+// so an interrupted transfer resumes where it stopped.
+func Transfer(ctx context.Context, client *device.Client, p *Plan, opts TransferOptions, onProgress func(Progress)) ([]string, error) {
+	session := p.opts.Session
+	localPath, remoteDir := p.build.Path, p.opts.RemoteDir
+	archive := p.build.Archive
+
+	// Save the session now and then so a crash loses little
+	var lastSave time.Time
+	saveSession := func() {
+		if err := config.SaveUploadSession(session); err != nil {
+			slog.Warn("Failed to save upload session", "error", err)
+		}
+		lastSave = time.Now()
+	}
+
+	reporter := newReporter(p.Bytes, onProgress)
+	// Share of the bytes sent, for transfers that only report that
+	sentShare := func(share float64) {
+		reporter.meter.SetSent(int64(share * float64(p.Bytes)))
+	}
+
+	// Files that uploaded only after retrying, with their retries
+	var retried []string
+
+	// rsync sends only the changed parts of each file; any failure other than
+	// a cancel falls back to SFTP, which uploads the pending files again
+	var err error
+	rsynced := false
+	if p.opts.Rsync && !archive && len(p.pending) > 0 {
+		if client.RsyncAvailable() {
+			relPaths := make([]string, len(p.pending))
+			for i, u := range p.pending {
+				relPaths[i] = u.relPath
+			}
+			reporter.setStatus("Uploading with rsync...")
+			err = client.Rsync(ctx, localPath, remoteDir, relPaths, sentShare)
+			rsynced = err == nil || ctx.Err() != nil
+			if err == nil {
+				for _, u := range p.pending {
+					session.Complete(u.relPath, u.hash, u.size)
+				}
+			} else if !rsynced {
+				slog.Warn("rsync upload failed, uploading with SFTP", "error", err)
+			}
+		} else {
+			slog.Info("rsync unavailable, uploading with SFTP", "game", p.opts.Name)
+			reporter.setStatus("rsync needs an SSH key and rsync on both ends, uploading with SFTP")
+		}
+	}
+	// rsync replaces files rather than writing into them, the other uploads
+	// need the files shared with earlier releases out of the way
+	err = nil
+	if p.opts.Releases && !archive && !rsynced {
+		if err = unlinkPending(ctx, client, remoteDir, p.pending); err != nil && ctx.Err() == nil {
+			err = fmt.Errorf("failed to prepare the new release: %w", err)
+		}
+	}
+	retrier := transfer.NewRetrier(transfer.RetryPolicy{
+		Retries:   opts.Retries,
+		Budget:    opts.RetryBudget,
+		BaseDelay: retryDelay,
+		MaxDelay:  retryMaxDelay,
+	})
+	switch {
+	case err != nil, rsynced:
+		// Failed, uploaded or cancelled above
+	case archive:
+		client.SetUploadCounter(reporter.meter.Add)
+		reporter.setStatus(fmt.Sprintf("Uploading and extracting %s...", filepath.Base(localPath)))
+		err = uploadArchive(ctx, client, localPath, remoteDir)
+		client.SetUploadCounter(nil)
+	case p.opts.Compressed && len(p.pending) > 0:
+		// The device extracts as it receives, the session only records a complete stream
+		err = uploadTarStream(ctx, client, remoteDir, p.pending, func(share float64, relPath string) {
+			sentShare(share)
+			reporter.setStatus(fmt.Sprintf("Uploading (compressed): %s", relPath))
+		})
+		if err == nil {
+			for _, u := range p.pending {
+				session.Complete(u.relPath, u.hash, u.size)
+			}
+		}
+	case p.chunked && len(p.pending) > 0:
+		client.SetUploadCounter(reporter.meter.Add)
+		err = uploadChunked(ctx, client, p.pending, opts.Workers, retrier, reporter.setStatus, func(u pendingUpload) {
+			session.Complete(u.relPath, u.hash, u.size)
+		})
+		client.SetUploadCounter(nil)
+	default:
+		client.SetUploadCounter(reporter.meter.Add)
+		err = uploadFiles(ctx, client, p.pending, opts.Workers, retrier, func(started int, relPath string) {
+			reporter.setStatus(fmt.Sprintf("Uploading: %s", relPath))
+		}, func(u pendingUpload, retries int) {
+			if retries > 0 {
+				retried = append(retried, fmt.Sprintf("%s (%d)", u.relPath, retries))
+			}
+			session.Complete(u.relPath, u.hash, u.size)
+			if time.Since(lastSave) > sessionInterval {
+				saveSession()
+			}
+		})
+		client.SetUploadCounter(nil)
+	}
+	reporter.close()
+	if len(p.pending) > 0 {
+		saveSession()
+	}
+	if ctx.Err() != nil {
+		return retried, ctx.Err()
+	}
+	return retried, err
+}
+
+// FinishFiles recreates the symlinks of the build on the device and carries
+// over the permissions of unchanged files. Symlinks are recreated on every
+// deploy, they cost a round trip each.
+func FinishFiles(client *device.Client, p *Plan) error {
+	for _, link := range p.symlinks {
+		linkPath := path.Join(p.opts.RemoteDir, link.relPath)
+		client.MkdirAll(path.Dir(linkPath))
+		if err := client.Symlink(link.target, linkPath); err != nil {
+			return fmt.Errorf("symlink %s: %w", link.relPath, err)
+		}
+	}
+	for _, fix := range p.permFixes {
+		if err := client.Chmod(fix.remote, fix.perm); err != nil {
+			slog.Warn("Failed to update permissions", "path", fix.remote, "error", err)
+		}
+	}
+	return nil
+}
+
+// Symlinks returns how many symlinks FinishFiles creates
+func (p *Plan) Symlinks() int {
+	return len(p.symlinks)
+}
+
+// uploadFiles uploads files with up to workers uploads in flight, calling
+// onStart as each one begins and onDone, with the retries it took, as each one
+// completes, one call at a time. A file that fails is retried by retrier;
+// stops at the first file it gives up on or when ctx is cancelled.
+func uploadFiles(ctx context.Context, client *device.Client, uploads []pendingUpload, workers int, retrier *transfer.Retrier, onStart func(started int, relPath string), onDone func(u pendingUpload, retries int)) error {
+	if workers < 1 {
+		workers = 1
+	}
+
+	// Create the directories up front so workers don't race on MkdirAll
+	dirs := make(map[string]bool)
+	for _, u := range uploads {
+		dir := path.Dir(u.remote)
+		if !dirs[dir] {
+			dirs[dir] = true
+			client.MkdirAll(dir)
+		}
+	}
+
+	var (
+		mu       sync.Mutex
+		next     int
+		firstErr error
+		wg       sync.WaitGroup
+	)
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				mu.Lock()
+				if firstErr != nil || next >= len(uploads) || ctx.Err() != nil {
+					mu.Unlock()
+					return
+				}
+				u := uploads[next]
+				onStart(next, u.relPath)
+				next++
+				mu.Unlock()
+
+				retries, err := retrier.Do(ctx, func() error {
+					var err error
+					if u.blocks != nil {
+						err = patchFile(client, u)
+					} else {
+						err = client.UploadFile(u.local, u.remote)
+					}
+					if err != nil && ctx.Err() == nil {
+						slog.Warn("File upload failed", "file", u.relPath, "error", err)
+					}
+					return err
+				})
+				if err != nil {
+					mu.Lock()
+					if firstErr == nil {
+						firstErr = fmt.Errorf("%s: %w", u.relPath, err)
+					}
+					mu.Unlock()
+					return
+				}
+				mu.Lock()
+				onDone(u, retries)
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+	return firstErr
+}
+
+// MarkExecutable marks files of a game directory as executable, in batches
+// that keep each command line short
+func MarkExecutable(client *device.Client, root string, relPaths []string) error {
+	for start := 0; start < len(relPaths); start += commandBatch {
+		end := min(start+commandBatch, len(relPaths))
+		args := make([]string, 0, end-start)
+		for _, relPath := range relPaths[start:end] {
+			args = append(args, device.ShellQuote(path.Join(root, relPath)))
+		}
+		if _, err := client.RunCommand("chmod +x -- " + strings.Join(args, " ")); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Verify hashes the files a plan sent on the device and returns the ones that
+// are missing or don't match the build
+func Verify(client *device.Client, p *Plan) ([]string, error) {
+	var mismatched []string
+	for start := 0; start < len(p.pending); start += commandBatch {
+		end := min(start+commandBatch, len(p.pending))
+		args := make([]string, 0, end-start)
+		for _, u := range p.pending[start:end] {
+			args = append(args, device.ShellQuote(u.relPath))
+		}
+		// sha256sum goes on past missing files, which are reported below
+		output, err := client.RunCommand(fmt.Sprintf("cd %s && sha256sum -- %s 2>/dev/null; command -v sha256sum >/dev/null",
+			device.ShellQuote(p.opts.RemoteDir), strings.Join(args, " ")))
+		if err != nil {
+			return nil, fmt.Errorf("failed to hash files on the device: %w", err)
+		}
+		hashes := make(map[string]string)
+		for _, line := range strings.Split(output, "\n") {
+			// "<hash>  <path>"; names sha256sum had to escape start with a
+			// backslash and are reported as mismatched
+			hash, name, ok := strings.Cut(line, "  ")
+			if ok && len(hash) == 64 {
+				hashes[name] = hash
+			}
+		}
+		for _, u := range p.pending[start:end] {
+			if hashes[u.relPath] != u.hash {
+				mismatched = append(mismatched, u.relPath)
+			}
+		}
+	}
+	return mismatched, nil
+}
@@ -0,0 +1,329 @@
+package upload
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"maps"
+	"os"
+	"path/filepath"
+	"runtime"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/lobinuxsoft/capydeploy/pkg/config"
+)
+
+// buildTime is the modification time of the files of test builds
+var buildTime = time.Unix(1700000000, 0)
+
+// hashOf returns the hex SHA-256 of s
+func hashOf(s string) string {
+	sum := sha256.Sum256([]byte(s))
+	return hex.EncodeToString(sum[:])
+}
+
+// writeBuild writes a build folder with the files given by relative path
+func writeBuild(t *testing.T, files map[string]string) string {
+	t.Helper()
+	root := t.TempDir()
+	for relPath, content := range files {
+		name := filepath.Join(root, relPath)
+		os.MkdirAll(filepath.Dir(name), 0755)
+		if err := os.WriteFile(name, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.Chtimes(name, buildTime, buildTime); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return root
+}
+
+func TestUnchangedOnDevice(t *testing.T) {
+	root := writeBuild(t, map[string]string{"game.pak": "level"})
+	local := filepath.Join(root, "game.pak")
+	hash := hashOf("level")
+	same := RemoteFile{Size: 5, MTime: buildTime.Unix()}
+
+	tests := []struct {
+		name     string
+		previous map[string]string
+		remote   RemoteFile
+		local    string
+		want     bool
+	}{
+		{"recorded hash and size match", map[string]string{"game.pak": hash}, RemoteFile{Size: 5, MTime: 1}, local, true},
+		{"recorded hash differs", map[string]string{"game.pak": hashOf("old")}, same, local, false},
+		{"recorded hash matches, size on the device doesn't", map[string]string{"game.pak": hash}, RemoteFile{Size: 4, MTime: buildTime.Unix()}, local, false},
+		{"file not in the previous deploy", map[string]string{"other.pak": hash}, same, local, false},
+		{"no manifest, size and time match", nil, same, local, true},
+		{"no manifest, time differs", nil, RemoteFile{Size: 5, MTime: buildTime.Unix() + 1}, local, false},
+		{"no manifest, size differs", nil, RemoteFile{Size: 6, MTime: buildTime.Unix()}, local, false},
+		{"local file gone", map[string]string{"game.pak": hash}, same, filepath.Join(root, "missing.pak"), false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := unchangedOnDevice(tt.previous, tt.remote, "game.pak", hash, tt.local); got != tt.want {
+				t.Errorf("unchangedOnDevice() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNewPlan_Delta(t *testing.T) {
+	files := map[string]string{
+		"game.x86_64":    "binary-v2",
+		"data/level.pak": "level-1",
+		"readme.txt":     "hello",
+	}
+	root := writeBuild(t, files)
+	hashes := make(map[string]string)
+	for relPath, content := range files {
+		hashes[relPath] = hashOf(content)
+	}
+	rebuilt := maps.Clone(hashes)
+	rebuilt["game.x86_64"] = hashOf("binary-v1")
+	// deployed returns the files of the build as the device has them
+	deployed := func(perm os.FileMode) map[string]RemoteFile {
+		remote := make(map[string]RemoteFile)
+		for relPath, content := range files {
+			remote[relPath] = RemoteFile{Size: int64(len(content)), MTime: buildTime.Unix(), Perm: perm}
+		}
+		return remote
+	}
+	// with returns the files on the device with one of them changed
+	with := func(remote map[string]RemoteFile, relPath string, f RemoteFile) map[string]RemoteFile {
+		remote[relPath] = f
+		return remote
+	}
+	session := func(completed map[string]string) *config.UploadSession {
+		s := config.NewUploadSession("deck", "/games/game", "setup")
+		for relPath, hash := range completed {
+			s.Complete(relPath, hash, 0)
+		}
+		return s
+	}
+
+	tests := []struct {
+		name      string
+		opts      Options
+		pending   []string
+		resumed   int
+		permFixes []string
+		bytes     int64
+		needed    int64
+	}{
+		{
+			name:    "first deploy sends every file",
+			opts:    Options{Delta: true},
+			pending: []string{"data/level.pak", "game.x86_64", "readme.txt"},
+			bytes:   21,
+			needed:  21,
+		},
+		{
+			name:    "recorded hashes skip unchanged files",
+			opts:    Options{Delta: true, PreviousFiles: rebuilt, RemoteFiles: deployed(0644)},
+			pending: []string{"game.x86_64"},
+			bytes:   9,
+		},
+		{
+			name: "recorded hash matches but the file on the device was cut short",
+			opts: Options{Delta: true, PreviousFiles: hashes,
+				RemoteFiles: with(deployed(0644), "readme.txt", RemoteFile{Size: 2, MTime: buildTime.Unix(), Perm: 0644})},
+			pending: []string{"readme.txt"},
+			bytes:   5,
+			needed:  3,
+		},
+		{
+			name: "without a manifest size and time decide",
+			opts: Options{Delta: true,
+				RemoteFiles: with(deployed(0644), "data/level.pak", RemoteFile{Size: 7, MTime: 1, Perm: 0644})},
+			pending: []string{"data/level.pak"},
+			bytes:   7,
+		},
+		{
+			name:    "full upload sends files the device has",
+			opts:    Options{PreviousFiles: hashes, RemoteFiles: deployed(0644)},
+			pending: []string{"data/level.pak", "game.x86_64", "readme.txt"},
+			bytes:   21,
+		},
+		{
+			name:    "new files on a device that has the others",
+			opts:    Options{Delta: true, PreviousFiles: hashes, RemoteFiles: map[string]RemoteFile{"readme.txt": {Size: 5, MTime: buildTime.Unix(), Perm: 0644}}},
+			pending: []string{"data/level.pak", "game.x86_64"},
+			bytes:   16,
+			needed:  16,
+		},
+		{
+			name: "files an interrupted upload sent are resumed, even without delta",
+			opts: Options{RemoteFiles: deployed(0644),
+				Session: session(map[string]string{"game.x86_64": hashes["game.x86_64"], "readme.txt": hashes["readme.txt"]})},
+			pending: []string{"data/level.pak"},
+			resumed: 2,
+			bytes:   7,
+		},
+		{
+			name: "a file the session sent from an older build is sent again",
+			opts: Options{RemoteFiles: deployed(0644),
+				Session: session(map[string]string{"game.x86_64": hashOf("binary-v1")})},
+			pending: []string{"data/level.pak", "game.x86_64", "readme.txt"},
+			bytes:   21,
+		},
+		{
+			name: "a resumed file cut short on the device is sent again",
+			opts: Options{Delta: true, RemoteFiles: with(deployed(0644), "readme.txt", RemoteFile{Size: 2, MTime: 1}),
+				Session: session(map[string]string{"readme.txt": hashes["readme.txt"]})},
+			pending: []string{"readme.txt"},
+			bytes:   5,
+			needed:  3,
+		},
+		{
+			name:      "unchanged files whose permissions differ are fixed, not sent",
+			opts:      Options{Delta: true, PreviousFiles: hashes, RemoteFiles: with(deployed(0644), "game.x86_64", RemoteFile{Size: 9, MTime: 1, Perm: 0755})},
+			permFixes: []string{"/games/game/game.x86_64"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			build, err := Scan(root, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			tt.opts.RemoteDir = "/games/game"
+			if tt.opts.Session == nil {
+				tt.opts.Session = session(nil)
+			}
+			p, err := NewPlan(context.Background(), build, tt.opts)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			var pending []string
+			for _, u := range p.pending {
+				pending = append(pending, u.relPath)
+			}
+			slices.Sort(pending)
+			if !slices.Equal(pending, tt.pending) {
+				t.Errorf("pending = %q, want %q", pending, tt.pending)
+			}
+			if p.Resumed != tt.resumed {
+				t.Errorf("Resumed = %d, want %d", p.Resumed, tt.resumed)
+			}
+			if want := p.Total - len(tt.pending); p.Unchanged != want {
+				t.Errorf("Unchanged = %d, want %d", p.Unchanged, want)
+			}
+			if p.Bytes != tt.bytes {
+				t.Errorf("Bytes = %d, want %d", p.Bytes, tt.bytes)
+			}
+			if p.Needed != tt.needed {
+				t.Errorf("Needed = %d, want %d", p.Needed, tt.needed)
+			}
+			if runtime.GOOS != "windows" {
+				var fixes []string
+				for _, fix := range p.permFixes {
+					fixes = append(fixes, fix.remote)
+				}
+				if !slices.Equal(fixes, tt.permFixes) {
+					t.Errorf("permFixes = %q, want %q", fixes, tt.permFixes)
+				}
+			}
+			if len(p.Hashes) != len(files) {
+				t.Errorf("Hashes has %d files, want every file of the build", len(p.Hashes))
+			}
+		})
+	}
+}
+
+func TestNewPlan_ArchiveOrFolder(t *testing.T) {
+	names := []string{"data/level.pak", "game.x86_64"}
+	entries := []archiveFile{{name: "game.x86_64"}, {name: "data/"}, {name: "data/level.pak"}}
+	// The device has the files of the build as the previous deploy left them
+	remote := map[string]RemoteFile{
+		"game.x86_64":    {MTime: buildTime.Unix(), Perm: 0644},
+		"data/level.pak": {MTime: buildTime.Unix(), Perm: 0644},
+	}
+	previous := map[string]string{"game.x86_64": hashOf(""), "data/level.pak": hashOf("")}
+
+	tests := []struct {
+		name    string
+		path    func(t *testing.T) string
+		pending []string
+		// whether the archive is what is sent and, for a zip, saved on the device
+		sent, saved bool
+	}{
+		{
+			name: "folder skips the files the device has",
+			path: func(t *testing.T) string {
+				return writeBuild(t, map[string]string{"game.x86_64": "", "data/level.pak": ""})
+			},
+		},
+		{
+			name:    "zip is sent whole and saved before it is extracted",
+			path:    func(t *testing.T) string { return writeTestZip(t, entries) },
+			pending: names,
+			sent:    true,
+			saved:   true,
+		},
+		{
+			name:    "tar.gz is sent whole and extracted as it arrives",
+			path:    func(t *testing.T) string { return writeTestTarGz(t, entries) },
+			pending: names,
+			sent:    true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			localPath := tt.path(t)
+			build, err := Scan(localPath, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if build.Archive != tt.sent {
+				t.Errorf("Archive = %v, want %v", build.Archive, tt.sent)
+			}
+			p, err := NewPlan(context.Background(), build, Options{
+				RemoteDir:     "/games/game",
+				Delta:         true,
+				PreviousFiles: previous,
+				RemoteFiles:   remote,
+				Session:       config.NewUploadSession("deck", "/games/game", "setup"),
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			var pending []string
+			for _, u := range p.pending {
+				pending = append(pending, u.relPath)
+			}
+			slices.Sort(pending)
+			if !slices.Equal(pending, tt.pending) {
+				t.Errorf("pending = %q, want %q", pending, tt.pending)
+			}
+			if p.Total != len(names) || len(p.Hashes) != len(names) {
+				t.Errorf("Total = %d with %d hashes, want %d", p.Total, len(p.Hashes), len(names))
+			}
+
+			var archiveSize int64
+			if tt.sent {
+				info, err := os.Stat(localPath)
+				if err != nil {
+					t.Fatal(err)
+				}
+				archiveSize = info.Size()
+			}
+			if p.Bytes != archiveSize {
+				t.Errorf("Bytes = %d, want %d", p.Bytes, archiveSize)
+			}
+			var needed int64
+			if tt.saved {
+				needed = archiveSize
+			}
+			if p.Needed != needed {
+				t.Errorf("Needed = %d, want %d", p.Needed, needed)
+			}
+		})
+	}
+}
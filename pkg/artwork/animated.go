@@ -0,0 +1,40 @@
+package artwork
+
+import (
+	"bytes"
+	"encoding/binary"
+)
+
+var pngSignature = []byte("\x89PNG\r\n\x1a\n")
+
+// IsAnimatedWebP reports whether data is a WebP file with the animation flag set
+func IsAnimatedWebP(data []byte) bool {
+	// RIFF header, then the VP8X chunk whose flags byte carries the animation bit
+	if len(data) < 21 || string(data[0:4]) != "RIFF" || string(data[8:12]) != "WEBP" {
+		return false
+	}
+	if string(data[12:16]) != "VP8X" {
+		return false
+	}
+	return data[20]&0x02 != 0
+}
+
+// IsAPNG reports whether data is a PNG with an animation control chunk
+func IsAPNG(data []byte) bool {
+	if !bytes.HasPrefix(data, pngSignature) {
+		return false
+	}
+
+	// acTL must come before the first IDAT chunk
+	for pos := len(pngSignature); pos+8 <= len(data); {
+		length := int(binary.BigEndian.Uint32(data[pos : pos+4]))
+		switch string(data[pos+4 : pos+8]) {
+		case "acTL":
+			return true
+		case "IDAT", "IEND":
+			return false
+		}
+		pos += 12 + length
+	}
+	return false
+}
@@ -0,0 +1,62 @@
+package artwork
+
+import (
+	"encoding/binary"
+	"testing"
+)
+
+func webpHeader(chunk string, flags byte) []byte {
+	data := []byte("RIFF\x00\x00\x00\x00WEBP" + chunk + "\x0a\x00\x00\x00")
+	return append(data, flags, 0, 0, 0, 0, 0, 0, 0, 0, 0)
+}
+
+func pngChunk(name string, payload []byte) []byte {
+	chunk := binary.BigEndian.AppendUint32(nil, uint32(len(payload)))
+	chunk = append(chunk, name...)
+	chunk = append(chunk, payload...)
+	return append(chunk, 0, 0, 0, 0) // CRC is not checked
+}
+
+func TestIsAnimatedWebP(t *testing.T) {
+	tests := []struct {
+		name string
+		data []byte
+		want bool
+	}{
+		{"animated", webpHeader("VP8X", 0x02), true},
+		{"extended static", webpHeader("VP8X", 0x10), false},
+		{"simple lossy", webpHeader("VP8 ", 0x02), false},
+		{"png", pngSignature, false},
+		{"short", []byte("RIFF"), false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := IsAnimatedWebP(tt.data); got != tt.want {
+				t.Errorf("IsAnimatedWebP() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestIsAPNG(t *testing.T) {
+	ihdr := pngChunk("IHDR", make([]byte, 13))
+	actl := pngChunk("acTL", make([]byte, 8))
+	idat := pngChunk("IDAT", []byte{1, 2, 3})
+
+	animated := append(append(append(append([]byte{}, pngSignature...), ihdr...), actl...), idat...)
+	static := append(append(append([]byte{}, pngSignature...), ihdr...), idat...)
+	late := append(append([]byte{}, static...), actl...)
+
+	if !IsAPNG(animated) {
+		t.Error("IsAPNG(animated) = false, want true")
+	}
+	if IsAPNG(static) {
+		t.Error("IsAPNG(static) = true, want false")
+	}
+	if IsAPNG(late) {
+		t.Error("IsAPNG() should ignore acTL after IDAT")
+	}
+	if IsAPNG(webpHeader("VP8X", 0x02)) {
+		t.Error("IsAPNG(webp) = true, want false")
+	}
+}
@@ -0,0 +1,152 @@
+package artwork
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"image/png"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// GeneratedScheme prefixes the URL of artwork generated locally from a screenshot
+const GeneratedScheme = "generated://"
+
+// Size of each Steam slot that can be generated
+var generatedSizes = map[string][2]int{
+	"capsule": {600, 900},
+	"wide":    {920, 430},
+	"hero":    {1920, 620},
+}
+
+// GeneratedStore keeps generated artwork as PNG files. Unlike the image cache,
+// files are never evicted since they can't be downloaded again.
+type GeneratedStore struct {
+	dir string
+}
+
+// NewGeneratedStore creates a generated artwork store in dir
+func NewGeneratedStore(dir string) (*GeneratedStore, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, err
+	}
+	return &GeneratedStore{dir: dir}, nil
+}
+
+// GetGeneratedDir returns the path to the generated artwork directory
+func GetGeneratedDir() (string, error) {
+	configDir, err := os.UserConfigDir()
+	if err != nil {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return "", err
+		}
+		configDir = home
+	}
+	return filepath.Join(configDir, "capydeploy", "generated"), nil
+}
+
+// IsGenerated reports whether url points to locally generated artwork
+func IsGenerated(url string) bool {
+	return strings.HasPrefix(url, GeneratedScheme)
+}
+
+// Save stores a generated PNG for slot (capsule, wide or hero) and returns its URL.
+// The image must have the exact size Steam uses for the slot.
+func (s *GeneratedStore) Save(slot string, data []byte) (string, error) {
+	size, ok := generatedSizes[slot]
+	if !ok {
+		return "", fmt.Errorf("unknown artwork slot: %s", slot)
+	}
+
+	cfg, err := png.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return "", fmt.Errorf("invalid PNG image: %w", err)
+	}
+	if cfg.Width != size[0] || cfg.Height != size[1] {
+		return "", fmt.Errorf("%s must be %dx%d, got %dx%d", slot, size[0], size[1], cfg.Width, cfg.Height)
+	}
+
+	sum := sha256.Sum256(data)
+	name := fmt.Sprintf("%s-%s.png", slot, hex.EncodeToString(sum[:8]))
+	if err := os.WriteFile(filepath.Join(s.dir, name), data, 0644); err != nil {
+		return "", err
+	}
+	return GeneratedScheme + name, nil
+}
+
+// SaveTranscoded stores an APNG converted from the animated image at sourceURL
+// and returns its URL. Converting the same source again replaces the file.
+func (s *GeneratedStore) SaveTranscoded(sourceURL string, data []byte) (string, error) {
+	if sourceURL == "" {
+		return "", fmt.Errorf("missing source URL")
+	}
+	if !IsAPNG(data) {
+		return "", fmt.Errorf("expected an animated PNG")
+	}
+
+	name := transcodedName(sourceURL)
+	if err := os.WriteFile(filepath.Join(s.dir, name), data, 0644); err != nil {
+		return "", err
+	}
+	return GeneratedScheme + name, nil
+}
+
+// Transcoded returns the URL of the APNG converted from sourceURL, if there is one
+func (s *GeneratedStore) Transcoded(sourceURL string) (string, bool) {
+	name := transcodedName(sourceURL)
+	if _, err := os.Stat(filepath.Join(s.dir, name)); err != nil {
+		return "", false
+	}
+	return GeneratedScheme + name, true
+}
+
+// SaveUpscaled stores an icon upscaled from sourceURL with method and returns its URL
+func (s *GeneratedStore) SaveUpscaled(sourceURL string, method UpscaleMethod, data []byte) (string, error) {
+	if sourceURL == "" {
+		return "", fmt.Errorf("missing source URL")
+	}
+	if _, err := png.DecodeConfig(bytes.NewReader(data)); err != nil {
+		return "", fmt.Errorf("invalid PNG image: %w", err)
+	}
+
+	name := upscaledName(sourceURL, method)
+	if err := os.WriteFile(filepath.Join(s.dir, name), data, 0644); err != nil {
+		return "", err
+	}
+	return GeneratedScheme + name, nil
+}
+
+// Upscaled returns the URL of the icon upscaled from sourceURL with method, if there is one
+func (s *GeneratedStore) Upscaled(sourceURL string, method UpscaleMethod) (string, bool) {
+	name := upscaledName(sourceURL, method)
+	if _, err := os.Stat(filepath.Join(s.dir, name)); err != nil {
+		return "", false
+	}
+	return GeneratedScheme + name, true
+}
+
+func transcodedName(sourceURL string) string {
+	return derivedName("apng", sourceURL)
+}
+
+func upscaledName(sourceURL string, method UpscaleMethod) string {
+	return derivedName("icon-"+string(method), sourceURL)
+}
+
+// derivedName names a file derived from a source URL
+func derivedName(prefix, sourceURL string) string {
+	sum := sha256.Sum256([]byte(sourceURL))
+	return fmt.Sprintf("%s-%s.png", prefix, hex.EncodeToString(sum[:8]))
+}
+
+// Get returns the PNG data of a generated artwork URL
+func (s *GeneratedStore) Get(url string) ([]byte, error) {
+	name := strings.TrimPrefix(url, GeneratedScheme)
+	if !IsGenerated(url) || name == "" || name != filepath.Base(name) || strings.Contains(name, "..") {
+		return nil, fmt.Errorf("invalid generated artwork URL: %s", url)
+	}
+	return os.ReadFile(filepath.Join(s.dir, name))
+}
@@ -0,0 +1,123 @@
+package artwork
+
+import (
+	"bytes"
+	"image"
+	"image/png"
+	"testing"
+)
+
+func encodePNG(t *testing.T, w, h int) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, image.NewRGBA(image.Rect(0, 0, w, h))); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func TestGeneratedStore_SaveGet(t *testing.T) {
+	store, err := NewGeneratedStore(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	data := encodePNG(t, 920, 430)
+	url, err := store.Save("wide", data)
+	if err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+	if !IsGenerated(url) {
+		t.Errorf("Save() = %q, want %s URL", url, GeneratedScheme)
+	}
+
+	got, err := store.Get(url)
+	if err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	if !bytes.Equal(got, data) {
+		t.Error("Get() returned different data")
+	}
+}
+
+func TestGeneratedStore_SaveValidates(t *testing.T) {
+	store, err := NewGeneratedStore(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := store.Save("capsule", encodePNG(t, 920, 430)); err == nil {
+		t.Error("expected error for wrong size")
+	}
+	if _, err := store.Save("logo", encodePNG(t, 600, 900)); err == nil {
+		t.Error("expected error for unsupported slot")
+	}
+	if _, err := store.Save("hero", []byte("not a png")); err == nil {
+		t.Error("expected error for invalid image")
+	}
+}
+
+func TestGeneratedStore_GetRejectsPaths(t *testing.T) {
+	store, err := NewGeneratedStore(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, url := range []string{
+		"generated://../config.json",
+		"generated://sub/file.png",
+		"generated://",
+		"https://example.com/a.png",
+	} {
+		if _, err := store.Get(url); err == nil {
+			t.Errorf("Get(%q) expected error", url)
+		}
+	}
+}
+
+func TestGeneratedStore_Transcoded(t *testing.T) {
+	store, err := NewGeneratedStore(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	source := "https://cdn2.steamgriddb.com/hero/a.webp"
+	if _, ok := store.Transcoded(source); ok {
+		t.Fatal("Transcoded() found a file before saving")
+	}
+	if _, err := store.SaveTranscoded(source, encodePNG(t, 10, 10)); err == nil {
+		t.Error("expected error for a static PNG")
+	}
+
+	apng := append(append([]byte{}, pngSignature...), pngChunk("acTL", make([]byte, 8))...)
+	url, err := store.SaveTranscoded(source, apng)
+	if err != nil {
+		t.Fatalf("SaveTranscoded() error = %v", err)
+	}
+	if got, ok := store.Transcoded(source); !ok || got != url {
+		t.Errorf("Transcoded() = %q, %v, want %q", got, ok, url)
+	}
+	if data, err := store.Get(url); err != nil || len(data) != len(apng) {
+		t.Errorf("Get() = %d bytes, %v", len(data), err)
+	}
+}
+
+func TestGeneratedStore_Upscaled(t *testing.T) {
+	store, err := NewGeneratedStore(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	source := "https://cdn2.steamgriddb.com/icon/a.png"
+	url, err := store.SaveUpscaled(source, UpscaleNearest, encodePNG(t, 256, 256))
+	if err != nil {
+		t.Fatalf("SaveUpscaled() error = %v", err)
+	}
+	if got, ok := store.Upscaled(source, UpscaleNearest); !ok || got != url {
+		t.Errorf("Upscaled() = %q, %v, want %q", got, ok, url)
+	}
+	// Each method keeps its own copy
+	if _, ok := store.Upscaled(source, UpscaleLanczos); ok {
+		t.Error("Upscaled() found a copy for another method")
+	}
+}
@@ -0,0 +1,253 @@
+package artwork
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/lobinuxsoft/capydeploy/pkg/steamgriddb"
+)
+
+// ProviderIGDB is the name of the IGDB provider
+const ProviderIGDB = "igdb"
+
+const (
+	igdbAPIURL      = "https://api.igdb.com/v4"
+	igdbTokenURL    = "https://id.twitch.tv/oauth2/token"
+	igdbImageURL    = "https://images.igdb.com/igdb/image/upload"
+	igdbSearchLimit = 20
+)
+
+// IGDBProvider fetches covers, artworks and screenshots from IGDB.
+// IGDB authenticates through Twitch using a client ID and secret.
+type IGDBProvider struct {
+	clientID     string
+	clientSecret string
+	httpClient   *http.Client
+
+	// Overridable for tests
+	apiURL   string
+	tokenURL string
+
+	mu          sync.Mutex
+	token       string
+	tokenExpiry time.Time
+}
+
+// NewIGDBProvider creates an IGDB provider with Twitch application credentials
+func NewIGDBProvider(clientID, clientSecret string) *IGDBProvider {
+	return &IGDBProvider{
+		clientID:     clientID,
+		clientSecret: clientSecret,
+		httpClient:   &http.Client{Timeout: 30 * time.Second},
+		apiURL:       igdbAPIURL,
+		tokenURL:     igdbTokenURL,
+	}
+}
+
+// SetHTTPClient makes the provider send requests through httpClient, e.g. to
+// use a proxy. Call it before the provider is shared.
+func (p *IGDBProvider) SetHTTPClient(httpClient *http.Client) {
+	if httpClient != nil {
+		p.httpClient = httpClient
+	}
+}
+
+// Name returns the provider identifier
+func (p *IGDBProvider) Name() string {
+	return ProviderIGDB
+}
+
+type igdbImage struct {
+	ImageID string `json:"image_id"`
+	Width   int    `json:"width"`
+	Height  int    `json:"height"`
+}
+
+type igdbGame struct {
+	ID          int         `json:"id"`
+	Name        string      `json:"name"`
+	Cover       *igdbImage  `json:"cover"`
+	Artworks    []igdbImage `json:"artworks"`
+	Screenshots []igdbImage `json:"screenshots"`
+}
+
+// Search returns IGDB games matching term
+func (p *IGDBProvider) Search(ctx context.Context, term string) ([]Game, error) {
+	query := fmt.Sprintf("search %q; fields id,name; limit %d;", sanitizeIGDBTerm(term), igdbSearchLimit)
+
+	var games []igdbGame
+	if err := p.query(ctx, "games", query, &games); err != nil {
+		return nil, err
+	}
+
+	results := make([]Game, 0, len(games))
+	for _, g := range games {
+		results = append(results, Game{ID: g.ID, Name: g.Name, Provider: ProviderIGDB})
+	}
+	return results, nil
+}
+
+// GetArtwork returns the cover, artworks and screenshots of an IGDB game.
+// The cover is offered as capsule, artworks and screenshots as hero and wide capsule.
+func (p *IGDBProvider) GetArtwork(ctx context.Context, gameID int) (*Artwork, error) {
+	query := fmt.Sprintf("fields cover.image_id,cover.width,cover.height,"+
+		"artworks.image_id,artworks.width,artworks.height,"+
+		"screenshots.image_id,screenshots.width,screenshots.height; where id = %d;", gameID)
+
+	var games []igdbGame
+	if err := p.query(ctx, "games", query, &games); err != nil {
+		return nil, err
+	}
+	if len(games) == 0 {
+		return &Artwork{}, nil
+	}
+
+	return igdbArtwork(games[0]), nil
+}
+
+// igdbArtwork maps an IGDB game to Steam artwork slots
+func igdbArtwork(g igdbGame) *Artwork {
+	result := &Artwork{}
+
+	if g.Cover != nil && g.Cover.ImageID != "" {
+		result.Capsules = append(result.Capsules, steamgriddb.GridData{
+			Style:  "cover",
+			Width:  g.Cover.Width,
+			Height: g.Cover.Height,
+			Mime:   "image/jpeg",
+			URL:    IGDBImageURL(g.Cover.ImageID, "t_cover_big_2x"),
+			Thumb:  IGDBImageURL(g.Cover.ImageID, "t_cover_big"),
+			Source: ProviderIGDB,
+		})
+	}
+
+	wide := func(img igdbImage, style string) {
+		if img.ImageID == "" {
+			return
+		}
+		result.Heroes = append(result.Heroes, steamgriddb.ImageData{
+			Style:  style,
+			Width:  img.Width,
+			Height: img.Height,
+			Mime:   "image/jpeg",
+			URL:    IGDBImageURL(img.ImageID, "t_1080p"),
+			Thumb:  IGDBImageURL(img.ImageID, "t_screenshot_med"),
+			Source: ProviderIGDB,
+		})
+		result.Wide = append(result.Wide, steamgriddb.GridData{
+			Style:  style,
+			Width:  img.Width,
+			Height: img.Height,
+			Mime:   "image/jpeg",
+			URL:    IGDBImageURL(img.ImageID, "t_720p"),
+			Thumb:  IGDBImageURL(img.ImageID, "t_screenshot_med"),
+			Source: ProviderIGDB,
+		})
+	}
+
+	for _, img := range g.Artworks {
+		wide(img, "artwork")
+	}
+	for _, img := range g.Screenshots {
+		wide(img, "screenshot")
+	}
+
+	return result
+}
+
+// IGDBImageURL builds the URL of an IGDB image at the given size preset
+func IGDBImageURL(imageID, size string) string {
+	return fmt.Sprintf("%s/%s/%s.jpg", igdbImageURL, size, imageID)
+}
+
+// sanitizeIGDBTerm strips characters that would break an Apicalypse search string
+func sanitizeIGDBTerm(term string) string {
+	return strings.TrimSpace(strings.NewReplacer(`"`, "", `\`, "", ";", "").Replace(term))
+}
+
+// query sends an Apicalypse query to an IGDB endpoint
+func (p *IGDBProvider) query(ctx context.Context, endpoint, body string, out interface{}) error {
+	token, err := p.accessToken(ctx)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "POST", p.apiURL+"/"+endpoint, strings.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Client-ID", p.clientID)
+	req.Header.Set("Authorization", "Bearer "+token)
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := p.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	data, _ := io.ReadAll(resp.Body)
+	if resp.StatusCode == http.StatusUnauthorized {
+		p.mu.Lock()
+		p.token = ""
+		p.mu.Unlock()
+	}
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("IGDB error %d: %s", resp.StatusCode, string(data))
+	}
+
+	return json.Unmarshal(data, out)
+}
+
+// accessToken returns a cached Twitch app token, requesting a new one when expired
+func (p *IGDBProvider) accessToken(ctx context.Context) (string, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.token != "" && time.Now().Before(p.tokenExpiry) {
+		return p.token, nil
+	}
+
+	params := url.Values{}
+	params.Set("client_id", p.clientID)
+	params.Set("client_secret", p.clientSecret)
+	params.Set("grant_type", "client_credentials")
+
+	// In the body rather than the URL, which errors quote
+	req, err := http.NewRequestWithContext(ctx, "POST", p.tokenURL, strings.NewReader(params.Encode()))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	resp, err := p.httpClient.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("IGDB authentication failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		// Don't echo the response, it may include the submitted credentials
+		return "", fmt.Errorf("IGDB authentication failed: HTTP %d", resp.StatusCode)
+	}
+
+	var tokenResp struct {
+		AccessToken string `json:"access_token"`
+		ExpiresIn   int    `json:"expires_in"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&tokenResp); err != nil {
+		return "", err
+	}
+
+	p.token = tokenResp.AccessToken
+	// Refresh a minute early to avoid using a token right as it expires
+	p.tokenExpiry = time.Now().Add(time.Duration(tokenResp.ExpiresIn)*time.Second - time.Minute)
+	return p.token, nil
+}
@@ -0,0 +1,122 @@
+package artwork
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func newTestIGDB(t *testing.T, handler http.HandlerFunc) *IGDBProvider {
+	t.Helper()
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/token", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"access_token":"tok","expires_in":3600}`))
+	})
+	mux.HandleFunc("/v4/", func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer tok" || r.Header.Get("Client-ID") != "id" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		handler(w, r)
+	})
+	srv := httptest.NewServer(mux)
+	t.Cleanup(srv.Close)
+
+	p := NewIGDBProvider("id", "secret")
+	p.apiURL = srv.URL + "/v4"
+	p.tokenURL = srv.URL + "/token"
+	return p
+}
+
+func TestIGDBProvider_Search(t *testing.T) {
+	var gotQuery string
+	p := newTestIGDB(t, func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		gotQuery = string(body)
+		w.Write([]byte(`[{"id":1,"name":"Indie Game"}]`))
+	})
+
+	games, err := p.Search(context.Background(), `Indie "Game";`)
+	if err != nil {
+		t.Fatalf("Search() error = %v", err)
+	}
+	if len(games) != 1 || games[0].Name != "Indie Game" || games[0].Provider != ProviderIGDB {
+		t.Errorf("Search() = %+v", games)
+	}
+	if !strings.HasPrefix(gotQuery, `search "Indie Game";`) {
+		t.Errorf("query = %q, want sanitized search term", gotQuery)
+	}
+}
+
+func TestIGDBProvider_GetArtwork(t *testing.T) {
+	p := newTestIGDB(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`[{"id":1,
+			"cover":{"image_id":"co1","width":264,"height":374},
+			"artworks":[{"image_id":"ar1","width":1920,"height":1080}],
+			"screenshots":[{"image_id":"sc1","width":1280,"height":720}]}]`))
+	})
+
+	art, err := p.GetArtwork(context.Background(), 1)
+	if err != nil {
+		t.Fatalf("GetArtwork() error = %v", err)
+	}
+
+	if len(art.Capsules) != 1 || art.Capsules[0].URL != IGDBImageURL("co1", "t_cover_big_2x") {
+		t.Errorf("Capsules = %+v", art.Capsules)
+	}
+	if len(art.Heroes) != 2 || len(art.Wide) != 2 {
+		t.Errorf("Heroes = %d, Wide = %d, want 2 each", len(art.Heroes), len(art.Wide))
+	}
+	for _, h := range art.Heroes {
+		if h.Source != ProviderIGDB {
+			t.Errorf("hero Source = %q, want %q", h.Source, ProviderIGDB)
+		}
+	}
+}
+
+func TestIGDBImageURL(t *testing.T) {
+	want := "https://images.igdb.com/igdb/image/upload/t_720p/abc.jpg"
+	if got := IGDBImageURL("abc", "t_720p"); got != want {
+		t.Errorf("IGDBImageURL() = %q, want %q", got, want)
+	}
+}
+
+func TestIGDBProvider_TokenCredentialsInBody(t *testing.T) {
+	var gotQuery, gotSecret string
+	mux := http.NewServeMux()
+	mux.HandleFunc("/token", func(w http.ResponseWriter, r *http.Request) {
+		gotQuery = r.URL.RawQuery
+		r.ParseForm()
+		gotSecret = r.PostForm.Get("client_secret")
+		w.Write([]byte(`{"access_token":"tok","expires_in":3600}`))
+	})
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	p := NewIGDBProvider("id", "secret")
+	p.tokenURL = srv.URL + "/token"
+	if _, err := p.accessToken(context.Background()); err != nil {
+		t.Fatalf("accessToken() error = %v", err)
+	}
+	if gotSecret != "secret" {
+		t.Errorf("client_secret in body = %q, want %q", gotSecret, "secret")
+	}
+	if strings.Contains(gotQuery, "secret") {
+		t.Errorf("credentials sent in the URL: %q", gotQuery)
+	}
+
+	// Transport errors quote the URL, which must not hold the secret
+	srv.Close()
+	p.token = ""
+	_, err := p.accessToken(context.Background())
+	if err == nil {
+		t.Fatal("accessToken() should fail with the server down")
+	}
+	if strings.Contains(err.Error(), "secret") {
+		t.Errorf("error leaks the client secret: %v", err)
+	}
+}
@@ -0,0 +1,35 @@
+// Package artwork defines alternative artwork providers used when a game is
+// missing from SteamGridDB.
+package artwork
+
+import (
+	"context"
+
+	"github.com/lobinuxsoft/capydeploy/pkg/steamgriddb"
+)
+
+// Game is a game entry returned by a provider search
+type Game struct {
+	ID       int    `json:"id"`
+	Name     string `json:"name"`
+	Provider string `json:"provider"`
+}
+
+// Artwork holds the images a provider offers for a game, grouped by Steam slot.
+// Every image has its Source set to the provider name.
+type Artwork struct {
+	Capsules []steamgriddb.GridData  `json:"capsules"`
+	Wide     []steamgriddb.GridData  `json:"wide"`
+	Heroes   []steamgriddb.ImageData `json:"heroes"`
+	Logos    []steamgriddb.ImageData `json:"logos"`
+}
+
+// Provider is an artwork source that can search games and list their images
+type Provider interface {
+	// Name returns the provider identifier, also used as image Source
+	Name() string
+	// Search returns games matching term
+	Search(ctx context.Context, term string) ([]Game, error)
+	// GetArtwork returns the images available for a game
+	GetArtwork(ctx context.Context, gameID int) (*Artwork, error)
+}
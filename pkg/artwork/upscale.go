@@ -0,0 +1,167 @@
+package artwork
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/draw"
+	_ "image/gif"
+	_ "image/jpeg"
+	"image/png"
+	"math"
+)
+
+// UpscaleMethod selects how small icons are enlarged before applying them
+type UpscaleMethod string
+
+const (
+	UpscaleNone    UpscaleMethod = ""
+	UpscaleNearest UpscaleMethod = "nearest" // integer factor, keeps pixel art crisp
+	UpscaleLanczos UpscaleMethod = "lanczos" // smooth, for detailed icons
+)
+
+// IconTargetSize is the icon size upscaling aims for
+const IconTargetSize = 256
+
+// ValidUpscaleMethod reports whether m is a known method
+func ValidUpscaleMethod(m UpscaleMethod) bool {
+	switch m {
+	case UpscaleNone, UpscaleNearest, UpscaleLanczos:
+		return true
+	}
+	return false
+}
+
+// UpscaleIcon enlarges an icon up to IconTargetSize and returns it as PNG.
+// Returns nil data when the icon can't be at least doubled or method is UpscaleNone.
+// PNG, JPEG and GIF icons are supported.
+func UpscaleIcon(data []byte, method UpscaleMethod) ([]byte, error) {
+	if method == UpscaleNone {
+		return nil, nil
+	}
+	if !ValidUpscaleMethod(method) {
+		return nil, fmt.Errorf("unknown upscale method: %s", method)
+	}
+
+	src, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("unsupported icon format: %w", err)
+	}
+
+	b := src.Bounds()
+	longest := max(b.Dx(), b.Dy())
+	if longest == 0 || longest*2 > IconTargetSize {
+		return nil, nil
+	}
+
+	var dst image.Image
+	switch method {
+	case UpscaleNearest:
+		dst = scaleNearest(src, IconTargetSize/longest)
+	case UpscaleLanczos:
+		scale := float64(IconTargetSize) / float64(longest)
+		dst = scaleLanczos(src, int(math.Round(float64(b.Dx())*scale)), int(math.Round(float64(b.Dy())*scale)))
+	}
+
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, dst); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// scaleNearest enlarges src by an integer factor, repeating every pixel
+func scaleNearest(src image.Image, factor int) *image.NRGBA {
+	b := src.Bounds()
+	dst := image.NewNRGBA(image.Rect(0, 0, b.Dx()*factor, b.Dy()*factor))
+	for y := 0; y < b.Dy(); y++ {
+		for x := 0; x < b.Dx(); x++ {
+			c := src.At(b.Min.X+x, b.Min.Y+y)
+			draw.Draw(dst, image.Rect(x*factor, y*factor, (x+1)*factor, (y+1)*factor), &image.Uniform{C: c}, image.Point{}, draw.Src)
+		}
+	}
+	return dst
+}
+
+// scaleLanczos resizes src to w x h with a separable Lanczos-3 filter.
+// Works on premultiplied colors so transparent edges don't bleed dark halos.
+func scaleLanczos(src image.Image, w, h int) *image.RGBA {
+	b := src.Bounds()
+	rgba := image.NewRGBA(image.Rect(0, 0, b.Dx(), b.Dy()))
+	draw.Draw(rgba, rgba.Bounds(), src, b.Min, draw.Src)
+
+	tmp := resampleAxis(rgba, w, b.Dy(), true)
+	return resampleAxis(tmp, w, h, false)
+}
+
+// resampleAxis resizes src along one axis to w x h
+func resampleAxis(src *image.RGBA, w, h int, horizontal bool) *image.RGBA {
+	dst := image.NewRGBA(image.Rect(0, 0, w, h))
+	srcLen, dstLen, across := src.Bounds().Dy(), h, w
+	if horizontal {
+		srcLen, dstLen, across = src.Bounds().Dx(), w, h
+	}
+	ratio := float64(srcLen) / float64(dstLen)
+	support := 3.0 * math.Max(ratio, 1)
+	filterScale := math.Max(ratio, 1)
+
+	for i := 0; i < dstLen; i++ {
+		center := (float64(i)+0.5)*ratio - 0.5
+		lo := int(math.Floor(center - support))
+		hi := int(math.Ceil(center + support))
+
+		weights := make([]float64, 0, hi-lo+1)
+		var total float64
+		for j := lo; j <= hi; j++ {
+			wt := lanczos3((float64(j) - center) / filterScale)
+			weights = append(weights, wt)
+			total += wt
+		}
+
+		for k := 0; k < across; k++ {
+			var r, g, bl, a float64
+			for n, wt := range weights {
+				j := min(max(lo+n, 0), srcLen-1)
+				var off int
+				if horizontal {
+					off = src.PixOffset(j, k)
+				} else {
+					off = src.PixOffset(k, j)
+				}
+				r += float64(src.Pix[off]) * wt
+				g += float64(src.Pix[off+1]) * wt
+				bl += float64(src.Pix[off+2]) * wt
+				a += float64(src.Pix[off+3]) * wt
+			}
+
+			alpha := clampByte(a / total)
+			var off int
+			if horizontal {
+				off = dst.PixOffset(i, k)
+			} else {
+				off = dst.PixOffset(k, i)
+			}
+			// Premultiplied channels can't exceed alpha
+			dst.Pix[off] = min(clampByte(r/total), alpha)
+			dst.Pix[off+1] = min(clampByte(g/total), alpha)
+			dst.Pix[off+2] = min(clampByte(bl/total), alpha)
+			dst.Pix[off+3] = alpha
+		}
+	}
+	return dst
+}
+
+func lanczos3(x float64) float64 {
+	if x == 0 {
+		return 1
+	}
+	if x <= -3 || x >= 3 {
+		return 0
+	}
+	px := math.Pi * x
+	return 3 * math.Sin(px) * math.Sin(px/3) / (px * px)
+}
+
+func clampByte(v float64) uint8 {
+	return uint8(math.Min(255, math.Max(0, math.Round(v))))
+}
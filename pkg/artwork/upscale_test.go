@@ -0,0 +1,79 @@
+package artwork
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/png"
+	"testing"
+)
+
+func checkerPNG(t *testing.T, size int) []byte {
+	t.Helper()
+	img := image.NewNRGBA(image.Rect(0, 0, size, size))
+	for y := 0; y < size; y++ {
+		for x := 0; x < size; x++ {
+			if (x+y)%2 == 0 {
+				img.Set(x, y, color.NRGBA{R: 255, A: 255})
+			}
+		}
+	}
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func decodeSize(t *testing.T, data []byte) image.Image {
+	t.Helper()
+	img, err := png.Decode(bytes.NewReader(data))
+	if err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	return img
+}
+
+func TestUpscaleIcon_Nearest(t *testing.T) {
+	out, err := UpscaleIcon(checkerPNG(t, 32), UpscaleNearest)
+	if err != nil {
+		t.Fatalf("UpscaleIcon() error = %v", err)
+	}
+	img := decodeSize(t, out)
+	if img.Bounds().Dx() != 256 || img.Bounds().Dy() != 256 {
+		t.Fatalf("size = %v, want 256x256", img.Bounds())
+	}
+
+	// Every source pixel becomes an 8x8 block with the exact same color
+	_, _, _, a0 := img.At(0, 0).RGBA()
+	_, _, _, a7 := img.At(7, 7).RGBA()
+	_, _, _, a8 := img.At(8, 0).RGBA()
+	if a0 == 0 || a7 == 0 || a8 != 0 {
+		t.Errorf("pixels were not repeated as blocks: alpha %d %d %d", a0, a7, a8)
+	}
+}
+
+func TestUpscaleIcon_Lanczos(t *testing.T) {
+	out, err := UpscaleIcon(checkerPNG(t, 48), UpscaleLanczos)
+	if err != nil {
+		t.Fatalf("UpscaleIcon() error = %v", err)
+	}
+	if img := decodeSize(t, out); img.Bounds().Dx() != 256 || img.Bounds().Dy() != 256 {
+		t.Errorf("size = %v, want 256x256", img.Bounds())
+	}
+}
+
+func TestUpscaleIcon_Skips(t *testing.T) {
+	if out, err := UpscaleIcon(checkerPNG(t, 200), UpscaleNearest); err != nil || out != nil {
+		t.Errorf("large icon: got %d bytes, %v, want nil", len(out), err)
+	}
+	if out, err := UpscaleIcon(checkerPNG(t, 32), UpscaleNone); err != nil || out != nil {
+		t.Errorf("no method: got %d bytes, %v, want nil", len(out), err)
+	}
+	if _, err := UpscaleIcon(checkerPNG(t, 32), "hq9x"); err == nil {
+		t.Error("expected error for unknown method")
+	}
+	if _, err := UpscaleIcon([]byte("not an image"), UpscaleLanczos); err == nil {
+		t.Error("expected error for undecodable data")
+	}
+}
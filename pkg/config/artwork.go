@@ -0,0 +1,244 @@
+package config
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/lobinuxsoft/capydeploy/pkg/steam"
+)
+
+// MaxRecentArtwork is the number of recent entries kept per artwork type
+const MaxRecentArtwork = 20
+
+// ArtworkRef references an artwork image kept in the recent/favorites lists
+type ArtworkRef struct {
+	Type     string    `json:"type"` // capsule, wide, hero, logo, icon
+	URL      string    `json:"url"`
+	Thumb    string    `json:"thumb,omitempty"`
+	Width    int       `json:"width,omitempty"`
+	Height   int       `json:"height,omitempty"`
+	Mime     string    `json:"mime,omitempty"`
+	Style    string    `json:"style,omitempty"`
+	GameName string    `json:"game_name,omitempty"`
+	UsedAt   time.Time `json:"used_at"`
+}
+
+// ArtworkHistory holds recently applied and favorite artwork
+type ArtworkHistory struct {
+	Recent    []ArtworkRef `json:"recent"`
+	Favorites []ArtworkRef `json:"favorites"`
+}
+
+// ArtworkSelection is the artwork chosen for a game in the artwork selector
+type ArtworkSelection struct {
+	GridDBGameID  int    `json:"gridDBGameID"`
+	GridPortrait  string `json:"gridPortrait"`
+	GridLandscape string `json:"gridLandscape"`
+	HeroImage     string `json:"heroImage"`
+	LogoImage     string `json:"logoImage"`
+	IconImage     string `json:"iconImage"`
+	// Logo placement over the hero, nil keeps Steam's default
+	LogoPosition *steam.LogoPosition `json:"logoPosition,omitempty"`
+	SavedAt      time.Time           `json:"savedAt"`
+}
+
+// ArtworkSelectionKey normalizes a game name into the key used to store its selection
+func ArtworkSelectionKey(gameName string) string {
+	return strings.ToLower(strings.Join(strings.Fields(gameName), " "))
+}
+
+// GetArtworkSelection returns the saved selection for a game, or nil if there is none
+func GetArtworkSelection(gameName string) (*ArtworkSelection, error) {
+	config, err := Load()
+	if err != nil {
+		return nil, err
+	}
+	sel, ok := config.ArtworkSelections[ArtworkSelectionKey(gameName)]
+	if !ok {
+		return nil, nil
+	}
+	return &sel, nil
+}
+
+// SaveArtworkSelection stores the selection for a game
+func SaveArtworkSelection(gameName string, sel ArtworkSelection) error {
+	key := ArtworkSelectionKey(gameName)
+	if key == "" {
+		return nil
+	}
+	return Update(func(config *AppConfig) error {
+		if config.ArtworkSelections == nil {
+			config.ArtworkSelections = make(map[string]ArtworkSelection)
+		}
+		sel.SavedAt = time.Now()
+		config.ArtworkSelections[key] = sel
+		return nil
+	})
+}
+
+// GetArtworkHistory returns the recent and favorite artwork lists
+func GetArtworkHistory() (*ArtworkHistory, error) {
+	config, err := Load()
+	if err != nil {
+		return nil, err
+	}
+	history := config.ArtworkHistory
+	if history.Recent == nil {
+		history.Recent = []ArtworkRef{}
+	}
+	if history.Favorites == nil {
+		history.Favorites = []ArtworkRef{}
+	}
+	return &history, nil
+}
+
+// AddRecentArtwork records artwork as recently applied
+func AddRecentArtwork(refs ...ArtworkRef) error {
+	if len(refs) == 0 {
+		return nil
+	}
+	return Update(func(config *AppConfig) error {
+		for _, ref := range refs {
+			config.ArtworkHistory.Recent = addRecent(config.ArtworkHistory.Recent, ref, MaxRecentArtwork)
+		}
+		return nil
+	})
+}
+
+// ToggleFavoriteArtwork adds or removes an artwork from favorites.
+// Returns true if the artwork is now a favorite.
+func ToggleFavoriteArtwork(ref ArtworkRef) (bool, error) {
+	var added bool
+	err := Update(func(config *AppConfig) error {
+		config.ArtworkHistory.Favorites, added = toggleFavorite(config.ArtworkHistory.Favorites, ref)
+		return nil
+	})
+	return added, err
+}
+
+// addRecent moves ref to the front of the list and keeps at most max entries per type
+func addRecent(list []ArtworkRef, ref ArtworkRef, max int) []ArtworkRef {
+	if ref.URL == "" {
+		return list
+	}
+	if ref.UsedAt.IsZero() {
+		ref.UsedAt = time.Now()
+	}
+
+	result := []ArtworkRef{ref}
+	count := 1
+	for _, r := range list {
+		if r.Type == ref.Type && r.URL == ref.URL {
+			continue
+		}
+		if r.Type == ref.Type {
+			if count >= max {
+				continue
+			}
+			count++
+		}
+		result = append(result, r)
+	}
+	return result
+}
+
+// toggleFavorite removes ref if present, otherwise prepends it
+func toggleFavorite(list []ArtworkRef, ref ArtworkRef) ([]ArtworkRef, bool) {
+	for i, r := range list {
+		if r.Type == ref.Type && r.URL == ref.URL {
+			return append(list[:i:i], list[i+1:]...), false
+		}
+	}
+	if ref.UsedAt.IsZero() {
+		ref.UsedAt = time.Now()
+	}
+	return append([]ArtworkRef{ref}, list...), true
+}
+
+// ArtworkFilter holds the artwork browser filter choices for one asset type.
+// JSON names match the frontend ImageFilters.
+type ArtworkFilter struct {
+	Style     string `json:"style"`
+	MimeType  string `json:"mimeType"`
+	ImageType string `json:"imageType"`
+	Dimension string `json:"dimension"`
+	ShowNsfw  bool   `json:"showNsfw"`
+	ShowHumor bool   `json:"showHumor"`
+}
+
+// artworkTypes are the asset types artwork filters can be stored for
+var artworkTypes = map[string]bool{
+	"capsule": true,
+	"wide":    true,
+	"hero":    true,
+	"logo":    true,
+	"icon":    true,
+}
+
+// GetArtworkFilters returns the saved filters keyed by asset type
+func GetArtworkFilters() (map[string]ArtworkFilter, error) {
+	config, err := Load()
+	if err != nil {
+		return nil, err
+	}
+	if config.ArtworkFilters == nil {
+		return map[string]ArtworkFilter{}, nil
+	}
+	return config.ArtworkFilters, nil
+}
+
+// SetArtworkFilter saves the filters for an asset type
+func SetArtworkFilter(assetType string, filter ArtworkFilter) error {
+	if !artworkTypes[assetType] {
+		return fmt.Errorf("unknown artwork type: %s", assetType)
+	}
+	return Update(func(config *AppConfig) error {
+		if config.ArtworkFilters == nil {
+			config.ArtworkFilters = make(map[string]ArtworkFilter)
+		}
+		config.ArtworkFilters[assetType] = filter
+		return nil
+	})
+}
+
+// AppliedArtworkKey identifies a shortcut on a device
+func AppliedArtworkKey(host string, appID uint32) string {
+	return fmt.Sprintf("%s/%d", host, appID)
+}
+
+// GetAppliedArtwork returns the image URL last applied to each slot of a shortcut
+func GetAppliedArtwork(host string, appID uint32) (map[string]string, error) {
+	config, err := Load()
+	if err != nil {
+		return nil, err
+	}
+	applied := map[string]string{}
+	for slot, url := range config.AppliedArtwork[AppliedArtworkKey(host, appID)] {
+		applied[slot] = url
+	}
+	return applied, nil
+}
+
+// SetAppliedArtwork records the images applied to a shortcut. Slots not in
+// slots keep their previous record.
+func SetAppliedArtwork(host string, appID uint32, slots map[string]string) error {
+	return Update(func(config *AppConfig) error {
+		if config.AppliedArtwork == nil {
+			config.AppliedArtwork = make(map[string]map[string]string)
+		}
+		key := AppliedArtworkKey(host, appID)
+		applied := config.AppliedArtwork[key]
+		if applied == nil {
+			applied = make(map[string]string)
+		}
+		for slot, url := range slots {
+			if !artworkTypes[slot] {
+				return fmt.Errorf("unknown artwork type: %s", slot)
+			}
+			applied[slot] = url
+		}
+		config.AppliedArtwork[key] = applied
+		return nil
+	})
+}
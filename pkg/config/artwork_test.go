@@ -0,0 +1,109 @@
+package config
+
+import "testing"
+
+func TestAddRecent(t *testing.T) {
+	var list []ArtworkRef
+	list = addRecent(list, ArtworkRef{Type: "hero", URL: "a"}, 2)
+	list = addRecent(list, ArtworkRef{Type: "logo", URL: "b"}, 2)
+	list = addRecent(list, ArtworkRef{Type: "hero", URL: "c"}, 2)
+
+	// Re-adding moves the entry to the front without duplicating it
+	list = addRecent(list, ArtworkRef{Type: "hero", URL: "a"}, 2)
+	if len(list) != 3 || list[0].URL != "a" {
+		t.Fatalf("list = %+v, want a first and 3 entries", list)
+	}
+
+	// Exceeding the per-type limit drops the oldest of that type only
+	list = addRecent(list, ArtworkRef{Type: "hero", URL: "d"}, 2)
+	var heroes, logos []string
+	for _, r := range list {
+		switch r.Type {
+		case "hero":
+			heroes = append(heroes, r.URL)
+		case "logo":
+			logos = append(logos, r.URL)
+		}
+	}
+	if len(heroes) != 2 || heroes[0] != "d" || heroes[1] != "a" {
+		t.Errorf("heroes = %v, want [d a]", heroes)
+	}
+	if len(logos) != 1 {
+		t.Errorf("logos = %v, want [b]", logos)
+	}
+
+	if got := addRecent(list, ArtworkRef{Type: "hero"}, 2); len(got) != len(list) {
+		t.Error("empty URL should be ignored")
+	}
+}
+
+func TestToggleFavorite(t *testing.T) {
+	ref := ArtworkRef{Type: "capsule", URL: "https://cdn/grid.png"}
+
+	list, added := toggleFavorite(nil, ref)
+	if !added || len(list) != 1 {
+		t.Fatalf("first toggle: added = %v, list = %+v", added, list)
+	}
+
+	// Same URL with a different type is a separate favorite
+	list, added = toggleFavorite(list, ArtworkRef{Type: "wide", URL: ref.URL})
+	if !added || len(list) != 2 {
+		t.Fatalf("second toggle: added = %v, list = %+v", added, list)
+	}
+
+	list, added = toggleFavorite(list, ref)
+	if added || len(list) != 1 || list[0].Type != "wide" {
+		t.Errorf("removal: added = %v, list = %+v", added, list)
+	}
+}
+
+func TestArtworkSelectionKey(t *testing.T) {
+	tests := []struct {
+		name string
+		want string
+	}{
+		{"My Game", "my game"},
+		{"  My   Game  ", "my game"},
+		{"MY GAME", "my game"},
+		{"", ""},
+	}
+
+	for _, tt := range tests {
+		if got := ArtworkSelectionKey(tt.name); got != tt.want {
+			t.Errorf("ArtworkSelectionKey(%q) = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestSetArtworkFilter_UnknownType(t *testing.T) {
+	if err := SetArtworkFilter("banner", ArtworkFilter{}); err == nil {
+		t.Error("SetArtworkFilter() should reject unknown asset types")
+	}
+}
+
+func TestAppliedArtwork(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	t.Setenv("HOME", t.TempDir())
+
+	if err := SetAppliedArtwork("deck", 42, map[string]string{"hero": "h1", "logo": "l1"}); err != nil {
+		t.Fatalf("SetAppliedArtwork() error = %v", err)
+	}
+	// Later applies only update the slots they touched
+	if err := SetAppliedArtwork("deck", 42, map[string]string{"logo": "l2"}); err != nil {
+		t.Fatalf("SetAppliedArtwork() error = %v", err)
+	}
+
+	got, err := GetAppliedArtwork("deck", 42)
+	if err != nil {
+		t.Fatalf("GetAppliedArtwork() error = %v", err)
+	}
+	if got["hero"] != "h1" || got["logo"] != "l2" {
+		t.Errorf("GetAppliedArtwork() = %v", got)
+	}
+	if other, _ := GetAppliedArtwork("other", 42); len(other) != 0 {
+		t.Errorf("GetAppliedArtwork(other device) = %v, want empty", other)
+	}
+	if err := SetAppliedArtwork("deck", 42, map[string]string{"banner": "x"}); err == nil {
+		t.Error("SetAppliedArtwork() should reject unknown slots")
+	}
+}
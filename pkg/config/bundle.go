@@ -0,0 +1,310 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// BundleFormat identifies a file written by ExportBundle
+const BundleFormat = "capydeploy-config"
+
+// Bundle is a shareable copy of the configuration. It never holds secrets:
+// device passwords, API keys, proxy credentials, the values of build
+// environment variables and post-deploy commands are left out.
+type Bundle struct {
+	Format     string    `json:"format"`
+	Version    int       `json:"version"`
+	ExportedAt time.Time `json:"exported_at"`
+
+	Devices           []DeviceConfig `json:"devices,omitempty"`
+	GameSetups        []GameSetup    `json:"game_setups,omitempty"`
+	DefaultRemotePath string         `json:"default_remote_path,omitempty"`
+	// Artwork presets
+	ArtworkFilters    map[string]ArtworkFilter    `json:"artwork_filters,omitempty"`
+	ArtworkSelections map[string]ArtworkSelection `json:"artwork_selections,omitempty"`
+	FavoriteArtwork   []ArtworkRef                `json:"favorite_artwork,omitempty"`
+	// Settings
+	IconUpscale          string               `json:"icon_upscale,omitempty"`
+	DefaultLaunchOptions string               `json:"default_launch_options,omitempty"`
+	ImageCache           *ImageCacheSettings  `json:"image_cache,omitempty"`
+	Performance          *PerformanceSettings `json:"performance,omitempty"`
+	Appearance           *Appearance          `json:"appearance,omitempty"`
+	Network              *NetworkSettings     `json:"network,omitempty"`
+	KeyBindings          KeyBindings          `json:"key_bindings,omitempty"`
+}
+
+// ImportSummary counts what an import added or replaced
+type ImportSummary struct {
+	Devices    int  `json:"devices"`
+	GameSetups int  `json:"gameSetups"`
+	Presets    int  `json:"presets"`
+	Settings   bool `json:"settings"`
+	// Names of the imported setups whose build or post-deploy command was
+	// left out
+	SkippedCommands []string `json:"skippedCommands,omitempty"`
+}
+
+// NewBundle copies the shareable parts of cfg
+func NewBundle(cfg *AppConfig) *Bundle {
+	b := &Bundle{
+		Format:               BundleFormat,
+		Version:              CurrentVersion,
+		ExportedAt:           time.Now(),
+		DefaultRemotePath:    cfg.DefaultRemotePath,
+		ArtworkFilters:       cfg.ArtworkFilters,
+		ArtworkSelections:    cfg.ArtworkSelections,
+		FavoriteArtwork:      cfg.ArtworkHistory.Favorites,
+		IconUpscale:          cfg.IconUpscale,
+		DefaultLaunchOptions: cfg.DefaultLaunchOptions,
+		ImageCache:           cfg.ImageCache,
+		Performance:          cfg.Performance,
+		Appearance:           cfg.Appearance,
+		KeyBindings:          cfg.KeyBindings,
+	}
+
+	for _, d := range cfg.Devices {
+		d.Password = ""
+		b.Devices = append(b.Devices, d)
+	}
+	for _, s := range cfg.GameSetups {
+		if s.Build != nil {
+			build := *s.Build
+			build.Env = redactEnv(build.Env)
+			s.Build = &build
+		}
+		s.PostDeploy = ""
+		b.GameSetups = append(b.GameSetups, s)
+	}
+	if cfg.Network != nil {
+		network := *cfg.Network
+		network.ProxyURL = stripUserinfo(network.ProxyURL)
+		b.Network = &network
+	}
+	return b
+}
+
+// ParseBundle decodes an exported bundle, rejecting other files and bundles
+// from a newer version
+func ParseBundle(data []byte) (*Bundle, error) {
+	var b Bundle
+	if err := json.Unmarshal(data, &b); err != nil {
+		return nil, fmt.Errorf("not a CapyDeploy config file: %w", err)
+	}
+	if b.Format != BundleFormat {
+		return nil, fmt.Errorf("not a CapyDeploy config file")
+	}
+	if b.Version > CurrentVersion {
+		return nil, fmt.Errorf("config file is from a newer version of CapyDeploy (v%d)", b.Version)
+	}
+	return &b, nil
+}
+
+// Merge imports the bundle into cfg. Devices are matched by host and game
+// setups by ID; existing entries are replaced but keep their password and
+// key file, which are specific to this machine. Setups never bring their
+// build and post-deploy commands, which would run on the next deploy: a
+// replaced setup keeps its own and a new one gets none, so commands from a
+// shared file are only run once entered here.
+// Settings in the bundle overwrite the current ones.
+func (b *Bundle) Merge(cfg *AppConfig) ImportSummary {
+	var summary ImportSummary
+
+	for _, d := range b.Devices {
+		d.Password = ""
+		replaced := false
+		for i, existing := range cfg.Devices {
+			if existing.Host == d.Host {
+				d.Password = existing.Password
+				if existing.KeyFile != "" {
+					d.KeyFile = existing.KeyFile
+				}
+				cfg.Devices[i] = d
+				replaced = true
+				break
+			}
+		}
+		if !replaced {
+			cfg.Devices = append(cfg.Devices, d)
+		}
+		summary.Devices++
+	}
+
+	for _, s := range b.GameSetups {
+		if s.ID == "" {
+			continue
+		}
+		if s.Build != nil || s.PostDeploy != "" {
+			summary.SkippedCommands = append(summary.SkippedCommands, s.Name)
+		}
+		s.Build, s.PostDeploy = nil, ""
+		replaced := false
+		for i, existing := range cfg.GameSetups {
+			if existing.ID == s.ID {
+				s.Build, s.PostDeploy = existing.Build, existing.PostDeploy
+				cfg.GameSetups[i] = s
+				replaced = true
+				break
+			}
+		}
+		if !replaced {
+			cfg.GameSetups = append(cfg.GameSetups, s)
+		}
+		summary.GameSetups++
+	}
+
+	if len(b.ArtworkFilters) > 0 && cfg.ArtworkFilters == nil {
+		cfg.ArtworkFilters = make(map[string]ArtworkFilter)
+	}
+	for k, v := range b.ArtworkFilters {
+		cfg.ArtworkFilters[k] = v
+		summary.Presets++
+	}
+	if len(b.ArtworkSelections) > 0 && cfg.ArtworkSelections == nil {
+		cfg.ArtworkSelections = make(map[string]ArtworkSelection)
+	}
+	for k, v := range b.ArtworkSelections {
+		cfg.ArtworkSelections[k] = v
+		summary.Presets++
+	}
+	for _, fav := range b.FavoriteArtwork {
+		if !isFavorite(cfg.ArtworkHistory.Favorites, fav) {
+			cfg.ArtworkHistory.Favorites = append(cfg.ArtworkHistory.Favorites, fav)
+			summary.Presets++
+		}
+	}
+
+	if b.DefaultRemotePath != "" {
+		cfg.DefaultRemotePath = b.DefaultRemotePath
+		summary.Settings = true
+	}
+	if b.IconUpscale != "" {
+		cfg.IconUpscale = b.IconUpscale
+		summary.Settings = true
+	}
+	if b.DefaultLaunchOptions != "" {
+		cfg.DefaultLaunchOptions = b.DefaultLaunchOptions
+		summary.Settings = true
+	}
+	if b.ImageCache != nil {
+		cfg.ImageCache = b.ImageCache
+		summary.Settings = true
+	}
+	if b.Performance != nil && b.Performance.Validate() == nil {
+		cfg.Performance = b.Performance
+		summary.Settings = true
+	}
+	if b.Appearance != nil && b.Appearance.Validate() == nil {
+		cfg.Appearance = b.Appearance
+		summary.Settings = true
+	}
+	if b.Network != nil && b.Network.Validate() == nil {
+		cfg.Network = b.Network
+		summary.Settings = true
+	}
+	if b.KeyBindings != nil && b.KeyBindings.Validate() == nil {
+		cfg.KeyBindings = b.KeyBindings
+		summary.Settings = true
+	}
+	return summary
+}
+
+// isFavorite reports whether list already holds ref
+func isFavorite(list []ArtworkRef, ref ArtworkRef) bool {
+	for _, r := range list {
+		if r.Type == ref.Type && r.URL == ref.URL {
+			return true
+		}
+	}
+	return false
+}
+
+// minEnvSecretLen is the length from which a build variable value is scrubbed
+const minEnvSecretLen = 4
+
+// Secrets returns the passwords, API keys, tokens, build variables and
+// post-deploy commands held by cfg, to scrub them from text shared outside the machine such as support bundles
+func (cfg *AppConfig) Secrets() []string {
+	var secrets []string
+	add := func(s string) {
+		if s != "" {
+			secrets = append(secrets, s)
+		}
+	}
+	for _, d := range cfg.Devices {
+		add(d.Password)
+	}
+	for _, s := range cfg.GameSetups {
+		if s.Build != nil {
+			for _, kv := range s.Build.Env {
+				// Values like "1" or "on" can't be credentials and would
+				// garble every log line they appear in
+				if _, value, _ := strings.Cut(kv, "="); len(value) >= minEnvSecretLen {
+					add(value)
+				}
+			}
+		}
+		add(s.PostDeploy)
+	}
+	add(cfg.SteamGridDBAPIKey)
+	add(cfg.IGDBClientSecret)
+	if cfg.Automation != nil {
+		add(cfg.Automation.Token)
+	}
+	if cfg.Network != nil {
+		if u, err := url.Parse(cfg.Network.ProxyURL); err == nil && u.User != nil {
+			password, _ := u.User.Password()
+			add(password)
+		}
+	}
+	return secrets
+}
+
+// redactEnv blanks the values of KEY=VALUE pairs, which may hold credentials,
+// keeping the keys so the receiver knows what to set
+func redactEnv(env []string) []string {
+	if env == nil {
+		return nil
+	}
+	redacted := make([]string, len(env))
+	for i, kv := range env {
+		key, _, _ := strings.Cut(kv, "=")
+		redacted[i] = key + "="
+	}
+	return redacted
+}
+
+// stripUserinfo removes credentials from a proxy URL
+func stripUserinfo(rawURL string) string {
+	u, err := url.Parse(rawURL)
+	if err != nil || u.User == nil {
+		return rawURL
+	}
+	u.User = nil
+	return u.String()
+}
+
+// ExportBundle returns the shareable configuration as indented JSON
+func ExportBundle() ([]byte, error) {
+	cfg, err := Load()
+	if err != nil {
+		return nil, err
+	}
+	return json.MarshalIndent(NewBundle(cfg), "", "  ")
+}
+
+// ImportBundle merges an exported configuration into the saved one
+func ImportBundle(data []byte) (ImportSummary, error) {
+	bundle, err := ParseBundle(data)
+	if err != nil {
+		return ImportSummary{}, err
+	}
+	var summary ImportSummary
+	err = Update(func(cfg *AppConfig) error {
+		summary = bundle.Merge(cfg)
+		return nil
+	})
+	return summary, err
+}
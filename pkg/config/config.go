@@ -1,11 +1,19 @@
 package config
 
 import (
+	"crypto/rand"
+	"encoding/hex"
 	"encoding/json"
 	"fmt"
+	"net/url"
 	"os"
+	"path"
 	"path/filepath"
+	"strings"
+	"sync"
 	"time"
+
+	"github.com/lobinuxsoft/capydeploy/pkg/steam"
 )
 
 // DeviceConfig represents a saved device configuration
@@ -26,7 +34,46 @@ type GameSetup struct {
 	Executable    string `json:"executable"`
 	LaunchOptions string `json:"launch_options,omitempty"`
 	Tags          string `json:"tags,omitempty"`
+	Version       string `json:"version,omitempty"` // recorded with each deploy
+	Notes         string `json:"notes,omitempty"`   // changelog recorded with each deploy
 	RemotePath    string `json:"remote_path"`
+	CaptureLogs   bool   `json:"capture_logs,omitempty"` // run through the log wrapper on the device
+	Channel       string `json:"channel,omitempty"`      // build channel, e.g. "Debug"; empty is release
+	// File and folder name patterns left out of the deploy, e.g. "*_DoNotShip"
+	Exclude []string `json:"exclude,omitempty"`
+	// Winetricks verbs installed into the game's Proton prefix once it exists,
+	// e.g. "vcrun2019"
+	PrefixVerbs []string `json:"prefix_verbs,omitempty"`
+	// Launch with the MangoHud overlay, adds MANGOHUD=1 to the launch options
+	MangoHud bool `json:"mangohud,omitempty"`
+	// Upload every file on each deploy instead of only the changed ones
+	FullUpload bool `json:"full_upload,omitempty"`
+	// Send the files as one compressed tar stream extracted on the device,
+	// instead of one SFTP transfer per file
+	CompressedUpload bool `json:"compressed_upload,omitempty"`
+	// Upload with rsync when this computer and the device have it, falling
+	// back to SFTP otherwise
+	Rsync bool `json:"rsync,omitempty"`
+	// Send only the changed fixed-size blocks of large files, such as pak
+	// files, instead of uploading them whole. Saved under its earlier name.
+	BlockPatch bool `json:"binary_patch,omitempty"`
+	// Upload through a store of chunks on the device shared by every game, so
+	// data it already has from any build is not sent again
+	ChunkStore bool `json:"chunk_store,omitempty"`
+	// Builds kept on the device to roll back to, each deploy uploading a new
+	// release next to them; 0 deploys over the game in place
+	KeepReleases int `json:"keep_releases,omitempty"`
+	// Delete the files on the device that the build no longer has, except the
+	// ones matching the exclude patterns, e.g. saves written next to the game
+	Mirror bool `json:"mirror,omitempty"`
+	// Command that builds the game before each deploy, nil deploys the folder as is
+	Build *BuildStep `json:"build,omitempty"`
+	// Shell command run on the device in the game folder once the files are
+	// uploaded, e.g. a script shipped with the game
+	PostDeploy string `json:"post_deploy,omitempty"`
+	// More executables of the game, e.g. a dedicated server, each deployed
+	// with its own Steam shortcut
+	ExtraShortcuts []ExtraShortcut `json:"extra_shortcuts,omitempty"`
 	// SteamGridDB artwork
 	GridDBGameID   int    `json:"griddb_game_id,omitempty"`
 	GridPortrait   string `json:"grid_portrait,omitempty"`   // 600x900 portrait grid
@@ -34,14 +81,413 @@ type GameSetup struct {
 	HeroImage      string `json:"hero_image,omitempty"`      // 1920x620 hero banner
 	LogoImage      string `json:"logo_image,omitempty"`      // Logo with transparency
 	IconImage      string `json:"icon_image,omitempty"`      // Square icon
+	// Logo placement over the hero, nil keeps Steam's default
+	LogoPosition *steam.LogoPosition `json:"logo_position,omitempty"`
+}
+
+// ReleaseChannel is the build channel deployed under the plain game name
+const ReleaseChannel = "Release"
+
+// MaxKeepReleases caps the builds of a game kept on the device
+const MaxKeepReleases = 20
+
+// DeployName returns the name a setup is deployed under, which names both its
+// remote directory and its Steam shortcut. Builds of other channels get the
+// channel appended ("MyGame [Debug]") so they coexist with the release build.
+func (s GameSetup) DeployName() string {
+	channel := strings.TrimSpace(s.Channel)
+	if channel == "" || strings.EqualFold(channel, ReleaseChannel) {
+		return s.Name
+	}
+	return fmt.Sprintf("%s [%s]", s.Name, channel)
+}
+
+// ShortcutName returns the name of the Steam shortcut of an extra executable,
+// with the channel appended like DeployName
+func (s GameSetup) ShortcutName(extra ExtraShortcut) string {
+	channel := strings.TrimSpace(s.Channel)
+	if channel == "" || strings.EqualFold(channel, ReleaseChannel) {
+		return extra.Name
+	}
+	return fmt.Sprintf("%s [%s]", extra.Name, channel)
+}
+
+// ExtraShortcut is another executable of a game, such as a launcher, a
+// dedicated server or a benchmark, started by its own Steam shortcut
+type ExtraShortcut struct {
+	Name string `json:"name"`
+	// Slash separated path relative to the game folder
+	Executable    string `json:"executable"`
+	LaunchOptions string `json:"launch_options,omitempty"`
+}
+
+// Validate checks the shortcut is named and its executable is inside the game folder
+func (e ExtraShortcut) Validate() error {
+	if strings.TrimSpace(e.Name) == "" {
+		return fmt.Errorf("extra shortcut name is required")
+	}
+	exe := strings.ReplaceAll(e.Executable, "\\", "/")
+	if strings.TrimSpace(exe) == "" {
+		return fmt.Errorf("extra shortcut %q needs an executable", e.Name)
+	}
+	cleaned := path.Clean(exe)
+	if path.IsAbs(exe) || filepath.IsAbs(e.Executable) || cleaned == ".." || strings.HasPrefix(cleaned, "../") {
+		return fmt.Errorf("executable of extra shortcut %q must be inside the game folder", e.Name)
+	}
+	return nil
+}
+
+// BuildStep is a command run on this machine before a deploy to build the game,
+// such as an engine's command line export or a make target
+type BuildStep struct {
+	Command string   `json:"command"`
+	Args    []string `json:"args,omitempty"`
+	// Absolute directory the command runs in, the setup's local folder when empty
+	WorkingDir string `json:"working_dir,omitempty"`
+	// KEY=VALUE pairs added to the hub's environment
+	Env []string `json:"env,omitempty"`
+	// Folder the build writes the game to, relative to the working directory.
+	// It is deployed instead of the local folder; empty deploys the local folder.
+	OutputDir string `json:"output_dir,omitempty"`
+}
+
+// Validate checks the command is set and the directories and variables are well formed
+func (b BuildStep) Validate() error {
+	if strings.TrimSpace(b.Command) == "" {
+		return fmt.Errorf("build command is required")
+	}
+	if b.WorkingDir != "" && !filepath.IsAbs(b.WorkingDir) {
+		return fmt.Errorf("build working directory must be an absolute path")
+	}
+	for _, kv := range b.Env {
+		key, _, ok := strings.Cut(kv, "=")
+		if !ok || key == "" || strings.ContainsAny(key, " \t\x00") {
+			return fmt.Errorf("build environment variable %q must be KEY=VALUE", key)
+		}
+	}
+	return nil
 }
 
 // AppConfig represents the application configuration
 type AppConfig struct {
-	Devices           []DeviceConfig `json:"devices"`
-	GameSetups        []GameSetup    `json:"game_setups"`
-	DefaultRemotePath string         `json:"default_remote_path"`
-	SteamGridDBAPIKey string         `json:"steamgriddb_api_key,omitempty"`
+	// Schema version, see CurrentVersion
+	Version           int                 `json:"version"`
+	Devices           []DeviceConfig      `json:"devices"`
+	GameSetups        []GameSetup         `json:"game_setups"`
+	DefaultRemotePath string              `json:"default_remote_path"`
+	SteamGridDBAPIKey string              `json:"steamgriddb_api_key,omitempty"`
+	IGDBClientID      string              `json:"igdb_client_id,omitempty"`
+	IGDBClientSecret  string              `json:"igdb_client_secret,omitempty"`
+	ImageCache        *ImageCacheSettings `json:"image_cache,omitempty"`
+	ArtworkHistory    ArtworkHistory      `json:"artwork_history"`
+	// Last artwork selection per game, keyed by ArtworkSelectionKey
+	ArtworkSelections map[string]ArtworkSelection `json:"artwork_selections,omitempty"`
+	// Artwork browser filters per asset type (capsule, wide, hero, logo, icon)
+	ArtworkFilters map[string]ArtworkFilter `json:"artwork_filters,omitempty"`
+	// Artwork last applied to each shortcut, keyed by AppliedArtworkKey
+	AppliedArtwork map[string]map[string]string `json:"applied_artwork,omitempty"`
+	// Upscaling applied to small icons: "" (off), "nearest" or "lanczos"
+	IconUpscale string `json:"icon_upscale,omitempty"`
+	// Launch options pre-filled in new game setups, e.g. "gamemoderun %command%"
+	DefaultLaunchOptions string `json:"default_launch_options,omitempty"`
+	// Transfer and download tuning, nil uses DefaultPerformanceSettings
+	Performance *PerformanceSettings `json:"performance,omitempty"`
+	// Theme and UI scale, nil uses DefaultAppearance
+	Appearance *Appearance `json:"appearance,omitempty"`
+	// Proxy and timeouts of SteamGridDB, IGDB and image requests
+	Network *NetworkSettings `json:"network,omitempty"`
+	// Local API for editor plugins and scripts, nil keeps it off
+	Automation *AutomationSettings `json:"automation,omitempty"`
+	// Key combination bound to each shortcut action, nil uses DefaultKeyBindings
+	KeyBindings KeyBindings `json:"key_bindings,omitempty"`
+	// Quit when the window is closed instead of keeping the Hub in the tray
+	QuitOnClose bool `json:"quit_on_close,omitempty"`
+	// IDs of the last deployed game setups, most recent first
+	RecentDeploys []string `json:"recent_deploys,omitempty"`
+	// Set once the first run wizard was finished or skipped
+	SetupCompleted bool `json:"setup_completed,omitempty"`
+	// Look for a newer Hub release on startup, off unless the user opts in
+	CheckForUpdates bool `json:"check_for_updates,omitempty"`
+	// Release the user chose to skip, not announced again
+	SkippedUpdate string `json:"skipped_update,omitempty"`
+
+	// Set when the file on disk is newer than this build understands
+	readOnly bool
+}
+
+// ImageCacheSettings holds the limits of the image disk cache
+type ImageCacheSettings struct {
+	MaxSizeMB int `json:"max_size_mb"` // 0 = unlimited
+	TTLHours  int `json:"ttl_hours"`   // 0 = never expire
+	// Budget of decoded images kept in memory by the artwork browser
+	MemoryMB int `json:"memory_mb,omitempty"` // 0 = default
+}
+
+// DefaultMemoryCacheMB is the in-memory image budget used when none is configured
+const DefaultMemoryCacheMB = 256
+
+// DefaultImageCacheSettings returns the default image cache limits
+func DefaultImageCacheSettings() ImageCacheSettings {
+	return ImageCacheSettings{
+		MaxSizeMB: 500,
+		TTLHours:  30 * 24,
+		MemoryMB:  DefaultMemoryCacheMB,
+	}
+}
+
+// PerformanceSettings tunes transfers and downloads for the network at hand
+type PerformanceSettings struct {
+	TransferWorkers int `json:"transfer_workers"` // files uploaded in parallel
+	ChunkSizeKB     int `json:"chunk_size_kb"`    // SFTP packet size
+	FetchWorkers    int `json:"fetch_workers"`    // concurrent image downloads
+	MaxRetries      int `json:"max_retries"`      // retries of rate limited SteamGridDB requests
+	// Bandwidth of uploads to the device, so a deploy over Wi-Fi doesn't lag
+	// a game being played on it
+	UploadLimitKBps int `json:"upload_limit_kbps,omitempty"` // 0 = unlimited
+	// Retries of a file that failed to upload, with a growing wait between
+	// them, and of all the files of a deploy before it gives up
+	UploadRetries     int `json:"upload_retries"`
+	UploadRetryBudget int `json:"upload_retry_budget"`
+}
+
+// Limits of the performance settings, outside them a device or API misbehaves
+const (
+	MaxTransferWorkers = 16
+	MinChunkSizeKB     = 8
+	MaxChunkSizeKB     = 256
+	MaxFetchWorkers    = 32
+	MaxRetries         = 20
+	MaxUploadRetries   = 10
+	MaxRetryBudget     = 1000
+)
+
+// DefaultPerformanceSettings returns the tuning used when none is configured
+func DefaultPerformanceSettings() PerformanceSettings {
+	return PerformanceSettings{
+		TransferWorkers:   1,
+		ChunkSizeKB:       32,
+		FetchWorkers:      6,
+		MaxRetries:        5,
+		UploadRetries:     3,
+		UploadRetryBudget: 20,
+	}
+}
+
+// Validate checks the settings are within the supported limits
+func (p PerformanceSettings) Validate() error {
+	switch {
+	case p.TransferWorkers < 1 || p.TransferWorkers > MaxTransferWorkers:
+		return fmt.Errorf("parallel uploads must be between 1 and %d", MaxTransferWorkers)
+	case p.ChunkSizeKB < MinChunkSizeKB || p.ChunkSizeKB > MaxChunkSizeKB:
+		return fmt.Errorf("chunk size must be between %d and %d KB", MinChunkSizeKB, MaxChunkSizeKB)
+	case p.FetchWorkers < 1 || p.FetchWorkers > MaxFetchWorkers:
+		return fmt.Errorf("image download workers must be between 1 and %d", MaxFetchWorkers)
+	case p.MaxRetries < 0 || p.MaxRetries > MaxRetries:
+		return fmt.Errorf("retries must be between 0 and %d", MaxRetries)
+	case p.UploadLimitKBps < 0:
+		return fmt.Errorf("upload limit can't be negative, use 0 for no limit")
+	case p.UploadRetries < 0 || p.UploadRetries > MaxUploadRetries:
+		return fmt.Errorf("upload retries must be between 0 and %d", MaxUploadRetries)
+	case p.UploadRetryBudget < 0 || p.UploadRetryBudget > MaxRetryBudget:
+		return fmt.Errorf("upload retries per deploy must be between 0 and %d", MaxRetryBudget)
+	}
+	return nil
+}
+
+// Appearance holds the UI theme, scale and language
+type Appearance struct {
+	Theme   string  `json:"theme"`    // "system", "dark" or "light"
+	UIScale float64 `json:"ui_scale"` // 1 = 100%
+	// "en" or "es", empty follows the system language
+	Language string `json:"language,omitempty"`
+}
+
+// UI scale limits, from a 1280x800 handheld to a 4K monitor
+const (
+	MinUIScale = 0.75
+	MaxUIScale = 2.0
+)
+
+// DefaultAppearance returns the appearance used when none is configured
+func DefaultAppearance() Appearance {
+	return Appearance{Theme: "dark", UIScale: 1}
+}
+
+// Validate checks the theme and language are known and the scale within limits
+func (a Appearance) Validate() error {
+	switch a.Theme {
+	case "system", "dark", "light":
+	default:
+		return fmt.Errorf("unknown theme: %q", a.Theme)
+	}
+	switch a.Language {
+	case "", "en", "es":
+	default:
+		return fmt.Errorf("unsupported language: %q", a.Language)
+	}
+	if a.UIScale < MinUIScale || a.UIScale > MaxUIScale {
+		return fmt.Errorf("UI scale must be between %.0f%% and %.0f%%", MinUIScale*100, MaxUIScale*100)
+	}
+	return nil
+}
+
+// NetworkSettings configures the HTTP requests made to artwork services
+type NetworkSettings struct {
+	// http://, https:// or socks5:// proxy. Empty honors HTTP_PROXY and HTTPS_PROXY.
+	ProxyURL       string `json:"proxy_url,omitempty"`
+	TimeoutSeconds int    `json:"timeout_seconds"` // per request
+}
+
+// Request timeout limits in seconds
+const (
+	MinRequestTimeout = 5
+	MaxRequestTimeout = 300
+)
+
+// DefaultNetworkSettings returns the network settings used when none are configured
+func DefaultNetworkSettings() NetworkSettings {
+	return NetworkSettings{TimeoutSeconds: 30}
+}
+
+// Validate checks the proxy URL and timeout
+func (n NetworkSettings) Validate() error {
+	if n.TimeoutSeconds < MinRequestTimeout || n.TimeoutSeconds > MaxRequestTimeout {
+		return fmt.Errorf("request timeout must be between %d and %d seconds", MinRequestTimeout, MaxRequestTimeout)
+	}
+	if n.ProxyURL == "" {
+		return nil
+	}
+	u, err := url.Parse(n.ProxyURL)
+	if err != nil {
+		return fmt.Errorf("invalid proxy URL: %w", err)
+	}
+	switch u.Scheme {
+	case "http", "https", "socks5":
+	default:
+		return fmt.Errorf("proxy URL must start with http://, https:// or socks5://")
+	}
+	if u.Host == "" {
+		return fmt.Errorf("proxy URL has no host")
+	}
+	return nil
+}
+
+// KeyBindings maps shortcut actions to key combinations such as "Ctrl+Shift+D".
+// An empty combination leaves the action unbound.
+type KeyBindings map[string]string
+
+// Shortcut actions
+const (
+	KeyActionDeployLast       = "deploy_last"
+	KeyActionCancelTransfer   = "cancel_transfer"
+	KeyActionToggleConnection = "toggle_connection"
+	KeyActionTabDevices       = "tab_devices"
+	KeyActionTabUpload        = "tab_upload"
+	KeyActionTabGames         = "tab_games"
+	KeyActionTabSettings      = "tab_settings"
+	KeyActionTabLogs          = "tab_logs"
+)
+
+// keyModifiers are the modifiers a combination may start with, in this order
+var keyModifiers = []string{"Ctrl", "Alt", "Shift", "Meta"}
+
+// DefaultKeyBindings returns the key combinations used when none are configured
+func DefaultKeyBindings() KeyBindings {
+	return KeyBindings{
+		KeyActionDeployLast:       "Ctrl+D",
+		KeyActionCancelTransfer:   "Ctrl+Shift+X",
+		KeyActionToggleConnection: "Ctrl+K",
+		KeyActionTabDevices:       "Ctrl+1",
+		KeyActionTabUpload:        "Ctrl+2",
+		KeyActionTabGames:         "Ctrl+3",
+		KeyActionTabSettings:      "Ctrl+4",
+		KeyActionTabLogs:          "Ctrl+5",
+	}
+}
+
+// Validate checks that every action is known, every combination well formed
+// and no combination bound twice
+func (b KeyBindings) Validate() error {
+	defaults := DefaultKeyBindings()
+	bound := make(map[string]string)
+	for action, combo := range b {
+		if _, ok := defaults[action]; !ok {
+			return fmt.Errorf("unknown shortcut action: %q", action)
+		}
+		if combo == "" {
+			continue
+		}
+		if err := validateCombo(combo); err != nil {
+			return err
+		}
+		if other, ok := bound[combo]; ok {
+			return fmt.Errorf("%s is bound to both %s and %s", combo, other, action)
+		}
+		bound[combo] = action
+	}
+	return nil
+}
+
+// validateCombo checks a combination: modifiers in canonical order, then one key
+func validateCombo(combo string) error {
+	parts := strings.Split(combo, "+")
+	key := parts[len(parts)-1]
+	if len(combo) > 32 || key == "" || strings.ContainsAny(key, " \t") {
+		return fmt.Errorf("invalid key combination: %q", combo)
+	}
+	next := 0
+	for _, mod := range parts[:len(parts)-1] {
+		found := false
+		for next < len(keyModifiers) {
+			next++
+			if keyModifiers[next-1] == mod {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return fmt.Errorf("invalid key combination: %q", combo)
+		}
+	}
+	for _, mod := range keyModifiers {
+		if key == mod {
+			return fmt.Errorf("invalid key combination: %q", combo)
+		}
+	}
+	return nil
+}
+
+// AutomationSettings configures the local automation API of the hub
+type AutomationSettings struct {
+	Enabled bool `json:"enabled"`
+	Port    int  `json:"port"` // on 127.0.0.1 only
+	// Bearer token every request must send, created when the API is enabled
+	Token string `json:"token,omitempty"`
+}
+
+// DefaultAutomationPort is the port of the automation API when none is configured
+const DefaultAutomationPort = 17380
+
+// DefaultAutomationSettings returns the automation settings used when none are configured
+func DefaultAutomationSettings() AutomationSettings {
+	return AutomationSettings{Port: DefaultAutomationPort}
+}
+
+// Validate checks the port is a non privileged one
+func (a AutomationSettings) Validate() error {
+	if a.Port < 1024 || a.Port > 65535 {
+		return fmt.Errorf("automation API port must be between 1024 and 65535")
+	}
+	return nil
+}
+
+// NewAutomationToken returns a random token for the automation API
+func NewAutomationToken() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
 }
 
 // GetConfigPath returns the path to the config file
@@ -64,7 +510,18 @@ func GetConfigPath() (string, error) {
 	return filepath.Join(appConfigDir, "config.json"), nil
 }
 
-// Load loads the configuration from disk
+// KnownHostsPath returns the known_hosts file with the host keys of the
+// devices the user trusted, next to the config file
+func KnownHostsPath() (string, error) {
+	configPath, err := GetConfigPath()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(filepath.Dir(configPath), "known_hosts"), nil
+}
+
+// Load loads the configuration from disk, migrating files written by older
+// versions. The original file is backed up before it's upgraded.
 func Load() (*AppConfig, error) {
 	configPath, err := GetConfigPath()
 	if err != nil {
@@ -76,72 +533,131 @@ func Load() (*AppConfig, error) {
 		if os.IsNotExist(err) {
 			// Return default config if file doesn't exist
 			return &AppConfig{
+				Version:           CurrentVersion,
 				Devices:           []DeviceConfig{},
-				DefaultRemotePath: "~/devkit-games",
+				DefaultRemotePath: DefaultRemotePath,
 			}, nil
 		}
 		return nil, err
 	}
 
+	var doc map[string]json.RawMessage
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return nil, err
+	}
+	version, err := migrateConfig(doc)
+	if err != nil {
+		return nil, err
+	}
+	if version < CurrentVersion {
+		if err := backupConfig(configPath, data, version); err != nil {
+			return nil, fmt.Errorf("failed to back up config before migrating: %w", err)
+		}
+		if data, err = json.Marshal(doc); err != nil {
+			return nil, err
+		}
+	}
+
 	var config AppConfig
 	if err := json.Unmarshal(data, &config); err != nil {
 		return nil, err
 	}
+	config.readOnly = version > CurrentVersion
 
+	if version < CurrentVersion {
+		if err := Save(&config); err != nil {
+			return nil, err
+		}
+	}
 	return &config, nil
 }
 
-// Save saves the configuration to disk
+// Save saves the configuration to disk. The file is replaced atomically so a
+// crash mid-write never leaves a truncated config behind.
 func Save(config *AppConfig) error {
+	if config.readOnly {
+		return ErrNewerConfig
+	}
 	configPath, err := GetConfigPath()
 	if err != nil {
 		return err
 	}
 
+	config.Version = CurrentVersion
 	data, err := json.MarshalIndent(config, "", "  ")
 	if err != nil {
 		return err
 	}
 
-	return os.WriteFile(configPath, data, 0600)
+	tmp, err := os.CreateTemp(filepath.Dir(configPath), "config-*.json.tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if err := tmp.Chmod(0600); err != nil {
+		tmp.Close()
+		return err
+	}
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), configPath)
 }
 
-// AddDevice adds a device to the config and saves it
-func AddDevice(device DeviceConfig) error {
+// updates serializes Update, so concurrent changes to the config don't
+// overwrite each other: each one loads and rewrites the whole file
+var updates sync.Mutex
+
+// Update loads the configuration, applies fn and saves it, one update at a
+// time. Nothing is saved when fn returns an error.
+func Update(fn func(*AppConfig) error) error {
+	updates.Lock()
+	defer updates.Unlock()
+
 	config, err := Load()
 	if err != nil {
 		return err
 	}
+	if err := fn(config); err != nil {
+		return err
+	}
+	return Save(config)
+}
 
-	// Check if device already exists (by host)
-	for i, d := range config.Devices {
-		if d.Host == device.Host {
-			// Update existing device
-			config.Devices[i] = device
-			return Save(config)
+// AddDevice adds a device to the config and saves it
+func AddDevice(device DeviceConfig) error {
+	return Update(func(config *AppConfig) error {
+		// Check if device already exists (by host)
+		for i, d := range config.Devices {
+			if d.Host == device.Host {
+				// Update existing device
+				config.Devices[i] = device
+				return nil
+			}
 		}
-	}
 
-	// Add new device
-	config.Devices = append(config.Devices, device)
-	return Save(config)
+		// Add new device
+		config.Devices = append(config.Devices, device)
+		return nil
+	})
 }
 
 // RemoveDevice removes a device from the config
 func RemoveDevice(host string) error {
-	config, err := Load()
-	if err != nil {
-		return err
-	}
-
-	for i, d := range config.Devices {
-		if d.Host == host {
-			config.Devices = append(config.Devices[:i], config.Devices[i+1:]...)
-			break
+	return Update(func(config *AppConfig) error {
+		for i, d := range config.Devices {
+			if d.Host == host {
+				config.Devices = append(config.Devices[:i], config.Devices[i+1:]...)
+				break
+			}
 		}
-	}
 
-	return Save(config)
+		return nil
+	})
 }
 
 // GetDevices returns all saved devices
@@ -155,106 +671,412 @@ func GetDevices() ([]DeviceConfig, error) {
 
 // UpdateDevice updates an existing device
 func UpdateDevice(oldHost string, device DeviceConfig) error {
+	return Update(func(config *AppConfig) error {
+		for i, d := range config.Devices {
+			if d.Host == oldHost {
+				config.Devices[i] = device
+				return nil
+			}
+		}
+
+		// If not found, add it
+		config.Devices = append(config.Devices, device)
+		return nil
+	})
+}
+
+// AddGameSetup adds a game setup to the config
+func AddGameSetup(setup GameSetup) error {
+	return Update(func(config *AppConfig) error {
+		// Generate ID if not set
+		if setup.ID == "" {
+			setup.ID = fmt.Sprintf("game_%d", time.Now().UnixNano())
+		}
+
+		// Check if setup already exists (by ID)
+		for i, s := range config.GameSetups {
+			if s.ID == setup.ID {
+				config.GameSetups[i] = setup
+				return nil
+			}
+		}
+
+		config.GameSetups = append(config.GameSetups, setup)
+		return nil
+	})
+}
+
+// UpdateGameSetup updates an existing game setup
+func UpdateGameSetup(id string, setup GameSetup) error {
+	return Update(func(config *AppConfig) error {
+		for i, s := range config.GameSetups {
+			if s.ID == id {
+				setup.ID = id // Keep the same ID
+				config.GameSetups[i] = setup
+				return nil
+			}
+		}
+
+		return fmt.Errorf("game setup not found: %s", id)
+	})
+}
+
+// RemoveGameSetup removes a game setup from the config
+func RemoveGameSetup(id string) error {
+	return Update(func(config *AppConfig) error {
+		for i, s := range config.GameSetups {
+			if s.ID == id {
+				config.GameSetups = append(config.GameSetups[:i], config.GameSetups[i+1:]...)
+				config.RecentDeploys = removeString(config.RecentDeploys, id)
+				return nil
+			}
+		}
+
+		return nil
+	})
+}
+
+// GetGameSetups returns all saved game setups
+func GetGameSetups() ([]GameSetup, error) {
 	config, err := Load()
 	if err != nil {
-		return err
+		return nil, err
+	}
+	return config.GameSetups, nil
+}
+
+// MaxRecentDeploys is the number of recently deployed game setups kept
+const MaxRecentDeploys = 5
+
+// GetRecentDeploys returns the IDs of the last deployed game setups, most recent first
+func GetRecentDeploys() ([]string, error) {
+	config, err := Load()
+	if err != nil {
+		return nil, err
+	}
+	return config.RecentDeploys, nil
+}
+
+// AddRecentDeploy records a game setup as deployed
+func AddRecentDeploy(id string) error {
+	if id == "" {
+		return nil
+	}
+	return Update(func(config *AppConfig) error {
+		config.RecentDeploys = addRecentDeploy(config.RecentDeploys, id, MaxRecentDeploys)
+		return nil
+	})
+}
+
+// addRecentDeploy moves id to the front of the list and keeps at most max entries
+func addRecentDeploy(list []string, id string, max int) []string {
+	result := append([]string{id}, removeString(list, id)...)
+	if len(result) > max {
+		result = result[:max]
 	}
+	return result
+}
 
-	for i, d := range config.Devices {
-		if d.Host == oldHost {
-			config.Devices[i] = device
-			return Save(config)
+func removeString(list []string, s string) []string {
+	result := make([]string, 0, len(list))
+	for _, item := range list {
+		if item != s {
+			result = append(result, item)
 		}
 	}
+	return result
+}
 
-	// If not found, add it
-	config.Devices = append(config.Devices, device)
-	return Save(config)
+// GetSteamGridDBAPIKey returns the SteamGridDB API key
+func GetSteamGridDBAPIKey() (string, error) {
+	config, err := Load()
+	if err != nil {
+		return "", err
+	}
+	return config.SteamGridDBAPIKey, nil
 }
 
-// AddGameSetup adds a game setup to the config
-func AddGameSetup(setup GameSetup) error {
+// SetSteamGridDBAPIKey saves the SteamGridDB API key
+func SetSteamGridDBAPIKey(apiKey string) error {
+	return Update(func(config *AppConfig) error {
+		config.SteamGridDBAPIKey = apiKey
+		return nil
+	})
+}
+
+// GetImageCacheSettings returns the image cache limits, falling back to defaults
+func GetImageCacheSettings() (ImageCacheSettings, error) {
 	config, err := Load()
 	if err != nil {
-		return err
+		return DefaultImageCacheSettings(), err
 	}
+	if config.ImageCache == nil {
+		return DefaultImageCacheSettings(), nil
+	}
+	settings := *config.ImageCache
+	if settings.MemoryMB == 0 {
+		settings.MemoryMB = DefaultMemoryCacheMB
+	}
+	return settings, nil
+}
 
-	// Generate ID if not set
-	if setup.ID == "" {
-		setup.ID = fmt.Sprintf("game_%d", time.Now().UnixNano())
+// SetImageCacheSettings saves the image cache limits
+func SetImageCacheSettings(settings ImageCacheSettings) error {
+	if settings.MaxSizeMB < 0 || settings.TTLHours < 0 || settings.MemoryMB < 0 {
+		return fmt.Errorf("cache limits cannot be negative")
 	}
+	return Update(func(config *AppConfig) error {
+		config.ImageCache = &settings
+		return nil
+	})
+}
 
-	// Check if setup already exists (by ID)
-	for i, s := range config.GameSetups {
-		if s.ID == setup.ID {
-			config.GameSetups[i] = setup
-			return Save(config)
-		}
+// GetDefaultLaunchOptions returns the launch options new game setups start with
+func GetDefaultLaunchOptions() (string, error) {
+	config, err := Load()
+	if err != nil {
+		return "", err
 	}
+	return config.DefaultLaunchOptions, nil
+}
 
-	config.GameSetups = append(config.GameSetups, setup)
-	return Save(config)
+// SetDefaultLaunchOptions saves the launch options new game setups start with
+func SetDefaultLaunchOptions(options string) error {
+	return Update(func(config *AppConfig) error {
+		config.DefaultLaunchOptions = strings.TrimSpace(options)
+		return nil
+	})
 }
 
-// UpdateGameSetup updates an existing game setup
-func UpdateGameSetup(id string, setup GameSetup) error {
+// GetPerformanceSettings returns the transfer and download tuning, falling back to defaults
+func GetPerformanceSettings() (PerformanceSettings, error) {
+	config, err := Load()
+	if err != nil {
+		return DefaultPerformanceSettings(), err
+	}
+	if config.Performance == nil || config.Performance.Validate() != nil {
+		return DefaultPerformanceSettings(), nil
+	}
+	return *config.Performance, nil
+}
+
+// SetPerformanceSettings saves the transfer and download tuning
+func SetPerformanceSettings(settings PerformanceSettings) error {
+	if err := settings.Validate(); err != nil {
+		return err
+	}
+	return Update(func(config *AppConfig) error {
+		config.Performance = &settings
+		return nil
+	})
+}
+
+// GetAppearance returns the UI theme and scale, falling back to defaults
+func GetAppearance() (Appearance, error) {
 	config, err := Load()
 	if err != nil {
+		return DefaultAppearance(), err
+	}
+	if config.Appearance == nil || config.Appearance.Validate() != nil {
+		return DefaultAppearance(), nil
+	}
+	return *config.Appearance, nil
+}
+
+// SetAppearance saves the UI theme and scale
+func SetAppearance(appearance Appearance) error {
+	if err := appearance.Validate(); err != nil {
 		return err
 	}
+	return Update(func(config *AppConfig) error {
+		config.Appearance = &appearance
+		return nil
+	})
+}
 
-	for i, s := range config.GameSetups {
-		if s.ID == id {
-			setup.ID = id // Keep the same ID
-			config.GameSetups[i] = setup
-			return Save(config)
-		}
+// GetNetworkSettings returns the proxy and timeout settings, falling back to defaults
+func GetNetworkSettings() (NetworkSettings, error) {
+	config, err := Load()
+	if err != nil {
+		return DefaultNetworkSettings(), err
+	}
+	if config.Network == nil || config.Network.Validate() != nil {
+		return DefaultNetworkSettings(), nil
 	}
+	return *config.Network, nil
+}
 
-	return fmt.Errorf("game setup not found: %s", id)
+// SetNetworkSettings saves the proxy and timeout settings
+func SetNetworkSettings(settings NetworkSettings) error {
+	settings.ProxyURL = strings.TrimSpace(settings.ProxyURL)
+	if err := settings.Validate(); err != nil {
+		return err
+	}
+	return Update(func(config *AppConfig) error {
+		config.Network = &settings
+		return nil
+	})
 }
 
-// RemoveGameSetup removes a game setup from the config
-func RemoveGameSetup(id string) error {
+// GetKeyBindings returns the key combination of every shortcut action. Actions
+// missing from the config get their default.
+func GetKeyBindings() (KeyBindings, error) {
 	config, err := Load()
 	if err != nil {
+		return DefaultKeyBindings(), err
+	}
+	bindings := DefaultKeyBindings()
+	if config.KeyBindings.Validate() != nil {
+		return bindings, nil
+	}
+	for action, combo := range config.KeyBindings {
+		bindings[action] = combo
+	}
+	return bindings, nil
+}
+
+// SetKeyBindings saves the key combinations of the shortcut actions
+func SetKeyBindings(bindings KeyBindings) error {
+	if err := bindings.Validate(); err != nil {
 		return err
 	}
+	return Update(func(config *AppConfig) error {
+		config.KeyBindings = bindings
+		return nil
+	})
+}
 
-	for i, s := range config.GameSetups {
-		if s.ID == id {
-			config.GameSetups = append(config.GameSetups[:i], config.GameSetups[i+1:]...)
-			return Save(config)
+// GetAutomationSettings returns the automation API settings
+func GetAutomationSettings() (AutomationSettings, error) {
+	config, err := Load()
+	if err != nil {
+		return DefaultAutomationSettings(), err
+	}
+	if config.Automation == nil || config.Automation.Validate() != nil {
+		return DefaultAutomationSettings(), nil
+	}
+	return *config.Automation, nil
+}
+
+// SetAutomationSettings saves the automation API settings, creating a token
+// the first time the API is enabled
+func SetAutomationSettings(settings AutomationSettings) error {
+	if err := settings.Validate(); err != nil {
+		return err
+	}
+	if settings.Enabled && settings.Token == "" {
+		token, err := NewAutomationToken()
+		if err != nil {
+			return fmt.Errorf("failed to create token: %w", err)
 		}
+		settings.Token = token
+	}
+	return Update(func(config *AppConfig) error {
+		config.Automation = &settings
+		return nil
+	})
+}
+
+// GetIGDBCredentials returns the Twitch client ID and secret used for IGDB
+func GetIGDBCredentials() (string, string, error) {
+	config, err := Load()
+	if err != nil {
+		return "", "", err
 	}
+	return config.IGDBClientID, config.IGDBClientSecret, nil
+}
 
-	return nil
+// SetIGDBCredentials saves the Twitch client ID and secret used for IGDB
+func SetIGDBCredentials(clientID, clientSecret string) error {
+	return Update(func(config *AppConfig) error {
+		config.IGDBClientID = clientID
+		config.IGDBClientSecret = clientSecret
+		return nil
+	})
 }
 
-// GetGameSetups returns all saved game setups
-func GetGameSetups() ([]GameSetup, error) {
+// NeedsFirstRunSetup reports whether the first run wizard should be shown: it
+// never was, and there is nothing configured that it would set up
+func NeedsFirstRunSetup() (bool, error) {
 	config, err := Load()
 	if err != nil {
-		return nil, err
+		return false, err
 	}
-	return config.GameSetups, nil
+	return !config.SetupCompleted && len(config.Devices) == 0 && len(config.GameSetups) == 0, nil
 }
 
-// GetSteamGridDBAPIKey returns the SteamGridDB API key
-func GetSteamGridDBAPIKey() (string, error) {
+// CompleteFirstRunSetup records that the first run wizard was finished or skipped
+func CompleteFirstRunSetup() error {
+	return Update(func(config *AppConfig) error {
+		config.SetupCompleted = true
+		return nil
+	})
+}
+
+// GetQuitOnClose reports whether closing the window quits the Hub
+func GetQuitOnClose() (bool, error) {
+	config, err := Load()
+	if err != nil {
+		return false, err
+	}
+	return config.QuitOnClose, nil
+}
+
+// SetQuitOnClose saves whether closing the window quits the Hub
+func SetQuitOnClose(quit bool) error {
+	return Update(func(config *AppConfig) error {
+		config.QuitOnClose = quit
+		return nil
+	})
+}
+
+// GetCheckForUpdates reports whether the Hub looks for a newer release on startup
+func GetCheckForUpdates() (bool, error) {
+	config, err := Load()
+	if err != nil {
+		return false, err
+	}
+	return config.CheckForUpdates, nil
+}
+
+// SetCheckForUpdates saves whether the Hub looks for a newer release on startup
+func SetCheckForUpdates(enabled bool) error {
+	return Update(func(config *AppConfig) error {
+		config.CheckForUpdates = enabled
+		return nil
+	})
+}
+
+// GetSkippedUpdate returns the release the user chose to skip
+func GetSkippedUpdate() (string, error) {
 	config, err := Load()
 	if err != nil {
 		return "", err
 	}
-	return config.SteamGridDBAPIKey, nil
+	return config.SkippedUpdate, nil
 }
 
-// SetSteamGridDBAPIKey saves the SteamGridDB API key
-func SetSteamGridDBAPIKey(apiKey string) error {
+// SetSkippedUpdate saves the release the user chose to skip
+func SetSkippedUpdate(version string) error {
+	return Update(func(config *AppConfig) error {
+		config.SkippedUpdate = version
+		return nil
+	})
+}
+
+// GetIconUpscale returns the upscaling method for small icons ("" when disabled)
+func GetIconUpscale() (string, error) {
 	config, err := Load()
 	if err != nil {
-		return err
+		return "", err
 	}
-	config.SteamGridDBAPIKey = apiKey
-	return Save(config)
+	return config.IconUpscale, nil
+}
+
+// SetIconUpscale saves the upscaling method for small icons
+func SetIconUpscale(method string) error {
+	return Update(func(config *AppConfig) error {
+		config.IconUpscale = method
+		return nil
+	})
 }
@@ -0,0 +1,139 @@
+package config
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+)
+
+// CurrentVersion is the schema version written by this build. Files without a
+// version predate versioning and are treated as version 1.
+const CurrentVersion = 3
+
+// DefaultRemotePath is where games are deployed when no path is configured
+const DefaultRemotePath = "~/devkit-games"
+
+// ErrNewerConfig is returned by Save when the config on disk was written by a
+// newer build, so saving would drop settings this build doesn't know about.
+var ErrNewerConfig = errors.New("config was written by a newer version of CapyDeploy, not overwriting it")
+
+// migration upgrades the raw config document from the previous version to version to.
+// Migrations work on raw JSON so renamed or restructured keys can be carried over.
+type migration struct {
+	to      int
+	migrate func(doc map[string]json.RawMessage) error
+}
+
+// migrations are applied in order to bring old files up to CurrentVersion
+var migrations = []migration{
+	{to: 2, migrate: migrateV2},
+	{to: 3, migrate: migrateV3},
+}
+
+// migrateV2 fills in defaults older builds left empty and stores the release
+// channel as an empty channel
+func migrateV2(doc map[string]json.RawMessage) error {
+	if raw, ok := doc["default_remote_path"]; !ok || string(raw) == `""` || string(raw) == "null" {
+		doc["default_remote_path"], _ = json.Marshal(DefaultRemotePath)
+	}
+	if raw, ok := doc["devices"]; !ok || string(raw) == "null" {
+		doc["devices"] = json.RawMessage("[]")
+	}
+
+	raw, ok := doc["game_setups"]
+	if !ok || string(raw) == "null" {
+		return nil
+	}
+	var setups []map[string]json.RawMessage
+	if err := json.Unmarshal(raw, &setups); err != nil {
+		return fmt.Errorf("game_setups: %w", err)
+	}
+	for _, setup := range setups {
+		var channel string
+		if err := json.Unmarshal(setup["channel"], &channel); err == nil && channel == ReleaseChannel {
+			delete(setup, "channel")
+		}
+	}
+	data, err := json.Marshal(setups)
+	if err != nil {
+		return err
+	}
+	doc["game_setups"] = data
+	return nil
+}
+
+// migrateV3 turns on upload retries in saved performance settings, which
+// predate them and would read as no retries
+func migrateV3(doc map[string]json.RawMessage) error {
+	raw, ok := doc["performance"]
+	if !ok || string(raw) == "null" {
+		return nil
+	}
+	var perf map[string]json.RawMessage
+	if err := json.Unmarshal(raw, &perf); err != nil {
+		return fmt.Errorf("performance: %w", err)
+	}
+	defaults := DefaultPerformanceSettings()
+	if _, ok := perf["upload_retries"]; !ok {
+		perf["upload_retries"], _ = json.Marshal(defaults.UploadRetries)
+	}
+	if _, ok := perf["upload_retry_budget"]; !ok {
+		perf["upload_retry_budget"], _ = json.Marshal(defaults.UploadRetryBudget)
+	}
+	data, err := json.Marshal(perf)
+	if err != nil {
+		return err
+	}
+	doc["performance"] = data
+	return nil
+}
+
+// configVersion reads the schema version of a raw config document
+func configVersion(doc map[string]json.RawMessage) (int, error) {
+	raw, ok := doc["version"]
+	if !ok {
+		return 1, nil
+	}
+	var version int
+	if err := json.Unmarshal(raw, &version); err != nil {
+		return 0, fmt.Errorf("invalid config version: %w", err)
+	}
+	if version < 1 {
+		return 1, nil
+	}
+	return version, nil
+}
+
+// migrateConfig upgrades a raw config document to CurrentVersion. Returns the
+// version the document had before migrating.
+func migrateConfig(doc map[string]json.RawMessage) (int, error) {
+	version, err := configVersion(doc)
+	if err != nil {
+		return 0, err
+	}
+	if version >= CurrentVersion {
+		return version, nil
+	}
+
+	for _, m := range migrations {
+		if m.to <= version {
+			continue
+		}
+		if err := m.migrate(doc); err != nil {
+			return version, fmt.Errorf("migrating config to version %d: %w", m.to, err)
+		}
+	}
+	doc["version"], _ = json.Marshal(CurrentVersion)
+	return version, nil
+}
+
+// backupConfig keeps a copy of the file as it was before a migration, so an
+// upgrade can always be rolled back by hand. An existing backup is kept.
+func backupConfig(configPath string, data []byte, version int) error {
+	backupPath := fmt.Sprintf("%s.v%d.bak", configPath, version)
+	if _, err := os.Stat(backupPath); err == nil {
+		return nil
+	}
+	return os.WriteFile(backupPath, data, 0600)
+}
@@ -0,0 +1,140 @@
+package config
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestMigrateConfig_Unversioned(t *testing.T) {
+	var doc map[string]json.RawMessage
+	legacy := `{"devices":null,"game_setups":[{"id":"a","name":"A","channel":"Release"},{"id":"b","name":"B","channel":"Debug"}]}`
+	if err := json.Unmarshal([]byte(legacy), &doc); err != nil {
+		t.Fatal(err)
+	}
+
+	from, err := migrateConfig(doc)
+	if err != nil {
+		t.Fatalf("migrateConfig() error = %v", err)
+	}
+	if from != 1 {
+		t.Errorf("from = %d, want 1", from)
+	}
+
+	data, _ := json.Marshal(doc)
+	var cfg AppConfig
+	if err := json.Unmarshal(data, &cfg); err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Version != CurrentVersion {
+		t.Errorf("Version = %d, want %d", cfg.Version, CurrentVersion)
+	}
+	if cfg.DefaultRemotePath != DefaultRemotePath {
+		t.Errorf("DefaultRemotePath = %q, want %q", cfg.DefaultRemotePath, DefaultRemotePath)
+	}
+	if cfg.Devices == nil {
+		t.Error("Devices should be an empty list, not null")
+	}
+	if len(cfg.GameSetups) != 2 || cfg.GameSetups[0].Channel != "" || cfg.GameSetups[1].Channel != "Debug" {
+		t.Errorf("GameSetups = %+v, want release channel cleared and others kept", cfg.GameSetups)
+	}
+}
+
+func TestMigrateConfig_UploadRetries(t *testing.T) {
+	var doc map[string]json.RawMessage
+	v2 := `{"version":2,"performance":{"transfer_workers":4,"chunk_size_kb":64,"fetch_workers":6,"max_retries":5}}`
+	if err := json.Unmarshal([]byte(v2), &doc); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := migrateConfig(doc); err != nil {
+		t.Fatalf("migrateConfig() error = %v", err)
+	}
+
+	data, _ := json.Marshal(doc)
+	var cfg AppConfig
+	if err := json.Unmarshal(data, &cfg); err != nil {
+		t.Fatal(err)
+	}
+	defaults := DefaultPerformanceSettings()
+	perf := cfg.Performance
+	if perf == nil || perf.TransferWorkers != 4 || perf.UploadRetries != defaults.UploadRetries || perf.UploadRetryBudget != defaults.UploadRetryBudget {
+		t.Errorf("Performance = %+v, want the saved settings with the default upload retries", perf)
+	}
+}
+
+func TestLoad_MigratesAndBacksUp(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	t.Setenv("HOME", t.TempDir())
+	configPath, err := GetConfigPath()
+	if err != nil {
+		t.Fatal(err)
+	}
+	legacy := []byte(`{"devices":[{"name":"Deck","host":"10.0.0.2","port":22,"user":"deck"}],"default_remote_path":"~/games"}`)
+	if err := os.WriteFile(configPath, legacy, 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if len(cfg.Devices) != 1 || cfg.DefaultRemotePath != "~/games" {
+		t.Errorf("Load() lost data: %+v", cfg)
+	}
+
+	backup, err := os.ReadFile(configPath + ".v1.bak")
+	if err != nil {
+		t.Fatalf("backup not written: %v", err)
+	}
+	if string(backup) != string(legacy) {
+		t.Error("backup doesn't match the original file")
+	}
+
+	info, err := os.Stat(configPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Mode().Perm() != 0600 {
+		t.Errorf("config permissions = %v, want 0600", info.Mode().Perm())
+	}
+	data, _ := os.ReadFile(configPath)
+	var doc map[string]json.RawMessage
+	if err := json.Unmarshal(data, &doc); err != nil {
+		t.Fatal(err)
+	}
+	if version, _ := configVersion(doc); version != CurrentVersion {
+		t.Errorf("migrated file version = %d, want %d", version, CurrentVersion)
+	}
+
+	leftovers, _ := filepath.Glob(filepath.Join(filepath.Dir(configPath), "*.tmp"))
+	if len(leftovers) != 0 {
+		t.Errorf("temporary files left behind: %v", leftovers)
+	}
+}
+
+func TestSave_RefusesNewerConfig(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	t.Setenv("HOME", t.TempDir())
+	configPath, err := GetConfigPath()
+	if err != nil {
+		t.Fatal(err)
+	}
+	newer := []byte(`{"version":99,"devices":[],"future_setting":true}`)
+	if err := os.WriteFile(configPath, newer, 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if err := Save(cfg); !errors.Is(err, ErrNewerConfig) {
+		t.Errorf("Save() error = %v, want ErrNewerConfig", err)
+	}
+	data, _ := os.ReadFile(configPath)
+	if string(data) != string(newer) {
+		t.Error("newer config was overwritten")
+	}
+}
@@ -0,0 +1,131 @@
+package config
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// uploadSessionDir holds the sessions of unfinished deploys, next to the config file
+const uploadSessionDir = "upload-sessions"
+
+// UploadSession records the files a deploy has uploaded, so a deploy that was
+// cancelled, lost its connection or outlived the app resumes instead of
+// starting over. It is removed once the deploy completes.
+type UploadSession struct {
+	Host       string    `json:"host"`
+	RemotePath string    `json:"remote_path"` // game directory on the device
+	SetupID    string    `json:"setup_id"`
+	StartedAt  time.Time `json:"started_at"`
+	UpdatedAt  time.Time `json:"updated_at"`
+	BytesSent  int64     `json:"bytes_sent"`
+	// Hash of each uploaded file, keyed by slash separated relative path
+	Completed map[string]string `json:"completed"`
+}
+
+// NewUploadSession starts the session of a deploy of setupID to remotePath on host
+func NewUploadSession(host, remotePath, setupID string) *UploadSession {
+	now := time.Now().UTC()
+	return &UploadSession{
+		Host:       host,
+		RemotePath: remotePath,
+		SetupID:    setupID,
+		StartedAt:  now,
+		UpdatedAt:  now,
+		Completed:  map[string]string{},
+	}
+}
+
+// Uploaded reports whether the session uploaded relPath with the given hash
+func (s *UploadSession) Uploaded(relPath, hash string) bool {
+	return s.Completed[relPath] == hash
+}
+
+// Complete records an uploaded file
+func (s *UploadSession) Complete(relPath, hash string, size int64) {
+	if s.Completed == nil {
+		s.Completed = map[string]string{}
+	}
+	s.Completed[relPath] = hash
+	s.BytesSent += size
+	s.UpdatedAt = time.Now().UTC()
+}
+
+// uploadSessionPath returns the file of the session of a deploy target
+func uploadSessionPath(host, remotePath string) (string, error) {
+	configPath, err := GetConfigPath()
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256([]byte(host + "\x00" + remotePath))
+	return filepath.Join(filepath.Dir(configPath), uploadSessionDir, hex.EncodeToString(sum[:8])+".json"), nil
+}
+
+// LoadUploadSession returns the unfinished session of a deploy to remotePath on
+// host, or nil if there is none
+func LoadUploadSession(host, remotePath string) (*UploadSession, error) {
+	sessionPath, err := uploadSessionPath(host, remotePath)
+	if err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(sessionPath)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var session UploadSession
+	if err := json.Unmarshal(data, &session); err != nil {
+		return nil, err
+	}
+	if session.Host != host || session.RemotePath != remotePath {
+		return nil, nil
+	}
+	return &session, nil
+}
+
+// SaveUploadSession writes a session, replacing the previous one of its target
+func SaveUploadSession(session *UploadSession) error {
+	sessionPath, err := uploadSessionPath(session.Host, session.RemotePath)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(sessionPath), 0755); err != nil {
+		return err
+	}
+	data, err := json.Marshal(session)
+	if err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(sessionPath), "session-*.json.tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), sessionPath)
+}
+
+// RemoveUploadSession deletes the session of a deploy target, if any
+func RemoveUploadSession(host, remotePath string) error {
+	sessionPath, err := uploadSessionPath(host, remotePath)
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(sessionPath); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,52 @@
+package config
+
+import "testing"
+
+func useTempConfigDir(t *testing.T) {
+	t.Helper()
+	dir := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", dir)
+	t.Setenv("HOME", dir)
+	t.Setenv("AppData", dir)
+}
+
+func TestUploadSession_RoundTrip(t *testing.T) {
+	useTempConfigDir(t)
+
+	if s, err := LoadUploadSession("deck.local", "/home/deck/devkit-games/Game"); err != nil || s != nil {
+		t.Fatalf("LoadUploadSession() = %v, %v, want no session", s, err)
+	}
+
+	s := NewUploadSession("deck.local", "/home/deck/devkit-games/Game", "game_1")
+	s.Complete("Game.x86_64", "abc", 100)
+	s.Complete("Data/level1.pak", "def", 50)
+	if err := SaveUploadSession(s); err != nil {
+		t.Fatalf("SaveUploadSession() error = %v", err)
+	}
+
+	got, err := LoadUploadSession("deck.local", "/home/deck/devkit-games/Game")
+	if err != nil || got == nil {
+		t.Fatalf("LoadUploadSession() = %v, %v", got, err)
+	}
+	if got.SetupID != "game_1" || got.BytesSent != 150 {
+		t.Errorf("session = %+v, want setup game_1 with 150 bytes sent", got)
+	}
+	if !got.Uploaded("Game.x86_64", "abc") || got.Uploaded("Game.x86_64", "changed") || got.Uploaded("missing", "abc") {
+		t.Errorf("Uploaded() doesn't match the completed files %v", got.Completed)
+	}
+
+	// Other targets have their own session
+	if other, _ := LoadUploadSession("deck.local", "/home/deck/devkit-games/Other"); other != nil {
+		t.Errorf("session of another game = %+v, want none", other)
+	}
+
+	if err := RemoveUploadSession("deck.local", "/home/deck/devkit-games/Game"); err != nil {
+		t.Fatalf("RemoveUploadSession() error = %v", err)
+	}
+	if s, _ := LoadUploadSession("deck.local", "/home/deck/devkit-games/Game"); s != nil {
+		t.Errorf("session after removal = %+v, want none", s)
+	}
+	if err := RemoveUploadSession("deck.local", "/home/deck/devkit-games/Game"); err != nil {
+		t.Errorf("removing a missing session error = %v", err)
+	}
+}
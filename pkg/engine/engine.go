@@ -0,0 +1,199 @@
+// Package engine recognizes the build output of game engines: the file that
+// starts the game, the files that must not ship with it and the arguments it
+// is usually launched with.
+package engine
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// Kind identifies the engine that produced a build
+type Kind string
+
+const (
+	Unity  Kind = "unity"
+	Unreal Kind = "unreal"
+)
+
+// Layout describes a recognized build folder
+type Layout struct {
+	Engine Kind `json:"engine"`
+	// Slash separated path of the file that starts the game, relative to the build folder
+	Executable string `json:"executable"`
+	// Patterns of files and folders left out of the deploy
+	Exclude []string `json:"exclude,omitempty"`
+	// Arguments the build is usually launched with, appended to the launch options
+	LaunchOptions string `json:"launchOptions,omitempty"`
+}
+
+// UnityExclude are the folders Unity writes next to a player build for
+// debugging, which are never meant to ship
+var UnityExclude = []string{
+	"*_BurstDebugInformation_DoNotShip",
+	"*_BackUpThisFolder_ButDontShipItWithYourGame",
+}
+
+// Detect inspects a build folder and returns its layout, or nil when the
+// folder is not a build of a known engine
+func Detect(folder string) (*Layout, error) {
+	entries, err := os.ReadDir(folder)
+	if err != nil {
+		return nil, err
+	}
+	if layout := detectUnity(folder, entries); layout != nil {
+		return layout, nil
+	}
+	return detectUnreal(folder, entries), nil
+}
+
+// unityExecutables are the player names Unity writes, in order of preference
+var unityExecutables = []string{"%s.x86_64", "%s.exe", "%s.x86", "%s"}
+
+// detectUnity looks for a "<Game>_Data" folder next to the player executable
+// of the same name
+func detectUnity(folder string, entries []os.DirEntry) *Layout {
+	files := make(map[string]bool)
+	for _, e := range entries {
+		if !e.IsDir() {
+			files[e.Name()] = true
+		}
+	}
+
+	for _, e := range entries {
+		if !e.IsDir() || !strings.HasSuffix(e.Name(), "_Data") {
+			continue
+		}
+		game := strings.TrimSuffix(e.Name(), "_Data")
+		if !isUnityData(filepath.Join(folder, e.Name())) && !files["UnityPlayer.so"] && !files["UnityPlayer.dll"] {
+			continue
+		}
+		for _, pattern := range unityExecutables {
+			if exe := fmt.Sprintf(pattern, game); files[exe] {
+				return &Layout{Engine: Unity, Executable: exe, Exclude: UnityExclude}
+			}
+		}
+	}
+	return nil
+}
+
+// isUnityData reports whether dir holds the data files of a Unity player
+func isUnityData(dir string) bool {
+	for _, name := range []string{"globalgamemanagers", "data.unity3d"} {
+		if _, err := os.Stat(filepath.Join(dir, name)); err == nil {
+			return true
+		}
+	}
+	return false
+}
+
+// UnrealExclude are the debug symbols a packaged Unreal build carries, which
+// only matter to crash analysis on the developer's machine
+var UnrealExclude = []string{
+	"*.debug",
+	"*.sym",
+	"*.pdb",
+	"Manifest_DebugFiles_*.txt",
+}
+
+// UnrealLaunchOptions writes the game log to Saved/Logs, where crashes on the
+// device can be diagnosed from
+const UnrealLaunchOptions = "-log"
+
+// detectUnreal looks for the Engine folder next to a "<Game>" project folder
+// with its Binaries. The packaged launcher in the build root sets up the
+// paths the game binary expects, so it is preferred over the binary itself.
+func detectUnreal(folder string, entries []os.DirEntry) *Layout {
+	files := make(map[string]bool)
+	hasEngine := false
+	for _, e := range entries {
+		if !e.IsDir() {
+			files[e.Name()] = true
+		} else if e.Name() == "Engine" {
+			hasEngine = true
+		}
+	}
+	if !hasEngine {
+		return nil
+	}
+
+	for _, e := range entries {
+		if !e.IsDir() || e.Name() == "Engine" {
+			continue
+		}
+		game := e.Name()
+		binaries := filepath.Join(folder, game, "Binaries")
+		if info, err := os.Stat(binaries); err != nil || !info.IsDir() {
+			continue
+		}
+
+		exe := ""
+		for _, launcher := range []string{game + ".sh", game + ".exe"} {
+			if files[launcher] {
+				exe = launcher
+				break
+			}
+		}
+		if exe == "" {
+			exe = unrealBinary(binaries, game)
+		}
+		if exe == "" {
+			continue
+		}
+		return &Layout{Engine: Unreal, Executable: exe, Exclude: UnrealExclude, LaunchOptions: UnrealLaunchOptions}
+	}
+	return nil
+}
+
+// unrealBinary finds the game binary of a build without a launcher, e.g.
+// "<Game>/Binaries/Linux/<Game>-Linux-Shipping"
+func unrealBinary(binaries, game string) string {
+	for _, platform := range []struct{ dir, ext string }{{"Linux", ""}, {"Win64", ".exe"}} {
+		entries, err := os.ReadDir(filepath.Join(binaries, platform.dir))
+		if err != nil {
+			continue
+		}
+		for _, e := range entries {
+			// Binaries also holds the debug symbols and libraries of the game
+			name := e.Name()
+			if e.IsDir() || !strings.HasPrefix(name, game) || filepath.Ext(name) != platform.ext {
+				continue
+			}
+			return path.Join(game, "Binaries", platform.dir, name)
+		}
+	}
+	return ""
+}
+
+// Excluded reports whether a slash separated relative path is left out of the
+// deploy. A pattern matches any element of the path, so excluding a folder
+// excludes everything in it.
+func Excluded(relPath string, patterns []string) bool {
+	if len(patterns) == 0 {
+		return false
+	}
+	for _, elem := range strings.Split(relPath, "/") {
+		for _, pattern := range patterns {
+			if ok, _ := path.Match(pattern, elem); ok {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// CheckPatterns reports the first malformed exclude pattern
+func CheckPatterns(patterns []string) error {
+	for _, pattern := range patterns {
+		if strings.Contains(pattern, "/") {
+			return fmt.Errorf("exclude pattern %q must be a file or folder name, not a path", pattern)
+		}
+		if _, err := path.Match(pattern, ""); err != nil {
+			return fmt.Errorf("invalid exclude pattern %q: %w", pattern, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,136 @@
+package engine
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// makeTree creates the files under root, directories ending in "/"
+func makeTree(t *testing.T, root string, paths ...string) {
+	t.Helper()
+	for _, p := range paths {
+		full := filepath.Join(root, filepath.FromSlash(p))
+		if p[len(p)-1] == '/' {
+			if err := os.MkdirAll(full, 0755); err != nil {
+				t.Fatal(err)
+			}
+			continue
+		}
+		if err := os.MkdirAll(filepath.Dir(full), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(full, nil, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestDetect_Unity(t *testing.T) {
+	tests := []struct {
+		name    string
+		files   []string
+		wantExe string
+	}{
+		{"linux", []string{"Capy.x86_64", "UnityPlayer.so", "Capy_Data/globalgamemanagers"}, "Capy.x86_64"},
+		{"windows", []string{"Capy.exe", "UnityPlayer.dll", "UnityCrashHandler64.exe", "Capy_Data/"}, "Capy.exe"},
+		{"both players", []string{"Capy.exe", "Capy.x86_64", "Capy_Data/data.unity3d"}, "Capy.x86_64"},
+		{"no player", []string{"Capy_Data/globalgamemanagers", "Other.x86_64"}, ""},
+		{"plain data folder", []string{"Capy.x86_64", "Capy_Data/"}, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			root := t.TempDir()
+			makeTree(t, root, tt.files...)
+
+			layout, err := Detect(root)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if tt.wantExe == "" {
+				if layout != nil {
+					t.Errorf("Detect() = %+v, want nil", layout)
+				}
+				return
+			}
+			if layout == nil || layout.Engine != Unity || layout.Executable != tt.wantExe {
+				t.Fatalf("Detect() = %+v, want Unity with %s", layout, tt.wantExe)
+			}
+			if len(layout.Exclude) == 0 {
+				t.Error("Unity layout has no exclude defaults")
+			}
+		})
+	}
+}
+
+func TestDetect_Unreal(t *testing.T) {
+	tests := []struct {
+		name    string
+		files   []string
+		wantExe string
+	}{
+		{"linux", []string{"Capy.sh", "Engine/Binaries/Linux/", "Capy/Binaries/Linux/Capy-Linux-Shipping", "Capy/Content/Paks/Capy-LinuxNoEditor.pak"}, "Capy.sh"},
+		{"windows", []string{"Capy.exe", "Engine/Binaries/ThirdParty/", "Capy/Binaries/Win64/Capy-Win64-Shipping.exe"}, "Capy.exe"},
+		{"no launcher", []string{"Engine/", "Capy/Binaries/Linux/Capy-Linux-Shipping.debug", "Capy/Binaries/Linux/Capy-Linux-Shipping"}, "Capy/Binaries/Linux/Capy-Linux-Shipping"},
+		{"no engine", []string{"Capy.sh", "Capy/Binaries/Linux/Capy-Linux-Shipping"}, ""},
+		{"no binaries", []string{"Capy.sh", "Engine/", "Capy/Content/"}, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			root := t.TempDir()
+			makeTree(t, root, tt.files...)
+
+			layout, err := Detect(root)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if tt.wantExe == "" {
+				if layout != nil {
+					t.Errorf("Detect() = %+v, want nil", layout)
+				}
+				return
+			}
+			if layout == nil || layout.Engine != Unreal || layout.Executable != tt.wantExe {
+				t.Fatalf("Detect() = %+v, want Unreal with %s", layout, tt.wantExe)
+			}
+			if layout.LaunchOptions == "" {
+				t.Error("Unreal layout has no launch options")
+			}
+		})
+	}
+}
+
+func TestExcluded(t *testing.T) {
+	tests := []struct {
+		path string
+		want bool
+	}{
+		{"Capy_BurstDebugInformation_DoNotShip/lib_burst_generated.txt", true},
+		{"Capy_BackUpThisFolder_ButDontShipItWithYourGame", true},
+		{"Capy_Data/Plugins/x86_64/lib.so", false},
+		{"Capy_Data/Logs/player.log", true},
+		{"Capy.x86_64", false},
+		{"Capy/Binaries/Linux/Capy-Linux-Shipping.debug", true},
+		{"Engine/Binaries/Linux/libUnrealGame.so", false},
+	}
+	patterns := append(append(UnityExclude, UnrealExclude...), "Logs")
+	for _, tt := range tests {
+		if got := Excluded(tt.path, patterns); got != tt.want {
+			t.Errorf("Excluded(%q) = %v, want %v", tt.path, got, tt.want)
+		}
+	}
+	if Excluded("Capy_Data/Logs/player.log", nil) {
+		t.Error("nothing is excluded without patterns")
+	}
+}
+
+func TestCheckPatterns(t *testing.T) {
+	if err := CheckPatterns(append(UnityExclude, "*.pdb")); err != nil {
+		t.Errorf("CheckPatterns() error = %v", err)
+	}
+	for _, bad := range []string{"[", "Saved/Logs"} {
+		if err := CheckPatterns([]string{bad}); err == nil {
+			t.Errorf("CheckPatterns(%q) = nil, want error", bad)
+		}
+	}
+}
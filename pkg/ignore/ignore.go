@@ -0,0 +1,143 @@
+// Package ignore matches paths against gitignore-style patterns, such as the
+// ones a game folder lists in its .devkitignore to keep files out of the deploy.
+package ignore
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// FileName is the ignore file looked up at the root of a game folder
+const FileName = ".devkitignore"
+
+type rule struct {
+	segments []string // slash separated elements of the pattern
+	negate   bool     // "!pattern" includes again what an earlier rule excluded
+	dirOnly  bool     // "pattern/" only matches folders
+	anchored bool     // a pattern with a slash matches from the root
+}
+
+// Matcher holds the rules of an ignore file. The zero value matches nothing.
+type Matcher struct {
+	rules []rule
+}
+
+// Parse reads gitignore-style patterns: blank lines and lines starting with
+// "#" are skipped, "!" negates, a trailing "/" matches only folders, a slash
+// elsewhere anchors the pattern to the root and "**" matches any number of
+// folders. Later rules override earlier ones.
+func Parse(r io.Reader) (*Matcher, error) {
+	m := &Matcher{}
+	scanner := bufio.NewScanner(r)
+	for n := 1; scanner.Scan(); n++ {
+		line := strings.TrimRight(scanner.Text(), " \t\r")
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		var rl rule
+		if strings.HasPrefix(line, "!") {
+			rl.negate = true
+			line = line[1:]
+		} else if strings.HasPrefix(line, `\`) {
+			// "\#" and "\!" start with a literal character
+			line = line[1:]
+		}
+		if strings.HasSuffix(line, "/") {
+			rl.dirOnly = true
+			line = strings.TrimRight(line, "/")
+		}
+		if strings.Contains(line, "/") {
+			rl.anchored = true
+			line = strings.TrimPrefix(line, "/")
+		}
+		if line == "" {
+			continue
+		}
+
+		rl.segments = strings.Split(line, "/")
+		for _, seg := range rl.segments {
+			if _, err := path.Match(seg, ""); err != nil {
+				return nil, fmt.Errorf("line %d: invalid pattern %q: %w", n, scanner.Text(), err)
+			}
+		}
+		m.rules = append(m.rules, rl)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// Load parses the ignore file of a folder. A folder without one gets an empty
+// matcher.
+func Load(root string) (*Matcher, error) {
+	f, err := os.Open(filepath.Join(root, FileName))
+	if os.IsNotExist(err) {
+		return &Matcher{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	m, err := Parse(f)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", FileName, err)
+	}
+	return m, nil
+}
+
+// Match reports whether a slash separated path relative to the root is
+// ignored. Like git, a file inside an ignored folder can't be included again,
+// so callers walking a tree should skip ignored folders.
+func (m *Matcher) Match(relPath string, isDir bool) bool {
+	if m == nil {
+		return false
+	}
+	elems := strings.Split(relPath, "/")
+	ignored := false
+	for _, rl := range m.rules {
+		if rl.dirOnly && !isDir {
+			continue
+		}
+		var ok bool
+		if rl.anchored {
+			ok = matchSegments(rl.segments, elems)
+		} else {
+			ok = matchSegments(rl.segments, elems[len(elems)-1:])
+		}
+		if ok {
+			ignored = !rl.negate
+		}
+	}
+	return ignored
+}
+
+// matchSegments matches path elements against pattern segments, "**"
+// standing for zero or more elements
+func matchSegments(pattern, elems []string) bool {
+	for len(pattern) > 0 {
+		if pattern[0] == "**" {
+			for i := 0; i <= len(elems); i++ {
+				if matchSegments(pattern[1:], elems[i:]) {
+					return true
+				}
+			}
+			return false
+		}
+		if len(elems) == 0 {
+			return false
+		}
+		if ok, _ := path.Match(pattern[0], elems[0]); !ok {
+			return false
+		}
+		pattern, elems = pattern[1:], elems[1:]
+	}
+	return len(elems) == 0
+}
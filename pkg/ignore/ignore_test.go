@@ -0,0 +1,81 @@
+package ignore
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestMatch(t *testing.T) {
+	m, err := Parse(strings.NewReader(`
+# Version control and caches
+.git/
+*.pdb
+/Saved
+Build/**/*.log
+**/Intermediate
+Logs/
+!Logs/keep.txt
+\#notes
+`))
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+
+	tests := []struct {
+		path  string
+		isDir bool
+		want  bool
+	}{
+		{".git", true, true},
+		{"Plugins/.git", true, true},
+		{".git", false, false},
+		{"Game.pdb", false, true},
+		{"Binaries/Win64/Game.pdb", false, true},
+		{"Saved", true, true},
+		{"Game/Saved", true, false},
+		{"Build/player.log", false, true},
+		{"Build/Logs/deep/player.log", false, true},
+		{"Other/player.log", false, false},
+		{"Intermediate", true, true},
+		{"Game/Source/Intermediate", true, true},
+		{"Logs", true, true},
+		{"Logs/keep.txt", false, false},
+		{"#notes", false, true},
+		{"Game.x86_64", false, false},
+	}
+	for _, tt := range tests {
+		if got := m.Match(tt.path, tt.isDir); got != tt.want {
+			t.Errorf("Match(%q, %v) = %v, want %v", tt.path, tt.isDir, got, tt.want)
+		}
+	}
+}
+
+func TestParse_InvalidPattern(t *testing.T) {
+	if _, err := Parse(strings.NewReader("*.pdb\nData/[\n")); err == nil || !strings.Contains(err.Error(), "line 2") {
+		t.Errorf("Parse() error = %v, want an error on line 2", err)
+	}
+}
+
+func TestLoad(t *testing.T) {
+	dir := t.TempDir()
+	m, err := Load(dir)
+	if err != nil {
+		t.Fatalf("Load() without an ignore file error = %v", err)
+	}
+	if m.Match("anything", false) {
+		t.Error("an empty matcher ignores nothing")
+	}
+
+	if err := os.WriteFile(filepath.Join(dir, FileName), []byte("*.debug\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	m, err = Load(dir)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if !m.Match("Game.debug", false) {
+		t.Error("Load() didn't read the patterns of the ignore file")
+	}
+}
@@ -0,0 +1,158 @@
+// Package logging provides the leveled logger of the hub: records go to a
+// rotating file and the most recent ones are kept in memory for the in-app
+// log viewer.
+package logging
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultRecent is the number of records kept in memory
+const DefaultRecent = 2000
+
+// Entry is a log record as shown in the log viewer
+type Entry struct {
+	Time    time.Time `json:"time"`
+	Level   string    `json:"level"`
+	Message string    `json:"message"`
+	// Attributes formatted as key=value, in the order they were logged
+	Attrs string `json:"attrs,omitempty"`
+}
+
+// Recent is a bounded buffer of the latest log entries
+type Recent struct {
+	mu      sync.Mutex
+	entries []Entry
+	next    int
+	full    bool
+}
+
+// NewRecent creates a buffer holding up to size entries
+func NewRecent(size int) *Recent {
+	if size <= 0 {
+		size = DefaultRecent
+	}
+	return &Recent{entries: make([]Entry, size)}
+}
+
+func (r *Recent) add(e Entry) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.entries[r.next] = e
+	r.next = (r.next + 1) % len(r.entries)
+	if r.next == 0 {
+		r.full = true
+	}
+}
+
+// Entries returns the buffered entries at or above minLevel, oldest first
+func (r *Recent) Entries(minLevel slog.Level) []Entry {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var ordered []Entry
+	if r.full {
+		ordered = append(ordered, r.entries[r.next:]...)
+	}
+	ordered = append(ordered, r.entries[:r.next]...)
+
+	result := make([]Entry, 0, len(ordered))
+	for _, e := range ordered {
+		if ParseLevel(e.Level) >= minLevel {
+			result = append(result, e)
+		}
+	}
+	return result
+}
+
+// ParseLevel converts a level name ("debug", "info", "warn", "error") to a
+// slog level, defaulting to info
+func ParseLevel(name string) slog.Level {
+	var level slog.Level
+	if err := level.UnmarshalText([]byte(strings.ToUpper(name))); err != nil {
+		return slog.LevelInfo
+	}
+	return level
+}
+
+// Handler is a slog.Handler that writes text records to w and keeps them in a
+// Recent buffer
+type Handler struct {
+	text   slog.Handler
+	recent *Recent
+	attrs  string // attributes added with WithAttrs, already formatted
+	group  string
+}
+
+// NewHandler creates a handler writing records at or above level to w
+func NewHandler(w io.Writer, level slog.Leveler, recent *Recent) *Handler {
+	return &Handler{
+		text:   slog.NewTextHandler(w, &slog.HandlerOptions{Level: level}),
+		recent: recent,
+	}
+}
+
+// Enabled reports whether the handler handles records at the given level
+func (h *Handler) Enabled(ctx context.Context, level slog.Level) bool {
+	return h.text.Enabled(ctx, level)
+}
+
+// Handle writes the record and adds it to the recent entries
+func (h *Handler) Handle(ctx context.Context, record slog.Record) error {
+	var attrs strings.Builder
+	attrs.WriteString(h.attrs)
+	record.Attrs(func(a slog.Attr) bool {
+		appendAttr(&attrs, h.group, a)
+		return true
+	})
+	h.recent.add(Entry{
+		Time:    record.Time,
+		Level:   record.Level.String(),
+		Message: record.Message,
+		Attrs:   attrs.String(),
+	})
+	return h.text.Handle(ctx, record)
+}
+
+// WithAttrs returns a handler that adds attrs to every record
+func (h *Handler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	var b strings.Builder
+	b.WriteString(h.attrs)
+	for _, a := range attrs {
+		appendAttr(&b, h.group, a)
+	}
+	return &Handler{text: h.text.WithAttrs(attrs), recent: h.recent, attrs: b.String(), group: h.group}
+}
+
+// WithGroup returns a handler that qualifies later attributes with name
+func (h *Handler) WithGroup(name string) slog.Handler {
+	group := name
+	if h.group != "" {
+		group = h.group + "." + name
+	}
+	return &Handler{text: h.text.WithGroup(name), recent: h.recent, attrs: h.attrs, group: group}
+}
+
+func appendAttr(b *strings.Builder, group string, a slog.Attr) {
+	if a.Equal(slog.Attr{}) {
+		return
+	}
+	key := a.Key
+	if group != "" {
+		key = group + "." + key
+	}
+	if b.Len() > 0 {
+		b.WriteByte(' ')
+	}
+	value := a.Value.Resolve().String()
+	if strings.ContainsAny(value, " \t\n\"=") {
+		value = fmt.Sprintf("%q", value)
+	}
+	b.WriteString(key + "=" + value)
+}
@@ -0,0 +1,101 @@
+package logging
+
+import (
+	"bytes"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestHandler_RecordsRecentEntries(t *testing.T) {
+	var buf bytes.Buffer
+	recent := NewRecent(10)
+	logger := slog.New(NewHandler(&buf, slog.LevelDebug, recent))
+
+	logger.Debug("scanning", "files", 3)
+	logger.With("device", "Steam Deck").Warn("upload failed", "file", "game data.pak")
+	logger.WithGroup("sgdb").Error("rate limited", "retry", 5)
+
+	all := recent.Entries(slog.LevelDebug)
+	if len(all) != 3 {
+		t.Fatalf("got %d entries, want 3", len(all))
+	}
+	if all[1].Level != "WARN" || all[1].Attrs != `device="Steam Deck" file="game data.pak"` {
+		t.Errorf("entry = %+v", all[1])
+	}
+	if all[2].Attrs != "sgdb.retry=5" {
+		t.Errorf("group attrs = %q, want sgdb.retry=5", all[2].Attrs)
+	}
+
+	if warnings := recent.Entries(slog.LevelWarn); len(warnings) != 2 {
+		t.Errorf("got %d entries at warn or above, want 2", len(warnings))
+	}
+	if !strings.Contains(buf.String(), `msg="upload failed"`) {
+		t.Errorf("record not written to the file: %s", buf.String())
+	}
+}
+
+func TestRecent_KeepsNewest(t *testing.T) {
+	recent := NewRecent(3)
+	logger := slog.New(NewHandler(&bytes.Buffer{}, slog.LevelInfo, recent))
+	for _, msg := range []string{"a", "b", "c", "d", "e"} {
+		logger.Info(msg)
+	}
+
+	entries := recent.Entries(slog.LevelDebug)
+	var got []string
+	for _, e := range entries {
+		got = append(got, e.Message)
+	}
+	if strings.Join(got, "") != "cde" {
+		t.Errorf("entries = %v, want [c d e]", got)
+	}
+}
+
+func TestParseLevel(t *testing.T) {
+	tests := map[string]slog.Level{
+		"debug": slog.LevelDebug,
+		"WARN":  slog.LevelWarn,
+		"error": slog.LevelError,
+		"bogus": slog.LevelInfo,
+	}
+	for name, want := range tests {
+		if got := ParseLevel(name); got != want {
+			t.Errorf("ParseLevel(%q) = %v, want %v", name, got, want)
+		}
+	}
+}
+
+func TestRotatingFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "logs", "hub.log")
+	r, err := OpenRotatingFile(path, 10, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+
+	for _, line := range []string{"first\n", "second\n", "third\n", "fourth\n"} {
+		if _, err := r.Write([]byte(line)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	read := func(p string) string {
+		data, _ := os.ReadFile(p)
+		return string(data)
+	}
+	if got := read(path); got != "fourth\n" {
+		t.Errorf("current = %q, want fourth", got)
+	}
+	if got := read(path + ".1"); got != "third\n" {
+		t.Errorf("backup 1 = %q, want third", got)
+	}
+	if got := read(path + ".2"); got != "second\n" {
+		t.Errorf("backup 2 = %q, want second", got)
+	}
+	if _, err := os.Stat(path + ".3"); !os.IsNotExist(err) {
+		t.Error("more backups kept than configured")
+	}
+}
@@ -0,0 +1,86 @@
+package steam
+
+import (
+	"regexp"
+	"strconv"
+)
+
+// compatToolMapping is the block of config.vdf that maps app IDs to the
+// compatibility tool chosen for them in the game's properties.
+var compatToolMapping = []string{"InstallConfigStore", "Software", "Valve", "Steam", "CompatToolMapping"}
+
+// CompatToolInfo is a compatibility tool declared by a compatibilitytool.vdf.
+type CompatToolInfo struct {
+	Name        string `json:"name"` // internal name, the one config.vdf refers to
+	DisplayName string `json:"displayName"`
+}
+
+// CompatToolFor returns the compatibility tool config.vdf maps to an app ID,
+// or "" when the app uses Steam's default.
+func CompatToolFor(config *KeyValues, appID uint32) string {
+	entry := config.Path(compatToolMapping...).Get(strconv.FormatUint(uint64(appID), 10))
+	if entry == nil {
+		return ""
+	}
+	if name := entry.Get("name"); name != nil {
+		return name.Value
+	}
+	return ""
+}
+
+// SetCompatTool maps an app ID to a compatibility tool in config.vdf, the way
+// Steam does when one is forced in the game's properties. An empty name goes
+// back to Steam's default.
+func SetCompatTool(config *KeyValues, appID uint32, name string) {
+	key := strconv.FormatUint(uint64(appID), 10)
+	if name == "" {
+		if mapping := config.Path(compatToolMapping...); mapping != nil {
+			mapping.Remove(key)
+		}
+		return
+	}
+
+	entry := config.Block(compatToolMapping...).Block(key)
+	entry.Set("name", name)
+	entry.Set("config", "")
+	entry.Set("priority", "250")
+}
+
+// CompatToolsFromManifest lists the tools a compatibilitytool.vdf declares.
+func CompatToolsFromManifest(manifest *KeyValues) []CompatToolInfo {
+	var tools []CompatToolInfo
+	for _, tool := range manifest.Path("compatibilitytools", "compat_tools").Children {
+		if !tool.IsBlock() {
+			continue
+		}
+		info := CompatToolInfo{Name: tool.Key, DisplayName: tool.Key}
+		if display := tool.Get("display_name"); display != nil && display.Value != "" {
+			info.DisplayName = display.Value
+		}
+		tools = append(tools, info)
+	}
+	return tools
+}
+
+// protonVersionDir matches the folders of Proton versions Steam installs, e.g. "Proton 8.0"
+var protonVersionDir = regexp.MustCompile(`^Proton (\d+)\.(\d+)$`)
+
+// OfficialProtonName returns the internal name of a Proton version Steam
+// installs in steamapps/common, from its folder name: "Proton 8.0" is
+// "proton_8" and "Proton 5.13" is "proton_513". Returns "" for other folders.
+func OfficialProtonName(dir string) string {
+	switch dir {
+	case "Proton - Experimental":
+		return "proton_experimental"
+	case "Proton Hotfix":
+		return "proton_hotfix"
+	}
+	m := protonVersionDir.FindStringSubmatch(dir)
+	if m == nil {
+		return ""
+	}
+	if m[2] == "0" {
+		return "proton_" + m[1]
+	}
+	return "proton_" + m[1] + m[2]
+}
@@ -0,0 +1,253 @@
+package steam
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+)
+
+// KeyValues is a node of Valve's text KeyValues format, the one config.vdf and
+// compatibilitytool.vdf are written in. A node holds either a string value or,
+// when Children is not nil, a block of child nodes.
+type KeyValues struct {
+	Key      string
+	Value    string
+	Children []*KeyValues
+}
+
+// ParseKeyValues parses a text KeyValues document into a root block holding
+// its top level nodes.
+func ParseKeyValues(data []byte) (*KeyValues, error) {
+	p := &kvParser{data: data}
+	root := &KeyValues{Children: []*KeyValues{}}
+	if err := p.parseBlock(root, false); err != nil {
+		return nil, err
+	}
+	return root, nil
+}
+
+// IsBlock reports whether the node holds children rather than a value.
+func (kv *KeyValues) IsBlock() bool {
+	return kv.Children != nil
+}
+
+// Get returns the child with this key, or nil. Keys are case insensitive, as in Steam.
+func (kv *KeyValues) Get(key string) *KeyValues {
+	if kv == nil {
+		return nil
+	}
+	for _, child := range kv.Children {
+		if strings.EqualFold(child.Key, key) {
+			return child
+		}
+	}
+	return nil
+}
+
+// Path follows a path of keys from this node, returning nil if any is missing.
+func (kv *KeyValues) Path(keys ...string) *KeyValues {
+	node := kv
+	for _, key := range keys {
+		node = node.Get(key)
+	}
+	return node
+}
+
+// Block returns the child block at the path of keys, creating the missing ones.
+func (kv *KeyValues) Block(keys ...string) *KeyValues {
+	node := kv
+	for _, key := range keys {
+		child := node.Get(key)
+		if child == nil {
+			child = &KeyValues{Key: key}
+			node.Children = append(node.Children, child)
+		}
+		if child.Children == nil {
+			child.Value = ""
+			child.Children = []*KeyValues{}
+		}
+		node = child
+	}
+	return node
+}
+
+// Set sets the string value of a child, adding it when missing.
+func (kv *KeyValues) Set(key, value string) {
+	if child := kv.Get(key); child != nil {
+		child.Value = value
+		child.Children = nil
+		return
+	}
+	kv.Children = append(kv.Children, &KeyValues{Key: key, Value: value})
+}
+
+// Remove deletes the child with this key, if any.
+func (kv *KeyValues) Remove(key string) {
+	for i, child := range kv.Children {
+		if strings.EqualFold(child.Key, key) {
+			kv.Children = append(kv.Children[:i], kv.Children[i+1:]...)
+			return
+		}
+	}
+}
+
+// Marshal writes the children of a root block in the layout Steam uses.
+func (kv *KeyValues) Marshal() []byte {
+	var buf bytes.Buffer
+	for _, child := range kv.Children {
+		child.write(&buf, 0)
+	}
+	return buf.Bytes()
+}
+
+func (kv *KeyValues) write(buf *bytes.Buffer, depth int) {
+	indent := strings.Repeat("\t", depth)
+	if !kv.IsBlock() {
+		fmt.Fprintf(buf, "%s%s\t\t%s\n", indent, quoteKV(kv.Key), quoteKV(kv.Value))
+		return
+	}
+	fmt.Fprintf(buf, "%s%s\n%s{\n", indent, quoteKV(kv.Key), indent)
+	for _, child := range kv.Children {
+		child.write(buf, depth+1)
+	}
+	fmt.Fprintf(buf, "%s}\n", indent)
+}
+
+// kvEscaper escapes the characters Steam escapes in quoted strings
+var kvEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`, "\t", `\t`)
+
+func quoteKV(s string) string {
+	return `"` + kvEscaper.Replace(s) + `"`
+}
+
+// kvParser reads KeyValues tokens: quoted or bare strings, braces, comments
+// and platform conditionals such as [$WIN32], which are skipped.
+type kvParser struct {
+	data []byte
+	pos  int
+	line int
+}
+
+func (p *kvParser) parseBlock(block *KeyValues, nested bool) error {
+	for {
+		key, kind, err := p.next()
+		if err != nil {
+			return err
+		}
+		switch kind {
+		case kvEOF:
+			if nested {
+				return p.errorf("unexpected end of file, missing '}'")
+			}
+			return nil
+		case kvClose:
+			if !nested {
+				return p.errorf("unexpected '}'")
+			}
+			return nil
+		case kvOpen:
+			return p.errorf("unexpected '{'")
+		}
+
+		value, kind, err := p.next()
+		if err != nil {
+			return err
+		}
+		switch kind {
+		case kvString:
+			block.Children = append(block.Children, &KeyValues{Key: key, Value: value})
+		case kvOpen:
+			child := &KeyValues{Key: key, Children: []*KeyValues{}}
+			if err := p.parseBlock(child, true); err != nil {
+				return err
+			}
+			block.Children = append(block.Children, child)
+		default:
+			return p.errorf("missing value for %q", key)
+		}
+	}
+}
+
+type kvToken int
+
+const (
+	kvEOF kvToken = iota
+	kvString
+	kvOpen
+	kvClose
+)
+
+func (p *kvParser) next() (string, kvToken, error) {
+	for p.pos < len(p.data) {
+		c := p.data[p.pos]
+		switch {
+		case c == '\n':
+			p.line++
+			p.pos++
+		case c == ' ' || c == '\t' || c == '\r':
+			p.pos++
+		case c == '/' && p.pos+1 < len(p.data) && p.data[p.pos+1] == '/':
+			for p.pos < len(p.data) && p.data[p.pos] != '\n' {
+				p.pos++
+			}
+		case c == '[':
+			for p.pos < len(p.data) && p.data[p.pos] != ']' {
+				p.pos++
+			}
+			p.pos++
+		case c == '{':
+			p.pos++
+			return "", kvOpen, nil
+		case c == '}':
+			p.pos++
+			return "", kvClose, nil
+		case c == '"':
+			s, err := p.quoted()
+			return s, kvString, err
+		default:
+			start := p.pos
+			for p.pos < len(p.data) && !strings.ContainsRune(" \t\r\n{}\"", rune(p.data[p.pos])) {
+				p.pos++
+			}
+			return string(p.data[start:p.pos]), kvString, nil
+		}
+	}
+	return "", kvEOF, nil
+}
+
+func (p *kvParser) quoted() (string, error) {
+	p.pos++ // opening quote
+	var sb strings.Builder
+	for p.pos < len(p.data) {
+		c := p.data[p.pos]
+		p.pos++
+		switch c {
+		case '"':
+			return sb.String(), nil
+		case '\n':
+			p.line++
+			sb.WriteByte(c)
+		case '\\':
+			if p.pos >= len(p.data) {
+				break
+			}
+			e := p.data[p.pos]
+			p.pos++
+			switch e {
+			case 'n':
+				sb.WriteByte('\n')
+			case 't':
+				sb.WriteByte('\t')
+			default:
+				sb.WriteByte(e)
+			}
+		default:
+			sb.WriteByte(c)
+		}
+	}
+	return "", p.errorf("unterminated string")
+}
+
+func (p *kvParser) errorf(format string, args ...any) error {
+	return fmt.Errorf("line %d: %s", p.line+1, fmt.Sprintf(format, args...))
+}
@@ -0,0 +1,135 @@
+package steam
+
+import "testing"
+
+const testConfigVDF = `"InstallConfigStore"
+{
+	"Software"
+	{
+		// Steam writes "valve" in lower case on some installs
+		"valve"
+		{
+			"Steam"
+			{
+				"AutoUpdateWindowEnabled"		"0"
+				"CompatToolMapping"
+				{
+					"0"
+					{
+						"name"		"proton_experimental"
+						"config"		""
+						"priority"		"75"
+					}
+				}
+				"Path"		"C:\\Program Files (x86)\\Steam"
+			}
+		}
+	}
+}
+`
+
+func TestParseKeyValues(t *testing.T) {
+	root, err := ParseKeyValues([]byte(testConfigVDF))
+	if err != nil {
+		t.Fatalf("ParseKeyValues() error = %v", err)
+	}
+
+	steam := root.Path("InstallConfigStore", "Software", "Valve", "Steam")
+	if steam == nil || !steam.IsBlock() {
+		t.Fatal("Steam block not found")
+	}
+	if got := steam.Get("path").Value; got != `C:\Program Files (x86)\Steam` {
+		t.Errorf("Path = %q", got)
+	}
+	if got := steam.Path("CompatToolMapping", "0", "priority").Value; got != "75" {
+		t.Errorf("priority = %q, want 75", got)
+	}
+
+	// What is written parses back to the same tree
+	again, err := ParseKeyValues(root.Marshal())
+	if err != nil {
+		t.Fatalf("ParseKeyValues(Marshal()) error = %v", err)
+	}
+	if string(again.Marshal()) != string(root.Marshal()) {
+		t.Errorf("Marshal() is not stable:\n%s", again.Marshal())
+	}
+}
+
+func TestParseKeyValues_Errors(t *testing.T) {
+	for _, doc := range []string{
+		`"a" { "b" "c"`,
+		`"a" "b" }`,
+		`"a" "unterminated`,
+		`"a"`,
+	} {
+		if _, err := ParseKeyValues([]byte(doc)); err == nil {
+			t.Errorf("ParseKeyValues(%q) = nil error", doc)
+		}
+	}
+}
+
+func TestSetCompatTool(t *testing.T) {
+	root, err := ParseKeyValues([]byte(testConfigVDF))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	SetCompatTool(root, 3123456789, "GE-Proton9-20")
+	if got := CompatToolFor(root, 3123456789); got != "GE-Proton9-20" {
+		t.Errorf("CompatToolFor() = %q, want GE-Proton9-20", got)
+	}
+	if got := CompatToolFor(root, 0); got != "proton_experimental" {
+		t.Errorf("other mappings changed, CompatToolFor(0) = %q", got)
+	}
+
+	SetCompatTool(root, 3123456789, "")
+	if got := CompatToolFor(root, 3123456789); got != "" {
+		t.Errorf("CompatToolFor() after reset = %q, want empty", got)
+	}
+
+	// A config without mappings gets the whole path created
+	empty := &KeyValues{Children: []*KeyValues{}}
+	SetCompatTool(empty, 42, "proton_9")
+	if got := CompatToolFor(empty, 42); got != "proton_9" {
+		t.Errorf("CompatToolFor() on new config = %q, want proton_9", got)
+	}
+}
+
+func TestCompatToolsFromManifest(t *testing.T) {
+	manifest, err := ParseKeyValues([]byte(`"compatibilitytools"
+{
+  "compat_tools"
+  {
+    "GE-Proton9-20" // Internal name of this tool
+    {
+      "install_path" "."
+      "display_name" "GE-Proton9-20 (custom)"
+      "from_oslist"  "windows"
+      "to_oslist"    "linux"
+    }
+  }
+}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	tools := CompatToolsFromManifest(manifest)
+	if len(tools) != 1 || tools[0].Name != "GE-Proton9-20" || tools[0].DisplayName != "GE-Proton9-20 (custom)" {
+		t.Errorf("CompatToolsFromManifest() = %+v", tools)
+	}
+}
+
+func TestOfficialProtonName(t *testing.T) {
+	tests := map[string]string{
+		"Proton 8.0":                   "proton_8",
+		"Proton 5.13":                  "proton_513",
+		"Proton - Experimental":        "proton_experimental",
+		"Proton Hotfix":                "proton_hotfix",
+		"Proton EasyAntiCheat Runtime": "",
+		"SteamLinuxRuntime_sniper":     "",
+	}
+	for dir, want := range tests {
+		if got := OfficialProtonName(dir); got != want {
+			t.Errorf("OfficialProtonName(%q) = %q, want %q", dir, got, want)
+		}
+	}
+}
@@ -0,0 +1,55 @@
+package steam
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// Logo pinned positions understood by the Steam library.
+const (
+	LogoUpperLeft    = "UpperLeft"
+	LogoUpperCenter  = "UpperCenter"
+	LogoCenterCenter = "CenterCenter"
+	LogoBottomLeft   = "BottomLeft"
+	LogoBottomCenter = "BottomCenter"
+)
+
+// LogoPosition is the placement of a logo over the hero image.
+// Width and height are percentages of the hero.
+type LogoPosition struct {
+	PinnedPosition string  `json:"pinnedPosition"`
+	WidthPct       float64 `json:"nWidthPct"`
+	HeightPct      float64 `json:"nHeightPct"`
+}
+
+// logoPositionFile is the content of the {appid}.json file in the grid directory.
+type logoPositionFile struct {
+	Version      int          `json:"nVersion"`
+	LogoPosition LogoPosition `json:"logoPosition"`
+}
+
+// Validate checks that the pinned position is known and the size is within 1-100%.
+func (p LogoPosition) Validate() error {
+	switch p.PinnedPosition {
+	case LogoUpperLeft, LogoUpperCenter, LogoCenterCenter, LogoBottomLeft, LogoBottomCenter:
+	default:
+		return fmt.Errorf("invalid logo position: %q", p.PinnedPosition)
+	}
+	if p.WidthPct <= 0 || p.WidthPct > 100 || p.HeightPct <= 0 || p.HeightPct > 100 {
+		return fmt.Errorf("logo size must be between 1 and 100 percent")
+	}
+	return nil
+}
+
+// LogoPositionFilename returns the grid directory filename holding the logo position.
+func LogoPositionFilename(appID uint32) string {
+	return fmt.Sprintf("%d.json", appID)
+}
+
+// MarshalLogoPosition encodes a logo position in the format Steam reads from the grid directory.
+func MarshalLogoPosition(pos LogoPosition) ([]byte, error) {
+	if err := pos.Validate(); err != nil {
+		return nil, err
+	}
+	return json.Marshal(logoPositionFile{Version: 1, LogoPosition: pos})
+}
@@ -0,0 +1,42 @@
+package steam
+
+import "testing"
+
+func TestMarshalLogoPosition(t *testing.T) {
+	data, err := MarshalLogoPosition(LogoPosition{PinnedPosition: LogoBottomLeft, WidthPct: 40, HeightPct: 60})
+	if err != nil {
+		t.Fatalf("MarshalLogoPosition() error = %v", err)
+	}
+
+	want := `{"nVersion":1,"logoPosition":{"pinnedPosition":"BottomLeft","nWidthPct":40,"nHeightPct":60}}`
+	if string(data) != want {
+		t.Errorf("MarshalLogoPosition() = %s, want %s", data, want)
+	}
+}
+
+func TestLogoPosition_Validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		pos     LogoPosition
+		wantErr bool
+	}{
+		{"valid", LogoPosition{PinnedPosition: LogoCenterCenter, WidthPct: 50, HeightPct: 50}, false},
+		{"unknown position", LogoPosition{PinnedPosition: "Left", WidthPct: 50, HeightPct: 50}, true},
+		{"zero width", LogoPosition{PinnedPosition: LogoUpperLeft, HeightPct: 50}, true},
+		{"over 100", LogoPosition{PinnedPosition: LogoUpperLeft, WidthPct: 120, HeightPct: 50}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.pos.Validate(); (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestLogoPositionFilename(t *testing.T) {
+	if got := LogoPositionFilename(12345); got != "12345.json" {
+		t.Errorf("LogoPositionFilename() = %q, want %q", got, "12345.json")
+	}
+}
@@ -92,6 +92,17 @@ const (
 	ArtworkPortrait                  // 600x900 vertical grid
 )
 
+// ArtworkFilename returns the grid folder filename Steam expects for an artwork type.
+func ArtworkFilename(appID uint32, artType ArtworkType, ext string) string {
+	return artworkFilename(appID, artType, ext)
+}
+
+// AnimatesWebP reports whether Steam plays animated WebP files in an artwork slot.
+// Capsules animate from WebP, the hero and logo need APNG and icons are always static.
+func AnimatesWebP(artType ArtworkType) bool {
+	return artType == ArtworkGrid || artType == ArtworkPortrait
+}
+
 // artworkFilename generates the filename for artwork based on type.
 func artworkFilename(appID uint32, artType ArtworkType, ext string) string {
 	switch artType {
@@ -217,6 +217,21 @@ func TestFormatFilename(t *testing.T) {
 	}
 }
 
+func TestAnimatesWebP(t *testing.T) {
+	tests := map[ArtworkType]bool{
+		ArtworkGrid:     true,
+		ArtworkPortrait: true,
+		ArtworkHero:     false,
+		ArtworkLogo:     false,
+		ArtworkIcon:     false,
+	}
+	for artType, want := range tests {
+		if got := AnimatesWebP(artType); got != want {
+			t.Errorf("AnimatesWebP(%d) = %v, want %v", artType, got, want)
+		}
+	}
+}
+
 func TestArtworkType_Constants(t *testing.T) {
 	// Verify artwork types have distinct values
 	types := []ArtworkType{
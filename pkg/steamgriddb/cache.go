@@ -0,0 +1,261 @@
+package steamgriddb
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Default image cache limits
+const (
+	DefaultCacheMaxBytes = 500 * 1024 * 1024
+	DefaultCacheTTL      = 30 * 24 * time.Hour
+)
+
+// metaSuffix is the extension of the sidecar file holding entry metadata
+const metaSuffix = ".meta"
+
+// cacheMeta is the metadata stored next to each cached image
+type cacheMeta struct {
+	URL         string    `json:"url"`
+	ContentType string    `json:"content_type"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+// ImageCache is a size-bounded on-disk image cache with LRU eviction.
+// Reads touch the entry's modification time so the least recently used
+// entries are evicted first. Entries older than the TTL are treated as misses.
+// Expired entries are removed by Evict, which StartJanitor runs in the
+// background; a Put only evicts once the cache goes over its size limit.
+type ImageCache struct {
+	dir      string
+	mu       sync.Mutex
+	maxBytes int64
+	ttl      time.Duration
+	// Bytes of the cached images, kept up to date by Put and eviction;
+	// -1 until the directory is first measured
+	size int64
+}
+
+// NewImageCache creates an image cache in dir. A maxBytes or ttl <= 0 disables that limit.
+func NewImageCache(dir string, maxBytes int64, ttl time.Duration) (*ImageCache, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, err
+	}
+	return &ImageCache{dir: dir, maxBytes: maxBytes, ttl: ttl, size: -1}, nil
+}
+
+// SetLimits updates the size and TTL limits
+func (c *ImageCache) SetLimits(maxBytes int64, ttl time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.maxBytes = maxBytes
+	c.ttl = ttl
+}
+
+// Dir returns the cache directory
+func (c *ImageCache) Dir() string {
+	return c.dir
+}
+
+// cacheKey returns the file name used to store a URL
+func cacheKey(url string) string {
+	hash := sha256.Sum256([]byte(url))
+	return hex.EncodeToString(hash[:])
+}
+
+// Get returns the cached data and content type for a URL
+func (c *ImageCache) Get(url string) ([]byte, string, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	dataPath := filepath.Join(c.dir, cacheKey(url))
+	meta, err := readMeta(dataPath + metaSuffix)
+	if err != nil || meta.URL != url {
+		return nil, "", false
+	}
+
+	if c.ttl > 0 && time.Since(meta.CreatedAt) > c.ttl {
+		c.removeLocked(dataPath)
+		return nil, "", false
+	}
+
+	data, err := os.ReadFile(dataPath)
+	if err != nil {
+		return nil, "", false
+	}
+
+	// Touch on read for LRU ordering
+	now := time.Now()
+	os.Chtimes(dataPath, now, now)
+
+	return data, meta.ContentType, true
+}
+
+// Has reports whether a URL is cached and not expired, without touching the entry
+func (c *ImageCache) Has(url string) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	dataPath := filepath.Join(c.dir, cacheKey(url))
+	meta, err := readMeta(dataPath + metaSuffix)
+	if err != nil || meta.URL != url {
+		return false
+	}
+	if c.ttl > 0 && time.Since(meta.CreatedAt) > c.ttl {
+		return false
+	}
+	_, err = os.Stat(dataPath)
+	return err == nil
+}
+
+// Put stores data for a URL and evicts the least recently used entries if the
+// cache goes over its size limit. The error is about storing the image only.
+func (c *ImageCache) Put(url string, data []byte, contentType string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	// The directory is measured once, later sizes are tracked
+	if c.size < 0 {
+		c.evictLocked()
+	}
+
+	dataPath := filepath.Join(c.dir, cacheKey(url))
+	var replaced int64
+	if info, err := os.Stat(dataPath); err == nil {
+		replaced = info.Size()
+	}
+	if err := os.WriteFile(dataPath, data, 0644); err != nil {
+		return err
+	}
+	c.size += int64(len(data)) - replaced
+
+	meta, err := json.Marshal(cacheMeta{URL: url, ContentType: contentType, CreatedAt: time.Now()})
+	if err != nil {
+		c.removeLocked(dataPath)
+		return err
+	}
+	if err := os.WriteFile(dataPath+metaSuffix, meta, 0644); err != nil {
+		c.removeLocked(dataPath)
+		return err
+	}
+
+	if c.maxBytes > 0 && c.size > c.maxBytes {
+		c.evictLocked()
+	}
+	return nil
+}
+
+// Evict removes expired entries and trims the cache to its size limit
+func (c *ImageCache) Evict() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.evictLocked()
+}
+
+type cacheEntry struct {
+	path    string
+	size    int64
+	modTime time.Time
+}
+
+func (c *ImageCache) evictLocked() error {
+	entries, err := os.ReadDir(c.dir)
+	if err != nil {
+		return err
+	}
+
+	var files []cacheEntry
+	var total int64
+	for _, entry := range entries {
+		if entry.IsDir() || strings.HasSuffix(entry.Name(), metaSuffix) {
+			continue
+		}
+		path := filepath.Join(c.dir, entry.Name())
+
+		if c.ttl > 0 {
+			if meta, err := readMeta(path + metaSuffix); err != nil || time.Since(meta.CreatedAt) > c.ttl {
+				removeEntry(path)
+				continue
+			}
+		}
+
+		info, err := entry.Info()
+		if err != nil {
+			continue
+		}
+		files = append(files, cacheEntry{path: path, size: info.Size(), modTime: info.ModTime()})
+		total += info.Size()
+	}
+
+	c.size = total
+	if c.maxBytes <= 0 || total <= c.maxBytes {
+		return nil
+	}
+
+	// Oldest access first
+	sort.Slice(files, func(i, j int) bool {
+		return files[i].modTime.Before(files[j].modTime)
+	})
+
+	for _, f := range files {
+		if total <= c.maxBytes {
+			break
+		}
+		removeEntry(f.path)
+		total -= f.size
+	}
+	c.size = total
+
+	return nil
+}
+
+// StartJanitor periodically evicts entries until ctx is cancelled
+func (c *ImageCache) StartJanitor(ctx context.Context, interval time.Duration) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		c.Evict()
+		for {
+			select {
+			case <-ticker.C:
+				c.Evict()
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+}
+
+func readMeta(path string) (*cacheMeta, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var meta cacheMeta
+	if err := json.Unmarshal(data, &meta); err != nil {
+		return nil, err
+	}
+	return &meta, nil
+}
+
+// removeLocked removes an entry and takes its size off the cache's
+func (c *ImageCache) removeLocked(dataPath string) {
+	if info, err := os.Stat(dataPath); err == nil && c.size >= 0 {
+		c.size -= info.Size()
+	}
+	removeEntry(dataPath)
+}
+
+func removeEntry(dataPath string) {
+	os.Remove(dataPath)
+	os.Remove(dataPath + metaSuffix)
+}
@@ -0,0 +1,132 @@
+package steamgriddb
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestImageCache_PutGet(t *testing.T) {
+	cache, err := NewImageCache(t.TempDir(), 0, 0)
+	if err != nil {
+		t.Fatalf("NewImageCache() error = %v", err)
+	}
+
+	url := "https://cdn.example.com/grid.png"
+	if err := cache.Put(url, []byte("png-data"), "image/png"); err != nil {
+		t.Fatalf("Put() error = %v", err)
+	}
+
+	data, contentType, ok := cache.Get(url)
+	if !ok {
+		t.Fatal("Get() should hit after Put()")
+	}
+	if string(data) != "png-data" {
+		t.Errorf("data = %q, want %q", data, "png-data")
+	}
+	if contentType != "image/png" {
+		t.Errorf("contentType = %q, want %q", contentType, "image/png")
+	}
+
+	if _, _, ok := cache.Get("https://cdn.example.com/other.png"); ok {
+		t.Error("Get() should miss for unknown URL")
+	}
+}
+
+func TestImageCache_TTL(t *testing.T) {
+	cache, err := NewImageCache(t.TempDir(), 0, time.Hour)
+	if err != nil {
+		t.Fatalf("NewImageCache() error = %v", err)
+	}
+
+	url := "https://cdn.example.com/hero.png"
+	if err := cache.Put(url, []byte("data"), "image/png"); err != nil {
+		t.Fatalf("Put() error = %v", err)
+	}
+
+	// Rewrite metadata as if the entry was created two hours ago
+	metaPath := filepath.Join(cache.Dir(), cacheKey(url)+metaSuffix)
+	old := `{"url":"` + url + `","content_type":"image/png","created_at":"` +
+		time.Now().Add(-2*time.Hour).Format(time.RFC3339) + `"}`
+	if err := os.WriteFile(metaPath, []byte(old), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, _, ok := cache.Get(url); ok {
+		t.Error("Get() should miss for expired entry")
+	}
+}
+
+func TestImageCache_EvictsLeastRecentlyUsed(t *testing.T) {
+	cache, err := NewImageCache(t.TempDir(), 0, 0)
+	if err != nil {
+		t.Fatalf("NewImageCache() error = %v", err)
+	}
+
+	urls := []string{"https://a/1.png", "https://a/2.png", "https://a/3.png"}
+	for i, url := range urls {
+		if err := cache.Put(url, make([]byte, 10), "image/png"); err != nil {
+			t.Fatalf("Put() error = %v", err)
+		}
+		// Spread access times so ordering is deterministic
+		past := time.Now().Add(time.Duration(i-10) * time.Minute)
+		os.Chtimes(filepath.Join(cache.Dir(), cacheKey(url)), past, past)
+	}
+
+	// Budget fits two of the three entries
+	cache.SetLimits(25, 0)
+
+	// Touch the oldest entry so the second one becomes least recently used
+	if _, _, ok := cache.Get(urls[0]); !ok {
+		t.Fatal("first entry should still be cached")
+	}
+
+	if err := cache.Evict(); err != nil {
+		t.Fatalf("Evict() error = %v", err)
+	}
+
+	if _, _, ok := cache.Get(urls[1]); ok {
+		t.Error("least recently used entry should have been evicted")
+	}
+	if _, _, ok := cache.Get(urls[0]); !ok {
+		t.Error("recently read entry should be kept")
+	}
+}
+
+func TestImageCache_PutEvictsOverBudget(t *testing.T) {
+	dir := t.TempDir()
+	// An image cached by an earlier run counts towards the budget
+	if err := os.WriteFile(filepath.Join(dir, cacheKey("https://a/old.png")), make([]byte, 10), 0644); err != nil {
+		t.Fatal(err)
+	}
+	past := time.Now().Add(-time.Hour)
+	os.Chtimes(filepath.Join(dir, cacheKey("https://a/old.png")), past, past)
+
+	cache, err := NewImageCache(dir, 25, 0)
+	if err != nil {
+		t.Fatalf("NewImageCache() error = %v", err)
+	}
+
+	// Replacing an image doesn't count it twice
+	for range 3 {
+		if err := cache.Put("https://a/1.png", make([]byte, 10), "image/png"); err != nil {
+			t.Fatalf("Put() error = %v", err)
+		}
+	}
+	if _, err := os.Stat(filepath.Join(dir, cacheKey("https://a/old.png"))); err != nil {
+		t.Errorf("entry evicted while the cache was under budget: %v", err)
+	}
+
+	if err := cache.Put("https://a/2.png", make([]byte, 10), "image/png"); err != nil {
+		t.Fatalf("Put() error = %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, cacheKey("https://a/old.png"))); !os.IsNotExist(err) {
+		t.Errorf("least recently used entry should have been evicted: %v", err)
+	}
+	for _, url := range []string{"https://a/1.png", "https://a/2.png"} {
+		if !cache.Has(url) {
+			t.Errorf("%s should be kept", url)
+		}
+	}
+}
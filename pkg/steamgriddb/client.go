@@ -1,59 +1,262 @@
 package steamgriddb
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
+	"log/slog"
 	"net/http"
 	"net/url"
 	"os"
 	"path/filepath"
 	"strconv"
+	"strings"
+	"sync"
+	"time"
 )
 
 const baseURL = "https://www.steamgriddb.com/api/v2"
 
-// Client is a SteamGridDB API client
+// defaultPageLimit is the page size used by SteamGridDB image endpoints
+const defaultPageLimit = 50
+
+// defaultTimeout bounds each API request, including reading the response
+const defaultTimeout = 30 * time.Second
+
+// Rate limit handling
+const (
+	defaultMaxRetries = 5
+	maxRetryWait      = 60 * time.Second
+)
+
+// RateLimitError is returned when SteamGridDB is still rate limiting after all retries
+type RateLimitError struct {
+	RetryAfter time.Duration
+}
+
+func (e *RateLimitError) Error() string {
+	return fmt.Sprintf("SteamGridDB rate limit reached, try again in %d seconds", int(e.RetryAfter.Seconds()))
+}
+
+// APIError is returned for non-successful SteamGridDB responses
+type APIError struct {
+	StatusCode int
+	Body       string
+}
+
+func (e *APIError) Error() string {
+	return fmt.Sprintf("API error %d: %s", e.StatusCode, e.Body)
+}
+
+// rateLimitGate pauses every client while SteamGridDB is rate limiting, so
+// pending requests queue up instead of each hitting the API and failing
+var rateLimitGate struct {
+	mu    sync.Mutex
+	until time.Time
+}
+
+// Client is a SteamGridDB API client. Every request method takes a context
+// that cancels the request, including any wait for a rate limit to clear.
 type Client struct {
-	apiKey     string
-	httpClient http.Client
+	apiKey      string
+	httpClient  *http.Client
+	baseURL     string
+	maxRetries  int
+	onRateLimit func(wait time.Duration)
+}
+
+// Option configures a Client
+type Option func(*Client)
+
+// WithHTTPClient makes the client send requests through httpClient
+func WithHTTPClient(httpClient *http.Client) Option {
+	return func(c *Client) {
+		if httpClient != nil {
+			c.httpClient = httpClient
+		}
+	}
+}
+
+// WithTimeout sets the timeout of each request (30 seconds by default)
+func WithTimeout(timeout time.Duration) Option {
+	return func(c *Client) {
+		client := *c.httpClient
+		client.Timeout = timeout
+		c.httpClient = &client
+	}
+}
+
+// WithBaseURL points the client at another API root, e.g. a mirror or test server
+func WithBaseURL(url string) Option {
+	return func(c *Client) {
+		c.baseURL = strings.TrimSuffix(url, "/")
+	}
+}
+
+// WithMaxRetries sets how many times a rate limited request is retried
+func WithMaxRetries(retries int) Option {
+	return func(c *Client) {
+		if retries >= 0 {
+			c.maxRetries = retries
+		}
+	}
+}
+
+// WithRateLimitHandler sets the callback described in SetRateLimitHandler
+func WithRateLimitHandler(fn func(wait time.Duration)) Option {
+	return func(c *Client) {
+		c.onRateLimit = fn
+	}
 }
 
 // NewClient creates a new SteamGridDB client
-func NewClient(apiKey string) *Client {
-	return &Client{apiKey: apiKey}
+func NewClient(apiKey string, opts ...Option) *Client {
+	c := &Client{
+		apiKey:     apiKey,
+		httpClient: &http.Client{Timeout: defaultTimeout},
+		baseURL:    baseURL,
+		maxRetries: defaultMaxRetries,
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
+}
+
+// SetRateLimitHandler sets a callback invoked with the wait time whenever a
+// request is delayed by rate limiting (e.g. to show a countdown)
+func (c *Client) SetRateLimitHandler(fn func(wait time.Duration)) {
+	c.onRateLimit = fn
 }
 
-func (c *Client) get(endpoint string, params url.Values) ([]byte, error) {
-	reqURL := baseURL + endpoint
+func (c *Client) get(ctx context.Context, endpoint string, params url.Values) ([]byte, error) {
+	reqURL := c.baseURL + endpoint
 	if len(params) > 0 {
 		reqURL += "?" + params.Encode()
 	}
 
-	req, err := http.NewRequest("GET", reqURL, nil)
-	if err != nil {
-		return nil, err
+	for attempt := 0; ; attempt++ {
+		if err := c.waitRateLimit(ctx); err != nil {
+			return nil, err
+		}
+
+		req, err := http.NewRequestWithContext(ctx, "GET", reqURL, nil)
+		if err != nil {
+			return nil, err
+		}
+		req.Header.Set("Authorization", "Bearer "+c.apiKey)
+
+		resp, err := c.httpClient.Do(req)
+		if err != nil {
+			return nil, err
+		}
+
+		body, _ := io.ReadAll(resp.Body)
+		resp.Body.Close()
+
+		if resp.StatusCode == http.StatusTooManyRequests {
+			wait := parseRetryAfter(resp.Header.Get("Retry-After"), attempt)
+			if attempt >= c.maxRetries {
+				return nil, &RateLimitError{RetryAfter: wait}
+			}
+			pauseRateLimit(wait)
+			continue
+		}
+
+		if resp.StatusCode != 200 {
+			return nil, &APIError{StatusCode: resp.StatusCode, Body: string(body)}
+		}
+
+		return body, nil
 	}
-	req.Header.Set("Authorization", "Bearer "+c.apiKey)
+}
 
-	resp, err := c.httpClient.Do(req)
-	if err != nil {
-		return nil, err
+// waitRateLimit blocks while the shared rate limit pause is active, or until ctx is done
+func (c *Client) waitRateLimit(ctx context.Context) error {
+	rateLimitGate.mu.Lock()
+	wait := time.Until(rateLimitGate.until)
+	rateLimitGate.mu.Unlock()
+
+	if wait <= 0 {
+		return ctx.Err()
 	}
-	defer resp.Body.Close()
+	if c.onRateLimit != nil {
+		c.onRateLimit(wait)
+	}
+
+	timer := time.NewTimer(wait)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// pauseRateLimit extends the shared pause so all clients wait at least d
+func pauseRateLimit(d time.Duration) {
+	rateLimitGate.mu.Lock()
+	defer rateLimitGate.mu.Unlock()
+	if until := time.Now().Add(d); until.After(rateLimitGate.until) {
+		rateLimitGate.until = until
+	}
+}
 
-	body, _ := io.ReadAll(resp.Body)
+// parseRetryAfter returns how long to wait from a Retry-After header (seconds
+// or HTTP date), falling back to exponential backoff when it is missing
+func parseRetryAfter(header string, attempt int) time.Duration {
+	var wait time.Duration
+	if secs, err := strconv.Atoi(strings.TrimSpace(header)); err == nil && secs >= 0 {
+		wait = time.Duration(secs) * time.Second
+	} else if date, err := http.ParseTime(header); err == nil {
+		wait = time.Until(date)
+		if wait < 0 {
+			wait = 0
+		}
+	} else {
+		wait = time.Second << uint(attempt)
+	}
+
+	if wait > maxRetryWait {
+		wait = maxRetryWait
+	}
+	return wait
+}
+
+// ValidateKey performs an authenticated request to check that the API key works.
+// Returns nil if the key is valid, or an error describing why it is not.
+func (c *Client) ValidateKey(ctx context.Context) error {
+	if strings.TrimSpace(c.apiKey) == "" {
+		return fmt.Errorf("API key is empty")
+	}
 
-	if resp.StatusCode != 200 {
-		return nil, fmt.Errorf("API error %d: %s", resp.StatusCode, string(body))
+	_, err := c.get(ctx, "/search/autocomplete/"+url.PathEscape("steam"), nil)
+	if err == nil {
+		return nil
 	}
 
-	return body, nil
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
+		switch apiErr.StatusCode {
+		case http.StatusUnauthorized:
+			return fmt.Errorf("invalid API key")
+		case http.StatusForbidden:
+			return fmt.Errorf("API key is not allowed to access the API")
+		default:
+			if apiErr.StatusCode >= 500 {
+				return fmt.Errorf("SteamGridDB is unavailable (HTTP %d), try again later", apiErr.StatusCode)
+			}
+		}
+	}
+	return err
 }
 
 // Search searches for games by name
-func (c *Client) Search(term string) ([]SearchResult, error) {
-	body, err := c.get("/search/autocomplete/"+url.PathEscape(term), nil)
+func (c *Client) Search(ctx context.Context, term string) ([]SearchResult, error) {
+	body, err := c.get(ctx, "/search/autocomplete/"+url.PathEscape(term), nil)
 	if err != nil {
 		return nil, err
 	}
@@ -67,9 +270,18 @@ func (c *Client) Search(term string) ([]SearchResult, error) {
 }
 
 // GetGrids returns grid images for a game
-func (c *Client) GetGrids(gameID int, filters *ImageFilters, page int) ([]GridData, error) {
+func (c *Client) GetGrids(ctx context.Context, gameID int, filters *ImageFilters, page int) ([]GridData, error) {
+	result, err := c.GetGridsPage(ctx, gameID, filters, page)
+	if err != nil {
+		return nil, err
+	}
+	return result.Items, nil
+}
+
+// GetGridsPage returns a page of grid images with paging metadata
+func (c *Client) GetGridsPage(ctx context.Context, gameID int, filters *ImageFilters, page int) (*GridPage, error) {
 	params := buildParams(filters, page)
-	body, err := c.get(fmt.Sprintf("/grids/game/%d", gameID), params)
+	body, err := c.get(ctx, fmt.Sprintf("/grids/game/%d", gameID), params)
 	if err != nil {
 		return nil, err
 	}
@@ -81,33 +293,58 @@ func (c *Client) GetGrids(gameID int, filters *ImageFilters, page int) ([]GridDa
 
 	// Debug: log first result
 	if len(resp.Data) > 0 {
-		fmt.Printf("[DEBUG] First grid: URL=%s, Thumb=%s, %dx%d\n",
-			resp.Data[0].URL, resp.Data[0].Thumb, resp.Data[0].Width, resp.Data[0].Height)
+		slog.Debug("First grid",
+			"url", resp.Data[0].URL, "thumb", resp.Data[0].Thumb,
+			"width", resp.Data[0].Width, "height", resp.Data[0].Height)
 	}
 
-	return resp.Data, nil
+	return &GridPage{
+		Items:  resp.Data,
+		Paging: newPageInfo(page, resp.pagingResponse, len(resp.Data)),
+	}, nil
 }
 
 // GetHeroes returns hero images for a game
-func (c *Client) GetHeroes(gameID int, filters *ImageFilters, page int) ([]ImageData, error) {
-	params := buildParams(filters, page)
-	body, err := c.get(fmt.Sprintf("/heroes/game/%d", gameID), params)
-	if err != nil {
-		return nil, err
-	}
+func (c *Client) GetHeroes(ctx context.Context, gameID int, filters *ImageFilters, page int) ([]ImageData, error) {
+	return c.getImages(ctx, "heroes", gameID, filters, page)
+}
 
-	var resp imageResponse
-	if err := json.Unmarshal(body, &resp); err != nil {
+// GetHeroesPage returns a page of hero images with paging metadata
+func (c *Client) GetHeroesPage(ctx context.Context, gameID int, filters *ImageFilters, page int) (*ImagePage, error) {
+	return c.getImagesPage(ctx, "heroes", gameID, filters, page)
+}
+
+// GetLogos returns logo images for a game
+func (c *Client) GetLogos(ctx context.Context, gameID int, filters *ImageFilters, page int) ([]ImageData, error) {
+	return c.getImages(ctx, "logos", gameID, filters, page)
+}
+
+// GetLogosPage returns a page of logo images with paging metadata
+func (c *Client) GetLogosPage(ctx context.Context, gameID int, filters *ImageFilters, page int) (*ImagePage, error) {
+	return c.getImagesPage(ctx, "logos", gameID, filters, page)
+}
+
+// GetIcons returns icon images for a game
+func (c *Client) GetIcons(ctx context.Context, gameID int, filters *ImageFilters, page int) ([]ImageData, error) {
+	return c.getImages(ctx, "icons", gameID, filters, page)
+}
+
+// GetIconsPage returns a page of icon images with paging metadata
+func (c *Client) GetIconsPage(ctx context.Context, gameID int, filters *ImageFilters, page int) (*ImagePage, error) {
+	return c.getImagesPage(ctx, "icons", gameID, filters, page)
+}
+
+func (c *Client) getImages(ctx context.Context, kind string, gameID int, filters *ImageFilters, page int) ([]ImageData, error) {
+	result, err := c.getImagesPage(ctx, kind, gameID, filters, page)
+	if err != nil {
 		return nil, err
 	}
-
-	return resp.Data, nil
+	return result.Items, nil
 }
 
-// GetLogos returns logo images for a game
-func (c *Client) GetLogos(gameID int, filters *ImageFilters, page int) ([]ImageData, error) {
+func (c *Client) getImagesPage(ctx context.Context, kind string, gameID int, filters *ImageFilters, page int) (*ImagePage, error) {
 	params := buildParams(filters, page)
-	body, err := c.get(fmt.Sprintf("/logos/game/%d", gameID), params)
+	body, err := c.get(ctx, fmt.Sprintf("/%s/game/%d", kind, gameID), params)
 	if err != nil {
 		return nil, err
 	}
@@ -117,23 +354,47 @@ func (c *Client) GetLogos(gameID int, filters *ImageFilters, page int) ([]ImageD
 		return nil, err
 	}
 
-	return resp.Data, nil
+	return &ImagePage{
+		Items:  resp.Data,
+		Paging: newPageInfo(page, resp.pagingResponse, len(resp.Data)),
+	}, nil
 }
 
-// GetIcons returns icon images for a game
-func (c *Client) GetIcons(gameID int, filters *ImageFilters, page int) ([]ImageData, error) {
-	params := buildParams(filters, page)
-	body, err := c.get(fmt.Sprintf("/icons/game/%d", gameID), params)
+// GetGame returns game details including external platform IDs
+func (c *Client) GetGame(ctx context.Context, gameID int) (*GameDetails, error) {
+	params := url.Values{}
+	params.Set("platformdata", "steam")
+	body, err := c.get(ctx, fmt.Sprintf("/games/id/%d", gameID), params)
 	if err != nil {
 		return nil, err
 	}
 
-	var resp imageResponse
+	var resp gameResponse
 	if err := json.Unmarshal(body, &resp); err != nil {
 		return nil, err
 	}
 
-	return resp.Data, nil
+	return &resp.Data, nil
+}
+
+// newPageInfo builds paging info from the API metadata. If the API omits the
+// total, another page is assumed to exist whenever the current one is full.
+func newPageInfo(page int, meta pagingResponse, count int) PageInfo {
+	limit := meta.Limit
+	if limit <= 0 {
+		limit = defaultPageLimit
+	}
+
+	info := PageInfo{Page: page, Limit: limit, Total: meta.Total}
+	switch {
+	case meta.Total > 0:
+		info.TotalPages = (meta.Total + limit - 1) / limit
+	case count >= limit:
+		info.TotalPages = page + 2
+	default:
+		info.TotalPages = page + 1
+	}
+	return info
 }
 
 func buildParams(filters *ImageFilters, page int) url.Values {
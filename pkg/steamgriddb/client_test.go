@@ -0,0 +1,231 @@
+package steamgriddb
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestNewPageInfo(t *testing.T) {
+	tests := []struct {
+		name  string
+		page  int
+		meta  pagingResponse
+		count int
+		want  PageInfo
+	}{
+		{
+			name:  "total from api",
+			page:  1,
+			meta:  pagingResponse{Page: 1, Total: 601, Limit: 50},
+			count: 50,
+			want:  PageInfo{Page: 1, Limit: 50, Total: 601, TotalPages: 13},
+		},
+		{
+			name:  "exact multiple",
+			page:  0,
+			meta:  pagingResponse{Total: 100, Limit: 50},
+			count: 50,
+			want:  PageInfo{Page: 0, Limit: 50, Total: 100, TotalPages: 2},
+		},
+		{
+			name:  "no total, full page",
+			page:  2,
+			count: 50,
+			want:  PageInfo{Page: 2, Limit: 50, TotalPages: 4},
+		},
+		{
+			name:  "no total, partial page",
+			page:  2,
+			count: 10,
+			want:  PageInfo{Page: 2, Limit: 50, TotalPages: 3},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := newPageInfo(tt.page, tt.meta, tt.count); got != tt.want {
+				t.Errorf("newPageInfo() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGridResponse_Paging(t *testing.T) {
+	body := `{"success":true,"page":0,"total":120,"limit":50,"data":[{"id":1,"url":"https://cdn/1.png"}]}`
+
+	var resp gridResponse
+	if err := json.Unmarshal([]byte(body), &resp); err != nil {
+		t.Fatalf("Unmarshal() error = %v", err)
+	}
+
+	if resp.Total != 120 || resp.Limit != 50 {
+		t.Errorf("paging = %+v, want total 120 limit 50", resp.pagingResponse)
+	}
+	if len(resp.Data) != 1 || resp.Data[0].ID != 1 {
+		t.Errorf("Data = %+v", resp.Data)
+	}
+}
+
+func TestGridData_Author(t *testing.T) {
+	body := `{"id":1,"score":4,"upvotes":5,"downvotes":1,"language":"en","author":{"name":"artist","steam64":"765","avatar":"https://cdn/a.jpg"}}`
+
+	var grid GridData
+	if err := json.Unmarshal([]byte(body), &grid); err != nil {
+		t.Fatalf("Unmarshal() error = %v", err)
+	}
+	if grid.Author == nil || grid.Author.Name != "artist" {
+		t.Errorf("Author = %+v, want artist", grid.Author)
+	}
+	if grid.Upvotes != 5 || grid.Downvotes != 1 || grid.Language != "en" {
+		t.Errorf("grid = %+v", grid)
+	}
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	tests := []struct {
+		name    string
+		header  string
+		attempt int
+		want    time.Duration
+	}{
+		{"seconds", "7", 0, 7 * time.Second},
+		{"zero", "0", 3, 0},
+		{"missing uses backoff", "", 2, 4 * time.Second},
+		{"invalid uses backoff", "soon", 0, time.Second},
+		{"capped", "3600", 0, maxRetryWait},
+		{"past date", "Mon, 01 Jan 2001 00:00:00 GMT", 0, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := parseRetryAfter(tt.header, tt.attempt); got != tt.want {
+				t.Errorf("parseRetryAfter(%q, %d) = %v, want %v", tt.header, tt.attempt, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestClient_RetriesOnRateLimit(t *testing.T) {
+	var calls int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&calls, 1) == 1 {
+			w.Header().Set("Retry-After", "0")
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		w.Write([]byte(`{"success":true,"data":[{"id":7,"name":"Game"}]}`))
+	}))
+	defer srv.Close()
+
+	c := NewClient("key")
+	c.baseURL = srv.URL
+
+	results, err := c.Search(context.Background(), "game")
+	if err != nil {
+		t.Fatalf("Search() error = %v", err)
+	}
+	if len(results) != 1 || results[0].ID != 7 {
+		t.Errorf("Search() = %+v", results)
+	}
+	if got := atomic.LoadInt32(&calls); got != 2 {
+		t.Errorf("calls = %d, want 2", got)
+	}
+}
+
+func TestClient_RateLimitError(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Retry-After", "0")
+		w.WriteHeader(http.StatusTooManyRequests)
+	}))
+	defer srv.Close()
+
+	c := NewClient("key")
+	c.baseURL = srv.URL
+	c.maxRetries = 1
+
+	_, err := c.Search(context.Background(), "game")
+	var rlErr *RateLimitError
+	if !errors.As(err, &rlErr) {
+		t.Fatalf("Search() error = %v, want RateLimitError", err)
+	}
+}
+
+func TestClient_Options(t *testing.T) {
+	httpClient := &http.Client{}
+	c := NewClient("key", WithHTTPClient(httpClient), WithTimeout(5*time.Second), WithBaseURL("http://mirror/api/"), WithMaxRetries(2))
+
+	if c.httpClient == httpClient {
+		t.Error("WithTimeout() should not modify the caller's HTTP client")
+	}
+	if c.httpClient.Timeout != 5*time.Second || httpClient.Timeout != 0 {
+		t.Errorf("timeout = %v, want 5s", c.httpClient.Timeout)
+	}
+	if c.baseURL != "http://mirror/api" || c.maxRetries != 2 {
+		t.Errorf("baseURL = %q, maxRetries = %d", c.baseURL, c.maxRetries)
+	}
+	if NewClient("key").httpClient.Timeout != defaultTimeout {
+		t.Error("NewClient() should set a default timeout")
+	}
+}
+
+func TestClient_ContextCancel(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-r.Context().Done()
+	}))
+	defer srv.Close()
+
+	c := NewClient("key", WithBaseURL(srv.URL))
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	if _, err := c.Search(ctx, "game"); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Search() error = %v, want deadline exceeded", err)
+	}
+}
+
+func TestClient_ValidateKey(t *testing.T) {
+	tests := []struct {
+		name    string
+		status  int
+		wantErr string
+	}{
+		{"valid", http.StatusOK, ""},
+		{"unauthorized", http.StatusUnauthorized, "invalid API key"},
+		{"server error", http.StatusBadGateway, "SteamGridDB is unavailable (HTTP 502), try again later"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.Header.Get("Authorization") != "Bearer key" {
+					t.Errorf("missing auth header")
+				}
+				w.WriteHeader(tt.status)
+				w.Write([]byte(`{"success":true,"data":[]}`))
+			}))
+			defer srv.Close()
+
+			c := NewClient("key")
+			c.baseURL = srv.URL
+
+			err := c.ValidateKey(context.Background())
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("ValidateKey() error = %v", err)
+				}
+			} else if err == nil || err.Error() != tt.wantErr {
+				t.Errorf("ValidateKey() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+
+	if err := NewClient("  ").ValidateKey(context.Background()); err == nil {
+		t.Error("ValidateKey() should fail for empty key")
+	}
+}
@@ -0,0 +1,235 @@
+package steamgriddb
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultFetchWorkers is the default number of concurrent image downloads
+const DefaultFetchWorkers = 6
+
+// maxResumeAttempts bounds how many times an interrupted download is resumed
+const maxResumeAttempts = 3
+
+// fetchCall is an in-flight download shared by every caller of the same URL
+type fetchCall struct {
+	done        chan struct{}
+	data        []byte
+	contentType string
+	err         error
+}
+
+// ImageFetcher downloads images with a bounded number of workers.
+// Concurrent requests for the same URL share a single download, and
+// CancelAll aborts every queued and running download.
+type ImageFetcher struct {
+	httpClient *http.Client
+	cache      *ImageCache
+	sem        chan struct{}
+
+	mu       sync.Mutex
+	inflight map[string]*fetchCall
+	ctx      context.Context
+	cancel   context.CancelFunc
+}
+
+// NewImageFetcher creates a fetcher with the given number of workers.
+// cache may be nil to disable disk caching.
+func NewImageFetcher(cache *ImageCache, workers int) *ImageFetcher {
+	if workers <= 0 {
+		workers = DefaultFetchWorkers
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	return &ImageFetcher{
+		httpClient: &http.Client{Timeout: 30 * time.Second},
+		cache:      cache,
+		sem:        make(chan struct{}, workers),
+		inflight:   make(map[string]*fetchCall),
+		ctx:        ctx,
+		cancel:     cancel,
+	}
+}
+
+// Fetch returns the image data and content type for a URL
+func (f *ImageFetcher) Fetch(url string) ([]byte, string, error) {
+	if f.cache != nil {
+		if data, contentType, ok := f.cache.Get(url); ok {
+			return data, contentType, nil
+		}
+	}
+
+	f.mu.Lock()
+	if call, ok := f.inflight[url]; ok {
+		f.mu.Unlock()
+		<-call.done
+		return call.data, call.contentType, call.err
+	}
+	call := &fetchCall{done: make(chan struct{})}
+	f.inflight[url] = call
+	ctx := f.ctx
+	f.mu.Unlock()
+
+	call.data, call.contentType, call.err = f.download(ctx, url)
+
+	f.mu.Lock()
+	delete(f.inflight, url)
+	f.mu.Unlock()
+	close(call.done)
+
+	if call.err == nil && f.cache != nil {
+		f.cache.Put(url, call.data, call.contentType)
+	}
+
+	return call.data, call.contentType, call.err
+}
+
+// CancelAll aborts all queued and running downloads.
+// Fetches started afterwards are not affected.
+func (f *ImageFetcher) CancelAll() {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.cancel()
+	f.ctx, f.cancel = context.WithCancel(context.Background())
+}
+
+// SetHTTPClient makes later downloads go through httpClient, e.g. to use a proxy
+func (f *ImageFetcher) SetHTTPClient(httpClient *http.Client) {
+	if httpClient == nil {
+		return
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.httpClient = httpClient
+}
+
+// SetWorkers changes the number of concurrent downloads. Downloads already
+// running finish on the previous limit.
+func (f *ImageFetcher) SetWorkers(workers int) {
+	if workers <= 0 {
+		workers = DefaultFetchWorkers
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.sem = make(chan struct{}, workers)
+}
+
+// download waits for a free worker and performs the HTTP request.
+// Interrupted transfers are resumed with a Range request when the server
+// supports it, so large animated images don't restart from zero.
+func (f *ImageFetcher) download(ctx context.Context, url string) ([]byte, string, error) {
+	f.mu.Lock()
+	sem := f.sem
+	f.mu.Unlock()
+	select {
+	case sem <- struct{}{}:
+		defer func() { <-sem }()
+	case <-ctx.Done():
+		return nil, "", ctx.Err()
+	}
+
+	var buf bytes.Buffer
+	var contentType, validator string
+	resumable := false
+
+	for attempt := 0; ; attempt++ {
+		offset := 0
+		if resumable {
+			offset = buf.Len()
+		}
+
+		resp, err := f.get(ctx, url, offset, validator)
+		if err != nil {
+			if resumable && attempt < maxResumeAttempts && ctx.Err() == nil {
+				continue
+			}
+			return nil, "", fmt.Errorf("failed to fetch image: %w", err)
+		}
+
+		switch {
+		case offset > 0 && resp.StatusCode == http.StatusPartialContent && rangeStart(resp) == offset:
+			// Continue where the previous attempt stopped
+		case resp.StatusCode == http.StatusOK:
+			buf.Reset()
+			contentType = resp.Header.Get("Content-Type")
+			validator = resp.Header.Get("ETag")
+			if validator == "" {
+				validator = resp.Header.Get("Last-Modified")
+			}
+			resumable = resp.Header.Get("Accept-Ranges") == "bytes"
+		case resp.StatusCode == http.StatusPartialContent && attempt < maxResumeAttempts:
+			// Not the range that was asked for, download the whole image again
+			resp.Body.Close()
+			buf.Reset()
+			resumable = false
+			continue
+		default:
+			resp.Body.Close()
+			return nil, "", fmt.Errorf("HTTP error: %d", resp.StatusCode)
+		}
+
+		_, err = io.Copy(&buf, resp.Body)
+		resp.Body.Close()
+		if err == nil {
+			break
+		}
+		if !resumable || attempt >= maxResumeAttempts || ctx.Err() != nil {
+			return nil, "", fmt.Errorf("failed to read image: %w", err)
+		}
+	}
+
+	if contentType == "" {
+		contentType = contentTypeFromURL(url)
+	}
+
+	return buf.Bytes(), contentType, nil
+}
+
+// get requests url, asking for the bytes from offset on when offset > 0.
+// validator (ETag or Last-Modified) makes the server send the whole image
+// again if it changed since the first attempt.
+func (f *ImageFetcher) get(ctx context.Context, url string, offset int, validator string) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+	if err != nil {
+		return nil, err
+	}
+	if offset > 0 {
+		req.Header.Set("Range", fmt.Sprintf("bytes=%d-", offset))
+		if validator != "" {
+			req.Header.Set("If-Range", validator)
+		}
+	}
+	f.mu.Lock()
+	client := f.httpClient
+	f.mu.Unlock()
+	return client.Do(req)
+}
+
+// rangeStart returns the first byte of a partial response, or -1 if unknown
+func rangeStart(resp *http.Response) int {
+	var start, end, total int
+	if _, err := fmt.Sscanf(resp.Header.Get("Content-Range"), "bytes %d-%d/%d", &start, &end, &total); err != nil {
+		return -1
+	}
+	return start
+}
+
+// contentTypeFromURL guesses the MIME type from the URL extension
+func contentTypeFromURL(url string) string {
+	lower := strings.ToLower(url)
+	switch {
+	case strings.HasSuffix(lower, ".png"):
+		return "image/png"
+	case strings.HasSuffix(lower, ".webp"):
+		return "image/webp"
+	case strings.HasSuffix(lower, ".gif"):
+		return "image/gif"
+	default:
+		return "image/jpeg"
+	}
+}
@@ -0,0 +1,154 @@
+package steamgriddb
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestImageFetcher_Dedup(t *testing.T) {
+	var hits int32
+	release := make(chan struct{})
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&hits, 1)
+		<-release
+		w.Header().Set("Content-Type", "image/png")
+		w.Write([]byte("img"))
+	}))
+	defer srv.Close()
+
+	f := NewImageFetcher(nil, 2)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 5; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			data, contentType, err := f.Fetch(srv.URL + "/a.png")
+			if err != nil || string(data) != "img" || contentType != "image/png" {
+				t.Errorf("Fetch() = %q, %q, %v", data, contentType, err)
+			}
+		}()
+	}
+
+	// Give the goroutines time to join the in-flight download
+	time.Sleep(50 * time.Millisecond)
+	close(release)
+	wg.Wait()
+
+	if got := atomic.LoadInt32(&hits); got != 1 {
+		t.Errorf("server hits = %d, want 1", got)
+	}
+}
+
+func TestImageFetcher_CancelAll(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-r.Context().Done()
+	}))
+	defer srv.Close()
+
+	f := NewImageFetcher(nil, 1)
+
+	errs := make(chan error, 2)
+	for _, path := range []string{"/running.png", "/queued.png"} {
+		go func(path string) {
+			_, _, err := f.Fetch(srv.URL + path)
+			errs <- err
+		}(path)
+	}
+
+	time.Sleep(50 * time.Millisecond)
+	f.CancelAll()
+
+	for i := 0; i < 2; i++ {
+		select {
+		case err := <-errs:
+			if err == nil {
+				t.Error("Fetch() should fail after CancelAll()")
+			}
+		case <-time.After(2 * time.Second):
+			t.Fatal("Fetch() did not return after CancelAll()")
+		}
+	}
+}
+
+func TestImageFetcher_Resume(t *testing.T) {
+	body := []byte("0123456789abcdefghij")
+	var ranges []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ranges = append(ranges, r.Header.Get("Range"))
+		w.Header().Set("Content-Type", "image/webp")
+		w.Header().Set("Accept-Ranges", "bytes")
+		w.Header().Set("ETag", `"v1"`)
+
+		if r.Header.Get("Range") == "" {
+			// Drop the connection halfway through the first attempt
+			w.Header().Set("Content-Length", strconv.Itoa(len(body)))
+			w.Write(body[:8])
+			return
+		}
+
+		var start int
+		fmt.Sscanf(r.Header.Get("Range"), "bytes=%d-", &start)
+		if r.Header.Get("If-Range") != `"v1"` {
+			t.Errorf("If-Range = %q, want the ETag", r.Header.Get("If-Range"))
+		}
+		w.Header().Set("Content-Range", fmt.Sprintf("bytes %d-%d/%d", start, len(body)-1, len(body)))
+		w.WriteHeader(http.StatusPartialContent)
+		w.Write(body[start:])
+	}))
+	defer srv.Close()
+
+	f := NewImageFetcher(nil, 1)
+	data, contentType, err := f.Fetch(srv.URL + "/anim.webp")
+	if err != nil {
+		t.Fatalf("Fetch() error = %v", err)
+	}
+	if string(data) != string(body) || contentType != "image/webp" {
+		t.Errorf("Fetch() = %q, %q", data, contentType)
+	}
+	if len(ranges) != 2 || ranges[1] != "bytes=8-" {
+		t.Errorf("ranges = %q, want a resume from byte 8", ranges)
+	}
+}
+
+func TestImageFetcher_NoResumeWithoutRanges(t *testing.T) {
+	var hits int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&hits, 1)
+		w.Header().Set("Content-Length", "20")
+		w.Write([]byte("partial"))
+	}))
+	defer srv.Close()
+
+	f := NewImageFetcher(nil, 1)
+	if _, _, err := f.Fetch(srv.URL + "/a.png"); err == nil {
+		t.Error("Fetch() should fail on a truncated response")
+	}
+	if got := atomic.LoadInt32(&hits); got != 1 {
+		t.Errorf("server hits = %d, want 1", got)
+	}
+}
+
+func TestContentTypeFromURL(t *testing.T) {
+	tests := []struct {
+		url  string
+		want string
+	}{
+		{"https://cdn/a.PNG", "image/png"},
+		{"https://cdn/a.webp", "image/webp"},
+		{"https://cdn/a.gif", "image/gif"},
+		{"https://cdn/a.jpg", "image/jpeg"},
+	}
+
+	for _, tt := range tests {
+		if got := contentTypeFromURL(tt.url); got != tt.want {
+			t.Errorf("contentTypeFromURL(%q) = %q, want %q", tt.url, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,151 @@
+package steamgriddb
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// steamCDNBaseURL is the base URL for official Steam store/library assets
+const steamCDNBaseURL = "https://cdn.cloudflare.steamstatic.com/steam/apps"
+
+// SourceSteam marks images that come from Steam's CDN instead of SteamGridDB
+const SourceSteam = "steam"
+
+// OfficialArtwork holds the official Steam artwork available for an app
+type OfficialArtwork struct {
+	AppID    int         `json:"appId"`
+	Capsules []GridData  `json:"capsules"` // library_600x900
+	Wide     []GridData  `json:"wide"`     // header (460x215)
+	Heroes   []ImageData `json:"heroes"`   // library_hero
+	Logos    []ImageData `json:"logos"`    // logo
+}
+
+// SteamAppID returns the Steam AppID linked to the game, or 0 if there is none
+func (g *GameDetails) SteamAppID() int {
+	for _, p := range g.ExternalPlatformData["steam"] {
+		if id, err := strconv.Atoi(p.ID); err == nil && id > 0 {
+			return id
+		}
+	}
+	return 0
+}
+
+// officialAsset describes a single well-known Steam CDN asset
+type officialAsset struct {
+	file   string
+	width  int
+	height int
+	mime   string
+}
+
+var (
+	officialCapsule = officialAsset{"library_600x900.jpg", 600, 900, "image/jpeg"}
+	officialHeader  = officialAsset{"header.jpg", 460, 215, "image/jpeg"}
+	officialHero    = officialAsset{"library_hero.jpg", 1920, 620, "image/jpeg"}
+	officialLogo    = officialAsset{"logo.png", 640, 360, "image/png"}
+)
+
+// OfficialAssetURL returns the Steam CDN URL of an asset file for an app
+func OfficialAssetURL(appID int, file string) string {
+	return fmt.Sprintf("%s/%d/%s", steamCDNBaseURL, appID, file)
+}
+
+// GetOfficialArtwork returns the official Steam artwork for a SteamGridDB game.
+// Returns an empty result (AppID 0) if the game is not linked to a Steam app.
+// Assets missing on the CDN are omitted.
+func (c *Client) GetOfficialArtwork(ctx context.Context, gameID int) (*OfficialArtwork, error) {
+	game, err := c.GetGame(ctx, gameID)
+	if err != nil {
+		return nil, err
+	}
+
+	appID := game.SteamAppID()
+	if appID == 0 {
+		return &OfficialArtwork{}, nil
+	}
+
+	return FetchOfficialArtwork(ctx, c.httpClient, appID), nil
+}
+
+// FetchOfficialArtwork checks which official assets exist on the Steam CDN for an app
+func FetchOfficialArtwork(ctx context.Context, httpClient *http.Client, appID int) *OfficialArtwork {
+	assets := []officialAsset{officialCapsule, officialHeader, officialHero, officialLogo}
+	available := make([]bool, len(assets))
+
+	var wg sync.WaitGroup
+	for i, asset := range assets {
+		wg.Add(1)
+		go func(i int, asset officialAsset) {
+			defer wg.Done()
+			available[i] = assetExists(ctx, httpClient, OfficialAssetURL(appID, asset.file))
+		}(i, asset)
+	}
+	wg.Wait()
+
+	result := &OfficialArtwork{AppID: appID}
+	for i, asset := range assets {
+		if !available[i] {
+			continue
+		}
+		url := OfficialAssetURL(appID, asset.file)
+		switch asset {
+		case officialCapsule:
+			result.Capsules = append(result.Capsules, officialGrid(url, asset))
+		case officialHeader:
+			result.Wide = append(result.Wide, officialGrid(url, asset))
+		case officialHero:
+			result.Heroes = append(result.Heroes, officialImage(url, asset))
+		case officialLogo:
+			result.Logos = append(result.Logos, officialImage(url, asset))
+		}
+	}
+
+	return result
+}
+
+func officialGrid(url string, asset officialAsset) GridData {
+	return GridData{
+		Style:  "official",
+		Width:  asset.width,
+		Height: asset.height,
+		Mime:   asset.mime,
+		URL:    url,
+		Thumb:  url,
+		Source: SourceSteam,
+	}
+}
+
+func officialImage(url string, asset officialAsset) ImageData {
+	return ImageData{
+		Style:  "official",
+		Width:  asset.width,
+		Height: asset.height,
+		Mime:   asset.mime,
+		URL:    url,
+		Thumb:  url,
+		Source: SourceSteam,
+	}
+}
+
+// assetExists performs a HEAD request to check if a CDN asset exists
+func assetExists(ctx context.Context, httpClient *http.Client, url string) bool {
+	client := *httpClient
+	if client.Timeout == 0 || client.Timeout > 10*time.Second {
+		client.Timeout = 10 * time.Second
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "HEAD", url, nil)
+	if err != nil {
+		return false
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return false
+	}
+	resp.Body.Close()
+	return resp.StatusCode == http.StatusOK
+}
@@ -0,0 +1,32 @@
+package steamgriddb
+
+import "testing"
+
+func TestGameDetails_SteamAppID(t *testing.T) {
+	tests := []struct {
+		name string
+		data map[string][]PlatformData
+		want int
+	}{
+		{"no platform data", nil, 0},
+		{"no steam entry", map[string][]PlatformData{"gog": {{ID: "123"}}}, 0},
+		{"steam entry", map[string][]PlatformData{"steam": {{ID: "620"}}}, 620},
+		{"invalid id skipped", map[string][]PlatformData{"steam": {{ID: "abc"}, {ID: "440"}}}, 440},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := &GameDetails{ExternalPlatformData: tt.data}
+			if got := g.SteamAppID(); got != tt.want {
+				t.Errorf("SteamAppID() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestOfficialAssetURL(t *testing.T) {
+	want := "https://cdn.cloudflare.steamstatic.com/steam/apps/620/library_600x900.jpg"
+	if got := OfficialAssetURL(620, "library_600x900.jpg"); got != want {
+		t.Errorf("OfficialAssetURL() = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,179 @@
+package steamgriddb
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// GameSnapshot holds every result seen for a game while browsing online,
+// so the artwork can be browsed again without network access
+type GameSnapshot struct {
+	Game      SearchResult `json:"game"`
+	Grids     []GridData   `json:"grids"`
+	Heroes    []ImageData  `json:"heroes"`
+	Logos     []ImageData  `json:"logos"`
+	Icons     []ImageData  `json:"icons"`
+	UpdatedAt time.Time    `json:"updated_at"`
+}
+
+// ResultStore persists browse results per game as JSON files
+type ResultStore struct {
+	dir string
+	mu  sync.Mutex
+}
+
+// NewResultStore creates a result store in dir
+func NewResultStore(dir string) (*ResultStore, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, err
+	}
+	return &ResultStore{dir: dir}, nil
+}
+
+// GetResultsCacheDir returns the path to the browse results directory
+func GetResultsCacheDir() (string, error) {
+	imagesDir, err := GetImageCacheDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(filepath.Dir(imagesDir), "results"), nil
+}
+
+func (s *ResultStore) path(gameID int) string {
+	return filepath.Join(s.dir, fmt.Sprintf("%d.json", gameID))
+}
+
+// Get returns the snapshot of a game, or nil if nothing was stored for it
+func (s *ResultStore) Get(gameID int) (*GameSnapshot, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.read(gameID)
+}
+
+func (s *ResultStore) read(gameID int) (*GameSnapshot, error) {
+	data, err := os.ReadFile(s.path(gameID))
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var snap GameSnapshot
+	if err := json.Unmarshal(data, &snap); err != nil {
+		return nil, err
+	}
+	return &snap, nil
+}
+
+// Merge adds the results in update to the stored snapshot of its game.
+// Images are deduplicated by URL, an empty game name keeps the stored one.
+func (s *ResultStore) Merge(update GameSnapshot) error {
+	if update.Game.ID == 0 {
+		return fmt.Errorf("missing game ID")
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	snap, err := s.read(update.Game.ID)
+	if err != nil || snap == nil {
+		snap = &GameSnapshot{Game: SearchResult{ID: update.Game.ID}}
+	}
+	if update.Game.Name != "" {
+		snap.Game = update.Game
+	}
+
+	snap.Grids = mergeGrids(snap.Grids, update.Grids)
+	snap.Heroes = mergeImages(snap.Heroes, update.Heroes)
+	snap.Logos = mergeImages(snap.Logos, update.Logos)
+	snap.Icons = mergeImages(snap.Icons, update.Icons)
+	snap.UpdatedAt = time.Now()
+
+	data, err := json.Marshal(snap)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(s.path(update.Game.ID), data, 0644)
+}
+
+// List returns all stored snapshots, most recently updated first
+func (s *ResultStore) List() ([]GameSnapshot, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	entries, err := os.ReadDir(s.dir)
+	if err != nil {
+		return nil, err
+	}
+
+	var snaps []GameSnapshot
+	for _, entry := range entries {
+		var id int
+		if _, err := fmt.Sscanf(entry.Name(), "%d.json", &id); err != nil {
+			continue
+		}
+		if snap, err := s.read(id); err == nil && snap != nil {
+			snaps = append(snaps, *snap)
+		}
+	}
+
+	sort.Slice(snaps, func(i, j int) bool {
+		return snaps[i].UpdatedAt.After(snaps[j].UpdatedAt)
+	})
+	return snaps, nil
+}
+
+// Search returns stored games whose name contains term (case-insensitive)
+func (s *ResultStore) Search(term string) ([]SearchResult, error) {
+	snaps, err := s.List()
+	if err != nil {
+		return nil, err
+	}
+
+	term = strings.ToLower(strings.TrimSpace(term))
+	results := []SearchResult{}
+	for _, snap := range snaps {
+		if snap.Game.Name == "" || !strings.Contains(strings.ToLower(snap.Game.Name), term) {
+			continue
+		}
+		results = append(results, snap.Game)
+	}
+	return results, nil
+}
+
+func mergeGrids(existing, added []GridData) []GridData {
+	seen := make(map[string]bool, len(existing))
+	for _, g := range existing {
+		seen[g.URL] = true
+	}
+	for _, g := range added {
+		if g.URL == "" || seen[g.URL] {
+			continue
+		}
+		seen[g.URL] = true
+		existing = append(existing, g)
+	}
+	return existing
+}
+
+func mergeImages(existing, added []ImageData) []ImageData {
+	seen := make(map[string]bool, len(existing))
+	for _, img := range existing {
+		seen[img.URL] = true
+	}
+	for _, img := range added {
+		if img.URL == "" || seen[img.URL] {
+			continue
+		}
+		seen[img.URL] = true
+		existing = append(existing, img)
+	}
+	return existing
+}
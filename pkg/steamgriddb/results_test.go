@@ -0,0 +1,72 @@
+package steamgriddb
+
+import "testing"
+
+func TestResultStore_Merge(t *testing.T) {
+	store, err := NewResultStore(t.TempDir())
+	if err != nil {
+		t.Fatalf("NewResultStore() error = %v", err)
+	}
+
+	if err := store.Merge(GameSnapshot{
+		Game:  SearchResult{ID: 1, Name: "Hollow Game"},
+		Grids: []GridData{{URL: "https://cdn/g1.png"}},
+	}); err != nil {
+		t.Fatalf("Merge() error = %v", err)
+	}
+
+	// A later page without name adds new images and keeps the name
+	if err := store.Merge(GameSnapshot{
+		Game:   SearchResult{ID: 1},
+		Grids:  []GridData{{URL: "https://cdn/g1.png"}, {URL: "https://cdn/g2.png"}},
+		Heroes: []ImageData{{URL: "https://cdn/h1.png"}},
+	}); err != nil {
+		t.Fatalf("Merge() error = %v", err)
+	}
+
+	snap, err := store.Get(1)
+	if err != nil || snap == nil {
+		t.Fatalf("Get() = %v, %v", snap, err)
+	}
+	if snap.Game.Name != "Hollow Game" {
+		t.Errorf("Game.Name = %q, want %q", snap.Game.Name, "Hollow Game")
+	}
+	if len(snap.Grids) != 2 || len(snap.Heroes) != 1 {
+		t.Errorf("Grids = %d, Heroes = %d, want 2 and 1", len(snap.Grids), len(snap.Heroes))
+	}
+
+	if err := store.Merge(GameSnapshot{}); err == nil {
+		t.Error("Merge() without game ID should fail")
+	}
+}
+
+func TestResultStore_Search(t *testing.T) {
+	store, _ := NewResultStore(t.TempDir())
+	store.Merge(GameSnapshot{Game: SearchResult{ID: 1, Name: "Hollow Game"}})
+	store.Merge(GameSnapshot{Game: SearchResult{ID: 2, Name: "Other"}})
+
+	results, err := store.Search("hollow")
+	if err != nil {
+		t.Fatalf("Search() error = %v", err)
+	}
+	if len(results) != 1 || results[0].ID != 1 {
+		t.Errorf("Search() = %+v, want game 1", results)
+	}
+
+	if snap, err := store.Get(99); err != nil || snap != nil {
+		t.Errorf("Get(99) = %v, %v, want nil, nil", snap, err)
+	}
+}
+
+func TestImageCache_Has(t *testing.T) {
+	cache, _ := NewImageCache(t.TempDir(), 0, 0)
+	url := "https://cdn/a.png"
+
+	if cache.Has(url) {
+		t.Error("Has() = true before Put")
+	}
+	cache.Put(url, []byte("png"), "image/png")
+	if !cache.Has(url) {
+		t.Error("Has() = false after Put")
+	}
+}
@@ -0,0 +1,50 @@
+package steamgriddb
+
+import "strings"
+
+// BestMatch picks the most likely game for a name from search results:
+// an exact (case-insensitive) name match first, then a verified game, then the first result
+func BestMatch(results []SearchResult, name string) *SearchResult {
+	if len(results) == 0 {
+		return nil
+	}
+	for i, r := range results {
+		if strings.EqualFold(strings.TrimSpace(r.Name), strings.TrimSpace(name)) {
+			return &results[i]
+		}
+	}
+	for i, r := range results {
+		if r.Verified {
+			return &results[i]
+		}
+	}
+	return &results[0]
+}
+
+// BestGrid returns the highest scored grid with the requested orientation, or nil
+func BestGrid(grids []GridData, portrait bool) *GridData {
+	var best *GridData
+	for i, g := range grids {
+		if (g.Height > g.Width) != portrait || g.Nsfw {
+			continue
+		}
+		if best == nil || g.Score > best.Score {
+			best = &grids[i]
+		}
+	}
+	return best
+}
+
+// BestImage returns the highest scored non-NSFW image, or nil
+func BestImage(images []ImageData) *ImageData {
+	var best *ImageData
+	for i, img := range images {
+		if img.Nsfw {
+			continue
+		}
+		if best == nil || img.Score > best.Score {
+			best = &images[i]
+		}
+	}
+	return best
+}
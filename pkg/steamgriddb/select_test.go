@@ -0,0 +1,61 @@
+package steamgriddb
+
+import "testing"
+
+func TestBestMatch(t *testing.T) {
+	results := []SearchResult{
+		{ID: 1, Name: "Portal 2 Demo"},
+		{ID: 2, Name: "Portal Stories", Verified: true},
+		{ID: 3, Name: "portal"},
+	}
+
+	tests := []struct {
+		name   string
+		query  string
+		want   int
+		result []SearchResult
+	}{
+		{"exact match", "Portal", 3, results},
+		{"verified fallback", "Portal 3", 2, results},
+		{"first fallback", "x", 1, results[:1]},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := BestMatch(tt.result, tt.query)
+			if got == nil || got.ID != tt.want {
+				t.Errorf("BestMatch() = %+v, want ID %d", got, tt.want)
+			}
+		})
+	}
+
+	if BestMatch(nil, "x") != nil {
+		t.Error("BestMatch(nil) should be nil")
+	}
+}
+
+func TestBestGrid(t *testing.T) {
+	grids := []GridData{
+		{ID: 1, Width: 600, Height: 900, Score: 5},
+		{ID: 2, Width: 600, Height: 900, Score: 9, Nsfw: true},
+		{ID: 3, Width: 600, Height: 900, Score: 7},
+		{ID: 4, Width: 920, Height: 430, Score: 3},
+	}
+
+	if got := BestGrid(grids, true); got == nil || got.ID != 3 {
+		t.Errorf("BestGrid(portrait) = %+v, want ID 3", got)
+	}
+	if got := BestGrid(grids, false); got == nil || got.ID != 4 {
+		t.Errorf("BestGrid(landscape) = %+v, want ID 4", got)
+	}
+	if got := BestGrid(nil, true); got != nil {
+		t.Errorf("BestGrid(nil) = %+v, want nil", got)
+	}
+}
+
+func TestBestImage(t *testing.T) {
+	images := []ImageData{{ID: 1, Score: 2}, {ID: 2, Score: 4}, {ID: 3, Score: 8, Nsfw: true}}
+	if got := BestImage(images); got == nil || got.ID != 2 {
+		t.Errorf("BestImage() = %+v, want ID 2", got)
+	}
+}
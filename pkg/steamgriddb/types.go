@@ -9,42 +9,66 @@ type SearchResult struct {
 	Verified bool     `json:"verified"`
 }
 
+// Author is the SteamGridDB user who uploaded an image
+type Author struct {
+	Name    string `json:"name"`
+	Steam64 string `json:"steam64"`
+	Avatar  string `json:"avatar"`
+}
+
 // GridData represents a grid image
 type GridData struct {
-	ID        int    `json:"id"`
-	Score     int    `json:"score"`
-	Style     string `json:"style"`
-	Width     int    `json:"width"`
-	Height    int    `json:"height"`
-	Nsfw      bool   `json:"nsfw"`
-	Humor     bool   `json:"humor"`
-	Mime      string `json:"mime"`
-	Language  string `json:"language"`
-	URL       string `json:"url"`
-	Thumb     string `json:"thumb"`
-	Lock      bool   `json:"lock"`
-	Epilepsy  bool   `json:"epilepsy"`
-	Upvotes   int    `json:"upvotes"`
-	Downvotes int    `json:"downvotes"`
+	ID        int     `json:"id"`
+	Score     int     `json:"score"`
+	Style     string  `json:"style"`
+	Width     int     `json:"width"`
+	Height    int     `json:"height"`
+	Nsfw      bool    `json:"nsfw"`
+	Humor     bool    `json:"humor"`
+	Mime      string  `json:"mime"`
+	Language  string  `json:"language"`
+	URL       string  `json:"url"`
+	Thumb     string  `json:"thumb"`
+	Lock      bool    `json:"lock"`
+	Epilepsy  bool    `json:"epilepsy"`
+	Upvotes   int     `json:"upvotes"`
+	Downvotes int     `json:"downvotes"`
+	Author    *Author `json:"author,omitempty"`
+	Source    string  `json:"source,omitempty"` // "" for SteamGridDB, "steam" for official CDN art
 }
 
 // ImageData represents a hero/logo/icon image
 type ImageData struct {
-	ID        int    `json:"id"`
-	Score     int    `json:"score"`
-	Style     string `json:"style"`
-	Width     int    `json:"width"`
-	Height    int    `json:"height"`
-	Nsfw      bool   `json:"nsfw"`
-	Humor     bool   `json:"humor"`
-	Mime      string `json:"mime"`
-	Language  string `json:"language"`
-	URL       string `json:"url"`
-	Thumb     string `json:"thumb"`
-	Lock      bool   `json:"lock"`
-	Epilepsy  bool   `json:"epilepsy"`
-	Upvotes   int    `json:"upvotes"`
-	Downvotes int    `json:"downvotes"`
+	ID        int     `json:"id"`
+	Score     int     `json:"score"`
+	Style     string  `json:"style"`
+	Width     int     `json:"width"`
+	Height    int     `json:"height"`
+	Nsfw      bool    `json:"nsfw"`
+	Humor     bool    `json:"humor"`
+	Mime      string  `json:"mime"`
+	Language  string  `json:"language"`
+	URL       string  `json:"url"`
+	Thumb     string  `json:"thumb"`
+	Lock      bool    `json:"lock"`
+	Epilepsy  bool    `json:"epilepsy"`
+	Upvotes   int     `json:"upvotes"`
+	Downvotes int     `json:"downvotes"`
+	Author    *Author `json:"author,omitempty"`
+	Source    string  `json:"source,omitempty"` // "" for SteamGridDB, "steam" for official CDN art
+}
+
+// GameDetails represents a game with its external platform IDs
+type GameDetails struct {
+	ID                   int                       `json:"id"`
+	Name                 string                    `json:"name"`
+	Verified             bool                      `json:"verified"`
+	ExternalPlatformData map[string][]PlatformData `json:"external_platform_data"`
+}
+
+// PlatformData represents a game's entry on an external platform
+type PlatformData struct {
+	ID string `json:"id"`
 }
 
 // ImageFilters represents filters for image queries
@@ -57,12 +81,39 @@ type ImageFilters struct {
 	ShowHumor bool   `json:"showHumor"`
 }
 
+// PageInfo describes a page of image results. Pages are 0-based.
+type PageInfo struct {
+	Page       int `json:"page"`
+	Limit      int `json:"limit"`
+	Total      int `json:"total"`
+	TotalPages int `json:"totalPages"`
+}
+
+// GridPage is a page of grid images
+type GridPage struct {
+	Items  []GridData `json:"items"`
+	Paging PageInfo   `json:"paging"`
+}
+
+// ImagePage is a page of hero/logo/icon images
+type ImagePage struct {
+	Items  []ImageData `json:"items"`
+	Paging PageInfo    `json:"paging"`
+}
+
 // API response types
 type apiResponse struct {
 	Success bool     `json:"success"`
 	Errors  []string `json:"errors"`
 }
 
+// pagingResponse holds the paging metadata returned by image endpoints
+type pagingResponse struct {
+	Page  int `json:"page"`
+	Total int `json:"total"`
+	Limit int `json:"limit"`
+}
+
 type searchResponse struct {
 	apiResponse
 	Data []SearchResult `json:"data"`
@@ -70,10 +121,17 @@ type searchResponse struct {
 
 type gridResponse struct {
 	apiResponse
+	pagingResponse
 	Data []GridData `json:"data"`
 }
 
 type imageResponse struct {
 	apiResponse
+	pagingResponse
 	Data []ImageData `json:"data"`
 }
+
+type gameResponse struct {
+	apiResponse
+	Data GameDetails `json:"data"`
+}
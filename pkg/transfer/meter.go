@@ -0,0 +1,62 @@
+package transfer
+
+import (
+	"sync/atomic"
+	"time"
+)
+
+// Stats summarizes a running upload for progress displays.
+type Stats struct {
+	SentBytes      int64   `json:"sentBytes"`
+	TotalBytes     int64   `json:"totalBytes"`
+	BytesPerSecond float64 `json:"bytesPerSecond"`
+	ETASeconds     float64 `json:"etaSeconds"` // 0 until the speed is known
+}
+
+// StatsCallback receives the stats of an upload as it progresses.
+type StatsCallback func(stats Stats)
+
+// Meter measures the speed and remaining time of an upload from the bytes
+// sent. It is safe for concurrent use, so parallel uploads can share one.
+type Meter struct {
+	total int64
+	sent  atomic.Int64
+	speed *SpeedCalculator
+}
+
+// NewMeter creates a meter for an upload of totalBytes.
+func NewMeter(totalBytes int64) *Meter {
+	return &Meter{
+		total: totalBytes,
+		speed: NewSpeedCalculator(5*time.Second, 200),
+	}
+}
+
+// Add records n more bytes sent.
+func (m *Meter) Add(n int64) {
+	if n <= 0 {
+		return
+	}
+	m.sent.Add(n)
+	m.speed.AddSample(n)
+}
+
+// SetSent records the bytes sent so far, for transfers that only report a
+// running total.
+func (m *Meter) SetSent(sent int64) {
+	m.Add(sent - m.sent.Load())
+}
+
+// Stats returns the current stats of the upload.
+func (m *Meter) Stats() Stats {
+	sent := m.sent.Load()
+	stats := Stats{
+		SentBytes:      sent,
+		TotalBytes:     m.total,
+		BytesPerSecond: m.speed.BytesPerSecond(),
+	}
+	if remaining := m.total - sent; remaining > 0 && stats.BytesPerSecond > 0 {
+		stats.ETASeconds = float64(remaining) / stats.BytesPerSecond
+	}
+	return stats
+}
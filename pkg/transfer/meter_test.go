@@ -0,0 +1,39 @@
+package transfer
+
+import (
+	"testing"
+	"time"
+)
+
+func TestMeter_Stats(t *testing.T) {
+	m := NewMeter(10000)
+
+	stats := m.Stats()
+	if stats.SentBytes != 0 || stats.TotalBytes != 10000 || stats.ETASeconds != 0 {
+		t.Errorf("Stats() before sending = %+v", stats)
+	}
+
+	m.Add(1000)
+	time.Sleep(100 * time.Millisecond)
+	m.Add(1000)
+
+	stats = m.Stats()
+	if stats.SentBytes != 2000 {
+		t.Errorf("SentBytes = %d, want 2000", stats.SentBytes)
+	}
+	if stats.BytesPerSecond <= 0 || stats.ETASeconds <= 0 {
+		t.Errorf("Stats() = %+v, want a speed and an ETA", stats)
+	}
+}
+
+func TestMeter_SetSent(t *testing.T) {
+	m := NewMeter(5000)
+	m.SetSent(3000)
+	m.SetSent(3000)
+	m.SetSent(5000)
+
+	stats := m.Stats()
+	if stats.SentBytes != 5000 || stats.ETASeconds != 0 {
+		t.Errorf("Stats() = %+v, want 5000 bytes sent and no time left", stats)
+	}
+}
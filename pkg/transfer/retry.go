@@ -0,0 +1,68 @@
+package transfer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync/atomic"
+	"time"
+)
+
+// ErrTooManyRetries is wrapped by the error of a transfer that used up the
+// retries of the whole transfer.
+var ErrTooManyRetries = errors.New("too many transfer errors")
+
+// RetryPolicy sets how failed file transfers are retried.
+type RetryPolicy struct {
+	Retries   int           // retries of each file
+	Budget    int           // retries of the whole transfer
+	BaseDelay time.Duration // wait before the first retry, doubled after each
+	MaxDelay  time.Duration // longest wait between retries
+}
+
+// Delay returns how long to wait before a retry, counted from 0.
+func (p RetryPolicy) Delay(retry int) time.Duration {
+	delay := p.BaseDelay
+	for i := 0; i < retry && delay < p.MaxDelay; i++ {
+		delay *= 2
+	}
+	return min(delay, p.MaxDelay)
+}
+
+// Retrier retries failed file transfers with exponential backoff. The retries
+// of all files share a budget, so a connection that keeps failing stops the
+// transfer instead of retrying every file. It is safe for concurrent use.
+type Retrier struct {
+	policy RetryPolicy
+	left   atomic.Int64
+}
+
+// NewRetrier creates a retrier for one transfer.
+func NewRetrier(policy RetryPolicy) *Retrier {
+	r := &Retrier{policy: policy}
+	r.left.Store(int64(policy.Budget))
+	return r
+}
+
+// Do calls fn until it succeeds and returns how many times it was retried.
+// It gives up with the last error once the file or the transfer is out of
+// retries, or ctx is done.
+func (r *Retrier) Do(ctx context.Context, fn func() error) (int, error) {
+	for retry := 0; ; retry++ {
+		err := fn()
+		if err == nil || ctx.Err() != nil || retry >= r.policy.Retries {
+			return retry, err
+		}
+		if r.left.Add(-1) < 0 {
+			return retry, fmt.Errorf("%w: %w", ErrTooManyRetries, err)
+		}
+
+		timer := time.NewTimer(r.policy.Delay(retry))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return retry, err
+		case <-timer.C:
+		}
+	}
+}
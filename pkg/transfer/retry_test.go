@@ -0,0 +1,74 @@
+package transfer
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestRetryPolicy_Delay(t *testing.T) {
+	policy := RetryPolicy{BaseDelay: time.Second, MaxDelay: 5 * time.Second}
+	want := []time.Duration{time.Second, 2 * time.Second, 4 * time.Second, 5 * time.Second, 5 * time.Second}
+	for retry, w := range want {
+		if got := policy.Delay(retry); got != w {
+			t.Errorf("Delay(%d) = %v, want %v", retry, got, w)
+		}
+	}
+}
+
+func TestRetrier_Do(t *testing.T) {
+	errTransient := errors.New("connection reset")
+	r := NewRetrier(RetryPolicy{Retries: 3, Budget: 10, BaseDelay: time.Millisecond, MaxDelay: time.Millisecond})
+
+	calls := 0
+	retries, err := r.Do(context.Background(), func() error {
+		calls++
+		if calls < 3 {
+			return errTransient
+		}
+		return nil
+	})
+	if err != nil || retries != 2 {
+		t.Errorf("Do() = %d, %v, want 2 retries and no error", retries, err)
+	}
+
+	calls = 0
+	retries, err = r.Do(context.Background(), func() error {
+		calls++
+		return errTransient
+	})
+	if !errors.Is(err, errTransient) || retries != 3 || calls != 4 {
+		t.Errorf("Do() = %d, %v after %d calls, want 3 retries and the last error", retries, err, calls)
+	}
+}
+
+func TestRetrier_Budget(t *testing.T) {
+	errTransient := errors.New("connection reset")
+	r := NewRetrier(RetryPolicy{Retries: 5, Budget: 2, BaseDelay: time.Millisecond, MaxDelay: time.Millisecond})
+
+	_, err := r.Do(context.Background(), func() error { return errTransient })
+	if !errors.Is(err, ErrTooManyRetries) || !errors.Is(err, errTransient) {
+		t.Errorf("Do() error = %v, want ErrTooManyRetries wrapping the last error", err)
+	}
+
+	// The budget is spent, later files fail on their first error
+	retries, err := r.Do(context.Background(), func() error { return errTransient })
+	if !errors.Is(err, ErrTooManyRetries) || retries != 0 {
+		t.Errorf("Do() = %d, %v, want no retries once the budget is spent", retries, err)
+	}
+}
+
+func TestRetrier_Cancel(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	r := NewRetrier(RetryPolicy{Retries: 5, Budget: 5, BaseDelay: time.Hour, MaxDelay: time.Hour})
+
+	go func() {
+		time.Sleep(10 * time.Millisecond)
+		cancel()
+	}()
+	retries, err := r.Do(ctx, func() error { return errors.New("timeout") })
+	if err == nil || retries != 0 {
+		t.Errorf("Do() = %d, %v, want to stop waiting when cancelled", retries, err)
+	}
+}